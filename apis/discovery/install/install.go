@@ -30,7 +30,7 @@ func Install(groupFactoryRegistry announced.APIGroupFactoryRegistry, registry *r
 	if err := announced.NewGroupMetaFactory(
 		&announced.GroupMetaFactoryArgs{
 			GroupName:                  discovery.GroupName,
-			RootScopedKinds:            sets.NewString("Ping", "JoinCluster"),
+			RootScopedKinds:            sets.NewString("Ping", "Member", "Plan", "Migration", "Upgrade", "Quarantine", "Scale", "EtcdCluster", "Certificate", "Rotation", "Backup", "Restore", "Removal"),
 			VersionPreferenceOrder:     []string{v1alpha1.SchemeGroupVersion.Version},
 			AddInternalObjectsToScheme: discovery.AddToScheme,
 		},
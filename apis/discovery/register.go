@@ -46,6 +46,18 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&Ping{},
 		&Member{},
+		&Plan{},
+		&Migration{},
+		&Upgrade{},
+		&Quarantine{},
+		&Scale{},
+		&EtcdCluster{},
+		&EtcdClusterList{},
+		&Certificate{},
+		&Rotation{},
+		&Backup{},
+		&Restore{},
+		&Removal{},
 	)
 	return nil
 }
@@ -24,10 +24,16 @@ type PeerInfo struct {
 }
 
 type PingRequest struct {
+	Info   *PeerInfo
+	Term   int64
+	Leader string
 }
 
 type PingResponse struct {
-	Info *PeerInfo
+	Info         *PeerInfo
+	Term         int64
+	Leader       string
+	LeaseGranted bool
 }
 
 // +genclient
@@ -69,3 +75,357 @@ type Member struct {
 	// +optional
 	Response *MemberResponse
 }
+
+type PlanRequest struct {
+	Leader         string
+	Term           int64
+	ClusterName    string
+	ClusterToken   string
+	InitialCluster []string
+	EtcdVersion    string
+	Backup         string
+	Revision       int64
+}
+
+type PlanResponse struct {
+	Accepted bool
+	Reason   string
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:skipVerbs=get,list,update,patch,delete,deleteCollection,watch
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type Plan struct {
+	metav1.TypeMeta
+	// +optional
+	Request *PlanRequest
+	// +optional
+	Response *PlanResponse
+}
+
+type MigrationPhase string
+
+type MigrationRequest struct {
+	Leader      string
+	Term        int64
+	Phase       MigrationPhase
+	FromVersion string
+	ToVersion   string
+}
+
+type MigrationResponse struct {
+	Phase  MigrationPhase
+	Ready  bool
+	Reason string
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:skipVerbs=get,list,update,patch,delete,deleteCollection,watch
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type Migration struct {
+	metav1.TypeMeta
+	// +optional
+	Request *MigrationRequest
+	// +optional
+	Response *MigrationResponse
+}
+
+type UpgradeRequest struct {
+	Leader         string
+	Term           int64
+	Version        string
+	ClusterVersion string
+}
+
+type UpgradeResponse struct {
+	Version        string
+	ClusterVersion string
+	Reason         string
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:skipVerbs=get,list,update,patch,delete,deleteCollection,watch
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type Upgrade struct {
+	metav1.TypeMeta
+	// +optional
+	Request *UpgradeRequest
+	// +optional
+	Response *UpgradeResponse
+}
+
+type QuarantineMode string
+
+type QuarantineRequest struct {
+	Leader string
+	Term   int64
+	Mode   QuarantineMode
+}
+
+type QuarantineMember struct {
+	ID          string
+	Mode        QuarantineMode
+	Running     bool
+	Quarantined bool
+}
+
+type QuarantineResponse struct {
+	Mode    QuarantineMode
+	Members []QuarantineMember
+	Reason  string
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:skipVerbs=get,list,update,patch,delete,deleteCollection,watch
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type Quarantine struct {
+	metav1.TypeMeta
+	// +optional
+	Request *QuarantineRequest
+	// +optional
+	Response *QuarantineResponse
+}
+
+type ScaleRequest struct {
+	Leader      string
+	Term        int64
+	ClusterSize int32
+	Members     []string
+}
+
+type ScaleResponse struct {
+	ClusterSize int32
+	Members     []string
+	Reason      string
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:skipVerbs=get,list,update,patch,delete,deleteCollection,watch
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type Scale struct {
+	metav1.TypeMeta
+	// +optional
+	Request *ScaleRequest
+	// +optional
+	Response *ScaleResponse
+}
+
+type CertificateRequest struct {
+	ID                   string
+	PeerCSR              string
+	ServerCSR            string
+	HealthcheckClientCSR string
+}
+
+type CertificateResponse struct {
+	ID                    string
+	CACert                string
+	PeerCert              string
+	ServerCert            string
+	HealthcheckClientCert string
+	Term                  int64
+	Reason                string
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:skipVerbs=get,list,update,patch,delete,deleteCollection,watch
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type Certificate struct {
+	metav1.TypeMeta
+	// +optional
+	Request *CertificateRequest
+	// +optional
+	Response *CertificateResponse
+}
+
+type RotationRequest struct {
+	Leader string
+	Term   int64
+}
+
+type RotationResponse struct {
+	Rotated bool
+	Reason  string
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:skipVerbs=get,list,update,patch,delete,deleteCollection,watch
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type Rotation struct {
+	metav1.TypeMeta
+	// +optional
+	Request *RotationRequest
+	// +optional
+	Response *RotationResponse
+}
+
+type BackupRequest struct {
+	Take bool
+}
+
+type BackupInfo struct {
+	Name        string
+	Revision    int64
+	EtcdVersion string
+	Timestamp   metav1.Time
+}
+
+type BackupResponse struct {
+	Name    string
+	Backups []BackupInfo
+	Reason  string
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:skipVerbs=get,list,update,patch,delete,deleteCollection,watch
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type Backup struct {
+	metav1.TypeMeta
+	// +optional
+	Request *BackupRequest
+	// +optional
+	Response *BackupResponse
+}
+
+type RestoreRequest struct {
+	Leader string
+	Term   int64
+	Backup string
+}
+
+type RestoreResponse struct {
+	Backup  string
+	Members []string
+	Reason  string
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:skipVerbs=get,list,update,patch,delete,deleteCollection,watch
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type Restore struct {
+	metav1.TypeMeta
+	// +optional
+	Request *RestoreRequest
+	// +optional
+	Response *RestoreResponse
+}
+
+type RemovalRequest struct {
+	Member string
+}
+
+type RemovalResponse struct {
+	Members []string
+	Reason  string
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:skipVerbs=get,list,update,patch,delete,deleteCollection,watch
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type Removal struct {
+	metav1.TypeMeta
+	// +optional
+	Request *RemovalRequest
+	// +optional
+	Response *RemovalResponse
+}
+
+type EtcdClusterSpec struct {
+	ClusterSize int32
+	EtcdVersion string
+	Quarantine  QuarantineMode
+}
+
+type EtcdClusterMember struct {
+	ID                string
+	Name              string
+	PeerURLs          []string
+	ClientURLs        []string
+	Version           string
+	IsLeader          bool
+	DBSize            int64
+	RaftIndex         int64
+	RaftTerm          int64
+	Alarms            []string
+	CertificateExpiry *metav1.Time
+	Conditions        []EtcdClusterCondition
+}
+
+type ConditionStatus string
+
+type EtcdClusterConditionType string
+
+type EtcdClusterCondition struct {
+	Type               EtcdClusterConditionType
+	Status             ConditionStatus
+	LastTransitionTime metav1.Time
+	Reason             string
+	Message            string
+}
+
+type EtcdClusterStatus struct {
+	Leader         string
+	Term           int64
+	Members        []EtcdClusterMember
+	LastBackupTime *metav1.Time
+	Conditions     []EtcdClusterCondition
+	ProcessExits   []EtcdProcessExit
+}
+
+type EtcdProcessExit struct {
+	Time     metav1.Time
+	ExitCode int32
+	Message  string
+	Expected bool
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:onlyVerbs=get,list,watch
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type EtcdCluster struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+	Spec   EtcdClusterSpec
+	Status EtcdClusterStatus
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type EtcdClusterList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+	Items []EtcdCluster
+}
@@ -29,6 +29,379 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
+		"github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1.EtcdCluster": {
+			Schema: spec.Schema{
+				SchemaProps: spec.SchemaProps{
+					Description: "EtcdCluster is the read-only status of the etcd cluster, reported by every peer under the name of the cluster.",
+					Properties: map[string]spec.Schema{
+						"kind": {
+							SchemaProps: spec.SchemaProps{
+								Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+								Type:        []string{"string"},
+								Format:      "",
+							},
+						},
+						"apiVersion": {
+							SchemaProps: spec.SchemaProps{
+								Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+								Type:        []string{"string"},
+								Format:      "",
+							},
+						},
+						"metadata": {
+							SchemaProps: spec.SchemaProps{
+								Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+							},
+						},
+						"spec": {
+							SchemaProps: spec.SchemaProps{
+								Ref: ref("github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1.EtcdClusterSpec"),
+							},
+						},
+						"status": {
+							SchemaProps: spec.SchemaProps{
+								Ref: ref("github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1.EtcdClusterStatus"),
+							},
+						},
+					},
+				},
+			},
+			Dependencies: []string{
+				"github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1.EtcdClusterSpec", "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1.EtcdClusterStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+		},
+		"github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1.EtcdClusterCondition": {
+			Schema: spec.Schema{
+				SchemaProps: spec.SchemaProps{
+					Description: "EtcdClusterCondition is a condition of the cluster or of one of its members",
+					Properties: map[string]spec.Schema{
+						"type": {
+							SchemaProps: spec.SchemaProps{
+								Type:   []string{"string"},
+								Format: "",
+							},
+						},
+						"status": {
+							SchemaProps: spec.SchemaProps{
+								Type:   []string{"string"},
+								Format: "",
+							},
+						},
+						"lastTransitionTime": {
+							SchemaProps: spec.SchemaProps{
+								Description: "LastTransitionTime is when Status last changed",
+								Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+							},
+						},
+						"reason": {
+							SchemaProps: spec.SchemaProps{
+								Type:   []string{"string"},
+								Format: "",
+							},
+						},
+						"message": {
+							SchemaProps: spec.SchemaProps{
+								Type:   []string{"string"},
+								Format: "",
+							},
+						},
+					},
+					Required: []string{"type", "status"},
+				},
+			},
+			Dependencies: []string{
+				"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+		},
+		"github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1.EtcdClusterList": {
+			Schema: spec.Schema{
+				SchemaProps: spec.SchemaProps{
+					Description: "EtcdClusterList is a list of EtcdClusters.",
+					Properties: map[string]spec.Schema{
+						"kind": {
+							SchemaProps: spec.SchemaProps{
+								Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+								Type:        []string{"string"},
+								Format:      "",
+							},
+						},
+						"apiVersion": {
+							SchemaProps: spec.SchemaProps{
+								Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+								Type:        []string{"string"},
+								Format:      "",
+							},
+						},
+						"metadata": {
+							SchemaProps: spec.SchemaProps{
+								Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+							},
+						},
+						"items": {
+							SchemaProps: spec.SchemaProps{
+								Type: []string{"array"},
+								Items: &spec.SchemaOrArray{
+									Schema: &spec.Schema{
+										SchemaProps: spec.SchemaProps{
+											Ref: ref("github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1.EtcdCluster"),
+										},
+									},
+								},
+							},
+						},
+					},
+					Required: []string{"items"},
+				},
+			},
+			Dependencies: []string{
+				"github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1.EtcdCluster", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+		},
+		"github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1.EtcdClusterMember": {
+			Schema: spec.Schema{
+				SchemaProps: spec.SchemaProps{
+					Properties: map[string]spec.Schema{
+						"id": {
+							SchemaProps: spec.SchemaProps{
+								Description: "ID is the etcd member id, Name is empty until the member started",
+								Type:        []string{"string"},
+								Format:      "",
+							},
+						},
+						"name": {
+							SchemaProps: spec.SchemaProps{
+								Type:   []string{"string"},
+								Format: "",
+							},
+						},
+						"peerURLs": {
+							SchemaProps: spec.SchemaProps{
+								Type: []string{"array"},
+								Items: &spec.SchemaOrArray{
+									Schema: &spec.Schema{
+										SchemaProps: spec.SchemaProps{
+											Type:   []string{"string"},
+											Format: "",
+										},
+									},
+								},
+							},
+						},
+						"clientURLs": {
+							SchemaProps: spec.SchemaProps{
+								Type: []string{"array"},
+								Items: &spec.SchemaOrArray{
+									Schema: &spec.Schema{
+										SchemaProps: spec.SchemaProps{
+											Type:   []string{"string"},
+											Format: "",
+										},
+									},
+								},
+							},
+						},
+						"version": {
+							SchemaProps: spec.SchemaProps{
+								Description: "Version is the etcd server version of the member, empty if it is unreachable",
+								Type:        []string{"string"},
+								Format:      "",
+							},
+						},
+						"isLeader": {
+							SchemaProps: spec.SchemaProps{
+								Description: "IsLeader is true for the raft leader of the cluster",
+								Type:        []string{"boolean"},
+								Format:      "",
+							},
+						},
+						"dbSize": {
+							SchemaProps: spec.SchemaProps{
+								Description: "DBSize, RaftIndex and RaftTerm are reported by the Status RPC of etcd v3 members",
+								Type:        []string{"integer"},
+								Format:      "int64",
+							},
+						},
+						"raftIndex": {
+							SchemaProps: spec.SchemaProps{
+								Type:   []string{"integer"},
+								Format: "int64",
+							},
+						},
+						"raftTerm": {
+							SchemaProps: spec.SchemaProps{
+								Type:   []string{"integer"},
+								Format: "int64",
+							},
+						},
+						"alarms": {
+							SchemaProps: spec.SchemaProps{
+								Description: "Alarms are the alarms raised by the member, like NOSPACE",
+								Type:        []string{"array"},
+								Items: &spec.SchemaOrArray{
+									Schema: &spec.Schema{
+										SchemaProps: spec.SchemaProps{
+											Type:   []string{"string"},
+											Format: "",
+										},
+									},
+								},
+							},
+						},
+						"certificateExpiry": {
+							SchemaProps: spec.SchemaProps{
+								Description: "CertificateExpiry is when the server certificate of the member expires",
+								Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+							},
+						},
+						"conditions": {
+							SchemaProps: spec.SchemaProps{
+								Description: "Conditions are the results of the health checks of the member",
+								Type:        []string{"array"},
+								Items: &spec.SchemaOrArray{
+									Schema: &spec.Schema{
+										SchemaProps: spec.SchemaProps{
+											Ref: ref("github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1.EtcdClusterCondition"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Dependencies: []string{
+				"github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1.EtcdClusterCondition", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+		},
+		"github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1.EtcdClusterSpec": {
+			Schema: spec.Schema{
+				SchemaProps: spec.SchemaProps{
+					Description: "EtcdClusterSpec is the desired state of the cluster, as known to the reporting peer",
+					Properties: map[string]spec.Schema{
+						"clusterSize": {
+							SchemaProps: spec.SchemaProps{
+								Type:   []string{"integer"},
+								Format: "int32",
+							},
+						},
+						"etcdVersion": {
+							SchemaProps: spec.SchemaProps{
+								Type:   []string{"string"},
+								Format: "",
+							},
+						},
+						"quarantine": {
+							SchemaProps: spec.SchemaProps{
+								Description: "Quarantine is the quarantine mode set through the quarantine resource",
+								Type:        []string{"string"},
+								Format:      "",
+							},
+						},
+					},
+				},
+			},
+			Dependencies: []string{},
+		},
+		"github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1.EtcdClusterStatus": {
+			Schema: spec.Schema{
+				SchemaProps: spec.SchemaProps{
+					Properties: map[string]spec.Schema{
+						"leader": {
+							SchemaProps: spec.SchemaProps{
+								Description: "Leader and Term are the discovery leader election as seen by the reporting peer",
+								Type:        []string{"string"},
+								Format:      "",
+							},
+						},
+						"term": {
+							SchemaProps: spec.SchemaProps{
+								Type:   []string{"integer"},
+								Format: "int64",
+							},
+						},
+						"members": {
+							SchemaProps: spec.SchemaProps{
+								Description: "Members are the etcd members, empty while etcd is not running on the reporting peer",
+								Type:        []string{"array"},
+								Items: &spec.SchemaOrArray{
+									Schema: &spec.Schema{
+										SchemaProps: spec.SchemaProps{
+											Ref: ref("github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1.EtcdClusterMember"),
+										},
+									},
+								},
+							},
+						},
+						"lastBackupTime": {
+							SchemaProps: spec.SchemaProps{
+								Description: "LastBackupTime is when the newest backup in the backup store was taken",
+								Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+							},
+						},
+						"conditions": {
+							SchemaProps: spec.SchemaProps{
+								Type: []string{"array"},
+								Items: &spec.SchemaOrArray{
+									Schema: &spec.Schema{
+										SchemaProps: spec.SchemaProps{
+											Ref: ref("github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1.EtcdClusterCondition"),
+										},
+									},
+								},
+							},
+						},
+						"processExits": {
+							SchemaProps: spec.SchemaProps{
+								Description: "ProcessExits are the last exits of the etcd process of the reporting peer, oldest first",
+								Type:        []string{"array"},
+								Items: &spec.SchemaOrArray{
+									Schema: &spec.Schema{
+										SchemaProps: spec.SchemaProps{
+											Ref: ref("github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1.EtcdProcessExit"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Dependencies: []string{
+				"github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1.EtcdClusterCondition", "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1.EtcdClusterMember", "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1.EtcdProcessExit", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+		},
+		"github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1.EtcdProcessExit": {
+			Schema: spec.Schema{
+				SchemaProps: spec.SchemaProps{
+					Description: "EtcdProcessExit is an exit of the etcd process of the reporting peer",
+					Properties: map[string]spec.Schema{
+						"time": {
+							SchemaProps: spec.SchemaProps{
+								Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+							},
+						},
+						"exitCode": {
+							SchemaProps: spec.SchemaProps{
+								Description: "ExitCode is -1 if etcd was killed by a signal or could not be started",
+								Type:        []string{"integer"},
+								Format:      "int32",
+							},
+						},
+						"message": {
+							SchemaProps: spec.SchemaProps{
+								Type:   []string{"string"},
+								Format: "",
+							},
+						},
+						"expected": {
+							SchemaProps: spec.SchemaProps{
+								Description: "Expected is true if etcd was stopped by the etcd manager",
+								Type:        []string{"boolean"},
+								Format:      "",
+							},
+						},
+					},
+					Required: []string{"time", "exitCode"},
+				},
+			},
+			Dependencies: []string{
+				"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+		},
 		"k8s.io/apimachinery/pkg/api/resource.Quantity": resource.Quantity{}.OpenAPIDefinition(),
 		"k8s.io/apimachinery/pkg/api/resource.int64Amount": {
 			Schema: spec.Schema{
@@ -26,10 +26,21 @@ type PeerInfo struct {
 }
 
 type PingRequest struct {
+	// Info describes the sender
+	Info *PeerInfo `json:"info,omitempty"`
+	// Term and Leader are the sender's view of the leader election.
+	// A sender that names itself as Leader asks the receiver to grant it a lease for Term.
+	Term   int64  `json:"term,omitempty"`
+	Leader string `json:"leader,omitempty"`
 }
 
 type PingResponse struct {
 	Info *PeerInfo `json:"info,omitempty"`
+	// Term and Leader are the receiver's view of the leader election
+	Term   int64  `json:"term,omitempty"`
+	Leader string `json:"leader,omitempty"`
+	// LeaseGranted is true if the receiver accepted the sender as leader for the requested term
+	LeaseGranted bool `json:"leaseGranted,omitempty"`
 }
 
 const (
@@ -64,10 +75,11 @@ type MemberRequest struct {
 }
 
 type MemberResponse struct {
-	ClusterName  string   `json:"clusterName,omitempty"`
-	ClusterToken string   `json:"clusterToken,omitempty"`
-	PeerURLs     []string `json:"peerURLs,omitempty"`
-	EtcdVersion  string   `json:"etcdVersion,omitempty"`
+	ClusterName  string `json:"clusterName,omitempty"`
+	ClusterToken string `json:"clusterToken,omitempty"`
+	// PeerURLs lists every member of the cluster as name=url, the format of etcd's --initial-cluster flag
+	PeerURLs    []string `json:"peerURLs,omitempty"`
+	EtcdVersion string   `json:"etcdVersion,omitempty"`
 }
 
 // +genclient
@@ -83,3 +95,580 @@ type Member struct {
 	// +optional
 	Response *MemberResponse `json:"response,omitempty"`
 }
+
+const (
+	ResourceKindPlan     = "Plan"
+	ResourcePluralPlan   = "plans"
+	ResourceSingularPlan = "plan"
+)
+
+// PlanRequest is the bootstrap plan of a new or restored cluster, pushed by the leader to every member
+type PlanRequest struct {
+	// Leader and Term identify the leader that made the plan
+	Leader string `json:"leader,omitempty"`
+	Term   int64  `json:"term,omitempty"`
+
+	ClusterName  string `json:"clusterName,omitempty"`
+	ClusterToken string `json:"clusterToken,omitempty"`
+	// InitialCluster lists every member of the new cluster as name=url, the format of etcd's --initial-cluster flag
+	InitialCluster []string `json:"initialCluster,omitempty"`
+	EtcdVersion    string   `json:"etcdVersion,omitempty"`
+
+	// Backup names the backup every member restores its data from, empty for an empty cluster
+	Backup string `json:"backup,omitempty"`
+	// Revision is the revision of the backup, members check they restore the same revision
+	Revision int64 `json:"revision,omitempty"`
+}
+
+type PlanResponse struct {
+	Accepted bool `json:"accepted,omitempty"`
+	// Reason explains why the plan was not accepted
+	Reason string `json:"reason,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:skipVerbs=get,list,update,patch,delete,deleteCollection,watch
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type Plan struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	Request *PlanRequest `json:"request,omitempty"`
+	// +optional
+	Response *PlanResponse `json:"response,omitempty"`
+}
+
+const (
+	ResourceKindMigration     = "Migration"
+	ResourcePluralMigration   = "migrations"
+	ResourceSingularMigration = "migration"
+)
+
+// MigrationPhase is a step of the migration of an etcd v2 cluster to etcd v3
+type MigrationPhase string
+
+const (
+	// MigrationPhaseQuarantine restarts the v2 members with their clients on the quarantined port
+	MigrationPhaseQuarantine MigrationPhase = "Quarantine"
+	// MigrationPhaseUpgrade restarts the quarantined members with etcd v3, which serves the v2 data on the v2 API
+	MigrationPhaseUpgrade MigrationPhase = "Upgrade"
+	// MigrationPhaseCopy is the copy of the v2 keys into the v3 keyspace by the leader
+	MigrationPhaseCopy MigrationPhase = "Copy"
+	// MigrationPhaseComplete restarts the v3 members with their clients back on the client port
+	MigrationPhaseComplete MigrationPhase = "Complete"
+)
+
+// MigrationRequest moves a member to a phase of the migration, pushed by the leader
+type MigrationRequest struct {
+	// Leader and Term identify the leader that runs the migration
+	Leader string `json:"leader,omitempty"`
+	Term   int64  `json:"term,omitempty"`
+
+	Phase MigrationPhase `json:"phase,omitempty"`
+	// FromVersion and ToVersion are the etcd versions the cluster migrates between
+	FromVersion string `json:"fromVersion,omitempty"`
+	ToVersion   string `json:"toVersion,omitempty"`
+}
+
+type MigrationResponse struct {
+	// Phase is the phase of the member, later than the requested one if an earlier leader moved on
+	Phase MigrationPhase `json:"phase,omitempty"`
+	// Ready is true once etcd runs as the phase requires
+	Ready bool `json:"ready,omitempty"`
+	// Reason explains why the member is not ready
+	Reason string `json:"reason,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:skipVerbs=get,list,update,patch,delete,deleteCollection,watch
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type Migration struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	Request *MigrationRequest `json:"request,omitempty"`
+	// +optional
+	Response *MigrationResponse `json:"response,omitempty"`
+}
+
+const (
+	ResourceKindUpgrade     = "Upgrade"
+	ResourcePluralUpgrade   = "upgrades"
+	ResourceSingularUpgrade = "upgrade"
+)
+
+// UpgradeRequest sets the etcd version of the cluster, sent by an operator to the leader, or
+// pushed by the leader to every peer. The leader also restarts one member at a time with the
+// new version.
+type UpgradeRequest struct {
+	// Leader and Term identify the leader that runs the upgrade, both are empty in the request
+	// of an operator
+	Leader string `json:"leader,omitempty"`
+	Term   int64  `json:"term,omitempty"`
+
+	// Version is the etcd version the member restarts with
+	Version string `json:"version,omitempty"`
+	// ClusterVersion is the etcd version the cluster is to run, it is kept by every peer
+	ClusterVersion string `json:"clusterVersion,omitempty"`
+}
+
+type UpgradeResponse struct {
+	// Version is the etcd version the member restarts with, empty if the upgrade was refused
+	Version string `json:"version,omitempty"`
+	// ClusterVersion is the etcd version the cluster is to run
+	ClusterVersion string `json:"clusterVersion,omitempty"`
+	// Reason explains why the upgrade was refused
+	Reason string `json:"reason,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:skipVerbs=get,list,update,patch,delete,deleteCollection,watch
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type Upgrade struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	Request *UpgradeRequest `json:"request,omitempty"`
+	// +optional
+	Response *UpgradeResponse `json:"response,omitempty"`
+}
+
+const (
+	ResourceKindQuarantine     = "Quarantine"
+	ResourcePluralQuarantine   = "quarantines"
+	ResourceSingularQuarantine = "quarantine"
+)
+
+// QuarantineMode tells whether etcd serves its clients on the client port or on the quarantined port
+type QuarantineMode string
+
+const (
+	// QuarantineModeEnabled moves the clients of etcd to the quarantined port, out of reach of normal clients
+	QuarantineModeEnabled QuarantineMode = "Enabled"
+	// QuarantineModeDisabled serves the clients of etcd on the client port
+	QuarantineModeDisabled QuarantineMode = "Disabled"
+)
+
+// QuarantineRequest changes the quarantine mode of the cluster. Operators send it to the
+// leader without Leader and Term, the leader then pushes the mode to every member.
+// An empty mode only reports the mode of the members.
+type QuarantineRequest struct {
+	// Leader and Term identify the leader that pushes the mode, empty for operators
+	Leader string `json:"leader,omitempty"`
+	Term   int64  `json:"term,omitempty"`
+
+	Mode QuarantineMode `json:"mode,omitempty"`
+}
+
+// QuarantineMember is the quarantine mode of a member
+type QuarantineMember struct {
+	ID string `json:"id"`
+	// Mode is the mode the member was moved to
+	Mode QuarantineMode `json:"mode,omitempty"`
+	// Running is true if etcd runs on the member, Quarantined if it runs on the quarantined port
+	Running     bool `json:"running,omitempty"`
+	Quarantined bool `json:"quarantined,omitempty"`
+}
+
+type QuarantineResponse struct {
+	// Mode is the mode of the cluster
+	Mode    QuarantineMode     `json:"mode,omitempty"`
+	Members []QuarantineMember `json:"members,omitempty"`
+	// Reason explains why the request was refused
+	Reason string `json:"reason,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:skipVerbs=get,list,update,patch,delete,deleteCollection,watch
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type Quarantine struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	Request *QuarantineRequest `json:"request,omitempty"`
+	// +optional
+	Response *QuarantineResponse `json:"response,omitempty"`
+}
+
+const (
+	ResourceKindScale     = "Scale"
+	ResourcePluralScale   = "scales"
+	ResourceSingularScale = "scale"
+)
+
+// ScaleRequest changes the desired size of the cluster. Operators send it to the leader
+// without Leader and Term, the leader then pushes the size and its members to every peer.
+// A zero size only reports the size of the cluster.
+type ScaleRequest struct {
+	// Leader and Term identify the leader that pushes the size, empty for operators
+	Leader string `json:"leader,omitempty"`
+	Term   int64  `json:"term,omitempty"`
+
+	ClusterSize int32 `json:"clusterSize,omitempty"`
+	// Members are the names of the etcd members, set by the leader. A peer that is no
+	// longer a member moves its data aside and waits to join again.
+	Members []string `json:"members,omitempty"`
+}
+
+type ScaleResponse struct {
+	// ClusterSize is the desired size of the cluster
+	ClusterSize int32 `json:"clusterSize,omitempty"`
+	// Members are the names of the etcd members, reported to operators
+	Members []string `json:"members,omitempty"`
+	// Reason explains why the request was refused
+	Reason string `json:"reason,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:skipVerbs=get,list,update,patch,delete,deleteCollection,watch
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type Scale struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	Request *ScaleRequest `json:"request,omitempty"`
+	// +optional
+	Response *ScaleResponse `json:"response,omitempty"`
+}
+
+const (
+	ResourceKindCertificate     = "Certificate"
+	ResourcePluralCertificate   = "certificates"
+	ResourceSingularCertificate = "certificate"
+)
+
+// CertificateRequest asks the leader to sign the certificates of a peer with the cluster CA.
+// New peers authenticate it with the bootstrap token of the cluster, and trust the leader
+// by the hash of the CA that issued its certificate.
+type CertificateRequest struct {
+	// ID the peer asks for, the leader refuses the ids of other peers and assigns one if empty
+	ID string `json:"id,omitempty"`
+	// PeerCSR, ServerCSR and HealthcheckClientCSR are pem encoded certificate requests,
+	// their SANs may only name the address the request is sent from, the hosts the leader
+	// discovered for the peer and the loopback addresses
+	PeerCSR              string `json:"peerCSR"`
+	ServerCSR            string `json:"serverCSR"`
+	HealthcheckClientCSR string `json:"healthcheckClientCSR"`
+}
+
+type CertificateResponse struct {
+	// ID is the id the leader assigned to the peer, the common name of its peer and server
+	// certificates
+	ID string `json:"id,omitempty"`
+	// CACert is the pem encoded certificate of the cluster CA, followed by the CAs trusted
+	// along with it while the CA is rotated
+	CACert string `json:"caCert,omitempty"`
+
+	PeerCert              string `json:"peerCert,omitempty"`
+	ServerCert            string `json:"serverCert,omitempty"`
+	HealthcheckClientCert string `json:"healthcheckClientCert,omitempty"`
+	// Term is the term of the leader known to the peer, 0 while the peer has seen no leader
+	// since it started. The first peers of a cluster sign their own certificates until then.
+	Term int64 `json:"term,omitempty"`
+	// Reason explains why the request was refused
+	Reason string `json:"reason,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:skipVerbs=get,list,update,patch,delete,deleteCollection,watch
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type Certificate struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	Request *CertificateRequest `json:"request,omitempty"`
+	// +optional
+	Response *CertificateResponse `json:"response,omitempty"`
+}
+
+const (
+	ResourceKindRotation     = "Rotation"
+	ResourcePluralRotation   = "rotations"
+	ResourceSingularRotation = "rotation"
+)
+
+// RotationRequest makes a member renew its certificates from the cluster CA if they expire
+// soon or the CA is rotated, pushed by the leader to one member at a time
+type RotationRequest struct {
+	// Leader and Term identify the leader that rotates the certificates
+	Leader string `json:"leader,omitempty"`
+	Term   int64  `json:"term,omitempty"`
+}
+
+type RotationResponse struct {
+	// Rotated is true if the member renewed its certificates and restarts to load them
+	Rotated bool `json:"rotated,omitempty"`
+	// Reason explains why the request was refused
+	Reason string `json:"reason,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:skipVerbs=get,list,update,patch,delete,deleteCollection,watch
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type Rotation struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	Request *RotationRequest `json:"request,omitempty"`
+	// +optional
+	Response *RotationResponse `json:"response,omitempty"`
+}
+
+const (
+	ResourceKindBackup     = "Backup"
+	ResourcePluralBackup   = "backups"
+	ResourceSingularBackup = "backup"
+)
+
+// BackupRequest lists the backups in the backup store, which every peer serves. With
+// Take, operators ask the leader to take a backup of the cluster first.
+type BackupRequest struct {
+	Take bool `json:"take,omitempty"`
+}
+
+// BackupInfo describes a backup in the backup store
+type BackupInfo struct {
+	Name        string `json:"name"`
+	Revision    int64  `json:"revision,omitempty"`
+	EtcdVersion string `json:"etcdVersion,omitempty"`
+	// Timestamp is when the backup was taken
+	Timestamp metav1.Time `json:"timestamp,omitempty"`
+}
+
+type BackupResponse struct {
+	// Name is the backup taken for the request
+	Name string `json:"name,omitempty"`
+	// Backups are the backups in the store, oldest first
+	Backups []BackupInfo `json:"backups,omitempty"`
+	// Reason explains why the request was refused
+	Reason string `json:"reason,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:skipVerbs=get,list,update,patch,delete,deleteCollection,watch
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type Backup struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	Request *BackupRequest `json:"request,omitempty"`
+	// +optional
+	Response *BackupResponse `json:"response,omitempty"`
+}
+
+const (
+	ResourceKindRestore     = "Restore"
+	ResourcePluralRestore   = "restores"
+	ResourceSingularRestore = "restore"
+)
+
+// RestoreRequest restores the running cluster from a backup. Operators send it to the
+// leader without Leader and Term, the leader then pushes the backup to every peer. The
+// peers stop etcd and the leader plans the restore, as for peers started to restore.
+type RestoreRequest struct {
+	// Leader and Term identify the leader that pushes the backup, empty for operators
+	Leader string `json:"leader,omitempty"`
+	Term   int64  `json:"term,omitempty"`
+
+	// Backup is the name of the backup, operators may leave it empty for the latest one
+	Backup string `json:"backup,omitempty"`
+}
+
+type RestoreResponse struct {
+	// Backup is the name of the backup the cluster is restored from
+	Backup string `json:"backup,omitempty"`
+	// Members are the peers that stop etcd to restore the backup
+	Members []string `json:"members,omitempty"`
+	// Reason explains why the request was refused
+	Reason string `json:"reason,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:skipVerbs=get,list,update,patch,delete,deleteCollection,watch
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type Restore struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	Request *RestoreRequest `json:"request,omitempty"`
+	// +optional
+	Response *RestoreResponse `json:"response,omitempty"`
+}
+
+const (
+	ResourceKindRemoval     = "Removal"
+	ResourcePluralRemoval   = "removals"
+	ResourceSingularRemoval = "removal"
+)
+
+// RemovalRequest removes a member from the etcd cluster. Operators send it to the leader.
+// The peer of the member moves its data aside and joins the cluster again as a new member,
+// unless the cluster is scaled down.
+type RemovalRequest struct {
+	Member string `json:"member"`
+}
+
+type RemovalResponse struct {
+	// Members are the names of the remaining members
+	Members []string `json:"members,omitempty"`
+	// Reason explains why the request was refused
+	Reason string `json:"reason,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:skipVerbs=get,list,update,patch,delete,deleteCollection,watch
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type Removal struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	Request *RemovalRequest `json:"request,omitempty"`
+	// +optional
+	Response *RemovalResponse `json:"response,omitempty"`
+}
+
+const (
+	ResourceKindEtcdCluster     = "EtcdCluster"
+	ResourcePluralEtcdCluster   = "etcdclusters"
+	ResourceSingularEtcdCluster = "etcdcluster"
+)
+
+// EtcdClusterSpec is the desired state of the cluster, as known to the reporting peer
+type EtcdClusterSpec struct {
+	ClusterSize int32  `json:"clusterSize,omitempty"`
+	EtcdVersion string `json:"etcdVersion,omitempty"`
+	// Quarantine is the quarantine mode set through the quarantine resource
+	Quarantine QuarantineMode `json:"quarantine,omitempty"`
+}
+
+type EtcdClusterMember struct {
+	// ID is the etcd member id, Name is empty until the member started
+	ID         string   `json:"id,omitempty"`
+	Name       string   `json:"name,omitempty"`
+	PeerURLs   []string `json:"peerURLs,omitempty"`
+	ClientURLs []string `json:"clientURLs,omitempty"`
+	// Version is the etcd server version of the member, empty if it is unreachable
+	Version string `json:"version,omitempty"`
+	// IsLeader is true for the raft leader of the cluster
+	IsLeader bool `json:"isLeader,omitempty"`
+	// DBSize, RaftIndex and RaftTerm are reported by the Status RPC of etcd v3 members
+	DBSize    int64 `json:"dbSize,omitempty"`
+	RaftIndex int64 `json:"raftIndex,omitempty"`
+	RaftTerm  int64 `json:"raftTerm,omitempty"`
+	// Alarms are the alarms raised by the member, like NOSPACE
+	Alarms []string `json:"alarms,omitempty"`
+	// CertificateExpiry is when the server certificate of the member expires
+	CertificateExpiry *metav1.Time `json:"certificateExpiry,omitempty"`
+	// Conditions are the results of the health checks of the member
+	Conditions []EtcdClusterCondition `json:"conditions,omitempty"`
+}
+
+type ConditionStatus string
+
+const (
+	ConditionTrue    ConditionStatus = "True"
+	ConditionFalse   ConditionStatus = "False"
+	ConditionUnknown ConditionStatus = "Unknown"
+)
+
+type EtcdClusterConditionType string
+
+const (
+	// EtcdClusterAvailable is true while the cluster has its desired size and every member is healthy
+	EtcdClusterAvailable EtcdClusterConditionType = "Available"
+	// EtcdClusterProgressing is true while the cluster migrates, upgrades or scales
+	EtcdClusterProgressing EtcdClusterConditionType = "Progressing"
+	// EtcdClusterQuarantined is true while the cluster runs quarantined
+	EtcdClusterQuarantined EtcdClusterConditionType = "Quarantined"
+	// EtcdClusterHealthy is true while every member of the cluster is healthy
+	EtcdClusterHealthy EtcdClusterConditionType = "Healthy"
+)
+
+// Conditions of the members of the cluster
+const (
+	// EtcdMemberHealthy is true while the member answers the Status RPC without alarms
+	EtcdMemberHealthy EtcdClusterConditionType = "Healthy"
+	// EtcdMemberReadable is true while the member serves linearizable reads
+	EtcdMemberReadable EtcdClusterConditionType = "Readable"
+	// EtcdMemberReachable is true while the discovery server of the member is found
+	EtcdMemberReachable EtcdClusterConditionType = "Reachable"
+	// EtcdMemberCertificateValid is true while the server certificate of the member is not about to expire
+	EtcdMemberCertificateValid EtcdClusterConditionType = "CertificateValid"
+)
+
+// EtcdClusterCondition is a condition of the cluster or of one of its members
+type EtcdClusterCondition struct {
+	Type   EtcdClusterConditionType `json:"type"`
+	Status ConditionStatus          `json:"status"`
+	// LastTransitionTime is when Status last changed
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	Reason             string      `json:"reason,omitempty"`
+	Message            string      `json:"message,omitempty"`
+}
+
+type EtcdClusterStatus struct {
+	// Leader and Term are the discovery leader election as seen by the reporting peer
+	Leader string `json:"leader,omitempty"`
+	Term   int64  `json:"term,omitempty"`
+	// Members are the etcd members, empty while etcd is not running on the reporting peer
+	Members []EtcdClusterMember `json:"members,omitempty"`
+	// LastBackupTime is when the newest backup in the backup store was taken
+	LastBackupTime *metav1.Time           `json:"lastBackupTime,omitempty"`
+	Conditions     []EtcdClusterCondition `json:"conditions,omitempty"`
+	// ProcessExits are the last exits of the etcd process of the reporting peer, oldest first
+	ProcessExits []EtcdProcessExit `json:"processExits,omitempty"`
+}
+
+// EtcdProcessExit is an exit of the etcd process of the reporting peer
+type EtcdProcessExit struct {
+	Time metav1.Time `json:"time"`
+	// ExitCode is -1 if etcd was killed by a signal or could not be started
+	ExitCode int32  `json:"exitCode"`
+	Message  string `json:"message,omitempty"`
+	// Expected is true if etcd was stopped by the etcd manager
+	Expected bool `json:"expected,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:onlyVerbs=get,list,watch
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// EtcdCluster is the read-only status of the etcd cluster, reported by every peer under
+// the name of the cluster.
+type EtcdCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              EtcdClusterSpec   `json:"spec,omitempty"`
+	Status            EtcdClusterStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// EtcdClusterList is a list of EtcdClusters.
+type EtcdClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EtcdCluster `json:"items"`
+}
@@ -24,6 +24,7 @@ import (
 	unsafe "unsafe"
 
 	discovery "github.com/etcd-manager/etcd-discovery/apis/discovery"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	conversion "k8s.io/apimachinery/pkg/conversion"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -36,12 +37,46 @@ func init() {
 // Public to allow building arbitrary schemes.
 func RegisterConversions(scheme *runtime.Scheme) error {
 	return scheme.AddGeneratedConversionFuncs(
+		Convert_v1alpha1_Backup_To_discovery_Backup,
+		Convert_discovery_Backup_To_v1alpha1_Backup,
+		Convert_v1alpha1_BackupInfo_To_discovery_BackupInfo,
+		Convert_discovery_BackupInfo_To_v1alpha1_BackupInfo,
+		Convert_v1alpha1_BackupRequest_To_discovery_BackupRequest,
+		Convert_discovery_BackupRequest_To_v1alpha1_BackupRequest,
+		Convert_v1alpha1_BackupResponse_To_discovery_BackupResponse,
+		Convert_discovery_BackupResponse_To_v1alpha1_BackupResponse,
+		Convert_v1alpha1_Certificate_To_discovery_Certificate,
+		Convert_discovery_Certificate_To_v1alpha1_Certificate,
+		Convert_v1alpha1_CertificateRequest_To_discovery_CertificateRequest,
+		Convert_discovery_CertificateRequest_To_v1alpha1_CertificateRequest,
+		Convert_v1alpha1_CertificateResponse_To_discovery_CertificateResponse,
+		Convert_discovery_CertificateResponse_To_v1alpha1_CertificateResponse,
+		Convert_v1alpha1_EtcdCluster_To_discovery_EtcdCluster,
+		Convert_discovery_EtcdCluster_To_v1alpha1_EtcdCluster,
+		Convert_v1alpha1_EtcdClusterCondition_To_discovery_EtcdClusterCondition,
+		Convert_discovery_EtcdClusterCondition_To_v1alpha1_EtcdClusterCondition,
+		Convert_v1alpha1_EtcdClusterList_To_discovery_EtcdClusterList,
+		Convert_discovery_EtcdClusterList_To_v1alpha1_EtcdClusterList,
+		Convert_v1alpha1_EtcdClusterMember_To_discovery_EtcdClusterMember,
+		Convert_discovery_EtcdClusterMember_To_v1alpha1_EtcdClusterMember,
+		Convert_v1alpha1_EtcdClusterSpec_To_discovery_EtcdClusterSpec,
+		Convert_discovery_EtcdClusterSpec_To_v1alpha1_EtcdClusterSpec,
+		Convert_v1alpha1_EtcdClusterStatus_To_discovery_EtcdClusterStatus,
+		Convert_discovery_EtcdClusterStatus_To_v1alpha1_EtcdClusterStatus,
+		Convert_v1alpha1_EtcdProcessExit_To_discovery_EtcdProcessExit,
+		Convert_discovery_EtcdProcessExit_To_v1alpha1_EtcdProcessExit,
 		Convert_v1alpha1_Member_To_discovery_Member,
 		Convert_discovery_Member_To_v1alpha1_Member,
 		Convert_v1alpha1_MemberRequest_To_discovery_MemberRequest,
 		Convert_discovery_MemberRequest_To_v1alpha1_MemberRequest,
 		Convert_v1alpha1_MemberResponse_To_discovery_MemberResponse,
 		Convert_discovery_MemberResponse_To_v1alpha1_MemberResponse,
+		Convert_v1alpha1_Migration_To_discovery_Migration,
+		Convert_discovery_Migration_To_v1alpha1_Migration,
+		Convert_v1alpha1_MigrationRequest_To_discovery_MigrationRequest,
+		Convert_discovery_MigrationRequest_To_v1alpha1_MigrationRequest,
+		Convert_v1alpha1_MigrationResponse_To_discovery_MigrationResponse,
+		Convert_discovery_MigrationResponse_To_v1alpha1_MigrationResponse,
 		Convert_v1alpha1_PeerInfo_To_discovery_PeerInfo,
 		Convert_discovery_PeerInfo_To_v1alpha1_PeerInfo,
 		Convert_v1alpha1_Ping_To_discovery_Ping,
@@ -50,9 +85,429 @@ func RegisterConversions(scheme *runtime.Scheme) error {
 		Convert_discovery_PingRequest_To_v1alpha1_PingRequest,
 		Convert_v1alpha1_PingResponse_To_discovery_PingResponse,
 		Convert_discovery_PingResponse_To_v1alpha1_PingResponse,
+		Convert_v1alpha1_Plan_To_discovery_Plan,
+		Convert_discovery_Plan_To_v1alpha1_Plan,
+		Convert_v1alpha1_PlanRequest_To_discovery_PlanRequest,
+		Convert_discovery_PlanRequest_To_v1alpha1_PlanRequest,
+		Convert_v1alpha1_PlanResponse_To_discovery_PlanResponse,
+		Convert_discovery_PlanResponse_To_v1alpha1_PlanResponse,
+		Convert_v1alpha1_Quarantine_To_discovery_Quarantine,
+		Convert_discovery_Quarantine_To_v1alpha1_Quarantine,
+		Convert_v1alpha1_QuarantineMember_To_discovery_QuarantineMember,
+		Convert_discovery_QuarantineMember_To_v1alpha1_QuarantineMember,
+		Convert_v1alpha1_QuarantineRequest_To_discovery_QuarantineRequest,
+		Convert_discovery_QuarantineRequest_To_v1alpha1_QuarantineRequest,
+		Convert_v1alpha1_QuarantineResponse_To_discovery_QuarantineResponse,
+		Convert_discovery_QuarantineResponse_To_v1alpha1_QuarantineResponse,
+		Convert_v1alpha1_Removal_To_discovery_Removal,
+		Convert_discovery_Removal_To_v1alpha1_Removal,
+		Convert_v1alpha1_RemovalRequest_To_discovery_RemovalRequest,
+		Convert_discovery_RemovalRequest_To_v1alpha1_RemovalRequest,
+		Convert_v1alpha1_RemovalResponse_To_discovery_RemovalResponse,
+		Convert_discovery_RemovalResponse_To_v1alpha1_RemovalResponse,
+		Convert_v1alpha1_Restore_To_discovery_Restore,
+		Convert_discovery_Restore_To_v1alpha1_Restore,
+		Convert_v1alpha1_RestoreRequest_To_discovery_RestoreRequest,
+		Convert_discovery_RestoreRequest_To_v1alpha1_RestoreRequest,
+		Convert_v1alpha1_RestoreResponse_To_discovery_RestoreResponse,
+		Convert_discovery_RestoreResponse_To_v1alpha1_RestoreResponse,
+		Convert_v1alpha1_Rotation_To_discovery_Rotation,
+		Convert_discovery_Rotation_To_v1alpha1_Rotation,
+		Convert_v1alpha1_RotationRequest_To_discovery_RotationRequest,
+		Convert_discovery_RotationRequest_To_v1alpha1_RotationRequest,
+		Convert_v1alpha1_RotationResponse_To_discovery_RotationResponse,
+		Convert_discovery_RotationResponse_To_v1alpha1_RotationResponse,
+		Convert_v1alpha1_Scale_To_discovery_Scale,
+		Convert_discovery_Scale_To_v1alpha1_Scale,
+		Convert_v1alpha1_ScaleRequest_To_discovery_ScaleRequest,
+		Convert_discovery_ScaleRequest_To_v1alpha1_ScaleRequest,
+		Convert_v1alpha1_ScaleResponse_To_discovery_ScaleResponse,
+		Convert_discovery_ScaleResponse_To_v1alpha1_ScaleResponse,
+		Convert_v1alpha1_Upgrade_To_discovery_Upgrade,
+		Convert_discovery_Upgrade_To_v1alpha1_Upgrade,
+		Convert_v1alpha1_UpgradeRequest_To_discovery_UpgradeRequest,
+		Convert_discovery_UpgradeRequest_To_v1alpha1_UpgradeRequest,
+		Convert_v1alpha1_UpgradeResponse_To_discovery_UpgradeResponse,
+		Convert_discovery_UpgradeResponse_To_v1alpha1_UpgradeResponse,
 	)
 }
 
+func autoConvert_v1alpha1_Backup_To_discovery_Backup(in *Backup, out *discovery.Backup, s conversion.Scope) error {
+	out.Request = (*discovery.BackupRequest)(unsafe.Pointer(in.Request))
+	out.Response = (*discovery.BackupResponse)(unsafe.Pointer(in.Response))
+	return nil
+}
+
+// Convert_v1alpha1_Backup_To_discovery_Backup is an autogenerated conversion function.
+func Convert_v1alpha1_Backup_To_discovery_Backup(in *Backup, out *discovery.Backup, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Backup_To_discovery_Backup(in, out, s)
+}
+
+func autoConvert_discovery_Backup_To_v1alpha1_Backup(in *discovery.Backup, out *Backup, s conversion.Scope) error {
+	out.Request = (*BackupRequest)(unsafe.Pointer(in.Request))
+	out.Response = (*BackupResponse)(unsafe.Pointer(in.Response))
+	return nil
+}
+
+// Convert_discovery_Backup_To_v1alpha1_Backup is an autogenerated conversion function.
+func Convert_discovery_Backup_To_v1alpha1_Backup(in *discovery.Backup, out *Backup, s conversion.Scope) error {
+	return autoConvert_discovery_Backup_To_v1alpha1_Backup(in, out, s)
+}
+
+func autoConvert_v1alpha1_BackupInfo_To_discovery_BackupInfo(in *BackupInfo, out *discovery.BackupInfo, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Revision = in.Revision
+	out.EtcdVersion = in.EtcdVersion
+	out.Timestamp = in.Timestamp
+	return nil
+}
+
+// Convert_v1alpha1_BackupInfo_To_discovery_BackupInfo is an autogenerated conversion function.
+func Convert_v1alpha1_BackupInfo_To_discovery_BackupInfo(in *BackupInfo, out *discovery.BackupInfo, s conversion.Scope) error {
+	return autoConvert_v1alpha1_BackupInfo_To_discovery_BackupInfo(in, out, s)
+}
+
+func autoConvert_discovery_BackupInfo_To_v1alpha1_BackupInfo(in *discovery.BackupInfo, out *BackupInfo, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Revision = in.Revision
+	out.EtcdVersion = in.EtcdVersion
+	out.Timestamp = in.Timestamp
+	return nil
+}
+
+// Convert_discovery_BackupInfo_To_v1alpha1_BackupInfo is an autogenerated conversion function.
+func Convert_discovery_BackupInfo_To_v1alpha1_BackupInfo(in *discovery.BackupInfo, out *BackupInfo, s conversion.Scope) error {
+	return autoConvert_discovery_BackupInfo_To_v1alpha1_BackupInfo(in, out, s)
+}
+
+func autoConvert_v1alpha1_BackupRequest_To_discovery_BackupRequest(in *BackupRequest, out *discovery.BackupRequest, s conversion.Scope) error {
+	out.Take = in.Take
+	return nil
+}
+
+// Convert_v1alpha1_BackupRequest_To_discovery_BackupRequest is an autogenerated conversion function.
+func Convert_v1alpha1_BackupRequest_To_discovery_BackupRequest(in *BackupRequest, out *discovery.BackupRequest, s conversion.Scope) error {
+	return autoConvert_v1alpha1_BackupRequest_To_discovery_BackupRequest(in, out, s)
+}
+
+func autoConvert_discovery_BackupRequest_To_v1alpha1_BackupRequest(in *discovery.BackupRequest, out *BackupRequest, s conversion.Scope) error {
+	out.Take = in.Take
+	return nil
+}
+
+// Convert_discovery_BackupRequest_To_v1alpha1_BackupRequest is an autogenerated conversion function.
+func Convert_discovery_BackupRequest_To_v1alpha1_BackupRequest(in *discovery.BackupRequest, out *BackupRequest, s conversion.Scope) error {
+	return autoConvert_discovery_BackupRequest_To_v1alpha1_BackupRequest(in, out, s)
+}
+
+func autoConvert_v1alpha1_BackupResponse_To_discovery_BackupResponse(in *BackupResponse, out *discovery.BackupResponse, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Backups = *(*[]discovery.BackupInfo)(unsafe.Pointer(&in.Backups))
+	out.Reason = in.Reason
+	return nil
+}
+
+// Convert_v1alpha1_BackupResponse_To_discovery_BackupResponse is an autogenerated conversion function.
+func Convert_v1alpha1_BackupResponse_To_discovery_BackupResponse(in *BackupResponse, out *discovery.BackupResponse, s conversion.Scope) error {
+	return autoConvert_v1alpha1_BackupResponse_To_discovery_BackupResponse(in, out, s)
+}
+
+func autoConvert_discovery_BackupResponse_To_v1alpha1_BackupResponse(in *discovery.BackupResponse, out *BackupResponse, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Backups = *(*[]BackupInfo)(unsafe.Pointer(&in.Backups))
+	out.Reason = in.Reason
+	return nil
+}
+
+// Convert_discovery_BackupResponse_To_v1alpha1_BackupResponse is an autogenerated conversion function.
+func Convert_discovery_BackupResponse_To_v1alpha1_BackupResponse(in *discovery.BackupResponse, out *BackupResponse, s conversion.Scope) error {
+	return autoConvert_discovery_BackupResponse_To_v1alpha1_BackupResponse(in, out, s)
+}
+
+func autoConvert_v1alpha1_Certificate_To_discovery_Certificate(in *Certificate, out *discovery.Certificate, s conversion.Scope) error {
+	out.Request = (*discovery.CertificateRequest)(unsafe.Pointer(in.Request))
+	out.Response = (*discovery.CertificateResponse)(unsafe.Pointer(in.Response))
+	return nil
+}
+
+// Convert_v1alpha1_Certificate_To_discovery_Certificate is an autogenerated conversion function.
+func Convert_v1alpha1_Certificate_To_discovery_Certificate(in *Certificate, out *discovery.Certificate, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Certificate_To_discovery_Certificate(in, out, s)
+}
+
+func autoConvert_discovery_Certificate_To_v1alpha1_Certificate(in *discovery.Certificate, out *Certificate, s conversion.Scope) error {
+	out.Request = (*CertificateRequest)(unsafe.Pointer(in.Request))
+	out.Response = (*CertificateResponse)(unsafe.Pointer(in.Response))
+	return nil
+}
+
+// Convert_discovery_Certificate_To_v1alpha1_Certificate is an autogenerated conversion function.
+func Convert_discovery_Certificate_To_v1alpha1_Certificate(in *discovery.Certificate, out *Certificate, s conversion.Scope) error {
+	return autoConvert_discovery_Certificate_To_v1alpha1_Certificate(in, out, s)
+}
+
+func autoConvert_v1alpha1_CertificateRequest_To_discovery_CertificateRequest(in *CertificateRequest, out *discovery.CertificateRequest, s conversion.Scope) error {
+	out.ID = in.ID
+	out.PeerCSR = in.PeerCSR
+	out.ServerCSR = in.ServerCSR
+	out.HealthcheckClientCSR = in.HealthcheckClientCSR
+	return nil
+}
+
+// Convert_v1alpha1_CertificateRequest_To_discovery_CertificateRequest is an autogenerated conversion function.
+func Convert_v1alpha1_CertificateRequest_To_discovery_CertificateRequest(in *CertificateRequest, out *discovery.CertificateRequest, s conversion.Scope) error {
+	return autoConvert_v1alpha1_CertificateRequest_To_discovery_CertificateRequest(in, out, s)
+}
+
+func autoConvert_discovery_CertificateRequest_To_v1alpha1_CertificateRequest(in *discovery.CertificateRequest, out *CertificateRequest, s conversion.Scope) error {
+	out.ID = in.ID
+	out.PeerCSR = in.PeerCSR
+	out.ServerCSR = in.ServerCSR
+	out.HealthcheckClientCSR = in.HealthcheckClientCSR
+	return nil
+}
+
+// Convert_discovery_CertificateRequest_To_v1alpha1_CertificateRequest is an autogenerated conversion function.
+func Convert_discovery_CertificateRequest_To_v1alpha1_CertificateRequest(in *discovery.CertificateRequest, out *CertificateRequest, s conversion.Scope) error {
+	return autoConvert_discovery_CertificateRequest_To_v1alpha1_CertificateRequest(in, out, s)
+}
+
+func autoConvert_v1alpha1_CertificateResponse_To_discovery_CertificateResponse(in *CertificateResponse, out *discovery.CertificateResponse, s conversion.Scope) error {
+	out.ID = in.ID
+	out.CACert = in.CACert
+	out.PeerCert = in.PeerCert
+	out.ServerCert = in.ServerCert
+	out.HealthcheckClientCert = in.HealthcheckClientCert
+	out.Term = in.Term
+	out.Reason = in.Reason
+	return nil
+}
+
+// Convert_v1alpha1_CertificateResponse_To_discovery_CertificateResponse is an autogenerated conversion function.
+func Convert_v1alpha1_CertificateResponse_To_discovery_CertificateResponse(in *CertificateResponse, out *discovery.CertificateResponse, s conversion.Scope) error {
+	return autoConvert_v1alpha1_CertificateResponse_To_discovery_CertificateResponse(in, out, s)
+}
+
+func autoConvert_discovery_CertificateResponse_To_v1alpha1_CertificateResponse(in *discovery.CertificateResponse, out *CertificateResponse, s conversion.Scope) error {
+	out.ID = in.ID
+	out.CACert = in.CACert
+	out.PeerCert = in.PeerCert
+	out.ServerCert = in.ServerCert
+	out.HealthcheckClientCert = in.HealthcheckClientCert
+	out.Term = in.Term
+	out.Reason = in.Reason
+	return nil
+}
+
+// Convert_discovery_CertificateResponse_To_v1alpha1_CertificateResponse is an autogenerated conversion function.
+func Convert_discovery_CertificateResponse_To_v1alpha1_CertificateResponse(in *discovery.CertificateResponse, out *CertificateResponse, s conversion.Scope) error {
+	return autoConvert_discovery_CertificateResponse_To_v1alpha1_CertificateResponse(in, out, s)
+}
+
+func autoConvert_v1alpha1_EtcdCluster_To_discovery_EtcdCluster(in *EtcdCluster, out *discovery.EtcdCluster, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha1_EtcdClusterSpec_To_discovery_EtcdClusterSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_v1alpha1_EtcdClusterStatus_To_discovery_EtcdClusterStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha1_EtcdCluster_To_discovery_EtcdCluster is an autogenerated conversion function.
+func Convert_v1alpha1_EtcdCluster_To_discovery_EtcdCluster(in *EtcdCluster, out *discovery.EtcdCluster, s conversion.Scope) error {
+	return autoConvert_v1alpha1_EtcdCluster_To_discovery_EtcdCluster(in, out, s)
+}
+
+func autoConvert_discovery_EtcdCluster_To_v1alpha1_EtcdCluster(in *discovery.EtcdCluster, out *EtcdCluster, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_discovery_EtcdClusterSpec_To_v1alpha1_EtcdClusterSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_discovery_EtcdClusterStatus_To_v1alpha1_EtcdClusterStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_discovery_EtcdCluster_To_v1alpha1_EtcdCluster is an autogenerated conversion function.
+func Convert_discovery_EtcdCluster_To_v1alpha1_EtcdCluster(in *discovery.EtcdCluster, out *EtcdCluster, s conversion.Scope) error {
+	return autoConvert_discovery_EtcdCluster_To_v1alpha1_EtcdCluster(in, out, s)
+}
+
+func autoConvert_v1alpha1_EtcdClusterCondition_To_discovery_EtcdClusterCondition(in *EtcdClusterCondition, out *discovery.EtcdClusterCondition, s conversion.Scope) error {
+	out.Type = discovery.EtcdClusterConditionType(in.Type)
+	out.Status = discovery.ConditionStatus(in.Status)
+	out.LastTransitionTime = in.LastTransitionTime
+	out.Reason = in.Reason
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_v1alpha1_EtcdClusterCondition_To_discovery_EtcdClusterCondition is an autogenerated conversion function.
+func Convert_v1alpha1_EtcdClusterCondition_To_discovery_EtcdClusterCondition(in *EtcdClusterCondition, out *discovery.EtcdClusterCondition, s conversion.Scope) error {
+	return autoConvert_v1alpha1_EtcdClusterCondition_To_discovery_EtcdClusterCondition(in, out, s)
+}
+
+func autoConvert_discovery_EtcdClusterCondition_To_v1alpha1_EtcdClusterCondition(in *discovery.EtcdClusterCondition, out *EtcdClusterCondition, s conversion.Scope) error {
+	out.Type = EtcdClusterConditionType(in.Type)
+	out.Status = ConditionStatus(in.Status)
+	out.LastTransitionTime = in.LastTransitionTime
+	out.Reason = in.Reason
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_discovery_EtcdClusterCondition_To_v1alpha1_EtcdClusterCondition is an autogenerated conversion function.
+func Convert_discovery_EtcdClusterCondition_To_v1alpha1_EtcdClusterCondition(in *discovery.EtcdClusterCondition, out *EtcdClusterCondition, s conversion.Scope) error {
+	return autoConvert_discovery_EtcdClusterCondition_To_v1alpha1_EtcdClusterCondition(in, out, s)
+}
+
+func autoConvert_v1alpha1_EtcdClusterList_To_discovery_EtcdClusterList(in *EtcdClusterList, out *discovery.EtcdClusterList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	out.Items = *(*[]discovery.EtcdCluster)(unsafe.Pointer(&in.Items))
+	return nil
+}
+
+// Convert_v1alpha1_EtcdClusterList_To_discovery_EtcdClusterList is an autogenerated conversion function.
+func Convert_v1alpha1_EtcdClusterList_To_discovery_EtcdClusterList(in *EtcdClusterList, out *discovery.EtcdClusterList, s conversion.Scope) error {
+	return autoConvert_v1alpha1_EtcdClusterList_To_discovery_EtcdClusterList(in, out, s)
+}
+
+func autoConvert_discovery_EtcdClusterList_To_v1alpha1_EtcdClusterList(in *discovery.EtcdClusterList, out *EtcdClusterList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	out.Items = *(*[]EtcdCluster)(unsafe.Pointer(&in.Items))
+	return nil
+}
+
+// Convert_discovery_EtcdClusterList_To_v1alpha1_EtcdClusterList is an autogenerated conversion function.
+func Convert_discovery_EtcdClusterList_To_v1alpha1_EtcdClusterList(in *discovery.EtcdClusterList, out *EtcdClusterList, s conversion.Scope) error {
+	return autoConvert_discovery_EtcdClusterList_To_v1alpha1_EtcdClusterList(in, out, s)
+}
+
+func autoConvert_v1alpha1_EtcdClusterMember_To_discovery_EtcdClusterMember(in *EtcdClusterMember, out *discovery.EtcdClusterMember, s conversion.Scope) error {
+	out.ID = in.ID
+	out.Name = in.Name
+	out.PeerURLs = *(*[]string)(unsafe.Pointer(&in.PeerURLs))
+	out.ClientURLs = *(*[]string)(unsafe.Pointer(&in.ClientURLs))
+	out.Version = in.Version
+	out.IsLeader = in.IsLeader
+	out.DBSize = in.DBSize
+	out.RaftIndex = in.RaftIndex
+	out.RaftTerm = in.RaftTerm
+	out.Alarms = *(*[]string)(unsafe.Pointer(&in.Alarms))
+	out.CertificateExpiry = (*v1.Time)(unsafe.Pointer(in.CertificateExpiry))
+	out.Conditions = *(*[]discovery.EtcdClusterCondition)(unsafe.Pointer(&in.Conditions))
+	return nil
+}
+
+// Convert_v1alpha1_EtcdClusterMember_To_discovery_EtcdClusterMember is an autogenerated conversion function.
+func Convert_v1alpha1_EtcdClusterMember_To_discovery_EtcdClusterMember(in *EtcdClusterMember, out *discovery.EtcdClusterMember, s conversion.Scope) error {
+	return autoConvert_v1alpha1_EtcdClusterMember_To_discovery_EtcdClusterMember(in, out, s)
+}
+
+func autoConvert_discovery_EtcdClusterMember_To_v1alpha1_EtcdClusterMember(in *discovery.EtcdClusterMember, out *EtcdClusterMember, s conversion.Scope) error {
+	out.ID = in.ID
+	out.Name = in.Name
+	out.PeerURLs = *(*[]string)(unsafe.Pointer(&in.PeerURLs))
+	out.ClientURLs = *(*[]string)(unsafe.Pointer(&in.ClientURLs))
+	out.Version = in.Version
+	out.IsLeader = in.IsLeader
+	out.DBSize = in.DBSize
+	out.RaftIndex = in.RaftIndex
+	out.RaftTerm = in.RaftTerm
+	out.Alarms = *(*[]string)(unsafe.Pointer(&in.Alarms))
+	out.CertificateExpiry = (*v1.Time)(unsafe.Pointer(in.CertificateExpiry))
+	out.Conditions = *(*[]EtcdClusterCondition)(unsafe.Pointer(&in.Conditions))
+	return nil
+}
+
+// Convert_discovery_EtcdClusterMember_To_v1alpha1_EtcdClusterMember is an autogenerated conversion function.
+func Convert_discovery_EtcdClusterMember_To_v1alpha1_EtcdClusterMember(in *discovery.EtcdClusterMember, out *EtcdClusterMember, s conversion.Scope) error {
+	return autoConvert_discovery_EtcdClusterMember_To_v1alpha1_EtcdClusterMember(in, out, s)
+}
+
+func autoConvert_v1alpha1_EtcdClusterSpec_To_discovery_EtcdClusterSpec(in *EtcdClusterSpec, out *discovery.EtcdClusterSpec, s conversion.Scope) error {
+	out.ClusterSize = in.ClusterSize
+	out.EtcdVersion = in.EtcdVersion
+	out.Quarantine = discovery.QuarantineMode(in.Quarantine)
+	return nil
+}
+
+// Convert_v1alpha1_EtcdClusterSpec_To_discovery_EtcdClusterSpec is an autogenerated conversion function.
+func Convert_v1alpha1_EtcdClusterSpec_To_discovery_EtcdClusterSpec(in *EtcdClusterSpec, out *discovery.EtcdClusterSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_EtcdClusterSpec_To_discovery_EtcdClusterSpec(in, out, s)
+}
+
+func autoConvert_discovery_EtcdClusterSpec_To_v1alpha1_EtcdClusterSpec(in *discovery.EtcdClusterSpec, out *EtcdClusterSpec, s conversion.Scope) error {
+	out.ClusterSize = in.ClusterSize
+	out.EtcdVersion = in.EtcdVersion
+	out.Quarantine = QuarantineMode(in.Quarantine)
+	return nil
+}
+
+// Convert_discovery_EtcdClusterSpec_To_v1alpha1_EtcdClusterSpec is an autogenerated conversion function.
+func Convert_discovery_EtcdClusterSpec_To_v1alpha1_EtcdClusterSpec(in *discovery.EtcdClusterSpec, out *EtcdClusterSpec, s conversion.Scope) error {
+	return autoConvert_discovery_EtcdClusterSpec_To_v1alpha1_EtcdClusterSpec(in, out, s)
+}
+
+func autoConvert_v1alpha1_EtcdClusterStatus_To_discovery_EtcdClusterStatus(in *EtcdClusterStatus, out *discovery.EtcdClusterStatus, s conversion.Scope) error {
+	out.Leader = in.Leader
+	out.Term = in.Term
+	out.Members = *(*[]discovery.EtcdClusterMember)(unsafe.Pointer(&in.Members))
+	out.LastBackupTime = (*v1.Time)(unsafe.Pointer(in.LastBackupTime))
+	out.Conditions = *(*[]discovery.EtcdClusterCondition)(unsafe.Pointer(&in.Conditions))
+	out.ProcessExits = *(*[]discovery.EtcdProcessExit)(unsafe.Pointer(&in.ProcessExits))
+	return nil
+}
+
+// Convert_v1alpha1_EtcdClusterStatus_To_discovery_EtcdClusterStatus is an autogenerated conversion function.
+func Convert_v1alpha1_EtcdClusterStatus_To_discovery_EtcdClusterStatus(in *EtcdClusterStatus, out *discovery.EtcdClusterStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_EtcdClusterStatus_To_discovery_EtcdClusterStatus(in, out, s)
+}
+
+func autoConvert_discovery_EtcdClusterStatus_To_v1alpha1_EtcdClusterStatus(in *discovery.EtcdClusterStatus, out *EtcdClusterStatus, s conversion.Scope) error {
+	out.Leader = in.Leader
+	out.Term = in.Term
+	out.Members = *(*[]EtcdClusterMember)(unsafe.Pointer(&in.Members))
+	out.LastBackupTime = (*v1.Time)(unsafe.Pointer(in.LastBackupTime))
+	out.Conditions = *(*[]EtcdClusterCondition)(unsafe.Pointer(&in.Conditions))
+	out.ProcessExits = *(*[]EtcdProcessExit)(unsafe.Pointer(&in.ProcessExits))
+	return nil
+}
+
+// Convert_discovery_EtcdClusterStatus_To_v1alpha1_EtcdClusterStatus is an autogenerated conversion function.
+func Convert_discovery_EtcdClusterStatus_To_v1alpha1_EtcdClusterStatus(in *discovery.EtcdClusterStatus, out *EtcdClusterStatus, s conversion.Scope) error {
+	return autoConvert_discovery_EtcdClusterStatus_To_v1alpha1_EtcdClusterStatus(in, out, s)
+}
+
+func autoConvert_v1alpha1_EtcdProcessExit_To_discovery_EtcdProcessExit(in *EtcdProcessExit, out *discovery.EtcdProcessExit, s conversion.Scope) error {
+	out.Time = in.Time
+	out.ExitCode = in.ExitCode
+	out.Message = in.Message
+	out.Expected = in.Expected
+	return nil
+}
+
+// Convert_v1alpha1_EtcdProcessExit_To_discovery_EtcdProcessExit is an autogenerated conversion function.
+func Convert_v1alpha1_EtcdProcessExit_To_discovery_EtcdProcessExit(in *EtcdProcessExit, out *discovery.EtcdProcessExit, s conversion.Scope) error {
+	return autoConvert_v1alpha1_EtcdProcessExit_To_discovery_EtcdProcessExit(in, out, s)
+}
+
+func autoConvert_discovery_EtcdProcessExit_To_v1alpha1_EtcdProcessExit(in *discovery.EtcdProcessExit, out *EtcdProcessExit, s conversion.Scope) error {
+	out.Time = in.Time
+	out.ExitCode = in.ExitCode
+	out.Message = in.Message
+	out.Expected = in.Expected
+	return nil
+}
+
+// Convert_discovery_EtcdProcessExit_To_v1alpha1_EtcdProcessExit is an autogenerated conversion function.
+func Convert_discovery_EtcdProcessExit_To_v1alpha1_EtcdProcessExit(in *discovery.EtcdProcessExit, out *EtcdProcessExit, s conversion.Scope) error {
+	return autoConvert_discovery_EtcdProcessExit_To_v1alpha1_EtcdProcessExit(in, out, s)
+}
+
 func autoConvert_v1alpha1_Member_To_discovery_Member(in *Member, out *discovery.Member, s conversion.Scope) error {
 	out.Request = (*discovery.MemberRequest)(unsafe.Pointer(in.Request))
 	out.Response = (*discovery.MemberResponse)(unsafe.Pointer(in.Response))
@@ -121,6 +576,80 @@ func Convert_discovery_MemberResponse_To_v1alpha1_MemberResponse(in *discovery.M
 	return autoConvert_discovery_MemberResponse_To_v1alpha1_MemberResponse(in, out, s)
 }
 
+func autoConvert_v1alpha1_Migration_To_discovery_Migration(in *Migration, out *discovery.Migration, s conversion.Scope) error {
+	out.Request = (*discovery.MigrationRequest)(unsafe.Pointer(in.Request))
+	out.Response = (*discovery.MigrationResponse)(unsafe.Pointer(in.Response))
+	return nil
+}
+
+// Convert_v1alpha1_Migration_To_discovery_Migration is an autogenerated conversion function.
+func Convert_v1alpha1_Migration_To_discovery_Migration(in *Migration, out *discovery.Migration, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Migration_To_discovery_Migration(in, out, s)
+}
+
+func autoConvert_discovery_Migration_To_v1alpha1_Migration(in *discovery.Migration, out *Migration, s conversion.Scope) error {
+	out.Request = (*MigrationRequest)(unsafe.Pointer(in.Request))
+	out.Response = (*MigrationResponse)(unsafe.Pointer(in.Response))
+	return nil
+}
+
+// Convert_discovery_Migration_To_v1alpha1_Migration is an autogenerated conversion function.
+func Convert_discovery_Migration_To_v1alpha1_Migration(in *discovery.Migration, out *Migration, s conversion.Scope) error {
+	return autoConvert_discovery_Migration_To_v1alpha1_Migration(in, out, s)
+}
+
+func autoConvert_v1alpha1_MigrationRequest_To_discovery_MigrationRequest(in *MigrationRequest, out *discovery.MigrationRequest, s conversion.Scope) error {
+	out.Leader = in.Leader
+	out.Term = in.Term
+	out.Phase = discovery.MigrationPhase(in.Phase)
+	out.FromVersion = in.FromVersion
+	out.ToVersion = in.ToVersion
+	return nil
+}
+
+// Convert_v1alpha1_MigrationRequest_To_discovery_MigrationRequest is an autogenerated conversion function.
+func Convert_v1alpha1_MigrationRequest_To_discovery_MigrationRequest(in *MigrationRequest, out *discovery.MigrationRequest, s conversion.Scope) error {
+	return autoConvert_v1alpha1_MigrationRequest_To_discovery_MigrationRequest(in, out, s)
+}
+
+func autoConvert_discovery_MigrationRequest_To_v1alpha1_MigrationRequest(in *discovery.MigrationRequest, out *MigrationRequest, s conversion.Scope) error {
+	out.Leader = in.Leader
+	out.Term = in.Term
+	out.Phase = MigrationPhase(in.Phase)
+	out.FromVersion = in.FromVersion
+	out.ToVersion = in.ToVersion
+	return nil
+}
+
+// Convert_discovery_MigrationRequest_To_v1alpha1_MigrationRequest is an autogenerated conversion function.
+func Convert_discovery_MigrationRequest_To_v1alpha1_MigrationRequest(in *discovery.MigrationRequest, out *MigrationRequest, s conversion.Scope) error {
+	return autoConvert_discovery_MigrationRequest_To_v1alpha1_MigrationRequest(in, out, s)
+}
+
+func autoConvert_v1alpha1_MigrationResponse_To_discovery_MigrationResponse(in *MigrationResponse, out *discovery.MigrationResponse, s conversion.Scope) error {
+	out.Phase = discovery.MigrationPhase(in.Phase)
+	out.Ready = in.Ready
+	out.Reason = in.Reason
+	return nil
+}
+
+// Convert_v1alpha1_MigrationResponse_To_discovery_MigrationResponse is an autogenerated conversion function.
+func Convert_v1alpha1_MigrationResponse_To_discovery_MigrationResponse(in *MigrationResponse, out *discovery.MigrationResponse, s conversion.Scope) error {
+	return autoConvert_v1alpha1_MigrationResponse_To_discovery_MigrationResponse(in, out, s)
+}
+
+func autoConvert_discovery_MigrationResponse_To_v1alpha1_MigrationResponse(in *discovery.MigrationResponse, out *MigrationResponse, s conversion.Scope) error {
+	out.Phase = MigrationPhase(in.Phase)
+	out.Ready = in.Ready
+	out.Reason = in.Reason
+	return nil
+}
+
+// Convert_discovery_MigrationResponse_To_v1alpha1_MigrationResponse is an autogenerated conversion function.
+func Convert_discovery_MigrationResponse_To_v1alpha1_MigrationResponse(in *discovery.MigrationResponse, out *MigrationResponse, s conversion.Scope) error {
+	return autoConvert_discovery_MigrationResponse_To_v1alpha1_MigrationResponse(in, out, s)
+}
+
 func autoConvert_v1alpha1_PeerInfo_To_discovery_PeerInfo(in *PeerInfo, out *discovery.PeerInfo, s conversion.Scope) error {
 	out.ID = in.ID
 	out.Hosts = *(*[]string)(unsafe.Pointer(&in.Hosts))
@@ -166,6 +695,9 @@ func Convert_discovery_Ping_To_v1alpha1_Ping(in *discovery.Ping, out *Ping, s co
 }
 
 func autoConvert_v1alpha1_PingRequest_To_discovery_PingRequest(in *PingRequest, out *discovery.PingRequest, s conversion.Scope) error {
+	out.Info = (*discovery.PeerInfo)(unsafe.Pointer(in.Info))
+	out.Term = in.Term
+	out.Leader = in.Leader
 	return nil
 }
 
@@ -175,6 +707,9 @@ func Convert_v1alpha1_PingRequest_To_discovery_PingRequest(in *PingRequest, out
 }
 
 func autoConvert_discovery_PingRequest_To_v1alpha1_PingRequest(in *discovery.PingRequest, out *PingRequest, s conversion.Scope) error {
+	out.Info = (*PeerInfo)(unsafe.Pointer(in.Info))
+	out.Term = in.Term
+	out.Leader = in.Leader
 	return nil
 }
 
@@ -185,6 +720,9 @@ func Convert_discovery_PingRequest_To_v1alpha1_PingRequest(in *discovery.PingReq
 
 func autoConvert_v1alpha1_PingResponse_To_discovery_PingResponse(in *PingResponse, out *discovery.PingResponse, s conversion.Scope) error {
 	out.Info = (*discovery.PeerInfo)(unsafe.Pointer(in.Info))
+	out.Term = in.Term
+	out.Leader = in.Leader
+	out.LeaseGranted = in.LeaseGranted
 	return nil
 }
 
@@ -195,6 +733,9 @@ func Convert_v1alpha1_PingResponse_To_discovery_PingResponse(in *PingResponse, o
 
 func autoConvert_discovery_PingResponse_To_v1alpha1_PingResponse(in *discovery.PingResponse, out *PingResponse, s conversion.Scope) error {
 	out.Info = (*PeerInfo)(unsafe.Pointer(in.Info))
+	out.Term = in.Term
+	out.Leader = in.Leader
+	out.LeaseGranted = in.LeaseGranted
 	return nil
 }
 
@@ -202,3 +743,521 @@ func autoConvert_discovery_PingResponse_To_v1alpha1_PingResponse(in *discovery.P
 func Convert_discovery_PingResponse_To_v1alpha1_PingResponse(in *discovery.PingResponse, out *PingResponse, s conversion.Scope) error {
 	return autoConvert_discovery_PingResponse_To_v1alpha1_PingResponse(in, out, s)
 }
+
+func autoConvert_v1alpha1_Plan_To_discovery_Plan(in *Plan, out *discovery.Plan, s conversion.Scope) error {
+	out.Request = (*discovery.PlanRequest)(unsafe.Pointer(in.Request))
+	out.Response = (*discovery.PlanResponse)(unsafe.Pointer(in.Response))
+	return nil
+}
+
+// Convert_v1alpha1_Plan_To_discovery_Plan is an autogenerated conversion function.
+func Convert_v1alpha1_Plan_To_discovery_Plan(in *Plan, out *discovery.Plan, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Plan_To_discovery_Plan(in, out, s)
+}
+
+func autoConvert_discovery_Plan_To_v1alpha1_Plan(in *discovery.Plan, out *Plan, s conversion.Scope) error {
+	out.Request = (*PlanRequest)(unsafe.Pointer(in.Request))
+	out.Response = (*PlanResponse)(unsafe.Pointer(in.Response))
+	return nil
+}
+
+// Convert_discovery_Plan_To_v1alpha1_Plan is an autogenerated conversion function.
+func Convert_discovery_Plan_To_v1alpha1_Plan(in *discovery.Plan, out *Plan, s conversion.Scope) error {
+	return autoConvert_discovery_Plan_To_v1alpha1_Plan(in, out, s)
+}
+
+func autoConvert_v1alpha1_PlanRequest_To_discovery_PlanRequest(in *PlanRequest, out *discovery.PlanRequest, s conversion.Scope) error {
+	out.Leader = in.Leader
+	out.Term = in.Term
+	out.ClusterName = in.ClusterName
+	out.ClusterToken = in.ClusterToken
+	out.InitialCluster = *(*[]string)(unsafe.Pointer(&in.InitialCluster))
+	out.EtcdVersion = in.EtcdVersion
+	out.Backup = in.Backup
+	out.Revision = in.Revision
+	return nil
+}
+
+// Convert_v1alpha1_PlanRequest_To_discovery_PlanRequest is an autogenerated conversion function.
+func Convert_v1alpha1_PlanRequest_To_discovery_PlanRequest(in *PlanRequest, out *discovery.PlanRequest, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PlanRequest_To_discovery_PlanRequest(in, out, s)
+}
+
+func autoConvert_discovery_PlanRequest_To_v1alpha1_PlanRequest(in *discovery.PlanRequest, out *PlanRequest, s conversion.Scope) error {
+	out.Leader = in.Leader
+	out.Term = in.Term
+	out.ClusterName = in.ClusterName
+	out.ClusterToken = in.ClusterToken
+	out.InitialCluster = *(*[]string)(unsafe.Pointer(&in.InitialCluster))
+	out.EtcdVersion = in.EtcdVersion
+	out.Backup = in.Backup
+	out.Revision = in.Revision
+	return nil
+}
+
+// Convert_discovery_PlanRequest_To_v1alpha1_PlanRequest is an autogenerated conversion function.
+func Convert_discovery_PlanRequest_To_v1alpha1_PlanRequest(in *discovery.PlanRequest, out *PlanRequest, s conversion.Scope) error {
+	return autoConvert_discovery_PlanRequest_To_v1alpha1_PlanRequest(in, out, s)
+}
+
+func autoConvert_v1alpha1_PlanResponse_To_discovery_PlanResponse(in *PlanResponse, out *discovery.PlanResponse, s conversion.Scope) error {
+	out.Accepted = in.Accepted
+	out.Reason = in.Reason
+	return nil
+}
+
+// Convert_v1alpha1_PlanResponse_To_discovery_PlanResponse is an autogenerated conversion function.
+func Convert_v1alpha1_PlanResponse_To_discovery_PlanResponse(in *PlanResponse, out *discovery.PlanResponse, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PlanResponse_To_discovery_PlanResponse(in, out, s)
+}
+
+func autoConvert_discovery_PlanResponse_To_v1alpha1_PlanResponse(in *discovery.PlanResponse, out *PlanResponse, s conversion.Scope) error {
+	out.Accepted = in.Accepted
+	out.Reason = in.Reason
+	return nil
+}
+
+// Convert_discovery_PlanResponse_To_v1alpha1_PlanResponse is an autogenerated conversion function.
+func Convert_discovery_PlanResponse_To_v1alpha1_PlanResponse(in *discovery.PlanResponse, out *PlanResponse, s conversion.Scope) error {
+	return autoConvert_discovery_PlanResponse_To_v1alpha1_PlanResponse(in, out, s)
+}
+
+func autoConvert_v1alpha1_Quarantine_To_discovery_Quarantine(in *Quarantine, out *discovery.Quarantine, s conversion.Scope) error {
+	out.Request = (*discovery.QuarantineRequest)(unsafe.Pointer(in.Request))
+	out.Response = (*discovery.QuarantineResponse)(unsafe.Pointer(in.Response))
+	return nil
+}
+
+// Convert_v1alpha1_Quarantine_To_discovery_Quarantine is an autogenerated conversion function.
+func Convert_v1alpha1_Quarantine_To_discovery_Quarantine(in *Quarantine, out *discovery.Quarantine, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Quarantine_To_discovery_Quarantine(in, out, s)
+}
+
+func autoConvert_discovery_Quarantine_To_v1alpha1_Quarantine(in *discovery.Quarantine, out *Quarantine, s conversion.Scope) error {
+	out.Request = (*QuarantineRequest)(unsafe.Pointer(in.Request))
+	out.Response = (*QuarantineResponse)(unsafe.Pointer(in.Response))
+	return nil
+}
+
+// Convert_discovery_Quarantine_To_v1alpha1_Quarantine is an autogenerated conversion function.
+func Convert_discovery_Quarantine_To_v1alpha1_Quarantine(in *discovery.Quarantine, out *Quarantine, s conversion.Scope) error {
+	return autoConvert_discovery_Quarantine_To_v1alpha1_Quarantine(in, out, s)
+}
+
+func autoConvert_v1alpha1_QuarantineMember_To_discovery_QuarantineMember(in *QuarantineMember, out *discovery.QuarantineMember, s conversion.Scope) error {
+	out.ID = in.ID
+	out.Mode = discovery.QuarantineMode(in.Mode)
+	out.Running = in.Running
+	out.Quarantined = in.Quarantined
+	return nil
+}
+
+// Convert_v1alpha1_QuarantineMember_To_discovery_QuarantineMember is an autogenerated conversion function.
+func Convert_v1alpha1_QuarantineMember_To_discovery_QuarantineMember(in *QuarantineMember, out *discovery.QuarantineMember, s conversion.Scope) error {
+	return autoConvert_v1alpha1_QuarantineMember_To_discovery_QuarantineMember(in, out, s)
+}
+
+func autoConvert_discovery_QuarantineMember_To_v1alpha1_QuarantineMember(in *discovery.QuarantineMember, out *QuarantineMember, s conversion.Scope) error {
+	out.ID = in.ID
+	out.Mode = QuarantineMode(in.Mode)
+	out.Running = in.Running
+	out.Quarantined = in.Quarantined
+	return nil
+}
+
+// Convert_discovery_QuarantineMember_To_v1alpha1_QuarantineMember is an autogenerated conversion function.
+func Convert_discovery_QuarantineMember_To_v1alpha1_QuarantineMember(in *discovery.QuarantineMember, out *QuarantineMember, s conversion.Scope) error {
+	return autoConvert_discovery_QuarantineMember_To_v1alpha1_QuarantineMember(in, out, s)
+}
+
+func autoConvert_v1alpha1_QuarantineRequest_To_discovery_QuarantineRequest(in *QuarantineRequest, out *discovery.QuarantineRequest, s conversion.Scope) error {
+	out.Leader = in.Leader
+	out.Term = in.Term
+	out.Mode = discovery.QuarantineMode(in.Mode)
+	return nil
+}
+
+// Convert_v1alpha1_QuarantineRequest_To_discovery_QuarantineRequest is an autogenerated conversion function.
+func Convert_v1alpha1_QuarantineRequest_To_discovery_QuarantineRequest(in *QuarantineRequest, out *discovery.QuarantineRequest, s conversion.Scope) error {
+	return autoConvert_v1alpha1_QuarantineRequest_To_discovery_QuarantineRequest(in, out, s)
+}
+
+func autoConvert_discovery_QuarantineRequest_To_v1alpha1_QuarantineRequest(in *discovery.QuarantineRequest, out *QuarantineRequest, s conversion.Scope) error {
+	out.Leader = in.Leader
+	out.Term = in.Term
+	out.Mode = QuarantineMode(in.Mode)
+	return nil
+}
+
+// Convert_discovery_QuarantineRequest_To_v1alpha1_QuarantineRequest is an autogenerated conversion function.
+func Convert_discovery_QuarantineRequest_To_v1alpha1_QuarantineRequest(in *discovery.QuarantineRequest, out *QuarantineRequest, s conversion.Scope) error {
+	return autoConvert_discovery_QuarantineRequest_To_v1alpha1_QuarantineRequest(in, out, s)
+}
+
+func autoConvert_v1alpha1_QuarantineResponse_To_discovery_QuarantineResponse(in *QuarantineResponse, out *discovery.QuarantineResponse, s conversion.Scope) error {
+	out.Mode = discovery.QuarantineMode(in.Mode)
+	out.Members = *(*[]discovery.QuarantineMember)(unsafe.Pointer(&in.Members))
+	out.Reason = in.Reason
+	return nil
+}
+
+// Convert_v1alpha1_QuarantineResponse_To_discovery_QuarantineResponse is an autogenerated conversion function.
+func Convert_v1alpha1_QuarantineResponse_To_discovery_QuarantineResponse(in *QuarantineResponse, out *discovery.QuarantineResponse, s conversion.Scope) error {
+	return autoConvert_v1alpha1_QuarantineResponse_To_discovery_QuarantineResponse(in, out, s)
+}
+
+func autoConvert_discovery_QuarantineResponse_To_v1alpha1_QuarantineResponse(in *discovery.QuarantineResponse, out *QuarantineResponse, s conversion.Scope) error {
+	out.Mode = QuarantineMode(in.Mode)
+	out.Members = *(*[]QuarantineMember)(unsafe.Pointer(&in.Members))
+	out.Reason = in.Reason
+	return nil
+}
+
+// Convert_discovery_QuarantineResponse_To_v1alpha1_QuarantineResponse is an autogenerated conversion function.
+func Convert_discovery_QuarantineResponse_To_v1alpha1_QuarantineResponse(in *discovery.QuarantineResponse, out *QuarantineResponse, s conversion.Scope) error {
+	return autoConvert_discovery_QuarantineResponse_To_v1alpha1_QuarantineResponse(in, out, s)
+}
+
+func autoConvert_v1alpha1_Removal_To_discovery_Removal(in *Removal, out *discovery.Removal, s conversion.Scope) error {
+	out.Request = (*discovery.RemovalRequest)(unsafe.Pointer(in.Request))
+	out.Response = (*discovery.RemovalResponse)(unsafe.Pointer(in.Response))
+	return nil
+}
+
+// Convert_v1alpha1_Removal_To_discovery_Removal is an autogenerated conversion function.
+func Convert_v1alpha1_Removal_To_discovery_Removal(in *Removal, out *discovery.Removal, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Removal_To_discovery_Removal(in, out, s)
+}
+
+func autoConvert_discovery_Removal_To_v1alpha1_Removal(in *discovery.Removal, out *Removal, s conversion.Scope) error {
+	out.Request = (*RemovalRequest)(unsafe.Pointer(in.Request))
+	out.Response = (*RemovalResponse)(unsafe.Pointer(in.Response))
+	return nil
+}
+
+// Convert_discovery_Removal_To_v1alpha1_Removal is an autogenerated conversion function.
+func Convert_discovery_Removal_To_v1alpha1_Removal(in *discovery.Removal, out *Removal, s conversion.Scope) error {
+	return autoConvert_discovery_Removal_To_v1alpha1_Removal(in, out, s)
+}
+
+func autoConvert_v1alpha1_RemovalRequest_To_discovery_RemovalRequest(in *RemovalRequest, out *discovery.RemovalRequest, s conversion.Scope) error {
+	out.Member = in.Member
+	return nil
+}
+
+// Convert_v1alpha1_RemovalRequest_To_discovery_RemovalRequest is an autogenerated conversion function.
+func Convert_v1alpha1_RemovalRequest_To_discovery_RemovalRequest(in *RemovalRequest, out *discovery.RemovalRequest, s conversion.Scope) error {
+	return autoConvert_v1alpha1_RemovalRequest_To_discovery_RemovalRequest(in, out, s)
+}
+
+func autoConvert_discovery_RemovalRequest_To_v1alpha1_RemovalRequest(in *discovery.RemovalRequest, out *RemovalRequest, s conversion.Scope) error {
+	out.Member = in.Member
+	return nil
+}
+
+// Convert_discovery_RemovalRequest_To_v1alpha1_RemovalRequest is an autogenerated conversion function.
+func Convert_discovery_RemovalRequest_To_v1alpha1_RemovalRequest(in *discovery.RemovalRequest, out *RemovalRequest, s conversion.Scope) error {
+	return autoConvert_discovery_RemovalRequest_To_v1alpha1_RemovalRequest(in, out, s)
+}
+
+func autoConvert_v1alpha1_RemovalResponse_To_discovery_RemovalResponse(in *RemovalResponse, out *discovery.RemovalResponse, s conversion.Scope) error {
+	out.Members = *(*[]string)(unsafe.Pointer(&in.Members))
+	out.Reason = in.Reason
+	return nil
+}
+
+// Convert_v1alpha1_RemovalResponse_To_discovery_RemovalResponse is an autogenerated conversion function.
+func Convert_v1alpha1_RemovalResponse_To_discovery_RemovalResponse(in *RemovalResponse, out *discovery.RemovalResponse, s conversion.Scope) error {
+	return autoConvert_v1alpha1_RemovalResponse_To_discovery_RemovalResponse(in, out, s)
+}
+
+func autoConvert_discovery_RemovalResponse_To_v1alpha1_RemovalResponse(in *discovery.RemovalResponse, out *RemovalResponse, s conversion.Scope) error {
+	out.Members = *(*[]string)(unsafe.Pointer(&in.Members))
+	out.Reason = in.Reason
+	return nil
+}
+
+// Convert_discovery_RemovalResponse_To_v1alpha1_RemovalResponse is an autogenerated conversion function.
+func Convert_discovery_RemovalResponse_To_v1alpha1_RemovalResponse(in *discovery.RemovalResponse, out *RemovalResponse, s conversion.Scope) error {
+	return autoConvert_discovery_RemovalResponse_To_v1alpha1_RemovalResponse(in, out, s)
+}
+
+func autoConvert_v1alpha1_Restore_To_discovery_Restore(in *Restore, out *discovery.Restore, s conversion.Scope) error {
+	out.Request = (*discovery.RestoreRequest)(unsafe.Pointer(in.Request))
+	out.Response = (*discovery.RestoreResponse)(unsafe.Pointer(in.Response))
+	return nil
+}
+
+// Convert_v1alpha1_Restore_To_discovery_Restore is an autogenerated conversion function.
+func Convert_v1alpha1_Restore_To_discovery_Restore(in *Restore, out *discovery.Restore, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Restore_To_discovery_Restore(in, out, s)
+}
+
+func autoConvert_discovery_Restore_To_v1alpha1_Restore(in *discovery.Restore, out *Restore, s conversion.Scope) error {
+	out.Request = (*RestoreRequest)(unsafe.Pointer(in.Request))
+	out.Response = (*RestoreResponse)(unsafe.Pointer(in.Response))
+	return nil
+}
+
+// Convert_discovery_Restore_To_v1alpha1_Restore is an autogenerated conversion function.
+func Convert_discovery_Restore_To_v1alpha1_Restore(in *discovery.Restore, out *Restore, s conversion.Scope) error {
+	return autoConvert_discovery_Restore_To_v1alpha1_Restore(in, out, s)
+}
+
+func autoConvert_v1alpha1_RestoreRequest_To_discovery_RestoreRequest(in *RestoreRequest, out *discovery.RestoreRequest, s conversion.Scope) error {
+	out.Leader = in.Leader
+	out.Term = in.Term
+	out.Backup = in.Backup
+	return nil
+}
+
+// Convert_v1alpha1_RestoreRequest_To_discovery_RestoreRequest is an autogenerated conversion function.
+func Convert_v1alpha1_RestoreRequest_To_discovery_RestoreRequest(in *RestoreRequest, out *discovery.RestoreRequest, s conversion.Scope) error {
+	return autoConvert_v1alpha1_RestoreRequest_To_discovery_RestoreRequest(in, out, s)
+}
+
+func autoConvert_discovery_RestoreRequest_To_v1alpha1_RestoreRequest(in *discovery.RestoreRequest, out *RestoreRequest, s conversion.Scope) error {
+	out.Leader = in.Leader
+	out.Term = in.Term
+	out.Backup = in.Backup
+	return nil
+}
+
+// Convert_discovery_RestoreRequest_To_v1alpha1_RestoreRequest is an autogenerated conversion function.
+func Convert_discovery_RestoreRequest_To_v1alpha1_RestoreRequest(in *discovery.RestoreRequest, out *RestoreRequest, s conversion.Scope) error {
+	return autoConvert_discovery_RestoreRequest_To_v1alpha1_RestoreRequest(in, out, s)
+}
+
+func autoConvert_v1alpha1_RestoreResponse_To_discovery_RestoreResponse(in *RestoreResponse, out *discovery.RestoreResponse, s conversion.Scope) error {
+	out.Backup = in.Backup
+	out.Members = *(*[]string)(unsafe.Pointer(&in.Members))
+	out.Reason = in.Reason
+	return nil
+}
+
+// Convert_v1alpha1_RestoreResponse_To_discovery_RestoreResponse is an autogenerated conversion function.
+func Convert_v1alpha1_RestoreResponse_To_discovery_RestoreResponse(in *RestoreResponse, out *discovery.RestoreResponse, s conversion.Scope) error {
+	return autoConvert_v1alpha1_RestoreResponse_To_discovery_RestoreResponse(in, out, s)
+}
+
+func autoConvert_discovery_RestoreResponse_To_v1alpha1_RestoreResponse(in *discovery.RestoreResponse, out *RestoreResponse, s conversion.Scope) error {
+	out.Backup = in.Backup
+	out.Members = *(*[]string)(unsafe.Pointer(&in.Members))
+	out.Reason = in.Reason
+	return nil
+}
+
+// Convert_discovery_RestoreResponse_To_v1alpha1_RestoreResponse is an autogenerated conversion function.
+func Convert_discovery_RestoreResponse_To_v1alpha1_RestoreResponse(in *discovery.RestoreResponse, out *RestoreResponse, s conversion.Scope) error {
+	return autoConvert_discovery_RestoreResponse_To_v1alpha1_RestoreResponse(in, out, s)
+}
+
+func autoConvert_v1alpha1_Rotation_To_discovery_Rotation(in *Rotation, out *discovery.Rotation, s conversion.Scope) error {
+	out.Request = (*discovery.RotationRequest)(unsafe.Pointer(in.Request))
+	out.Response = (*discovery.RotationResponse)(unsafe.Pointer(in.Response))
+	return nil
+}
+
+// Convert_v1alpha1_Rotation_To_discovery_Rotation is an autogenerated conversion function.
+func Convert_v1alpha1_Rotation_To_discovery_Rotation(in *Rotation, out *discovery.Rotation, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Rotation_To_discovery_Rotation(in, out, s)
+}
+
+func autoConvert_discovery_Rotation_To_v1alpha1_Rotation(in *discovery.Rotation, out *Rotation, s conversion.Scope) error {
+	out.Request = (*RotationRequest)(unsafe.Pointer(in.Request))
+	out.Response = (*RotationResponse)(unsafe.Pointer(in.Response))
+	return nil
+}
+
+// Convert_discovery_Rotation_To_v1alpha1_Rotation is an autogenerated conversion function.
+func Convert_discovery_Rotation_To_v1alpha1_Rotation(in *discovery.Rotation, out *Rotation, s conversion.Scope) error {
+	return autoConvert_discovery_Rotation_To_v1alpha1_Rotation(in, out, s)
+}
+
+func autoConvert_v1alpha1_RotationRequest_To_discovery_RotationRequest(in *RotationRequest, out *discovery.RotationRequest, s conversion.Scope) error {
+	out.Leader = in.Leader
+	out.Term = in.Term
+	return nil
+}
+
+// Convert_v1alpha1_RotationRequest_To_discovery_RotationRequest is an autogenerated conversion function.
+func Convert_v1alpha1_RotationRequest_To_discovery_RotationRequest(in *RotationRequest, out *discovery.RotationRequest, s conversion.Scope) error {
+	return autoConvert_v1alpha1_RotationRequest_To_discovery_RotationRequest(in, out, s)
+}
+
+func autoConvert_discovery_RotationRequest_To_v1alpha1_RotationRequest(in *discovery.RotationRequest, out *RotationRequest, s conversion.Scope) error {
+	out.Leader = in.Leader
+	out.Term = in.Term
+	return nil
+}
+
+// Convert_discovery_RotationRequest_To_v1alpha1_RotationRequest is an autogenerated conversion function.
+func Convert_discovery_RotationRequest_To_v1alpha1_RotationRequest(in *discovery.RotationRequest, out *RotationRequest, s conversion.Scope) error {
+	return autoConvert_discovery_RotationRequest_To_v1alpha1_RotationRequest(in, out, s)
+}
+
+func autoConvert_v1alpha1_RotationResponse_To_discovery_RotationResponse(in *RotationResponse, out *discovery.RotationResponse, s conversion.Scope) error {
+	out.Rotated = in.Rotated
+	out.Reason = in.Reason
+	return nil
+}
+
+// Convert_v1alpha1_RotationResponse_To_discovery_RotationResponse is an autogenerated conversion function.
+func Convert_v1alpha1_RotationResponse_To_discovery_RotationResponse(in *RotationResponse, out *discovery.RotationResponse, s conversion.Scope) error {
+	return autoConvert_v1alpha1_RotationResponse_To_discovery_RotationResponse(in, out, s)
+}
+
+func autoConvert_discovery_RotationResponse_To_v1alpha1_RotationResponse(in *discovery.RotationResponse, out *RotationResponse, s conversion.Scope) error {
+	out.Rotated = in.Rotated
+	out.Reason = in.Reason
+	return nil
+}
+
+// Convert_discovery_RotationResponse_To_v1alpha1_RotationResponse is an autogenerated conversion function.
+func Convert_discovery_RotationResponse_To_v1alpha1_RotationResponse(in *discovery.RotationResponse, out *RotationResponse, s conversion.Scope) error {
+	return autoConvert_discovery_RotationResponse_To_v1alpha1_RotationResponse(in, out, s)
+}
+
+func autoConvert_v1alpha1_Scale_To_discovery_Scale(in *Scale, out *discovery.Scale, s conversion.Scope) error {
+	out.Request = (*discovery.ScaleRequest)(unsafe.Pointer(in.Request))
+	out.Response = (*discovery.ScaleResponse)(unsafe.Pointer(in.Response))
+	return nil
+}
+
+// Convert_v1alpha1_Scale_To_discovery_Scale is an autogenerated conversion function.
+func Convert_v1alpha1_Scale_To_discovery_Scale(in *Scale, out *discovery.Scale, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Scale_To_discovery_Scale(in, out, s)
+}
+
+func autoConvert_discovery_Scale_To_v1alpha1_Scale(in *discovery.Scale, out *Scale, s conversion.Scope) error {
+	out.Request = (*ScaleRequest)(unsafe.Pointer(in.Request))
+	out.Response = (*ScaleResponse)(unsafe.Pointer(in.Response))
+	return nil
+}
+
+// Convert_discovery_Scale_To_v1alpha1_Scale is an autogenerated conversion function.
+func Convert_discovery_Scale_To_v1alpha1_Scale(in *discovery.Scale, out *Scale, s conversion.Scope) error {
+	return autoConvert_discovery_Scale_To_v1alpha1_Scale(in, out, s)
+}
+
+func autoConvert_v1alpha1_ScaleRequest_To_discovery_ScaleRequest(in *ScaleRequest, out *discovery.ScaleRequest, s conversion.Scope) error {
+	out.Leader = in.Leader
+	out.Term = in.Term
+	out.ClusterSize = in.ClusterSize
+	out.Members = *(*[]string)(unsafe.Pointer(&in.Members))
+	return nil
+}
+
+// Convert_v1alpha1_ScaleRequest_To_discovery_ScaleRequest is an autogenerated conversion function.
+func Convert_v1alpha1_ScaleRequest_To_discovery_ScaleRequest(in *ScaleRequest, out *discovery.ScaleRequest, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ScaleRequest_To_discovery_ScaleRequest(in, out, s)
+}
+
+func autoConvert_discovery_ScaleRequest_To_v1alpha1_ScaleRequest(in *discovery.ScaleRequest, out *ScaleRequest, s conversion.Scope) error {
+	out.Leader = in.Leader
+	out.Term = in.Term
+	out.ClusterSize = in.ClusterSize
+	out.Members = *(*[]string)(unsafe.Pointer(&in.Members))
+	return nil
+}
+
+// Convert_discovery_ScaleRequest_To_v1alpha1_ScaleRequest is an autogenerated conversion function.
+func Convert_discovery_ScaleRequest_To_v1alpha1_ScaleRequest(in *discovery.ScaleRequest, out *ScaleRequest, s conversion.Scope) error {
+	return autoConvert_discovery_ScaleRequest_To_v1alpha1_ScaleRequest(in, out, s)
+}
+
+func autoConvert_v1alpha1_ScaleResponse_To_discovery_ScaleResponse(in *ScaleResponse, out *discovery.ScaleResponse, s conversion.Scope) error {
+	out.ClusterSize = in.ClusterSize
+	out.Members = *(*[]string)(unsafe.Pointer(&in.Members))
+	out.Reason = in.Reason
+	return nil
+}
+
+// Convert_v1alpha1_ScaleResponse_To_discovery_ScaleResponse is an autogenerated conversion function.
+func Convert_v1alpha1_ScaleResponse_To_discovery_ScaleResponse(in *ScaleResponse, out *discovery.ScaleResponse, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ScaleResponse_To_discovery_ScaleResponse(in, out, s)
+}
+
+func autoConvert_discovery_ScaleResponse_To_v1alpha1_ScaleResponse(in *discovery.ScaleResponse, out *ScaleResponse, s conversion.Scope) error {
+	out.ClusterSize = in.ClusterSize
+	out.Members = *(*[]string)(unsafe.Pointer(&in.Members))
+	out.Reason = in.Reason
+	return nil
+}
+
+// Convert_discovery_ScaleResponse_To_v1alpha1_ScaleResponse is an autogenerated conversion function.
+func Convert_discovery_ScaleResponse_To_v1alpha1_ScaleResponse(in *discovery.ScaleResponse, out *ScaleResponse, s conversion.Scope) error {
+	return autoConvert_discovery_ScaleResponse_To_v1alpha1_ScaleResponse(in, out, s)
+}
+
+func autoConvert_v1alpha1_Upgrade_To_discovery_Upgrade(in *Upgrade, out *discovery.Upgrade, s conversion.Scope) error {
+	out.Request = (*discovery.UpgradeRequest)(unsafe.Pointer(in.Request))
+	out.Response = (*discovery.UpgradeResponse)(unsafe.Pointer(in.Response))
+	return nil
+}
+
+// Convert_v1alpha1_Upgrade_To_discovery_Upgrade is an autogenerated conversion function.
+func Convert_v1alpha1_Upgrade_To_discovery_Upgrade(in *Upgrade, out *discovery.Upgrade, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Upgrade_To_discovery_Upgrade(in, out, s)
+}
+
+func autoConvert_discovery_Upgrade_To_v1alpha1_Upgrade(in *discovery.Upgrade, out *Upgrade, s conversion.Scope) error {
+	out.Request = (*UpgradeRequest)(unsafe.Pointer(in.Request))
+	out.Response = (*UpgradeResponse)(unsafe.Pointer(in.Response))
+	return nil
+}
+
+// Convert_discovery_Upgrade_To_v1alpha1_Upgrade is an autogenerated conversion function.
+func Convert_discovery_Upgrade_To_v1alpha1_Upgrade(in *discovery.Upgrade, out *Upgrade, s conversion.Scope) error {
+	return autoConvert_discovery_Upgrade_To_v1alpha1_Upgrade(in, out, s)
+}
+
+func autoConvert_v1alpha1_UpgradeRequest_To_discovery_UpgradeRequest(in *UpgradeRequest, out *discovery.UpgradeRequest, s conversion.Scope) error {
+	out.Leader = in.Leader
+	out.Term = in.Term
+	out.Version = in.Version
+	out.ClusterVersion = in.ClusterVersion
+	return nil
+}
+
+// Convert_v1alpha1_UpgradeRequest_To_discovery_UpgradeRequest is an autogenerated conversion function.
+func Convert_v1alpha1_UpgradeRequest_To_discovery_UpgradeRequest(in *UpgradeRequest, out *discovery.UpgradeRequest, s conversion.Scope) error {
+	return autoConvert_v1alpha1_UpgradeRequest_To_discovery_UpgradeRequest(in, out, s)
+}
+
+func autoConvert_discovery_UpgradeRequest_To_v1alpha1_UpgradeRequest(in *discovery.UpgradeRequest, out *UpgradeRequest, s conversion.Scope) error {
+	out.Leader = in.Leader
+	out.Term = in.Term
+	out.Version = in.Version
+	out.ClusterVersion = in.ClusterVersion
+	return nil
+}
+
+// Convert_discovery_UpgradeRequest_To_v1alpha1_UpgradeRequest is an autogenerated conversion function.
+func Convert_discovery_UpgradeRequest_To_v1alpha1_UpgradeRequest(in *discovery.UpgradeRequest, out *UpgradeRequest, s conversion.Scope) error {
+	return autoConvert_discovery_UpgradeRequest_To_v1alpha1_UpgradeRequest(in, out, s)
+}
+
+func autoConvert_v1alpha1_UpgradeResponse_To_discovery_UpgradeResponse(in *UpgradeResponse, out *discovery.UpgradeResponse, s conversion.Scope) error {
+	out.Version = in.Version
+	out.ClusterVersion = in.ClusterVersion
+	out.Reason = in.Reason
+	return nil
+}
+
+// Convert_v1alpha1_UpgradeResponse_To_discovery_UpgradeResponse is an autogenerated conversion function.
+func Convert_v1alpha1_UpgradeResponse_To_discovery_UpgradeResponse(in *UpgradeResponse, out *discovery.UpgradeResponse, s conversion.Scope) error {
+	return autoConvert_v1alpha1_UpgradeResponse_To_discovery_UpgradeResponse(in, out, s)
+}
+
+func autoConvert_discovery_UpgradeResponse_To_v1alpha1_UpgradeResponse(in *discovery.UpgradeResponse, out *UpgradeResponse, s conversion.Scope) error {
+	out.Version = in.Version
+	out.ClusterVersion = in.ClusterVersion
+	out.Reason = in.Reason
+	return nil
+}
+
+// Convert_discovery_UpgradeResponse_To_v1alpha1_UpgradeResponse is an autogenerated conversion function.
+func Convert_discovery_UpgradeResponse_To_v1alpha1_UpgradeResponse(in *discovery.UpgradeResponse, out *UpgradeResponse, s conversion.Scope) error {
+	return autoConvert_discovery_UpgradeResponse_To_v1alpha1_UpgradeResponse(in, out, s)
+}
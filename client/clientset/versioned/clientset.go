@@ -26,8 +26,6 @@ import (
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	DiscoveryV1alpha1() discoveryv1alpha1.DiscoveryV1alpha1Interface
-	// Deprecated: please explicitly pick a version if possible.
-	Discovery() discoveryv1alpha1.DiscoveryV1alpha1Interface
 }
 
 // Clientset contains the clients for groups. Each group has exactly one
@@ -42,12 +40,6 @@ func (c *Clientset) DiscoveryV1alpha1() discoveryv1alpha1.DiscoveryV1alpha1Inter
 	return c.discoveryV1alpha1
 }
 
-// Deprecated: Discovery retrieves the default version of DiscoveryClient.
-// Please explicitly pick a version.
-func (c *Clientset) Discovery() discoveryv1alpha1.DiscoveryV1alpha1Interface {
-	return c.discoveryV1alpha1
-}
-
 // Discovery retrieves the DiscoveryClient
 func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 	if c == nil {
@@ -0,0 +1,56 @@
+/*
+Copyright 2018 The Pharmer Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	rest "k8s.io/client-go/rest"
+)
+
+// BackupsGetter has a method to return a BackupInterface.
+// A group's client should implement this interface.
+type BackupsGetter interface {
+	Backups() BackupInterface
+}
+
+// BackupInterface has methods to work with Backup resources.
+type BackupInterface interface {
+	Create(*v1alpha1.Backup) (*v1alpha1.Backup, error)
+	BackupExpansion
+}
+
+// backups implements BackupInterface
+type backups struct {
+	client rest.Interface
+}
+
+// newBackups returns a Backups
+func newBackups(c *DiscoveryV1alpha1Client) *backups {
+	return &backups{
+		client: c.RESTClient(),
+	}
+}
+
+// Create takes the representation of a backup and creates it.  Returns the server's representation of the backup, and an error, if there is any.
+func (c *backups) Create(backup *v1alpha1.Backup) (result *v1alpha1.Backup, err error) {
+	result = &v1alpha1.Backup{}
+	err = c.client.Post().
+		Resource("backups").
+		Body(backup).
+		Do().
+		Into(result)
+	return
+}
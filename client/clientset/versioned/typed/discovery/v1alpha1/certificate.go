@@ -0,0 +1,56 @@
+/*
+Copyright 2018 The Pharmer Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	rest "k8s.io/client-go/rest"
+)
+
+// CertificatesGetter has a method to return a CertificateInterface.
+// A group's client should implement this interface.
+type CertificatesGetter interface {
+	Certificates() CertificateInterface
+}
+
+// CertificateInterface has methods to work with Certificate resources.
+type CertificateInterface interface {
+	Create(*v1alpha1.Certificate) (*v1alpha1.Certificate, error)
+	CertificateExpansion
+}
+
+// certificates implements CertificateInterface
+type certificates struct {
+	client rest.Interface
+}
+
+// newCertificates returns a Certificates
+func newCertificates(c *DiscoveryV1alpha1Client) *certificates {
+	return &certificates{
+		client: c.RESTClient(),
+	}
+}
+
+// Create takes the representation of a certificate and creates it.  Returns the server's representation of the certificate, and an error, if there is any.
+func (c *certificates) Create(certificate *v1alpha1.Certificate) (result *v1alpha1.Certificate, err error) {
+	result = &v1alpha1.Certificate{}
+	err = c.client.Post().
+		Resource("certificates").
+		Body(certificate).
+		Do().
+		Into(result)
+	return
+}
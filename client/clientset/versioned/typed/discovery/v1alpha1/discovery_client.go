@@ -24,8 +24,19 @@ import (
 
 type DiscoveryV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	BackupsGetter
+	CertificatesGetter
+	EtcdClustersGetter
 	MembersGetter
+	MigrationsGetter
 	PingsGetter
+	PlansGetter
+	QuarantinesGetter
+	RemovalsGetter
+	RestoresGetter
+	RotationsGetter
+	ScalesGetter
+	UpgradesGetter
 }
 
 // DiscoveryV1alpha1Client is used to interact with features provided by the discovery.etcd-manager.com group.
@@ -33,14 +44,58 @@ type DiscoveryV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *DiscoveryV1alpha1Client) Backups() BackupInterface {
+	return newBackups(c)
+}
+
+func (c *DiscoveryV1alpha1Client) Certificates() CertificateInterface {
+	return newCertificates(c)
+}
+
+func (c *DiscoveryV1alpha1Client) EtcdClusters() EtcdClusterInterface {
+	return newEtcdClusters(c)
+}
+
 func (c *DiscoveryV1alpha1Client) Members() MemberInterface {
 	return newMembers(c)
 }
 
+func (c *DiscoveryV1alpha1Client) Migrations() MigrationInterface {
+	return newMigrations(c)
+}
+
 func (c *DiscoveryV1alpha1Client) Pings() PingInterface {
 	return newPings(c)
 }
 
+func (c *DiscoveryV1alpha1Client) Plans() PlanInterface {
+	return newPlans(c)
+}
+
+func (c *DiscoveryV1alpha1Client) Quarantines() QuarantineInterface {
+	return newQuarantines(c)
+}
+
+func (c *DiscoveryV1alpha1Client) Removals() RemovalInterface {
+	return newRemovals(c)
+}
+
+func (c *DiscoveryV1alpha1Client) Restores() RestoreInterface {
+	return newRestores(c)
+}
+
+func (c *DiscoveryV1alpha1Client) Rotations() RotationInterface {
+	return newRotations(c)
+}
+
+func (c *DiscoveryV1alpha1Client) Scales() ScaleInterface {
+	return newScales(c)
+}
+
+func (c *DiscoveryV1alpha1Client) Upgrades() UpgradeInterface {
+	return newUpgrades(c)
+}
+
 // NewForConfig creates a new DiscoveryV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*DiscoveryV1alpha1Client, error) {
 	config := *c
@@ -0,0 +1,82 @@
+/*
+Copyright 2018 The Pharmer Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	scheme "github.com/etcd-manager/etcd-discovery/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// EtcdClustersGetter has a method to return a EtcdClusterInterface.
+// A group's client should implement this interface.
+type EtcdClustersGetter interface {
+	EtcdClusters() EtcdClusterInterface
+}
+
+// EtcdClusterInterface has methods to work with EtcdCluster resources.
+type EtcdClusterInterface interface {
+	Get(name string, options v1.GetOptions) (*v1alpha1.EtcdCluster, error)
+	List(opts v1.ListOptions) (*v1alpha1.EtcdClusterList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	EtcdClusterExpansion
+}
+
+// etcdClusters implements EtcdClusterInterface
+type etcdClusters struct {
+	client rest.Interface
+}
+
+// newEtcdClusters returns a EtcdClusters
+func newEtcdClusters(c *DiscoveryV1alpha1Client) *etcdClusters {
+	return &etcdClusters{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the etcdCluster, and returns the corresponding etcdCluster object, and an error if there is any.
+func (c *etcdClusters) Get(name string, options v1.GetOptions) (result *v1alpha1.EtcdCluster, err error) {
+	result = &v1alpha1.EtcdCluster{}
+	err = c.client.Get().
+		Resource("etcdclusters").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of EtcdClusters that match those selectors.
+func (c *etcdClusters) List(opts v1.ListOptions) (result *v1alpha1.EtcdClusterList, err error) {
+	result = &v1alpha1.EtcdClusterList{}
+	err = c.client.Get().
+		Resource("etcdclusters").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested etcdClusters.
+func (c *etcdClusters) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.Get().
+		Resource("etcdclusters").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
@@ -25,14 +25,58 @@ type FakeDiscoveryV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakeDiscoveryV1alpha1) Backups() v1alpha1.BackupInterface {
+	return &FakeBackups{c}
+}
+
+func (c *FakeDiscoveryV1alpha1) Certificates() v1alpha1.CertificateInterface {
+	return &FakeCertificates{c}
+}
+
+func (c *FakeDiscoveryV1alpha1) EtcdClusters() v1alpha1.EtcdClusterInterface {
+	return &FakeEtcdClusters{c}
+}
+
 func (c *FakeDiscoveryV1alpha1) Members() v1alpha1.MemberInterface {
 	return &FakeMembers{c}
 }
 
+func (c *FakeDiscoveryV1alpha1) Migrations() v1alpha1.MigrationInterface {
+	return &FakeMigrations{c}
+}
+
 func (c *FakeDiscoveryV1alpha1) Pings() v1alpha1.PingInterface {
 	return &FakePings{c}
 }
 
+func (c *FakeDiscoveryV1alpha1) Plans() v1alpha1.PlanInterface {
+	return &FakePlans{c}
+}
+
+func (c *FakeDiscoveryV1alpha1) Quarantines() v1alpha1.QuarantineInterface {
+	return &FakeQuarantines{c}
+}
+
+func (c *FakeDiscoveryV1alpha1) Removals() v1alpha1.RemovalInterface {
+	return &FakeRemovals{c}
+}
+
+func (c *FakeDiscoveryV1alpha1) Restores() v1alpha1.RestoreInterface {
+	return &FakeRestores{c}
+}
+
+func (c *FakeDiscoveryV1alpha1) Rotations() v1alpha1.RotationInterface {
+	return &FakeRotations{c}
+}
+
+func (c *FakeDiscoveryV1alpha1) Scales() v1alpha1.ScaleInterface {
+	return &FakeScales{c}
+}
+
+func (c *FakeDiscoveryV1alpha1) Upgrades() v1alpha1.UpgradeInterface {
+	return &FakeUpgrades{c}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeDiscoveryV1alpha1) RESTClient() rest.Interface {
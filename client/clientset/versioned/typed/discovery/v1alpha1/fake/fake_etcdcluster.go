@@ -0,0 +1,71 @@
+/*
+Copyright 2018 The Pharmer Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package fake
+
+import (
+	v1alpha1 "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeEtcdClusters implements EtcdClusterInterface
+type FakeEtcdClusters struct {
+	Fake *FakeDiscoveryV1alpha1
+}
+
+var etcdclustersResource = schema.GroupVersionResource{Group: "discovery.etcd-manager.com", Version: "v1alpha1", Resource: "etcdclusters"}
+
+var etcdclustersKind = schema.GroupVersionKind{Group: "discovery.etcd-manager.com", Version: "v1alpha1", Kind: "EtcdCluster"}
+
+// Get takes name of the etcdCluster, and returns the corresponding etcdCluster object, and an error if there is any.
+func (c *FakeEtcdClusters) Get(name string, options v1.GetOptions) (result *v1alpha1.EtcdCluster, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(etcdclustersResource, name), &v1alpha1.EtcdCluster{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.EtcdCluster), err
+}
+
+// List takes label and field selectors, and returns the list of EtcdClusters that match those selectors.
+func (c *FakeEtcdClusters) List(opts v1.ListOptions) (result *v1alpha1.EtcdClusterList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(etcdclustersResource, etcdclustersKind, opts), &v1alpha1.EtcdClusterList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.EtcdClusterList{}
+	for _, item := range obj.(*v1alpha1.EtcdClusterList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested etcdClusters.
+func (c *FakeEtcdClusters) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(etcdclustersResource, opts))
+}
@@ -15,6 +15,28 @@ limitations under the License.
 */
 package v1alpha1
 
+type BackupExpansion interface{}
+
+type CertificateExpansion interface{}
+
+type EtcdClusterExpansion interface{}
+
 type MemberExpansion interface{}
 
+type MigrationExpansion interface{}
+
 type PingExpansion interface{}
+
+type PlanExpansion interface{}
+
+type QuarantineExpansion interface{}
+
+type RemovalExpansion interface{}
+
+type RestoreExpansion interface{}
+
+type RotationExpansion interface{}
+
+type ScaleExpansion interface{}
+
+type UpgradeExpansion interface{}
@@ -0,0 +1,56 @@
+/*
+Copyright 2018 The Pharmer Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	rest "k8s.io/client-go/rest"
+)
+
+// MigrationsGetter has a method to return a MigrationInterface.
+// A group's client should implement this interface.
+type MigrationsGetter interface {
+	Migrations() MigrationInterface
+}
+
+// MigrationInterface has methods to work with Migration resources.
+type MigrationInterface interface {
+	Create(*v1alpha1.Migration) (*v1alpha1.Migration, error)
+	MigrationExpansion
+}
+
+// migrations implements MigrationInterface
+type migrations struct {
+	client rest.Interface
+}
+
+// newMigrations returns a Migrations
+func newMigrations(c *DiscoveryV1alpha1Client) *migrations {
+	return &migrations{
+		client: c.RESTClient(),
+	}
+}
+
+// Create takes the representation of a migration and creates it.  Returns the server's representation of the migration, and an error, if there is any.
+func (c *migrations) Create(migration *v1alpha1.Migration) (result *v1alpha1.Migration, err error) {
+	result = &v1alpha1.Migration{}
+	err = c.client.Post().
+		Resource("migrations").
+		Body(migration).
+		Do().
+		Into(result)
+	return
+}
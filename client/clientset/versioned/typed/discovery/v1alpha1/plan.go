@@ -0,0 +1,56 @@
+/*
+Copyright 2018 The Pharmer Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	rest "k8s.io/client-go/rest"
+)
+
+// PlansGetter has a method to return a PlanInterface.
+// A group's client should implement this interface.
+type PlansGetter interface {
+	Plans() PlanInterface
+}
+
+// PlanInterface has methods to work with Plan resources.
+type PlanInterface interface {
+	Create(*v1alpha1.Plan) (*v1alpha1.Plan, error)
+	PlanExpansion
+}
+
+// plans implements PlanInterface
+type plans struct {
+	client rest.Interface
+}
+
+// newPlans returns a Plans
+func newPlans(c *DiscoveryV1alpha1Client) *plans {
+	return &plans{
+		client: c.RESTClient(),
+	}
+}
+
+// Create takes the representation of a plan and creates it.  Returns the server's representation of the plan, and an error, if there is any.
+func (c *plans) Create(plan *v1alpha1.Plan) (result *v1alpha1.Plan, err error) {
+	result = &v1alpha1.Plan{}
+	err = c.client.Post().
+		Resource("plans").
+		Body(plan).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2018 The Pharmer Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	rest "k8s.io/client-go/rest"
+)
+
+// QuarantinesGetter has a method to return a QuarantineInterface.
+// A group's client should implement this interface.
+type QuarantinesGetter interface {
+	Quarantines() QuarantineInterface
+}
+
+// QuarantineInterface has methods to work with Quarantine resources.
+type QuarantineInterface interface {
+	Create(*v1alpha1.Quarantine) (*v1alpha1.Quarantine, error)
+	QuarantineExpansion
+}
+
+// quarantines implements QuarantineInterface
+type quarantines struct {
+	client rest.Interface
+}
+
+// newQuarantines returns a Quarantines
+func newQuarantines(c *DiscoveryV1alpha1Client) *quarantines {
+	return &quarantines{
+		client: c.RESTClient(),
+	}
+}
+
+// Create takes the representation of a quarantine and creates it.  Returns the server's representation of the quarantine, and an error, if there is any.
+func (c *quarantines) Create(quarantine *v1alpha1.Quarantine) (result *v1alpha1.Quarantine, err error) {
+	result = &v1alpha1.Quarantine{}
+	err = c.client.Post().
+		Resource("quarantines").
+		Body(quarantine).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2018 The Pharmer Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	rest "k8s.io/client-go/rest"
+)
+
+// RemovalsGetter has a method to return a RemovalInterface.
+// A group's client should implement this interface.
+type RemovalsGetter interface {
+	Removals() RemovalInterface
+}
+
+// RemovalInterface has methods to work with Removal resources.
+type RemovalInterface interface {
+	Create(*v1alpha1.Removal) (*v1alpha1.Removal, error)
+	RemovalExpansion
+}
+
+// removals implements RemovalInterface
+type removals struct {
+	client rest.Interface
+}
+
+// newRemovals returns a Removals
+func newRemovals(c *DiscoveryV1alpha1Client) *removals {
+	return &removals{
+		client: c.RESTClient(),
+	}
+}
+
+// Create takes the representation of a removal and creates it.  Returns the server's representation of the removal, and an error, if there is any.
+func (c *removals) Create(removal *v1alpha1.Removal) (result *v1alpha1.Removal, err error) {
+	result = &v1alpha1.Removal{}
+	err = c.client.Post().
+		Resource("removals").
+		Body(removal).
+		Do().
+		Into(result)
+	return
+}
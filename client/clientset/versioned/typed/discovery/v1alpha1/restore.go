@@ -0,0 +1,56 @@
+/*
+Copyright 2018 The Pharmer Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	rest "k8s.io/client-go/rest"
+)
+
+// RestoresGetter has a method to return a RestoreInterface.
+// A group's client should implement this interface.
+type RestoresGetter interface {
+	Restores() RestoreInterface
+}
+
+// RestoreInterface has methods to work with Restore resources.
+type RestoreInterface interface {
+	Create(*v1alpha1.Restore) (*v1alpha1.Restore, error)
+	RestoreExpansion
+}
+
+// restores implements RestoreInterface
+type restores struct {
+	client rest.Interface
+}
+
+// newRestores returns a Restores
+func newRestores(c *DiscoveryV1alpha1Client) *restores {
+	return &restores{
+		client: c.RESTClient(),
+	}
+}
+
+// Create takes the representation of a restore and creates it.  Returns the server's representation of the restore, and an error, if there is any.
+func (c *restores) Create(restore *v1alpha1.Restore) (result *v1alpha1.Restore, err error) {
+	result = &v1alpha1.Restore{}
+	err = c.client.Post().
+		Resource("restores").
+		Body(restore).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2018 The Pharmer Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	rest "k8s.io/client-go/rest"
+)
+
+// RotationsGetter has a method to return a RotationInterface.
+// A group's client should implement this interface.
+type RotationsGetter interface {
+	Rotations() RotationInterface
+}
+
+// RotationInterface has methods to work with Rotation resources.
+type RotationInterface interface {
+	Create(*v1alpha1.Rotation) (*v1alpha1.Rotation, error)
+	RotationExpansion
+}
+
+// rotations implements RotationInterface
+type rotations struct {
+	client rest.Interface
+}
+
+// newRotations returns a Rotations
+func newRotations(c *DiscoveryV1alpha1Client) *rotations {
+	return &rotations{
+		client: c.RESTClient(),
+	}
+}
+
+// Create takes the representation of a rotation and creates it.  Returns the server's representation of the rotation, and an error, if there is any.
+func (c *rotations) Create(rotation *v1alpha1.Rotation) (result *v1alpha1.Rotation, err error) {
+	result = &v1alpha1.Rotation{}
+	err = c.client.Post().
+		Resource("rotations").
+		Body(rotation).
+		Do().
+		Into(result)
+	return
+}
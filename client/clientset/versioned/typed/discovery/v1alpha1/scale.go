@@ -0,0 +1,56 @@
+/*
+Copyright 2018 The Pharmer Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	rest "k8s.io/client-go/rest"
+)
+
+// ScalesGetter has a method to return a ScaleInterface.
+// A group's client should implement this interface.
+type ScalesGetter interface {
+	Scales() ScaleInterface
+}
+
+// ScaleInterface has methods to work with Scale resources.
+type ScaleInterface interface {
+	Create(*v1alpha1.Scale) (*v1alpha1.Scale, error)
+	ScaleExpansion
+}
+
+// scales implements ScaleInterface
+type scales struct {
+	client rest.Interface
+}
+
+// newScales returns a Scales
+func newScales(c *DiscoveryV1alpha1Client) *scales {
+	return &scales{
+		client: c.RESTClient(),
+	}
+}
+
+// Create takes the representation of a scale and creates it.  Returns the server's representation of the scale, and an error, if there is any.
+func (c *scales) Create(scale *v1alpha1.Scale) (result *v1alpha1.Scale, err error) {
+	result = &v1alpha1.Scale{}
+	err = c.client.Post().
+		Resource("scales").
+		Body(scale).
+		Do().
+		Into(result)
+	return
+}
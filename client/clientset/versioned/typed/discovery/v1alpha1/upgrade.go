@@ -0,0 +1,56 @@
+/*
+Copyright 2018 The Pharmer Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	rest "k8s.io/client-go/rest"
+)
+
+// UpgradesGetter has a method to return a UpgradeInterface.
+// A group's client should implement this interface.
+type UpgradesGetter interface {
+	Upgrades() UpgradeInterface
+}
+
+// UpgradeInterface has methods to work with Upgrade resources.
+type UpgradeInterface interface {
+	Create(*v1alpha1.Upgrade) (*v1alpha1.Upgrade, error)
+	UpgradeExpansion
+}
+
+// upgrades implements UpgradeInterface
+type upgrades struct {
+	client rest.Interface
+}
+
+// newUpgrades returns a Upgrades
+func newUpgrades(c *DiscoveryV1alpha1Client) *upgrades {
+	return &upgrades{
+		client: c.RESTClient(),
+	}
+}
+
+// Create takes the representation of a upgrade and creates it.  Returns the server's representation of the upgrade, and an error, if there is any.
+func (c *upgrades) Create(upgrade *v1alpha1.Upgrade) (result *v1alpha1.Upgrade, err error) {
+	result = &v1alpha1.Upgrade{}
+	err = c.client.Post().
+		Resource("upgrades").
+		Body(upgrade).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,45 @@
+package authz
+
+import (
+	"github.com/etcd-manager/etcd-discovery/apis/discovery"
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	auditinternal "k8s.io/apiserver/pkg/apis/audit"
+	"k8s.io/apiserver/pkg/audit/policy"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+)
+
+// policyChecker audits the requests denied by the authorizer and the joins of members at
+// the metadata level at least. The denials by the body of requests are audited by
+// WithRequestBody.
+type policyChecker struct {
+	authorizer *Authorizer
+	checker    policy.Checker
+}
+
+// NewPolicyChecker returns an audit policy checker that records the denials of a on top of
+// the policy of checker, which may be nil
+func NewPolicyChecker(a *Authorizer, checker policy.Checker) policy.Checker {
+	return &policyChecker{a, checker}
+}
+
+func (p *policyChecker) LevelAndStages(attrs authorizer.Attributes) (auditinternal.Level, []auditinternal.Stage) {
+	level, omitStages := policy.DefaultAuditLevel, []auditinternal.Stage(nil)
+	if p.checker != nil {
+		level, omitStages = p.checker.LevelAndStages(attrs)
+	}
+	if level.GreaterOrEqual(auditinternal.LevelMetadata) {
+		return level, omitStages
+	}
+	if isJoin(attrs) {
+		return auditinternal.LevelMetadata, []auditinternal.Stage{auditinternal.StageRequestReceived}
+	}
+	if decision, _ := p.authorizer.authorize(attrs); decision != authorizer.DecisionAllow {
+		return auditinternal.LevelMetadata, []auditinternal.Stage{auditinternal.StageRequestReceived}
+	}
+	return level, omitStages
+}
+
+func isJoin(attrs authorizer.Attributes) bool {
+	return attrs.IsResourceRequest() && attrs.GetAPIGroup() == discovery.GroupName &&
+		attrs.GetResource() == api.ResourcePluralMember && attrs.GetVerb() == "create"
+}
@@ -0,0 +1,154 @@
+package authz
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/etcd-manager/etcd-discovery/apis/discovery"
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/constants"
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+)
+
+// rule allows the members of groups to use verbs on a resource of the discovery api. The
+// requests of some resources are authorized by their body too, see WithRequestBody.
+type rule struct {
+	verbs  sets.String
+	groups []string
+	body   bodyRule
+}
+
+// bodyRule authorizes a request by the fields of its body
+type bodyRule func(attrs authorizer.Attributes, req *bodyRequest) (authorizer.Decision, string)
+
+var (
+	create = sets.NewString("create")
+	read   = sets.NewString("get", "list", "watch")
+
+	peers             = []string{constants.PeerOrganization}
+	operators         = []string{constants.OperatorGroup}
+	peersAndOperators = []string{constants.PeerOrganization, constants.OperatorGroup}
+)
+
+// rules of the resources of the discovery api. Requests of the leader are sent with the
+// certificate of a peer, requests of operators with a certificate of the operator group.
+var rules = map[string]rule{
+	api.ResourcePluralPing:        {create, peersAndOperators, ping},
+	api.ResourcePluralMember:      {create, peers, join},
+	api.ResourcePluralCertificate: {create, []string{constants.PeerOrganization, constants.BootstrapGroup}, nil},
+	api.ResourcePluralPlan:        {create, peers, leaderPush},
+	api.ResourcePluralMigration:   {create, peers, leaderPush},
+	api.ResourcePluralUpgrade:     {create, peersAndOperators, leaderPushOrOperator},
+	api.ResourcePluralRotation:    {create, peers, leaderPush},
+	api.ResourcePluralQuarantine:  {create, peersAndOperators, leaderPushOrOperator},
+	api.ResourcePluralScale:       {create, peersAndOperators, leaderPushOrOperator},
+	api.ResourcePluralRestore:     {create, peersAndOperators, leaderPushOrOperator},
+	api.ResourcePluralBackup:      {create, operators, nil},
+	api.ResourcePluralRemoval:     {create, operators, nil},
+	api.ResourcePluralEtcdCluster: {read, operators, nil},
+}
+
+// Authorizer authorizes the requests of the discovery api by the groups of their users.
+// The health checks are served to anyone, the other non-resource paths to peers and
+// operators. Denied requests are logged, NewPolicyChecker audits them.
+type Authorizer struct{}
+
+var _ authorizer.Authorizer = &Authorizer{}
+
+func New() *Authorizer {
+	return &Authorizer{}
+}
+
+func (a *Authorizer) Authorize(attrs authorizer.Attributes) (authorizer.Decision, string, error) {
+	decision, reason := a.authorize(attrs)
+	if decision != authorizer.DecisionAllow {
+		glog.V(2).Infof("denied %s %s of user %q: %s", attrs.GetVerb(), attrs.GetPath(), userName(attrs), reason)
+	}
+	return decision, reason, nil
+}
+
+func (a *Authorizer) authorize(attrs authorizer.Attributes) (authorizer.Decision, string) {
+	// the loopback client of the server
+	if privileged(attrs) {
+		return authorizer.DecisionAllow, ""
+	}
+	if !attrs.IsResourceRequest() {
+		path := attrs.GetPath()
+		if path == "/healthz" || strings.HasPrefix(path, "/healthz/") {
+			return authorizer.DecisionAllow, ""
+		}
+		return allowGroups(attrs, peersAndOperators, path)
+	}
+
+	if attrs.GetAPIGroup() != discovery.GroupName {
+		return authorizer.DecisionNoOpinion, fmt.Sprintf("unknown api group %q", attrs.GetAPIGroup())
+	}
+	resource := attrs.GetResource()
+	if attrs.GetSubresource() != "" {
+		resource += "/" + attrs.GetSubresource()
+	}
+	r, ok := rules[resource]
+	if !ok || !r.verbs.Has(attrs.GetVerb()) {
+		return authorizer.DecisionDeny, fmt.Sprintf("%s of %s is not allowed", attrs.GetVerb(), resource)
+	}
+	return allowGroups(attrs, r.groups, resource)
+}
+
+// allowGroups allows the request if its user is a member of one of groups
+func allowGroups(attrs authorizer.Attributes, groups []string, target string) (authorizer.Decision, string) {
+	if u := attrs.GetUser(); u != nil && sets.NewString(u.GetGroups()...).HasAny(groups...) {
+		return authorizer.DecisionAllow, ""
+	}
+	return authorizer.DecisionDeny, fmt.Sprintf("only members of %s may %s %s", strings.Join(groups, ", "), attrs.GetVerb(), target)
+}
+
+// leaderPush allows the requests the leader pushes to the peers to the peer named as their
+// leader only. Requests without a leader are refused by the storage.
+func leaderPush(attrs authorizer.Attributes, req *bodyRequest) (authorizer.Decision, string) {
+	if req.Leader == "" || req.Leader == userName(attrs) {
+		return authorizer.DecisionAllow, ""
+	}
+	return authorizer.DecisionDeny, fmt.Sprintf("peer %q may not %s %s of leader %q", userName(attrs), attrs.GetVerb(), attrs.GetResource(), req.Leader)
+}
+
+// leaderPushOrOperator allows operators to ask the leader, which pushes the request to the
+// peers as leaderPush
+func leaderPushOrOperator(attrs authorizer.Attributes, req *bodyRequest) (authorizer.Decision, string) {
+	if req.Leader == "" {
+		return allowGroups(attrs, operators, attrs.GetResource())
+	}
+	if decision, reason := allowGroups(attrs, peers, attrs.GetResource()); decision != authorizer.DecisionAllow {
+		return decision, reason
+	}
+	return leaderPush(attrs, req)
+}
+
+// ping allows peers to ping as the peer of their certificate only, the election grants
+// leases to the id of the sender. Pings without a sender and those of operators only
+// learn the leader.
+func ping(attrs authorizer.Attributes, req *bodyRequest) (authorizer.Decision, string) {
+	if !IsPeer(attrs.GetUser()) || req.Info == nil || req.Info.ID == userName(attrs) {
+		return authorizer.DecisionAllow, ""
+	}
+	return authorizer.DecisionDeny, fmt.Sprintf("peer %q may not ping as %q", userName(attrs), req.Info.ID)
+}
+
+// join allows a peer to add itself to the cluster only, see AuthorizeJoin
+func join(attrs authorizer.Attributes, req *bodyRequest) (authorizer.Decision, string) {
+	if req.PeerURL == "" {
+		return authorizer.DecisionAllow, ""
+	}
+	if err := AuthorizeJoin(attrs.GetUser(), req.PeerURL); err != nil {
+		return authorizer.DecisionDeny, err.Error()
+	}
+	return authorizer.DecisionAllow, ""
+}
+
+func userName(attrs authorizer.Attributes) string {
+	if u := attrs.GetUser(); u != nil {
+		return u.GetName()
+	}
+	return ""
+}
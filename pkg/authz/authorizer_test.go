@@ -0,0 +1,225 @@
+package authz_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/etcd-manager/etcd-discovery/apis/discovery"
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/authz"
+	"github.com/etcd-manager/etcd-discovery/pkg/constants"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/sets"
+	auditinternal "k8s.io/apiserver/pkg/apis/audit"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericfilters "k8s.io/apiserver/pkg/endpoints/filters"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+)
+
+func resource(u user.Info, verb, resource string) authorizer.Attributes {
+	return authorizer.AttributesRecord{
+		User:            u,
+		Verb:            verb,
+		APIGroup:        discovery.GroupName,
+		APIVersion:      api.SchemeGroupVersion.Version,
+		Resource:        resource,
+		ResourceRequest: true,
+		Path:            "/apis/" + discovery.GroupName + "/v1alpha1/" + resource,
+	}
+}
+
+func path(u user.Info, path string) authorizer.Attributes {
+	return authorizer.AttributesRecord{User: u, Verb: "get", Path: path}
+}
+
+func TestAuthorize(t *testing.T) {
+	peer := &user.DefaultInfo{Name: "a", Groups: []string{constants.PeerOrganization, user.AllAuthenticated}}
+	operator := &user.DefaultInfo{Name: "admin", Groups: []string{constants.OperatorGroup, user.AllAuthenticated}}
+	bootstrap := &user.DefaultInfo{Name: constants.BootstrapUser, Groups: []string{constants.BootstrapGroup}}
+	other := &user.DefaultInfo{Name: "other", Groups: []string{user.AllAuthenticated}}
+
+	for _, test := range []struct {
+		name  string
+		attrs authorizer.Attributes
+		allow bool
+	}{
+		{"peer pings", resource(peer, "create", api.ResourcePluralPing), true},
+		{"operator pings", resource(operator, "create", api.ResourcePluralPing), true},
+		{"other pings", resource(other, "create", api.ResourcePluralPing), false},
+		{"peer joins", resource(peer, "create", api.ResourcePluralMember), true},
+		{"operator joins", resource(operator, "create", api.ResourcePluralMember), false},
+		{"bootstrap joins", resource(bootstrap, "create", api.ResourcePluralMember), false},
+		{"bootstrap requests certificates", resource(bootstrap, "create", api.ResourcePluralCertificate), true},
+		{"peer rotates", resource(peer, "create", api.ResourcePluralRotation), true},
+		{"operator rotates", resource(operator, "create", api.ResourcePluralRotation), false},
+		{"operator upgrades", resource(operator, "create", api.ResourcePluralUpgrade), true},
+		{"operator quarantines", resource(operator, "create", api.ResourcePluralQuarantine), true},
+		{"operator restores", resource(operator, "create", api.ResourcePluralRestore), true},
+		{"peer restores", resource(peer, "create", api.ResourcePluralRestore), true},
+		{"operator backs up", resource(operator, "create", api.ResourcePluralBackup), true},
+		{"peer backs up", resource(peer, "create", api.ResourcePluralBackup), false},
+		{"operator removes a member", resource(operator, "create", api.ResourcePluralRemoval), true},
+		{"peer removes a member", resource(peer, "create", api.ResourcePluralRemoval), false},
+		{"operator reads status", resource(operator, "watch", api.ResourcePluralEtcdCluster), true},
+		{"peer reads status", resource(peer, "get", api.ResourcePluralEtcdCluster), false},
+		{"operator deletes status", resource(operator, "delete", api.ResourcePluralEtcdCluster), false},
+		{"unknown resource", resource(operator, "create", "secrets"), false},
+		{"health", path(nil, "/healthz"), true},
+		{"peer reads metrics", path(peer, "/metrics"), true},
+		{"other reads metrics", path(other, "/metrics"), false},
+		{"loopback", resource(&user.DefaultInfo{Name: user.APIServerUser, Groups: []string{user.SystemPrivilegedGroup}}, "create", api.ResourcePluralPlan), true},
+	} {
+		decision, reason, err := authz.New().Authorize(test.attrs)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if allowed := decision == authorizer.DecisionAllow; allowed != test.allow {
+			t.Errorf("%s: expected allowed %v, got %v (%s)", test.name, test.allow, allowed, reason)
+		}
+	}
+}
+
+func TestAuthorizeJoin(t *testing.T) {
+	chain := []*x509.Certificate{{
+		Subject:     pkix.Name{CommonName: "a", Organization: []string{constants.PeerOrganization}},
+		DNSNames:    []string{"a.example.com"},
+		IPAddresses: []net.IP{net.ParseIP("10.0.0.1")},
+	}}
+	u, ok, err := authz.UserConversion.User(chain)
+	if err != nil || !ok {
+		t.Fatalf("expected a user, got %v, %v", ok, err)
+	}
+	if u.GetName() != "a" || len(u.GetGroups()) != 1 || u.GetGroups()[0] != constants.PeerOrganization {
+		t.Fatalf("unexpected user %+v", u)
+	}
+
+	for _, test := range []struct {
+		peerURL string
+		allow   bool
+	}{
+		{"https://10.0.0.1:2380", true},
+		{"https://a.example.com:2380", true},
+		{"https://10.0.0.2:2380", false},
+		{"https://b.example.com:2380", false},
+	} {
+		err := authz.AuthorizeJoin(u, test.peerURL)
+		if (err == nil) != test.allow {
+			t.Errorf("%s: expected allowed %v, got %v", test.peerURL, test.allow, err)
+		}
+	}
+
+	if err := authz.AuthorizeJoin(&user.DefaultInfo{Name: "b"}, "https://10.0.0.1:2380"); err == nil {
+		t.Errorf("expected a certificate without alternative names to be denied")
+	}
+}
+
+func TestPolicyChecker(t *testing.T) {
+	checker := authz.NewPolicyChecker(authz.New(), nil)
+	peer := &user.DefaultInfo{Name: "a", Groups: []string{constants.PeerOrganization}}
+	other := &user.DefaultInfo{Name: "other"}
+
+	for _, test := range []struct {
+		name  string
+		attrs authorizer.Attributes
+		level auditinternal.Level
+	}{
+		{"allowed", resource(peer, "create", api.ResourcePluralPing), auditinternal.LevelNone},
+		{"denied", resource(other, "create", api.ResourcePluralPing), auditinternal.LevelMetadata},
+		{"join", resource(peer, "create", api.ResourcePluralMember), auditinternal.LevelMetadata},
+	} {
+		if level, _ := checker.LevelAndStages(test.attrs); level != test.level {
+			t.Errorf("%s: expected level %s, got %s", test.name, test.level, level)
+		}
+	}
+}
+
+func TestWithRequestBody(t *testing.T) {
+	leader := &user.DefaultInfo{Name: "a", Groups: []string{constants.PeerOrganization}, Extra: map[string][]string{authz.AltNamesKey: {"10.0.0.1"}}}
+	follower := &user.DefaultInfo{Name: "b", Groups: []string{constants.PeerOrganization}}
+	operator := &user.DefaultInfo{Name: "admin", Groups: []string{constants.OperatorGroup}}
+
+	for _, test := range []struct {
+		name     string
+		user     user.Info
+		resource string
+		body     string
+		allow    bool
+	}{
+		{"leader pushes a scale", leader, api.ResourcePluralScale, `{"request":{"leader":"a","term":1}}`, true},
+		{"peer pushes the scale of another leader", follower, api.ResourcePluralScale, `{"request":{"leader":"a","term":1}}`, false},
+		{"operator pushes a scale", operator, api.ResourcePluralScale, `{"request":{"leader":"a","term":1}}`, false},
+		{"operator asks the leader to scale", operator, api.ResourcePluralScale, `{"request":{"clusterSize":3}}`, true},
+		{"peer asks the leader to scale", follower, api.ResourcePluralScale, `{"request":{"clusterSize":3}}`, false},
+		{"leader pushes a plan", leader, api.ResourcePluralPlan, `{"request":{"leader":"a"}}`, true},
+		{"peer pushes the plan of another leader", follower, api.ResourcePluralPlan, "request:\n  leader: a\n", false},
+		{"peer rotates for another leader", follower, api.ResourcePluralRotation, `{"request":{"leader":"a","term":1}}`, false},
+		{"peer restores for another leader", follower, api.ResourcePluralRestore, `{"request":{"leader":"a","backup":"x"}}`, false},
+		{"peer joins itself", leader, api.ResourcePluralMember, `{"request":{"peerURL":"https://10.0.0.1:2380"}}`, true},
+		{"peer joins another", follower, api.ResourcePluralMember, `{"request":{"peerURL":"https://10.0.0.1:2380"}}`, false},
+		{"unreadable body", leader, api.ResourcePluralScale, `{"request":`, false},
+		{"peer pings", follower, api.ResourcePluralPing, `{"request":{"info":{"id":"b"},"leader":"a"}}`, true},
+		{"peer campaigns", leader, api.ResourcePluralPing, `{"request":{"info":{"id":"a"},"leader":"a","term":2}}`, true},
+		{"peer campaigns as another peer", follower, api.ResourcePluralPing, `{"request":{"info":{"id":"a"},"leader":"a","term":2}}`, false},
+		{"peer pings without sender", follower, api.ResourcePluralPing, `{"request":{"leader":"b","term":2}}`, true},
+		{"operator pings", operator, api.ResourcePluralPing, `{"request":{}}`, true},
+	} {
+		var served string
+		sink := &fakeSink{}
+		mapper := apirequest.NewRequestContextMapper()
+		handler := authz.New().WithRequestBody(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			data, _ := ioutil.ReadAll(req.Body)
+			served = string(data)
+		}), mapper, serializer.NewCodecFactory(runtime.NewScheme()), sink)
+		handler = withUser(handler, mapper, test.user)
+		handler = genericfilters.WithRequestInfo(handler, &apirequest.RequestInfoFactory{APIPrefixes: sets.NewString("apis")}, mapper)
+		handler = apirequest.WithRequestContext(handler, mapper)
+
+		req := httptest.NewRequest(http.MethodPost, "/apis/"+discovery.GroupName+"/v1alpha1/"+test.resource, strings.NewReader(test.body))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if allowed := w.Code == http.StatusOK; allowed != test.allow {
+			t.Errorf("%s: expected allowed %v, got status %d: %s", test.name, test.allow, w.Code, w.Body.String())
+		}
+		if test.allow && served != test.body {
+			t.Errorf("%s: expected the body to be served, got %q", test.name, served)
+		}
+		if test.allow && len(sink.events) != 0 {
+			t.Errorf("%s: expected no audit event, got %d", test.name, len(sink.events))
+		}
+		if !test.allow && (len(sink.events) != 1 || sink.events[0].Level != auditinternal.LevelMetadata ||
+			sink.events[0].ResponseStatus == nil || sink.events[0].ResponseStatus.Code != http.StatusForbidden ||
+			sink.events[0].User.Username != test.user.GetName()) {
+			t.Errorf("%s: expected the denial to be audited, got %+v", test.name, sink.events)
+		}
+	}
+}
+
+// fakeSink records the audit events
+type fakeSink struct {
+	events []*auditinternal.Event
+}
+
+func (s *fakeSink) ProcessEvents(events ...*auditinternal.Event) {
+	for _, ev := range events {
+		s.events = append(s.events, ev.DeepCopy())
+	}
+}
+
+// withUser authenticates the requests of handler as u
+func withUser(handler http.Handler, mapper apirequest.RequestContextMapper, u user.Info) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if ctx, ok := mapper.Get(req); ok {
+			mapper.Update(req, apirequest.WithUser(ctx, u))
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
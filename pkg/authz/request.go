@@ -0,0 +1,108 @@
+package authz
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/etcd-manager/etcd-discovery/apis/discovery"
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/golang/glog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	auditinternal "k8s.io/apiserver/pkg/apis/audit"
+	"k8s.io/apiserver/pkg/audit"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/endpoints/filters"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+)
+
+// bodyRequest holds the fields of the request of a resource that are authorized
+type bodyRequest struct {
+	Info    *api.PeerInfo `json:"info"`
+	Leader  string        `json:"leader"`
+	PeerURL string        `json:"peerURL"`
+}
+
+// WithRequestBody authorizes the requests of the resources with a body rule by their body,
+// which the authorizer does not see: only the leader pushes requests that name it, peers
+// only ping as themselves and only add themselves to the cluster. It runs after the
+// authorizer. Denials are audited like those of the authorizer, to sink.
+func (a *Authorizer) WithRequestBody(handler http.Handler, mapper apirequest.RequestContextMapper, s runtime.NegotiatedSerializer, sink audit.Sink) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx, ok := mapper.Get(req)
+		if !ok {
+			responsewriters.InternalError(w, req, fmt.Errorf("no context found for request"))
+			return
+		}
+		attrs, err := filters.GetAuthorizerAttributes(ctx)
+		if err != nil {
+			responsewriters.InternalError(w, req, err)
+			return
+		}
+		r := rules[attrs.GetResource()]
+		if !attrs.IsResourceRequest() || attrs.GetAPIGroup() != discovery.GroupName || r.body == nil || privileged(attrs) {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		decision, reason := authorizer.DecisionDeny, "the request body could not be read"
+		data, err := ioutil.ReadAll(req.Body)
+		if attrs.GetUser() == nil {
+			reason = "the request is not authenticated"
+		} else if err == nil {
+			req.Body = ioutil.NopCloser(bytes.NewReader(data))
+			var body struct {
+				Request *bodyRequest `json:"request"`
+			}
+			if err := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), len(data)+1).Decode(&body); err == nil {
+				if body.Request == nil {
+					body.Request = &bodyRequest{}
+				}
+				decision, reason = r.body(attrs, body.Request)
+			}
+		}
+		if decision != authorizer.DecisionAllow {
+			glog.V(2).Infof("denied %s %s of user %q: %s", attrs.GetVerb(), attrs.GetPath(), userName(attrs), reason)
+			auditDenial(ctx, sink, req, attrs, reason)
+			responsewriters.Forbidden(ctx, attrs, w, req, reason, s)
+			return
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
+
+// auditDenial sends the denial of req to sink at the metadata level, like policyChecker
+// audits the denials of the authorizer. The event of a request audited by the policy
+// records the denial itself.
+func auditDenial(ctx apirequest.Context, sink audit.Sink, req *http.Request, attrs authorizer.Attributes, reason string) {
+	if sink == nil || apirequest.AuditEventFrom(ctx) != nil {
+		return
+	}
+	ev, err := audit.NewEventFromRequest(req, auditinternal.LevelMetadata, attrs)
+	if err != nil {
+		glog.Warningf("error auditing the denial of %s %s: %v", attrs.GetVerb(), attrs.GetPath(), err)
+		return
+	}
+	ev.Stage = auditinternal.StageResponseComplete
+	ev.StageTimestamp = metav1.NewMicroTime(time.Now())
+	ev.ResponseStatus = &metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusForbidden,
+		Reason:  metav1.StatusReasonForbidden,
+		Message: reason,
+	}
+	sink.ProcessEvents(ev)
+}
+
+// privileged tells if the request is sent by the loopback client of the server
+func privileged(attrs authorizer.Attributes) bool {
+	u := attrs.GetUser()
+	return u != nil && sets.NewString(u.GetGroups()...).Has(user.SystemPrivilegedGroup)
+}
@@ -0,0 +1,38 @@
+package authz
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+)
+
+// sourceAddressKey is the context key of the address a request was sent from
+type sourceAddressKey struct{}
+
+// WithSourceAddress keeps the address of the connection of a request in its context, the
+// leader issues certificates for it. Forwarding headers are not trusted.
+func WithSourceAddress(handler http.Handler, mapper apirequest.RequestContextMapper) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if ctx, ok := mapper.Get(req); ok {
+			if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+				if ip := net.ParseIP(host); ip != nil {
+					mapper.Update(req, WithSource(ctx, ip))
+				}
+			}
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
+
+// WithSource returns a copy of ctx that holds the source address of its request
+func WithSource(ctx apirequest.Context, ip net.IP) apirequest.Context {
+	return apirequest.WithValue(ctx, sourceAddressKey{}, ip)
+}
+
+// SourceFrom returns the address the request of ctx was sent from
+func SourceFrom(ctx context.Context) (net.IP, bool) {
+	ip, ok := ctx.Value(sourceAddressKey{}).(net.IP)
+	return ip, ok
+}
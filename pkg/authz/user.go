@@ -0,0 +1,63 @@
+package authz
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/etcd-manager/etcd-discovery/apis/discovery"
+	"github.com/etcd-manager/etcd-discovery/pkg/constants"
+	"k8s.io/apimachinery/pkg/util/sets"
+	x509request "k8s.io/apiserver/pkg/authentication/request/x509"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+// AltNamesKey is the extra of users authenticated with a client certificate that lists the
+// DNS names and IP addresses of the certificate
+const AltNamesKey = "alt-names." + discovery.GroupName
+
+// UserConversion builds the user of a client certificate from its common name and
+// organizations, like x509.CommonNameUserConversion, and keeps its subject alternative
+// names to authorize the joins of peers
+var UserConversion = x509request.UserConversionFunc(func(chain []*x509.Certificate) (user.Info, bool, error) {
+	cert := chain[0]
+	if len(cert.Subject.CommonName) == 0 {
+		return nil, false, nil
+	}
+	u := &user.DefaultInfo{
+		Name:   cert.Subject.CommonName,
+		Groups: cert.Subject.Organization,
+	}
+	var names []string
+	names = append(names, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		names = append(names, ip.String())
+	}
+	if len(names) > 0 {
+		u.Extra = map[string][]string{AltNamesKey: names}
+	}
+	return u, true, nil
+})
+
+// IsPeer tells if u is authenticated with the certificate of a peer of the cluster
+func IsPeer(u user.Info) bool {
+	return u != nil && sets.NewString(u.GetGroups()...).Has(constants.PeerOrganization)
+}
+
+// AuthorizeJoin returns an error unless the host of peerURL is one of the subject
+// alternative names of the certificate of u, so a peer may only add itself to the cluster
+func AuthorizeJoin(u user.Info, peerURL string) error {
+	pu, err := url.Parse(peerURL)
+	if err != nil {
+		return fmt.Errorf("invalid peer url %q", peerURL)
+	}
+	host := pu.Hostname()
+	ip := net.ParseIP(host)
+	for _, name := range u.GetExtra()[AltNamesKey] {
+		if name == host || (ip != nil && ip.Equal(net.ParseIP(name))) {
+			return nil
+		}
+	}
+	return fmt.Errorf("the certificate of %s is not valid for %s", u.GetName(), host)
+}
@@ -0,0 +1,274 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const azureAPIVersion = "2019-12-12"
+
+// azureClient talks to Azure Blob Storage.
+//
+// azure://container/prefix takes the query parameters account, endpoint (e.g.
+// http://127.0.0.1:10000/devstoreaccount1 for Azurite) and encryption-scope. The account
+// and its key are read from AZURE_STORAGE_ACCOUNT and AZURE_STORAGE_KEY, or a shared access
+// signature from AZURE_STORAGE_SAS_TOKEN. A customer provided AES-256 key, base64 encoded,
+// is read from AZURE_STORAGE_ENCRYPTION_KEY.
+type azureClient struct {
+	account         string
+	key             []byte
+	sas             url.Values
+	container       string
+	endpoint        *url.URL
+	encryptionScope string
+	encryptionKey   []byte
+
+	client *http.Client
+}
+
+var _ objectClient = &azureClient{}
+
+func newAzureStore(u *url.URL) (BackupStore, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("container is missing in backup store %q", u)
+	}
+	c := &azureClient{
+		account:         queryOption(u, "account", "AZURE_STORAGE_ACCOUNT"),
+		container:       u.Host,
+		encryptionScope: u.Query().Get("encryption-scope"),
+		client:          &http.Client{Timeout: objectTimeout},
+	}
+	if c.account == "" {
+		return nil, fmt.Errorf("azure storage account is not set, use AZURE_STORAGE_ACCOUNT")
+	}
+
+	if key := os.Getenv("AZURE_STORAGE_KEY"); key != "" {
+		data, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, fmt.Errorf("AZURE_STORAGE_KEY is not base64 encoded: %v", err)
+		}
+		c.key = data
+	} else if sas := os.Getenv("AZURE_STORAGE_SAS_TOKEN"); sas != "" {
+		values, err := url.ParseQuery(strings.TrimPrefix(sas, "?"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid AZURE_STORAGE_SAS_TOKEN: %v", err)
+		}
+		c.sas = values
+	} else {
+		return nil, fmt.Errorf("azure storage credentials are not set, use AZURE_STORAGE_KEY or AZURE_STORAGE_SAS_TOKEN")
+	}
+
+	if key := os.Getenv("AZURE_STORAGE_ENCRYPTION_KEY"); key != "" {
+		data, err := base64.StdEncoding.DecodeString(key)
+		if err != nil || len(data) != 32 {
+			return nil, fmt.Errorf("AZURE_STORAGE_ENCRYPTION_KEY must be a base64 encoded 256 bit key")
+		}
+		c.encryptionKey = data
+	}
+	if c.encryptionScope != "" && c.encryptionKey != nil {
+		return nil, fmt.Errorf("encryption-scope and AZURE_STORAGE_ENCRYPTION_KEY are mutually exclusive")
+	}
+
+	endpoint := u.Query().Get("endpoint")
+	if endpoint == "" {
+		endpoint = "https://" + c.account + ".blob.core.windows.net"
+	}
+	ep, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil || ep.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %q in backup store %q", endpoint, u)
+	}
+	c.endpoint = ep
+	return newObjectStore(u, c), nil
+}
+
+func (c *azureClient) blobURL(key string, query url.Values) *url.URL {
+	u := *c.endpoint
+	u.Path += "/" + c.container
+	if key != "" {
+		u.Path += "/" + key
+	}
+	if c.sas != nil {
+		if query == nil {
+			query = url.Values{}
+		}
+		for k, v := range c.sas {
+			query[k] = v
+		}
+	}
+	u.RawQuery = query.Encode()
+	return &u
+}
+
+func (c *azureClient) do(method string, u *url.URL, body io.ReadSeeker, size int64, header http.Header, expected ...int) (*http.Response, error) {
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.Body = ioutil.NopCloser(body)
+		req.ContentLength = size
+	}
+	req.Header.Set("X-Ms-Date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("X-Ms-Version", azureAPIVersion)
+	if c.encryptionKey != nil {
+		hash := sha256.Sum256(c.encryptionKey)
+		req.Header.Set("X-Ms-Encryption-Key", base64.StdEncoding.EncodeToString(c.encryptionKey))
+		req.Header.Set("X-Ms-Encryption-Key-Sha256", base64.StdEncoding.EncodeToString(hash[:]))
+		req.Header.Set("X-Ms-Encryption-Algorithm", "AES256")
+	}
+	if c.key != nil {
+		signSharedKey(req, c.account, c.key)
+	}
+	return doRequest(c.client, req, expected...)
+}
+
+func (c *azureClient) PutObject(key string, body io.ReadSeeker, size int64) error {
+	return c.putObject(key, body, size, http.Header{})
+}
+
+func (c *azureClient) CreateObject(key string, body io.ReadSeeker, size int64) error {
+	header := http.Header{}
+	header.Set("If-None-Match", "*")
+	return c.putObject(key, body, size, header)
+}
+
+func (c *azureClient) putObject(key string, body io.ReadSeeker, size int64, header http.Header) error {
+	header.Set("X-Ms-Blob-Type", "BlockBlob")
+	if c.encryptionScope != "" {
+		header.Set("X-Ms-Encryption-Scope", c.encryptionScope)
+	}
+	resp, err := c.do(http.MethodPut, c.blobURL(key, nil), body, size, header, http.StatusCreated, http.StatusConflict)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// a conditional put of an existing blob fails with BlobAlreadyExists
+	if resp.StatusCode == http.StatusConflict {
+		return errExists
+	}
+	return nil
+}
+
+func (c *azureClient) GetObject(key string) (io.ReadCloser, error) {
+	resp, err := c.do(http.MethodGet, c.blobURL(key, nil), nil, 0, nil, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+type azureEnumerationResults struct {
+	Blobs struct {
+		Blob []struct {
+			Name string
+		}
+	}
+	NextMarker string
+}
+
+func (c *azureClient) ListObjects(prefix string) ([]string, error) {
+	var keys []string
+	query := url.Values{
+		"restype": {"container"},
+		"comp":    {"list"},
+		"prefix":  {prefix},
+	}
+	for {
+		resp, err := c.do(http.MethodGet, c.blobURL("", query), nil, 0, nil, http.StatusOK)
+		if err != nil {
+			return nil, err
+		}
+		result := &azureEnumerationResults{}
+		err = xml.NewDecoder(resp.Body).Decode(result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error parsing container listing: %v", err)
+		}
+		for _, blob := range result.Blobs.Blob {
+			keys = append(keys, blob.Name)
+		}
+		if result.NextMarker == "" {
+			return keys, nil
+		}
+		query.Set("marker", result.NextMarker)
+	}
+}
+
+func (c *azureClient) DeleteObject(key string) error {
+	resp, err := c.do(http.MethodDelete, c.blobURL(key, nil), nil, 0, nil, http.StatusAccepted)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// signSharedKey authorizes req with the shared key of account
+func signSharedKey(req *http.Request, account string, key []byte) {
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-Md5"),
+		req.Header.Get("Content-Type"),
+		"", // Date, x-ms-date is used instead
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+	}, "\n") + "\n" + azureCanonicalHeaders(req.Header) + azureCanonicalResource(req.URL, account)
+
+	signature := base64.StdEncoding.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", "SharedKey "+account+":"+signature)
+}
+
+func azureCanonicalHeaders(header http.Header) string {
+	var names []string
+	for name := range header {
+		if strings.HasPrefix(strings.ToLower(name), "x-ms-") {
+			names = append(names, strings.ToLower(name))
+		}
+	}
+	sort.Strings(names)
+	var b bytes.Buffer
+	for _, name := range names {
+		b.WriteString(name + ":" + strings.TrimSpace(header.Get(name)) + "\n")
+	}
+	return b.String()
+}
+
+func azureCanonicalResource(u *url.URL, account string) string {
+	resource := "/" + account + u.EscapedPath()
+	query := u.Query()
+	var names []string
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values := query[name]
+		sort.Strings(values)
+		resource += "\n" + strings.ToLower(name) + ":" + strings.Join(values, ",")
+	}
+	return resource
+}
@@ -0,0 +1,134 @@
+package backup
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeAzure serves a container of an Azurite style account, checking the shared key of every request
+func fakeAzure(t *testing.T, account, container string, key []byte, objects *fakeObjects) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signed, _ := http.NewRequest(r.Method, "http://"+r.Host+r.RequestURI, nil)
+		signed.ContentLength = r.ContentLength
+		for k, v := range r.Header {
+			if k != "Authorization" {
+				signed.Header[k] = v
+			}
+		}
+		signSharedKey(signed, account, key)
+		if signed.Header.Get("Authorization") != r.Header.Get("Authorization") {
+			t.Errorf("%s %s: signature mismatch", r.Method, r.URL)
+			http.Error(w, "AuthenticationFailed", http.StatusForbidden)
+			return
+		}
+
+		base := "/" + account + "/" + container
+		query := r.URL.Query()
+		if r.URL.Path == base && query.Get("comp") == "list" {
+			keys, more := objects.list(query.Get("prefix"), query.Get("marker"))
+			result := azureEnumerationResults{}
+			for _, key := range keys {
+				result.Blobs.Blob = append(result.Blobs.Blob, struct{ Name string }{key})
+				if more {
+					result.NextMarker = key
+				}
+			}
+			xml.NewEncoder(w).Encode(struct {
+				XMLName xml.Name `xml:"EnumerationResults"`
+				azureEnumerationResults
+			}{azureEnumerationResults: result})
+			return
+		}
+
+		key := strings.TrimPrefix(r.URL.Path, base+"/")
+		switch r.Method {
+		case http.MethodPut:
+			if r.Header.Get("X-Ms-Blob-Type") != "BlockBlob" {
+				http.Error(w, "InvalidBlobType", http.StatusBadRequest)
+				return
+			}
+			if err := objects.put(key, r); err == errFakeExists {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			} else if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodGet:
+			data, ok := objects.get(key)
+			if !ok {
+				http.Error(w, "BlobNotFound", http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		case http.MethodDelete:
+			if !objects.delete(key) {
+				http.Error(w, "BlobNotFound", http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+		}
+	})
+}
+
+func TestAzureStore(t *testing.T) {
+	key := make([]byte, 64)
+	rand.Read(key)
+	defer setenv(map[string]string{
+		"AZURE_STORAGE_ACCOUNT": "devstoreaccount1",
+		"AZURE_STORAGE_KEY":     base64.StdEncoding.EncodeToString(key),
+	})()
+
+	objects := newFakeObjects()
+	server := httptest.NewServer(fakeAzure(t, "devstoreaccount1", "backups", key, objects))
+	defer server.Close()
+
+	store, err := NewStore("azure://backups/etcd?encryption-scope=etcd&endpoint=" + url.QueryEscape(server.URL+"/devstoreaccount1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBackupStore(t, store)
+
+	name := NewName(time.Date(2018, 3, 4, 5, 6, 7, 0, time.UTC))
+	header := objects.header("etcd/" + name + "/" + snapshotFileName)
+	if header == nil {
+		t.Fatalf("backup %s not found in container", name)
+	}
+	if header.Get("X-Ms-Encryption-Scope") != "etcd" {
+		t.Errorf("backup was not encrypted with the encryption scope: %v", header)
+	}
+}
+
+func TestAzureStoreOptions(t *testing.T) {
+	defer setenv(map[string]string{
+		"AZURE_STORAGE_ACCOUNT":        "account",
+		"AZURE_STORAGE_KEY":            base64.StdEncoding.EncodeToString([]byte("key")),
+		"AZURE_STORAGE_ENCRYPTION_KEY": base64.StdEncoding.EncodeToString(make([]byte, 32)),
+	})()
+
+	for _, spec := range []string{
+		"azure:///prefix",
+		"azure://container?encryption-scope=etcd",
+	} {
+		if _, err := NewStore(spec); err == nil {
+			t.Errorf("%s: expected error", spec)
+		}
+	}
+
+	store, err := NewStore("azure://container/prefix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := store.(*objectStore).client.(*azureClient)
+	if u := c.blobURL("prefix/a", nil).String(); u != "https://account.blob.core.windows.net/container/prefix/a" {
+		t.Errorf("unexpected blob url %s", u)
+	}
+}
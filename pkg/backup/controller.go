@@ -0,0 +1,213 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/etcd-manager/etcd-discovery/pkg/etcdclient"
+	"github.com/etcd-manager/etcd-discovery/pkg/metrics"
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// Snapshotter is the part of etcdclient.EtcdClient used to take backups
+type Snapshotter interface {
+	ServerVersion(ctx context.Context) (string, error)
+	LocalNodeInfo(ctx context.Context) (*etcdclient.LocalNodeInfo, error)
+	SnapshotSave(ctx context.Context, path string) error
+	SupportsSnapshot() bool
+}
+
+var _ Snapshotter = etcdclient.EtcdClient(nil)
+
+// nameAttempts is how many names a backup tries before the store is given up on
+const nameAttempts = 5
+
+// Controller takes backups on a schedule and applies the retention policy to the store
+type Controller struct {
+	store     BackupStore
+	interval  time.Duration
+	retention RetentionPolicy
+	clock     clock.Clock
+
+	mutex   sync.Mutex
+	running bool
+	// last is the time of the newest backup, loaded from the store if zero
+	last time.Time
+}
+
+// NewController returns a backup controller. A zero interval disables scheduled backups.
+func NewController(store BackupStore, interval time.Duration, retention RetentionPolicy, clock clock.Clock) *Controller {
+	return &Controller{
+		store:     store,
+		interval:  interval,
+		retention: retention,
+		clock:     clock,
+	}
+}
+
+func (c *Controller) Store() BackupStore {
+	return c.store
+}
+
+// MaybeBackup takes a backup if the newest one is older than the interval. It returns
+// the name of the new backup, or "" if none was due or another backup is running.
+func (c *Controller) MaybeBackup(ctx context.Context, client Snapshotter, clusterToken string) (string, error) {
+	if c.interval <= 0 {
+		return "", nil
+	}
+	if !c.start() {
+		return "", nil
+	}
+	defer c.finish()
+
+	if c.last.IsZero() {
+		last, err := c.newestBackup()
+		if err != nil {
+			return "", err
+		}
+		c.last = last
+	}
+	if c.clock.Since(c.last) < c.interval {
+		return "", nil
+	}
+	return c.backup(ctx, client, clusterToken)
+}
+
+// Backup takes a backup now
+func (c *Controller) Backup(ctx context.Context, client Snapshotter, clusterToken string) (string, error) {
+	if !c.start() {
+		return "", fmt.Errorf("a backup is already running")
+	}
+	defer c.finish()
+	return c.backup(ctx, client, clusterToken)
+}
+
+// LastBackup returns when the newest backup in the store was taken, zero if there is none
+func (c *Controller) LastBackup() (time.Time, error) {
+	return c.newestBackup()
+}
+
+func (c *Controller) start() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.running {
+		return false
+	}
+	c.running = true
+	return true
+}
+
+func (c *Controller) finish() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.running = false
+}
+
+func (c *Controller) newestBackup() (time.Time, error) {
+	names, err := c.store.ListBackups()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(names) == 0 {
+		return time.Time{}, nil
+	}
+	last, err := ParseName(names[len(names)-1])
+	if err != nil {
+		return time.Time{}, err
+	}
+	metrics.SetLastBackup(last)
+	return last, nil
+}
+
+func (c *Controller) backup(ctx context.Context, client Snapshotter, clusterToken string) (string, error) {
+	start := c.clock.Now()
+	name, size, err := c.snapshot(ctx, client, clusterToken)
+	if err != nil {
+		metrics.BackupFailures.Inc()
+		return "", err
+	}
+	metrics.BackupDuration.Observe(c.clock.Since(start).Seconds())
+	metrics.BackupSize.Set(float64(size))
+	metrics.SetLastBackup(c.last)
+
+	c.applyRetention()
+	return name, nil
+}
+
+// snapshot stores a new backup and returns its name and the size of its snapshot
+func (c *Controller) snapshot(ctx context.Context, client Snapshotter, clusterToken string) (string, int64, error) {
+	if !client.SupportsSnapshot() {
+		return "", 0, fmt.Errorf("etcd does not support snapshots")
+	}
+
+	now := c.clock.Now()
+	name := NewName(now)
+
+	version, err := client.ServerVersion(ctx)
+	if err != nil {
+		return "", 0, fmt.Errorf("error getting etcd version: %v", err)
+	}
+	info, err := client.LocalNodeInfo(ctx)
+	if err != nil {
+		return "", 0, fmt.Errorf("error getting etcd revision: %v", err)
+	}
+
+	tmp, err := ioutil.TempFile("", "etcd-backup")
+	if err != nil {
+		return "", 0, err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if err := client.SnapshotSave(ctx, tmp.Name()); err != nil {
+		return "", 0, err
+	}
+	stat, err := os.Stat(tmp.Name())
+	if err != nil {
+		return "", 0, err
+	}
+	checksum, err := fileChecksum(tmp.Name())
+	if err != nil {
+		return "", 0, err
+	}
+	manifest := &Manifest{
+		Revision:     info.Revision,
+		EtcdVersion:  version,
+		ClusterToken: clusterToken,
+		Timestamp:    now.UTC(),
+		Checksum:     checksum,
+	}
+	for i := 1; ; i++ {
+		err := c.store.AddBackup(name, tmp.Name(), manifest)
+		if err == nil {
+			break
+		}
+		if err != ErrBackupExists || i == nameAttempts {
+			return "", 0, err
+		}
+		// an operator or another leader took a backup in the same millisecond
+		name = NewName(now.Add(time.Duration(i) * time.Millisecond))
+	}
+	c.last = now
+	glog.Infof("stored backup %s of revision %d in %s", name, info.Revision, c.store.Spec())
+	return name, stat.Size(), nil
+}
+
+func (c *Controller) applyRetention() {
+	names, err := c.store.ListBackups()
+	if err != nil {
+		glog.Warningf("error listing backups: %v", err)
+		return
+	}
+	for _, name := range c.retention.Expired(names) {
+		glog.V(2).Infof("removing expired backup %s", name)
+		if err := c.store.RemoveBackup(name); err != nil {
+			glog.Warningf("error removing backup %s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package backup
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/etcd-manager/etcd-discovery/pkg/etcdclient"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+type fakeSnapshotter struct {
+	revision int64
+}
+
+func (f *fakeSnapshotter) ServerVersion(ctx context.Context) (string, error) {
+	return "3.2.13", nil
+}
+
+func (f *fakeSnapshotter) LocalNodeInfo(ctx context.Context) (*etcdclient.LocalNodeInfo, error) {
+	return &etcdclient.LocalNodeInfo{IsLeader: true, Revision: f.revision}, nil
+}
+
+func (f *fakeSnapshotter) SnapshotSave(ctx context.Context, path string) error {
+	return ioutil.WriteFile(path, []byte("snapshot"), 0644)
+}
+
+func (f *fakeSnapshotter) SupportsSnapshot() bool {
+	return true
+}
+
+func TestControllerMaybeBackup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := NewFileStore(dir)
+	fakeClock := clock.NewFakeClock(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := NewController(store, time.Hour, RetentionPolicy{KeepLast: 2}, fakeClock)
+	client := &fakeSnapshotter{revision: 7}
+
+	name, err := c.MaybeBackup(context.Background(), client, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name == "" {
+		t.Fatalf("expected a backup of an empty store")
+	}
+	manifest, err := store.LoadManifest(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Revision != 7 || manifest.ClusterToken != "token" || manifest.EtcdVersion != "3.2.13" || !manifest.Timestamp.Equal(fakeClock.Now()) {
+		t.Errorf("unexpected manifest %+v", manifest)
+	}
+
+	fakeClock.Step(30 * time.Minute)
+	if name, err := c.MaybeBackup(context.Background(), client, "token"); err != nil || name != "" {
+		t.Fatalf("backup taken before it was due: %q, %v", name, err)
+	}
+
+	for i := 0; i < 3; i++ {
+		fakeClock.Step(time.Hour)
+		if name, err := c.MaybeBackup(context.Background(), client, "token"); err != nil || name == "" {
+			t.Fatalf("expected a backup: %q, %v", name, err)
+		}
+	}
+	names, err := store.ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 {
+		t.Errorf("expected retention to keep 2 backups, got %v", names)
+	}
+
+	// a new controller, e.g. on a new leader, continues the schedule of the store
+	c = NewController(store, time.Hour, RetentionPolicy{KeepLast: 2}, fakeClock)
+	if name, err := c.MaybeBackup(context.Background(), client, "token"); err != nil || name != "" {
+		t.Fatalf("backup taken before it was due: %q, %v", name, err)
+	}
+}
+
+func TestControllerSnapshotRetriesTakenName(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := NewFileStore(dir)
+	fakeClock := clock.NewFakeClock(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := NewController(store, time.Hour, RetentionPolicy{}, fakeClock)
+	client := &fakeSnapshotter{revision: 7}
+
+	first, _, err := c.snapshot(context.Background(), client, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.revision = 8
+	second, _, err := c.snapshot(context.Background(), client, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := NewName(fakeClock.Now().Add(time.Millisecond)); first == second || second != expected {
+		t.Fatalf("expected the backup after %s to be named %s, got %s", first, expected, second)
+	}
+	if manifest, err := store.LoadManifest(first); err != nil || manifest.Revision != 7 {
+		t.Errorf("backup %s was replaced: %+v, %v", first, manifest, err)
+	}
+}
@@ -0,0 +1,178 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	snapshotFileName = "etcd.backup.gz"
+	manifestFileName = "_etcd_backup.meta"
+)
+
+// fileStore keeps every backup in a directory named after the backup
+type fileStore struct {
+	root string
+}
+
+var _ BackupStore = &fileStore{}
+
+func NewFileStore(root string) BackupStore {
+	return &fileStore{root: root}
+}
+
+func (s *fileStore) Spec() string {
+	return "file://" + s.root
+}
+
+func (s *fileStore) AddBackup(name string, snapshotFile string, manifest *Manifest) error {
+	if err := os.MkdirAll(s.root, 0755); err != nil {
+		return fmt.Errorf("error creating backup store %s: %v", s.root, err)
+	}
+	// write into a temporary directory, so a listed backup is always complete
+	tmp, err := ioutil.TempDir(s.root, "."+name)
+	if err != nil {
+		return fmt.Errorf("error creating backup directory: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := copyFile(snapshotFile, filepath.Join(tmp, snapshotFileName)); err != nil {
+		return err
+	}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("error serializing backup manifest: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(tmp, manifestFileName), data, 0644); err != nil {
+		return fmt.Errorf("error writing backup manifest: %v", err)
+	}
+	if err := os.Chmod(tmp, 0755); err != nil {
+		return err
+	}
+	// a directory is not renamed over another backup of the name, which is not empty
+	if err := os.Rename(tmp, filepath.Join(s.root, name)); err != nil {
+		if _, statErr := os.Stat(filepath.Join(s.root, name)); statErr == nil {
+			return ErrBackupExists
+		}
+		return fmt.Errorf("error storing backup %s: %v", name, err)
+	}
+	return nil
+}
+
+func (s *fileStore) ListBackups() ([]string, error) {
+	files, err := ioutil.ReadDir(s.root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading backup store %s: %v", s.root, err)
+	}
+	var names []string
+	for _, f := range files {
+		if f.IsDir() {
+			names = append(names, f.Name())
+		}
+	}
+	return sortNames(names), nil
+}
+
+func (s *fileStore) LoadManifest(name string) (*Manifest, error) {
+	data, err := ioutil.ReadFile(filepath.Join(s.root, name, manifestFileName))
+	if err != nil {
+		return nil, fmt.Errorf("error reading manifest of backup %s: %v", name, err)
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("error parsing manifest of backup %s: %v", name, err)
+	}
+	return manifest, nil
+}
+
+func (s *fileStore) DownloadBackup(name string, destFile string) error {
+	return copyFile(filepath.Join(s.root, name, snapshotFileName), destFile)
+}
+
+func (s *fileStore) RemoveBackup(name string) error {
+	if _, err := ParseName(name); err != nil {
+		return fmt.Errorf("invalid backup name %q", name)
+	}
+	return os.RemoveAll(filepath.Join(s.root, name))
+}
+
+func (s *fileStore) SaveCA(cert, key []byte) error {
+	dir := filepath.Join(s.root, filepath.Dir(caCertFile))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("error creating CA directory: %v", err)
+	}
+	// the key is written first, a certificate is only read along with its key
+	if err := ioutil.WriteFile(filepath.Join(s.root, caKeyFile), key, 0600); err != nil {
+		return fmt.Errorf("error writing CA key: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(s.root, caCertFile), cert, 0644); err != nil {
+		return fmt.Errorf("error writing CA certificate: %v", err)
+	}
+	return nil
+}
+
+func (s *fileStore) CreateCA(cert, key []byte) error {
+	dir := filepath.Join(s.root, filepath.Dir(caCertFile))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("error creating CA directory: %v", err)
+	}
+	// the peer that creates the key owns the CA, the others wait for its certificate
+	f, err := os.OpenFile(filepath.Join(s.root, caKeyFile), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		if os.IsExist(err) {
+			return ErrCAExists
+		}
+		return fmt.Errorf("error creating CA key: %v", err)
+	}
+	_, err = f.Write(key)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("error writing CA key: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(s.root, caCertFile), cert, 0644); err != nil {
+		return fmt.Errorf("error writing CA certificate: %v", err)
+	}
+	return nil
+}
+
+func (s *fileStore) LoadCA() ([]byte, []byte, error) {
+	cert, err := ioutil.ReadFile(filepath.Join(s.root, caCertFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, ErrNoCA
+		}
+		return nil, nil, fmt.Errorf("error reading CA certificate: %v", err)
+	}
+	key, err := ioutil.ReadFile(filepath.Join(s.root, caKeyFile))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading CA key: %v", err)
+	}
+	return cert, key, nil
+}
+
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("error copying %s to %s: %v", src, dest, err)
+	}
+	return out.Close()
+}
@@ -0,0 +1,43 @@
+package backup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewStore("file://" + filepath.Join(dir, "store"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names, err := store.ListBackups(); err != nil || len(names) != 0 {
+		t.Fatalf("expected empty store, got %v, %v", names, err)
+	}
+	testBackupStore(t, store)
+}
+
+func TestNewStore(t *testing.T) {
+	for _, spec := range []string{"/var/backups", "file:///var/backups"} {
+		store, err := NewStore(spec)
+		if err != nil {
+			t.Errorf("%s: %v", spec, err)
+			continue
+		}
+		if store.Spec() != "file:///var/backups" {
+			t.Errorf("%s: unexpected spec %s", spec, store.Spec())
+		}
+	}
+	for _, spec := range []string{"ftp://host/backups", "file://host/backups"} {
+		if _, err := NewStore(spec); err == nil {
+			t.Errorf("%s: expected error", spec)
+		}
+	}
+}
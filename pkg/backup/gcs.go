@@ -0,0 +1,308 @@
+package backup
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	gcsEndpoint      = "https://storage.googleapis.com"
+	gcsScope         = "https://www.googleapis.com/auth/devstorage.read_write"
+	gceMetadataToken = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+// gcsClient talks to the JSON API of Google Cloud Storage.
+//
+// gs://bucket/prefix takes the query parameters endpoint and kms-key-name, the Cloud KMS
+// key encrypting new objects. A customer supplied AES-256 key, base64 encoded, is read from
+// GCS_ENCRYPTION_KEY. The access token is read from GOOGLE_OAUTH_ACCESS_TOKEN, obtained for
+// the service account in GOOGLE_APPLICATION_CREDENTIALS, or from the GCE metadata server.
+type gcsClient struct {
+	bucket        string
+	endpoint      string
+	kmsKeyName    string
+	encryptionKey []byte
+
+	client *http.Client
+	token  func() (string, error)
+}
+
+var _ objectClient = &gcsClient{}
+
+func newGCSStore(u *url.URL) (BackupStore, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("bucket is missing in backup store %q", u)
+	}
+	c := &gcsClient{
+		bucket:     u.Host,
+		endpoint:   strings.TrimSuffix(u.Query().Get("endpoint"), "/"),
+		kmsKeyName: u.Query().Get("kms-key-name"),
+		client:     &http.Client{Timeout: objectTimeout},
+	}
+	if c.endpoint == "" {
+		c.endpoint = gcsEndpoint
+	}
+	if key := os.Getenv("GCS_ENCRYPTION_KEY"); key != "" {
+		data, err := base64.StdEncoding.DecodeString(key)
+		if err != nil || len(data) != 32 {
+			return nil, fmt.Errorf("GCS_ENCRYPTION_KEY must be a base64 encoded 256 bit key")
+		}
+		c.encryptionKey = data
+	}
+	if c.kmsKeyName != "" && c.encryptionKey != nil {
+		return nil, fmt.Errorf("kms-key-name and GCS_ENCRYPTION_KEY are mutually exclusive")
+	}
+
+	switch {
+	case os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN") != "":
+		token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")
+		c.token = func() (string, error) { return token, nil }
+	case os.Getenv("GOOGLE_APPLICATION_CREDENTIALS") != "":
+		sa, err := loadServiceAccount(os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"))
+		if err != nil {
+			return nil, err
+		}
+		c.token = newCachedToken(func() (string, time.Duration, error) {
+			return sa.fetchToken(c.client)
+		})
+	default:
+		c.token = newCachedToken(func() (string, time.Duration, error) {
+			return fetchMetadataToken(c.client)
+		})
+	}
+	return newObjectStore(u, c), nil
+}
+
+func (c *gcsClient) objectURL(key string) string {
+	return c.endpoint + "/storage/v1/b/" + url.PathEscape(c.bucket) + "/o/" + url.PathEscape(key)
+}
+
+func (c *gcsClient) do(method, u string, body io.ReadSeeker, size int64, expected ...int) (*http.Response, error) {
+	token, err := c.token()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Body = ioutil.NopCloser(body)
+		req.ContentLength = size
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if c.encryptionKey != nil {
+		hash := sha256.Sum256(c.encryptionKey)
+		req.Header.Set("X-Goog-Encryption-Algorithm", "AES256")
+		req.Header.Set("X-Goog-Encryption-Key", base64.StdEncoding.EncodeToString(c.encryptionKey))
+		req.Header.Set("X-Goog-Encryption-Key-Sha256", base64.StdEncoding.EncodeToString(hash[:]))
+	}
+	return doRequest(c.client, req, expected...)
+}
+
+func (c *gcsClient) PutObject(key string, body io.ReadSeeker, size int64) error {
+	return c.putObject(key, body, size, url.Values{})
+}
+
+func (c *gcsClient) CreateObject(key string, body io.ReadSeeker, size int64) error {
+	// generation 0 matches objects that do not exist
+	return c.putObject(key, body, size, url.Values{"ifGenerationMatch": {"0"}})
+}
+
+func (c *gcsClient) putObject(key string, body io.ReadSeeker, size int64, query url.Values) error {
+	query.Set("uploadType", "media")
+	query.Set("name", key)
+	if c.kmsKeyName != "" {
+		query.Set("kmsKeyName", c.kmsKeyName)
+	}
+	u := c.endpoint + "/upload/storage/v1/b/" + url.PathEscape(c.bucket) + "/o?" + query.Encode()
+	resp, err := c.do(http.MethodPost, u, body, size, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (c *gcsClient) GetObject(key string) (io.ReadCloser, error) {
+	resp, err := c.do(http.MethodGet, c.objectURL(key)+"?alt=media", nil, 0, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *gcsClient) ListObjects(prefix string) ([]string, error) {
+	var keys []string
+	query := url.Values{
+		"prefix": {prefix},
+		"fields": {"items(name),nextPageToken"},
+	}
+	for {
+		u := c.endpoint + "/storage/v1/b/" + url.PathEscape(c.bucket) + "/o?" + query.Encode()
+		resp, err := c.do(http.MethodGet, u, nil, 0, http.StatusOK)
+		if err != nil {
+			return nil, err
+		}
+		var result struct {
+			Items []struct {
+				Name string `json:"name"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error parsing bucket listing: %v", err)
+		}
+		for _, item := range result.Items {
+			keys = append(keys, item.Name)
+		}
+		if result.NextPageToken == "" {
+			return keys, nil
+		}
+		query.Set("pageToken", result.NextPageToken)
+	}
+}
+
+func (c *gcsClient) DeleteObject(key string) error {
+	resp, err := c.do(http.MethodDelete, c.objectURL(key), nil, 0, http.StatusNoContent, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// newCachedToken caches the tokens returned by fetch until shortly before they expire
+func newCachedToken(fetch func() (string, time.Duration, error)) func() (string, error) {
+	var mutex sync.Mutex
+	var token string
+	var expiry time.Time
+	return func() (string, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if token != "" && time.Now().Before(expiry) {
+			return token, nil
+		}
+		t, ttl, err := fetch()
+		if err != nil {
+			return "", err
+		}
+		token, expiry = t, time.Now().Add(ttl-time.Minute)
+		return token, nil
+	}
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+func decodeToken(resp *http.Response) (string, time.Duration, error) {
+	defer resp.Body.Close()
+	var t tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return "", 0, fmt.Errorf("error parsing access token: %v", err)
+	}
+	if t.AccessToken == "" {
+		return "", 0, fmt.Errorf("empty access token")
+	}
+	return t.AccessToken, time.Duration(t.ExpiresIn) * time.Second, nil
+}
+
+func fetchMetadataToken(client *http.Client) (string, time.Duration, error) {
+	req, err := http.NewRequest(http.MethodGet, gceMetadataToken, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := doRequest(client, req, http.StatusOK)
+	if err != nil {
+		return "", 0, fmt.Errorf("no google credentials in environment or metadata server: %v", err)
+	}
+	return decodeToken(resp)
+}
+
+type serviceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+
+	key *rsa.PrivateKey
+}
+
+func loadServiceAccount(path string) (*serviceAccount, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading google credentials: %v", err)
+	}
+	sa := &serviceAccount{}
+	if err := json.Unmarshal(data, sa); err != nil {
+		return nil, fmt.Errorf("error parsing google credentials %s: %v", path, err)
+	}
+	block, _ := pem.Decode([]byte(sa.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("no private key in google credentials %s", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing private key in google credentials %s: %v", path, err)
+	}
+	var ok bool
+	if sa.key, ok = key.(*rsa.PrivateKey); !ok {
+		return nil, fmt.Errorf("private key in google credentials %s is not a RSA key", path)
+	}
+	if sa.TokenURI == "" {
+		sa.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return sa, nil
+}
+
+// fetchToken exchanges a signed JWT for an access token
+func (sa *serviceAccount) fetchToken(client *http.Client) (string, time.Duration, error) {
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   sa.ClientEmail,
+		"scope": gcsScope,
+		"aud":   sa.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, sa.key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", 0, err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)},
+	}
+	req, err := http.NewRequest(http.MethodPost, sa.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := doRequest(client, req, http.StatusOK)
+	if err != nil {
+		return "", 0, fmt.Errorf("error getting access token for %s: %v", sa.ClientEmail, err)
+	}
+	return decodeToken(resp)
+}
@@ -0,0 +1,177 @@
+package backup
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeGCS serves the JSON API of a bucket to requests bearing token
+func fakeGCS(t *testing.T, bucket, token string, objects *fakeObjects) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		upload := "/upload/storage/v1/b/" + bucket + "/o"
+		list := "/storage/v1/b/" + bucket + "/o"
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == upload:
+			if r.URL.Query().Get("uploadType") != "media" {
+				http.Error(w, "unsupported upload", http.StatusBadRequest)
+				return
+			}
+			if err := objects.put(r.URL.Query().Get("name"), r); err == errFakeExists {
+				http.Error(w, err.Error(), http.StatusPreconditionFailed)
+				return
+			} else if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Write([]byte("{}"))
+		case r.Method == http.MethodGet && r.URL.Path == list:
+			keys, more := objects.list(r.URL.Query().Get("prefix"), r.URL.Query().Get("pageToken"))
+			result := map[string]interface{}{}
+			var items []map[string]string
+			for _, key := range keys {
+				items = append(items, map[string]string{"name": key})
+				if more {
+					result["nextPageToken"] = key
+				}
+			}
+			result["items"] = items
+			json.NewEncoder(w).Encode(result)
+		case strings.HasPrefix(r.URL.Path, list+"/"):
+			key := strings.TrimPrefix(r.URL.Path, list+"/")
+			switch r.Method {
+			case http.MethodGet:
+				data, ok := objects.get(key)
+				if !ok || r.URL.Query().Get("alt") != "media" {
+					http.Error(w, "not found", http.StatusNotFound)
+					return
+				}
+				w.Write(data)
+			case http.MethodDelete:
+				if !objects.delete(key) {
+					http.Error(w, "not found", http.StatusNotFound)
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+}
+
+func TestGCSStore(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Read(key)
+	defer setenv(map[string]string{
+		"GOOGLE_OAUTH_ACCESS_TOKEN": "token",
+		"GCS_ENCRYPTION_KEY":        base64.StdEncoding.EncodeToString(key),
+	})()
+
+	objects := newFakeObjects()
+	server := httptest.NewServer(fakeGCS(t, "backups", "token", objects))
+	defer server.Close()
+
+	store, err := NewStore("gs://backups/etcd?endpoint=" + url.QueryEscape(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBackupStore(t, store)
+
+	name := NewName(time.Date(2018, 3, 4, 5, 6, 7, 0, time.UTC))
+	header := objects.header("etcd/" + name + "/" + snapshotFileName)
+	if header == nil {
+		t.Fatalf("backup %s not found in bucket", name)
+	}
+	hash := sha256.Sum256(key)
+	if header.Get("X-Goog-Encryption-Algorithm") != "AES256" || header.Get("X-Goog-Encryption-Key-Sha256") != base64.StdEncoding.EncodeToString(hash[:]) {
+		t.Errorf("backup was not encrypted with the customer supplied key: %v", header)
+	}
+}
+
+func TestGCSServiceAccount(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/token" {
+			fakeGCS(t, "backups", "sa-token", newFakeObjects()).ServeHTTP(w, r)
+			return
+		}
+		parts := strings.Split(r.FormValue("assertion"), ".")
+		if len(parts) != 3 {
+			http.Error(w, "invalid assertion", http.StatusBadRequest)
+			return
+		}
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hash[:], signature); err != nil {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		if !strings.Contains(string(claims), `"iss":"backup@example.iam.gserviceaccount.com"`) {
+			http.Error(w, "invalid claims", http.StatusUnauthorized)
+			return
+		}
+		tokens++
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "sa-token", "expires_in": 3600})
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds, _ := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "backup@example.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    server.URL + "/token",
+	})
+	credsFile := filepath.Join(dir, "credentials.json")
+	if err := ioutil.WriteFile(credsFile, creds, 0600); err != nil {
+		t.Fatal(err)
+	}
+	defer setenv(map[string]string{"GOOGLE_APPLICATION_CREDENTIALS": credsFile})()
+	os.Unsetenv("GOOGLE_OAUTH_ACCESS_TOKEN")
+
+	store, err := NewStore("gs://backups?endpoint=" + url.QueryEscape(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := store.ListBackups(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if tokens != 1 {
+		t.Errorf("expected the access token to be cached, fetched %d tokens", tokens)
+	}
+}
@@ -0,0 +1,252 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	// errNotFound is returned by object clients for missing objects
+	errNotFound = errors.New("object not found")
+	// errExists is returned by CreateObject if the object exists
+	errExists = errors.New("object exists")
+)
+
+// objectTimeout bounds a single request to an object store, snapshots may be large
+const objectTimeout = 30 * time.Minute
+
+// objectClient is the part of an object storage API used to store backups.
+// Keys are relative to the bucket or container.
+type objectClient interface {
+	PutObject(key string, body io.ReadSeeker, size int64) error
+	// CreateObject writes the object unless it exists, errExists otherwise
+	CreateObject(key string, body io.ReadSeeker, size int64) error
+	GetObject(key string) (io.ReadCloser, error)
+	// ListObjects returns the keys starting with prefix
+	ListObjects(prefix string) ([]string, error)
+	DeleteObject(key string) error
+}
+
+// objectStore keeps a backup in two objects, <prefix><name>/etcd.backup.gz and its manifest.
+// The manifest is written last and removed first, so listed backups are complete.
+type objectStore struct {
+	spec   string
+	prefix string
+	client objectClient
+}
+
+var _ BackupStore = &objectStore{}
+
+func newObjectStore(u *url.URL, client objectClient) *objectStore {
+	prefix := strings.Trim(u.Path, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	spec := *u
+	spec.RawQuery = ""
+	return &objectStore{
+		spec:   spec.String(),
+		prefix: prefix,
+		client: client,
+	}
+}
+
+func (s *objectStore) Spec() string {
+	return s.spec
+}
+
+func (s *objectStore) key(name, file string) string {
+	return s.prefix + name + "/" + file
+}
+
+func (s *objectStore) AddBackup(name string, snapshotFile string, manifest *Manifest) error {
+	f, err := os.Open(snapshotFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	// the snapshot of another backup of the name is not replaced
+	if err := s.client.CreateObject(s.key(name, snapshotFileName), f, stat.Size()); err != nil {
+		if err == errExists {
+			return ErrBackupExists
+		}
+		return fmt.Errorf("error uploading backup %s to %s: %v", name, s.spec, err)
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("error serializing backup manifest: %v", err)
+	}
+	if err := s.client.PutObject(s.key(name, manifestFileName), bytes.NewReader(data), int64(len(data))); err != nil {
+		return fmt.Errorf("error uploading manifest of backup %s to %s: %v", name, s.spec, err)
+	}
+	return nil
+}
+
+func (s *objectStore) ListBackups() ([]string, error) {
+	keys, err := s.client.ListObjects(s.prefix)
+	if err != nil {
+		return nil, fmt.Errorf("error listing backups in %s: %v", s.spec, err)
+	}
+	var names []string
+	for _, key := range keys {
+		name := strings.TrimPrefix(key, s.prefix)
+		if strings.Count(name, "/") == 1 && strings.HasSuffix(name, "/"+manifestFileName) {
+			names = append(names, strings.TrimSuffix(name, "/"+manifestFileName))
+		}
+	}
+	return sortNames(names), nil
+}
+
+func (s *objectStore) LoadManifest(name string) (*Manifest, error) {
+	r, err := s.client.GetObject(s.key(name, manifestFileName))
+	if err != nil {
+		return nil, fmt.Errorf("error reading manifest of backup %s: %v", name, err)
+	}
+	defer r.Close()
+	manifest := &Manifest{}
+	if err := json.NewDecoder(r).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("error parsing manifest of backup %s: %v", name, err)
+	}
+	return manifest, nil
+}
+
+func (s *objectStore) DownloadBackup(name string, destFile string) error {
+	r, err := s.client.GetObject(s.key(name, snapshotFileName))
+	if err != nil {
+		return fmt.Errorf("error downloading backup %s: %v", name, err)
+	}
+	defer r.Close()
+
+	out, err := os.Create(destFile)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return fmt.Errorf("error downloading backup %s: %v", name, err)
+	}
+	return out.Close()
+}
+
+func (s *objectStore) RemoveBackup(name string) error {
+	if _, err := ParseName(name); err != nil {
+		return fmt.Errorf("invalid backup name %q", name)
+	}
+	for _, file := range []string{manifestFileName, snapshotFileName} {
+		if err := s.client.DeleteObject(s.key(name, file)); err != nil && err != errNotFound {
+			return fmt.Errorf("error removing backup %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+func (s *objectStore) SaveCA(cert, key []byte) error {
+	// the key is written first, a certificate is only read along with its key
+	if err := s.client.PutObject(s.prefix+caKeyFile, bytes.NewReader(key), int64(len(key))); err != nil {
+		return fmt.Errorf("error uploading CA key to %s: %v", s.spec, err)
+	}
+	if err := s.client.PutObject(s.prefix+caCertFile, bytes.NewReader(cert), int64(len(cert))); err != nil {
+		return fmt.Errorf("error uploading CA certificate to %s: %v", s.spec, err)
+	}
+	return nil
+}
+
+func (s *objectStore) CreateCA(cert, key []byte) error {
+	// the peer that creates the key owns the CA, the others wait for its certificate
+	if err := s.client.CreateObject(s.prefix+caKeyFile, bytes.NewReader(key), int64(len(key))); err != nil {
+		if err == errExists {
+			return ErrCAExists
+		}
+		return fmt.Errorf("error uploading CA key to %s: %v", s.spec, err)
+	}
+	if err := s.client.PutObject(s.prefix+caCertFile, bytes.NewReader(cert), int64(len(cert))); err != nil {
+		return fmt.Errorf("error uploading CA certificate to %s: %v", s.spec, err)
+	}
+	return nil
+}
+
+func (s *objectStore) LoadCA() ([]byte, []byte, error) {
+	cert, err := s.readObject(s.prefix + caCertFile)
+	if err != nil {
+		if err == errNotFound {
+			return nil, nil, ErrNoCA
+		}
+		return nil, nil, fmt.Errorf("error reading CA certificate from %s: %v", s.spec, err)
+	}
+	key, err := s.readObject(s.prefix + caKeyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading CA key from %s: %v", s.spec, err)
+	}
+	return cert, key, nil
+}
+
+func (s *objectStore) readObject(key string) ([]byte, error) {
+	r, err := s.client.GetObject(key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// doRequest sends req and checks the response status. The caller closes the body.
+func doRequest(client *http.Client, req *http.Request, expected ...int) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	return checkResponse(req, resp, expected...)
+}
+
+// checkResponse returns resp if it has one of the expected status codes, or closes it
+// and returns an error
+func checkResponse(req *http.Request, resp *http.Response, expected ...int) (*http.Response, error) {
+	for _, code := range expected {
+		if resp.StatusCode == code {
+			return resp, nil
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errNotFound
+	}
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return nil, errExists
+	}
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	return nil, fmt.Errorf("%s %s: unexpected status %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(body)))
+}
+
+// rewind seeks body back to the start, so a request can be retried
+func rewind(body io.ReadSeeker) error {
+	if body == nil {
+		return nil
+	}
+	_, err := body.Seek(0, io.SeekStart)
+	return err
+}
+
+// queryOption returns the query parameter name of u, or the environment variable env
+func queryOption(u *url.URL, name, env string) string {
+	if v := u.Query().Get(name); v != "" {
+		return v
+	}
+	if env != "" {
+		return os.Getenv(env)
+	}
+	return ""
+}
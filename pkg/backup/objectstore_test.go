@@ -0,0 +1,192 @@
+package backup
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// setenv sets environment variables for a test, the returned func restores them
+func setenv(env map[string]string) func() {
+	old := map[string]*string{}
+	for k, v := range env {
+		if prev, ok := os.LookupEnv(k); ok {
+			old[k] = &prev
+		} else {
+			old[k] = nil
+		}
+		os.Setenv(k, v)
+	}
+	return func() {
+		for k, v := range old {
+			if v == nil {
+				os.Unsetenv(k)
+			} else {
+				os.Setenv(k, *v)
+			}
+		}
+	}
+}
+
+// fakeObjects is the in-memory bucket of the fake object storage servers
+type fakeObjects struct {
+	mutex   sync.Mutex
+	objects map[string][]byte
+	// headers are the request headers of the last write of an object
+	headers map[string]http.Header
+}
+
+func newFakeObjects() *fakeObjects {
+	return &fakeObjects{
+		objects: map[string][]byte{},
+		headers: map[string]http.Header{},
+	}
+}
+
+// errFakeExists is returned by put for conditional writes of existing objects
+var errFakeExists = errors.New("object exists")
+
+// put writes an object, unless the request is conditional on a missing object and it exists
+func (f *fakeObjects) put(key string, r *http.Request) error {
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if _, ok := f.objects[key]; ok && (r.Header.Get("If-None-Match") == "*" || r.URL.Query().Get("ifGenerationMatch") == "0") {
+		return errFakeExists
+	}
+	f.objects[key] = data
+	f.headers[key] = r.Header
+	return nil
+}
+
+func (f *fakeObjects) get(key string) ([]byte, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	data, ok := f.objects[key]
+	return data, ok
+}
+
+func (f *fakeObjects) delete(key string) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	_, ok := f.objects[key]
+	delete(f.objects, key)
+	return ok
+}
+
+// list returns a page of at most one key after marker, so clients have to paginate
+func (f *fakeObjects) list(prefix, marker string) (keys []string, more bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var all []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) && key > marker {
+			all = append(all, key)
+		}
+	}
+	sort.Strings(all)
+	if len(all) == 0 {
+		return nil, false
+	}
+	return all[:1], len(all) > 1
+}
+
+func (f *fakeObjects) header(key string) http.Header {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.headers[key]
+}
+
+// testBackupStore runs a store through adding, listing, reading and removing backups,
+// and saving the CA
+func testBackupStore(t *testing.T, store BackupStore) {
+	dir, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	snapshot := filepath.Join(dir, "snapshot")
+	if err := ioutil.WriteFile(snapshot, []byte("snapshot data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2018, 3, 4, 5, 6, 7, 0, time.UTC)
+	manifest := &Manifest{Revision: 42, EtcdVersion: "3.2.13", ClusterToken: "token", Timestamp: now}
+	expected := []string{NewName(now.Add(-time.Hour)), NewName(now)}
+	for _, name := range expected {
+		if err := store.AddBackup(name, snapshot, manifest); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := store.AddBackup(expected[1], snapshot, &Manifest{Revision: 43}); err != ErrBackupExists {
+		t.Errorf("expected ErrBackupExists for a taken name, got %v", err)
+	}
+
+	names, err := store.ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected backups %v, got %v", expected, names)
+	}
+
+	loaded, err := store.LoadManifest(names[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, manifest) {
+		t.Errorf("expected manifest %+v, got %+v", manifest, loaded)
+	}
+
+	dest := filepath.Join(dir, "restored")
+	if err := store.DownloadBackup(names[1], dest); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := ioutil.ReadFile(dest); err != nil || string(data) != "snapshot data" {
+		t.Errorf("unexpected snapshot %q, %v", data, err)
+	}
+
+	if err := store.RemoveBackup(names[0]); err != nil {
+		t.Fatal(err)
+	}
+	if names, err := store.ListBackups(); err != nil || !reflect.DeepEqual(names, expected[1:]) {
+		t.Errorf("expected %v after remove, got %v, %v", expected[1:], names, err)
+	}
+	if _, err := store.LoadManifest(expected[0]); err == nil {
+		t.Errorf("manifest of removed backup %s is still readable", expected[0])
+	}
+
+	if _, _, err := store.LoadCA(); err != ErrNoCA {
+		t.Errorf("expected ErrNoCA before the CA is saved, got %v", err)
+	}
+	if err := store.CreateCA([]byte("created"), []byte("created key")); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.CreateCA([]byte("other"), []byte("other key")); err != ErrCAExists {
+		t.Errorf("expected the CA to be created once, got %v", err)
+	}
+	if cert, key, err := store.LoadCA(); err != nil || string(cert) != "created" || string(key) != "created key" {
+		t.Errorf("unexpected created CA %q, %q, %v", cert, key, err)
+	}
+	if err := store.SaveCA([]byte("cert"), []byte("key")); err != nil {
+		t.Fatal(err)
+	}
+	if cert, key, err := store.LoadCA(); err != nil || string(cert) != "cert" || string(key) != "key" {
+		t.Errorf("unexpected CA %q, %q, %v", cert, key, err)
+	}
+	if names, err := store.ListBackups(); err != nil || !reflect.DeepEqual(names, expected[1:]) {
+		t.Errorf("expected the CA not to be listed as a backup, got %v, %v", names, err)
+	}
+}
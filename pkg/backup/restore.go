@@ -0,0 +1,103 @@
+package backup
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// Latest selects the newest backup of a store
+const Latest = "latest"
+
+// ResolveName returns the name of the backup selected by name, Latest or the name of a backup
+func ResolveName(store BackupStore, name string) (string, error) {
+	if name != Latest {
+		if _, err := ParseName(name); err != nil {
+			return "", fmt.Errorf("invalid backup name %q", name)
+		}
+		return name, nil
+	}
+	names, err := store.ListBackups()
+	if err != nil {
+		return "", err
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("no backups found in %s", store.Spec())
+	}
+	return names[len(names)-1], nil
+}
+
+// FetchBackup downloads backup name and writes the uncompressed snapshot to destFile,
+// after verifying the checksum of the manifest.
+func FetchBackup(store BackupStore, name string, destFile string) (*Manifest, error) {
+	manifest, err := store.LoadManifest(name)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Checksum == "" {
+		return nil, fmt.Errorf("backup %s has no checksum", name)
+	}
+
+	tmp, err := ioutil.TempFile("", "etcd-backup")
+	if err != nil {
+		return nil, err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if err := store.DownloadBackup(name, tmp.Name()); err != nil {
+		return nil, fmt.Errorf("error downloading backup %s: %v", name, err)
+	}
+	checksum, err := fileChecksum(tmp.Name())
+	if err != nil {
+		return nil, err
+	}
+	if checksum != manifest.Checksum {
+		return nil, fmt.Errorf("checksum of backup %s is %s, expected %s", name, checksum, manifest.Checksum)
+	}
+	if err := gunzipFile(tmp.Name(), destFile); err != nil {
+		return nil, fmt.Errorf("error decompressing backup %s: %v", name, err)
+	}
+	return manifest, nil
+}
+
+// fileChecksum returns the hex encoded sha256 of a file
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", fmt.Errorf("error reading %s: %v", path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func gunzipFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		return err
+	}
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, gz); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,87 @@
+package backup
+
+import (
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// gzipSnapshotter writes compressed snapshots, like the etcd v3 client
+type gzipSnapshotter struct {
+	fakeSnapshotter
+}
+
+func (f *gzipSnapshotter) SnapshotSave(ctx context.Context, path string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	gz := gzip.NewWriter(out)
+	if _, err := gz.Write([]byte("snapshot")); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func TestFetchBackup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := NewFileStore(filepath.Join(dir, "store"))
+	if _, err := ResolveName(store, Latest); err == nil {
+		t.Errorf("expected error resolving the latest backup of an empty store")
+	}
+
+	fakeClock := clock.NewFakeClock(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := NewController(store, time.Hour, RetentionPolicy{}, fakeClock)
+	client := &gzipSnapshotter{fakeSnapshotter{revision: 7}}
+	first, err := c.Backup(context.Background(), client, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeClock.Step(time.Hour)
+	latest, err := c.Backup(context.Background(), client, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name, err := ResolveName(store, Latest); err != nil || name != latest {
+		t.Errorf("expected latest backup %s, got %s, %v", latest, name, err)
+	}
+	if name, err := ResolveName(store, first); err != nil || name != first {
+		t.Errorf("expected backup %s, got %s, %v", first, name, err)
+	}
+	if _, err := ResolveName(store, "yesterday"); err == nil {
+		t.Errorf("expected error resolving an invalid name")
+	}
+
+	dest := filepath.Join(dir, "snapshot.db")
+	manifest, err := FetchBackup(store, latest, dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Revision != 7 {
+		t.Errorf("unexpected manifest %+v", manifest)
+	}
+	if data, err := ioutil.ReadFile(dest); err != nil || string(data) != "snapshot" {
+		t.Errorf("unexpected snapshot %q, %v", data, err)
+	}
+
+	// corrupt the stored snapshot
+	if err := ioutil.WriteFile(filepath.Join(dir, "store", latest, snapshotFileName), []byte("garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FetchBackup(store, latest, dest); err == nil {
+		t.Errorf("expected checksum error fetching a corrupted backup")
+	}
+}
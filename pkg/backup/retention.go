@@ -0,0 +1,70 @@
+package backup
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RetentionPolicy decides which backups are kept. The newest backup of a day
+// (or week) stands for that day (or week). A zero policy keeps every backup.
+type RetentionPolicy struct {
+	// KeepLast is the number of most recent backups kept
+	KeepLast int
+	// KeepDaily is the number of most recent days a backup is kept for
+	KeepDaily int
+	// KeepWeekly is the number of most recent weeks a backup is kept for
+	KeepWeekly int
+}
+
+func (p RetentionPolicy) IsZero() bool {
+	return p.KeepLast == 0 && p.KeepDaily == 0 && p.KeepWeekly == 0
+}
+
+// Expired returns the backups the policy does not keep. The newest backup is always kept.
+func (p RetentionPolicy) Expired(names []string) []string {
+	if p.IsZero() {
+		return nil
+	}
+
+	// newest first
+	backups := sortNames(names)
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+
+	keep := map[string]bool{}
+	for i, name := range backups {
+		if i < p.KeepLast || i == 0 {
+			keep[name] = true
+		}
+	}
+	keepPeriodic := func(count int, period func(name string) string) {
+		seen := map[string]bool{}
+		for _, name := range backups {
+			if len(seen) >= count {
+				return
+			}
+			key := period(name)
+			if !seen[key] {
+				seen[key] = true
+				keep[name] = true
+			}
+		}
+	}
+	keepPeriodic(p.KeepDaily, func(name string) string {
+		t, _ := ParseName(name)
+		return t.Format("2006-01-02")
+	})
+	keepPeriodic(p.KeepWeekly, func(name string) string {
+		t, _ := ParseName(name)
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-%d", year, week)
+	})
+
+	var expired []string
+	for _, name := range backups {
+		if !keep[name] {
+			expired = append(expired, name)
+		}
+	}
+	sort.Strings(expired)
+	return expired
+}
@@ -0,0 +1,44 @@
+package backup
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRetentionPolicyExpired(t *testing.T) {
+	// a backup every 12 hours for 4 weeks
+	start := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	var names []string
+	for i := 0; i < 56; i++ {
+		names = append(names, NewName(start.Add(time.Duration(i)*12*time.Hour)))
+	}
+
+	cases := []struct {
+		name   string
+		policy RetentionPolicy
+		kept   []string
+	}{
+		{"zero keeps everything", RetentionPolicy{}, names},
+		{"last", RetentionPolicy{KeepLast: 3}, names[53:]},
+		{"daily", RetentionPolicy{KeepDaily: 3}, []string{names[51], names[53], names[55]}},
+		{"weekly", RetentionPolicy{KeepWeekly: 2}, []string{names[41], names[55]}},
+		{"combined", RetentionPolicy{KeepLast: 1, KeepDaily: 2, KeepWeekly: 2}, []string{names[41], names[53], names[55]}},
+	}
+	for _, c := range cases {
+		expired := c.policy.Expired(names)
+		expiredSet := map[string]bool{}
+		for _, name := range expired {
+			expiredSet[name] = true
+		}
+		var kept []string
+		for _, name := range names {
+			if !expiredSet[name] {
+				kept = append(kept, name)
+			}
+		}
+		if !reflect.DeepEqual(kept, c.kept) {
+			t.Errorf("%s: expected to keep %v, kept %v", c.name, c.kept, kept)
+		}
+	}
+}
@@ -0,0 +1,389 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3EmptyPayload    = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	ec2MetadataURL    = "http://169.254.169.254/latest"
+)
+
+// s3Client talks to Amazon S3 or a compatible server such as MinIO.
+//
+// s3://bucket/prefix takes the query parameters region, endpoint (e.g. http://minio:9000,
+// which implies path style addressing), path-style=true, sse (AES256 or aws:kms) and
+// sse-kms-key-id. Credentials are read from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN, or from the EC2 instance profile.
+type s3Client struct {
+	bucket    string
+	region    string
+	endpoint  *url.URL
+	pathStyle bool
+	sse       string
+	kmsKeyID  string
+
+	client      *http.Client
+	credentials func() (*awsCredentials, error)
+}
+
+var _ objectClient = &s3Client{}
+
+type awsCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expiration      time.Time
+}
+
+func newS3Store(u *url.URL) (BackupStore, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("bucket is missing in backup store %q", u)
+	}
+	c := &s3Client{
+		bucket:   u.Host,
+		region:   queryOption(u, "region", "AWS_REGION"),
+		sse:      u.Query().Get("sse"),
+		kmsKeyID: u.Query().Get("sse-kms-key-id"),
+		client:   &http.Client{Timeout: objectTimeout},
+	}
+	if c.region == "" {
+		c.region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if c.region == "" {
+		c.region = "us-east-1"
+	}
+	switch c.sse {
+	case "", "AES256", "aws:kms":
+	default:
+		return nil, fmt.Errorf("unsupported server side encryption %q, use AES256 or aws:kms", c.sse)
+	}
+	if c.kmsKeyID != "" && c.sse != "aws:kms" {
+		return nil, fmt.Errorf("sse-kms-key-id requires sse=aws:kms")
+	}
+
+	endpoint := u.Query().Get("endpoint")
+	if endpoint != "" {
+		// compatible servers rarely have wildcard dns for buckets
+		c.pathStyle = true
+	} else {
+		endpoint = "https://s3." + c.region + ".amazonaws.com"
+	}
+	ep, err := url.Parse(endpoint)
+	if err != nil || ep.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %q in backup store %q", endpoint, u)
+	}
+	c.endpoint = ep
+	if v := u.Query().Get("path-style"); v != "" {
+		c.pathStyle = v == "true"
+	}
+
+	if id := os.Getenv("AWS_ACCESS_KEY_ID"); id != "" {
+		creds := &awsCredentials{
+			AccessKeyID:     id,
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+		c.credentials = func() (*awsCredentials, error) { return creds, nil }
+	} else {
+		c.credentials = newEC2RoleCredentials(c.client)
+	}
+	return newObjectStore(u, c), nil
+}
+
+// objectURL returns the url of key, or of the bucket if key is ""
+func (c *s3Client) objectURL(key string, query url.Values) *url.URL {
+	u := *c.endpoint
+	if c.pathStyle {
+		u.Path = "/" + c.bucket
+		if key != "" {
+			u.Path += "/" + key
+		}
+	} else {
+		u.Host = c.bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	u.RawPath = awsEscape(u.Path, true)
+	u.RawQuery = query.Encode()
+	return &u
+}
+
+func (c *s3Client) do(method string, u *url.URL, body io.ReadSeeker, size int64, header http.Header, expected ...int) (*http.Response, error) {
+	creds, err := c.credentials()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	payload := s3EmptyPayload
+	if body != nil {
+		req.Body = ioutil.NopCloser(body)
+		req.ContentLength = size
+		payload = s3UnsignedPayload
+	}
+	signV4(req, creds, c.region, "s3", payload, time.Now())
+	return doRequest(c.client, req, expected...)
+}
+
+func (c *s3Client) PutObject(key string, body io.ReadSeeker, size int64) error {
+	return c.putObject(key, body, size, http.Header{})
+}
+
+func (c *s3Client) CreateObject(key string, body io.ReadSeeker, size int64) error {
+	header := http.Header{}
+	header.Set("If-None-Match", "*")
+	return c.putObject(key, body, size, header)
+}
+
+func (c *s3Client) putObject(key string, body io.ReadSeeker, size int64, header http.Header) error {
+	if c.sse != "" {
+		header.Set("X-Amz-Server-Side-Encryption", c.sse)
+	}
+	if c.kmsKeyID != "" {
+		header.Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", c.kmsKeyID)
+	}
+	resp, err := c.do(http.MethodPut, c.objectURL(key, nil), body, size, header, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (c *s3Client) GetObject(key string) (io.ReadCloser, error) {
+	resp, err := c.do(http.MethodGet, c.objectURL(key, nil), nil, 0, nil, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+type s3ListBucketResult struct {
+	Contents []struct {
+		Key string
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+func (c *s3Client) ListObjects(prefix string) ([]string, error) {
+	var keys []string
+	query := url.Values{
+		"list-type": {"2"},
+		"prefix":    {prefix},
+	}
+	for {
+		resp, err := c.do(http.MethodGet, c.objectURL("", query), nil, 0, nil, http.StatusOK)
+		if err != nil {
+			return nil, err
+		}
+		result := &s3ListBucketResult{}
+		err = xml.NewDecoder(resp.Body).Decode(result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error parsing bucket listing: %v", err)
+		}
+		for _, obj := range result.Contents {
+			keys = append(keys, obj.Key)
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+func (c *s3Client) DeleteObject(key string) error {
+	resp, err := c.do(http.MethodDelete, c.objectURL(key, nil), nil, 0, nil, http.StatusNoContent, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// signV4 signs req with AWS signature version 4
+func signV4(req *http.Request, creds *awsCredentials, region, service, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		switch {
+		case strings.HasPrefix(name, "x-amz-"), name == "content-md5", name == "content-type", name == "range":
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	var names []string
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders bytes.Buffer
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		awsCanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func awsCanonicalQuery(query url.Values) string {
+	var params []string
+	for name, values := range query {
+		for _, v := range values {
+			params = append(params, awsEscape(name, false)+"="+awsEscape(v, false))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// awsEscape percent encodes everything but unreserved characters, and / if keepSlash is set
+func awsEscape(s string, keepSlash bool) string {
+	var b bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && keepSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// newEC2RoleCredentials returns the credentials of the instance profile, refreshed before they expire
+func newEC2RoleCredentials(client *http.Client) func() (*awsCredentials, error) {
+	var mutex sync.Mutex
+	var creds *awsCredentials
+	return func() (*awsCredentials, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if creds != nil && time.Now().Add(5*time.Minute).Before(creds.Expiration) {
+			return creds, nil
+		}
+		c, err := fetchEC2RoleCredentials(client)
+		if err != nil {
+			return nil, fmt.Errorf("no aws credentials in environment or instance profile: %v", err)
+		}
+		creds = c
+		return creds, nil
+	}
+}
+
+func fetchEC2RoleCredentials(client *http.Client) (*awsCredentials, error) {
+	get := func(path, token string) ([]byte, error) {
+		req, err := http.NewRequest(http.MethodGet, ec2MetadataURL+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-Aws-Ec2-Metadata-Token", token)
+		resp, err := doRequest(client, req, http.StatusOK)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		return ioutil.ReadAll(resp.Body)
+	}
+
+	// IMDSv2 session token
+	req, err := http.NewRequest(http.MethodPut, ec2MetadataURL+"/api/token", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Aws-Ec2-Metadata-Token-Ttl-Seconds", "21600")
+	resp, err := doRequest(client, req, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	token, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := get("/meta-data/iam/security-credentials/", string(token))
+	if err != nil {
+		return nil, err
+	}
+	// an instance has at most one role
+	role = bytes.SplitN(bytes.TrimSpace(role), []byte("\n"), 2)[0]
+	data, err := get("/meta-data/iam/security-credentials/"+string(role), string(token))
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		AccessKeyId     string
+		SecretAccessKey string
+		Token           string
+		Expiration      time.Time
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("error parsing instance profile credentials: %v", err)
+	}
+	return &awsCredentials{
+		AccessKeyID:     result.AccessKeyId,
+		SecretAccessKey: result.SecretAccessKey,
+		SessionToken:    result.Token,
+		Expiration:      result.Expiration,
+	}, nil
+}
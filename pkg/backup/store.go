@@ -0,0 +1,132 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// NameFormat is the layout of backup names, they sort by the time the backup was taken.
+// Backups taken in the same millisecond get the name of the next millisecond.
+const NameFormat = "2006-01-02T15-04-05.000Z"
+
+// legacyNameFormat is the layout of the names of backups taken before they had milliseconds
+const legacyNameFormat = "2006-01-02T15-04-05Z"
+
+const (
+	// caCertFile and caKeyFile keep the cluster CA in the pki directory of the store
+	caCertFile = "pki/ca.crt"
+	caKeyFile  = "pki/ca.key"
+)
+
+var (
+	// ErrNoCA is returned by LoadCA if no CA was saved in the store
+	ErrNoCA = errors.New("no CA in the backup store")
+	// ErrCAExists is returned by CreateCA if the store has a CA
+	ErrCAExists = errors.New("the backup store has a CA")
+	// ErrBackupExists is returned by AddBackup if the store has a backup of the name
+	ErrBackupExists = errors.New("the backup store has a backup of the name")
+)
+
+// Manifest describes a backup
+type Manifest struct {
+	Revision     int64     `json:"revision"`
+	EtcdVersion  string    `json:"etcdVersion"`
+	ClusterToken string    `json:"clusterToken"`
+	Timestamp    time.Time `json:"timestamp"`
+	// Checksum is the hex encoded sha256 of the compressed snapshot
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// BackupStore stores gzip compressed etcd snapshots along with their manifests
+type BackupStore interface {
+	// Spec returns the location of the store
+	Spec() string
+
+	// AddBackup stores the snapshot file under name, it returns ErrBackupExists rather than
+	// replace a backup
+	AddBackup(name string, snapshotFile string, manifest *Manifest) error
+	// ListBackups returns the names of all backups, oldest first
+	ListBackups() ([]string, error)
+	// LoadManifest returns the manifest of backup name
+	LoadManifest(name string) (*Manifest, error)
+	// DownloadBackup copies the snapshot of backup name to destFile
+	DownloadBackup(name string, destFile string) error
+	// RemoveBackup deletes backup name
+	RemoveBackup(name string) error
+
+	// SaveCA stores the pem encoded certificate and key of the cluster CA
+	SaveCA(cert, key []byte) error
+	// CreateCA saves the CA like SaveCA unless the store has one, then it returns
+	// ErrCAExists. The peers of a new cluster race to create the CA, one of them wins.
+	CreateCA(cert, key []byte) error
+	// LoadCA returns the certificate and key saved by SaveCA, ErrNoCA if there are none
+	LoadCA() (cert, key []byte, err error)
+}
+
+// NewStore returns the BackupStore for storage, a file://, s3://, gs://, azure:// or swift://
+// url. A path without a scheme is a file:// store. Object stores take their options from
+// the query of the url and their secrets from the environment.
+func NewStore(storage string) (BackupStore, error) {
+	u, err := url.Parse(storage)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing backup store %q: %v", storage, err)
+	}
+	switch u.Scheme {
+	case "":
+		return NewFileStore(storage), nil
+	case "file":
+		if u.Host != "" {
+			return nil, fmt.Errorf("unexpected host %q in backup store %q", u.Host, storage)
+		}
+		return NewFileStore(u.Path), nil
+	case "s3":
+		return newS3Store(u)
+	case "gs":
+		return newGCSStore(u)
+	case "azure":
+		return newAzureStore(u)
+	case "swift":
+		return newSwiftStore(u)
+	default:
+		return nil, fmt.Errorf("unsupported backup store %q", storage)
+	}
+}
+
+// NewName returns the name of a backup taken at t
+func NewName(t time.Time) string {
+	return t.UTC().Format(NameFormat)
+}
+
+// ParseName returns the time a backup was taken
+func ParseName(name string) (time.Time, error) {
+	t, err := time.Parse(NameFormat, name)
+	if err != nil {
+		if legacy, legacyErr := time.Parse(legacyNameFormat, name); legacyErr == nil {
+			return legacy, nil
+		}
+	}
+	return t, err
+}
+
+// sortNames orders backups oldest first, skipping names that are not backups
+func sortNames(names []string) []string {
+	var backups []string
+	taken := map[string]time.Time{}
+	for _, name := range names {
+		if t, err := ParseName(name); err == nil {
+			backups = append(backups, name)
+			taken[name] = t
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		ti, tj := taken[backups[i]], taken[backups[j]]
+		if ti.Equal(tj) {
+			return backups[i] < backups[j]
+		}
+		return ti.Before(tj)
+	})
+	return backups
+}
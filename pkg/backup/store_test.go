@@ -0,0 +1,27 @@
+package backup
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSortNames(t *testing.T) {
+	taken := time.Date(2018, 3, 4, 5, 6, 7, 0, time.UTC)
+	legacy := taken.Format(legacyNameFormat)
+	if parsed, err := ParseName(legacy); err != nil || !parsed.Equal(taken) {
+		t.Fatalf("expected legacy name %s to be taken at %v, got %v, %v", legacy, taken, parsed, err)
+	}
+
+	names := []string{
+		NewName(taken.Add(time.Second)),
+		NewName(taken.Add(500 * time.Millisecond)),
+		"ca",
+		legacy,
+		NewName(taken.Add(-time.Second)),
+	}
+	expected := []string{names[4], legacy, names[1], names[0]}
+	if sorted := sortNames(names); !reflect.DeepEqual(sorted, expected) {
+		t.Errorf("expected %v, got %v", expected, sorted)
+	}
+}
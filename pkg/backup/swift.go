@@ -0,0 +1,280 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// swiftClient talks to OpenStack Swift.
+//
+// swift://container/prefix is authorized with OS_STORAGE_URL and OS_AUTH_TOKEN, or by
+// OS_AUTH_URL with OS_USERNAME and OS_PASSWORD. An OS_AUTH_URL ending in /v3 uses keystone,
+// scoped by OS_PROJECT_NAME, OS_USER_DOMAIN_NAME, OS_PROJECT_DOMAIN_NAME and OS_REGION_NAME,
+// otherwise the v1 auth of swift itself. Swift has no per request encryption options:
+// objects are encrypted at rest when the operator enables the encryption middleware.
+type swiftClient struct {
+	container string
+	client    *http.Client
+
+	mutex      sync.Mutex
+	storageURL string
+	token      string
+	// authenticate returns a new storage url and token, nil for a fixed token
+	authenticate func() (string, string, error)
+}
+
+var _ objectClient = &swiftClient{}
+
+func newSwiftStore(u *url.URL) (BackupStore, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("container is missing in backup store %q", u)
+	}
+	c := &swiftClient{
+		container: u.Host,
+		client:    &http.Client{Timeout: objectTimeout},
+	}
+
+	authURL := os.Getenv("OS_AUTH_URL")
+	switch {
+	case os.Getenv("OS_STORAGE_URL") != "" && os.Getenv("OS_AUTH_TOKEN") != "":
+		c.storageURL = strings.TrimSuffix(os.Getenv("OS_STORAGE_URL"), "/")
+		c.token = os.Getenv("OS_AUTH_TOKEN")
+	case authURL == "":
+		return nil, fmt.Errorf("swift credentials are not set, use OS_AUTH_URL or OS_STORAGE_URL and OS_AUTH_TOKEN")
+	case strings.HasSuffix(strings.TrimSuffix(authURL, "/"), "/v3"):
+		c.authenticate = func() (string, string, error) {
+			return keystoneV3Auth(c.client, strings.TrimSuffix(authURL, "/"))
+		}
+	default:
+		c.authenticate = func() (string, string, error) {
+			return swiftV1Auth(c.client, authURL)
+		}
+	}
+	return newObjectStore(u, c), nil
+}
+
+func (c *swiftClient) credentials(renew bool) (string, string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.authenticate != nil && (c.token == "" || renew) {
+		storageURL, token, err := c.authenticate()
+		if err != nil {
+			return "", "", fmt.Errorf("error authenticating to swift: %v", err)
+		}
+		c.storageURL, c.token = strings.TrimSuffix(storageURL, "/"), token
+	}
+	return c.storageURL, c.token, nil
+}
+
+// do sends a request to path below the container, authenticating again if the token expired
+func (c *swiftClient) do(method, path string, query url.Values, header http.Header, body io.ReadSeeker, size int64, expected ...int) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		storageURL, token, err := c.credentials(attempt > 0)
+		if err != nil {
+			return nil, err
+		}
+		u := storageURL + "/" + url.PathEscape(c.container)
+		if path != "" {
+			u += "/" + escapeKey(path)
+		}
+		if query != nil {
+			u += "?" + query.Encode()
+		}
+		req, err := http.NewRequest(method, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		if body != nil {
+			if err := rewind(body); err != nil {
+				return nil, err
+			}
+			req.Body = ioutil.NopCloser(body)
+			req.ContentLength = size
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		req.Header.Set("X-Auth-Token", token)
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusUnauthorized && c.authenticate != nil && attempt == 0 {
+			resp.Body.Close()
+			continue
+		}
+		return checkResponse(req, resp, expected...)
+	}
+}
+
+func (c *swiftClient) PutObject(key string, body io.ReadSeeker, size int64) error {
+	return c.putObject(key, body, size, nil)
+}
+
+func (c *swiftClient) CreateObject(key string, body io.ReadSeeker, size int64) error {
+	return c.putObject(key, body, size, http.Header{"If-None-Match": {"*"}})
+}
+
+func (c *swiftClient) putObject(key string, body io.ReadSeeker, size int64, header http.Header) error {
+	resp, err := c.do(http.MethodPut, key, nil, header, body, size, http.StatusCreated)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (c *swiftClient) GetObject(key string) (io.ReadCloser, error) {
+	resp, err := c.do(http.MethodGet, key, nil, nil, nil, 0, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *swiftClient) ListObjects(prefix string) ([]string, error) {
+	var keys []string
+	query := url.Values{
+		"prefix": {prefix},
+		"format": {"json"},
+	}
+	for {
+		resp, err := c.do(http.MethodGet, "", query, nil, nil, 0, http.StatusOK, http.StatusNoContent)
+		if err != nil {
+			return nil, err
+		}
+		var objects []struct {
+			Name string `json:"name"`
+		}
+		if resp.StatusCode == http.StatusOK {
+			err = json.NewDecoder(resp.Body).Decode(&objects)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error parsing container listing: %v", err)
+		}
+		if len(objects) == 0 {
+			return keys, nil
+		}
+		for _, obj := range objects {
+			keys = append(keys, obj.Name)
+		}
+		query.Set("marker", objects[len(objects)-1].Name)
+	}
+}
+
+func (c *swiftClient) DeleteObject(key string) error {
+	resp, err := c.do(http.MethodDelete, key, nil, nil, nil, 0, http.StatusNoContent, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// escapeKey escapes the segments of an object key
+func escapeKey(key string) string {
+	parts := strings.Split(key, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
+
+func swiftV1Auth(client *http.Client, authURL string) (string, string, error) {
+	req, err := http.NewRequest(http.MethodGet, authURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("X-Auth-User", os.Getenv("OS_USERNAME"))
+	req.Header.Set("X-Auth-Key", os.Getenv("OS_PASSWORD"))
+	resp, err := doRequest(client, req, http.StatusOK, http.StatusNoContent)
+	if err != nil {
+		return "", "", err
+	}
+	resp.Body.Close()
+	storageURL, token := resp.Header.Get("X-Storage-Url"), resp.Header.Get("X-Auth-Token")
+	if storageURL == "" || token == "" {
+		return "", "", fmt.Errorf("auth response is missing the storage url or token")
+	}
+	return storageURL, token, nil
+}
+
+func keystoneV3Auth(client *http.Client, authURL string) (string, string, error) {
+	domain := func(env string) map[string]string {
+		name := os.Getenv(env)
+		if name == "" {
+			name = "Default"
+		}
+		return map[string]string{"name": name}
+	}
+	auth := map[string]interface{}{
+		"identity": map[string]interface{}{
+			"methods": []string{"password"},
+			"password": map[string]interface{}{
+				"user": map[string]interface{}{
+					"name":     os.Getenv("OS_USERNAME"),
+					"domain":   domain("OS_USER_DOMAIN_NAME"),
+					"password": os.Getenv("OS_PASSWORD"),
+				},
+			},
+		},
+	}
+	if project := os.Getenv("OS_PROJECT_NAME"); project != "" {
+		auth["scope"] = map[string]interface{}{
+			"project": map[string]interface{}{
+				"name":   project,
+				"domain": domain("OS_PROJECT_DOMAIN_NAME"),
+			},
+		}
+	}
+	data, err := json.Marshal(map[string]interface{}{"auth": auth})
+	if err != nil {
+		return "", "", err
+	}
+	req, err := http.NewRequest(http.MethodPost, authURL+"/auth/tokens", bytes.NewReader(data))
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := doRequest(client, req, http.StatusCreated)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Token struct {
+			Catalog []struct {
+				Type      string `json:"type"`
+				Endpoints []struct {
+					Interface string `json:"interface"`
+					Region    string `json:"region"`
+					URL       string `json:"url"`
+				} `json:"endpoints"`
+			} `json:"catalog"`
+		} `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", "", fmt.Errorf("error parsing keystone token: %v", err)
+	}
+	region := os.Getenv("OS_REGION_NAME")
+	for _, service := range result.Token.Catalog {
+		if service.Type != "object-store" {
+			continue
+		}
+		for _, ep := range service.Endpoints {
+			if ep.Interface == "public" && (region == "" || ep.Region == region) {
+				return ep.URL, resp.Header.Get("X-Subject-Token"), nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("no public object-store endpoint in region %q", region)
+}
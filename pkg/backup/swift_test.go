@@ -0,0 +1,135 @@
+package backup
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSwift serves v1 auth and a container, the token expires after every expireAfter requests
+type fakeSwift struct {
+	container string
+	objects   *fakeObjects
+
+	mutex       sync.Mutex
+	token       string
+	tokens      int
+	requests    int
+	expireAfter int
+}
+
+func (f *fakeSwift) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	if r.URL.Path == "/auth/v1.0" {
+		if r.Header.Get("X-Auth-User") != "test:tester" || r.Header.Get("X-Auth-Key") != "testing" {
+			f.mutex.Unlock()
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		f.tokens++
+		f.token = "token" + strconv.Itoa(f.tokens)
+		f.requests = 0
+		w.Header().Set("X-Storage-Url", "http://"+r.Host+"/v1/AUTH_test")
+		w.Header().Set("X-Auth-Token", f.token)
+		f.mutex.Unlock()
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	f.requests++
+	valid := r.Header.Get("X-Auth-Token") == f.token && (f.expireAfter == 0 || f.requests <= f.expireAfter)
+	f.mutex.Unlock()
+	if !valid {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	base := "/v1/AUTH_test/" + f.container
+	if r.URL.Path == base && r.Method == http.MethodGet {
+		keys, _ := f.objects.list(r.URL.Query().Get("prefix"), r.URL.Query().Get("marker"))
+		if len(keys) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		var listing []map[string]string
+		for _, key := range keys {
+			listing = append(listing, map[string]string{"name": key})
+		}
+		json.NewEncoder(w).Encode(listing)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, base+"/")
+	switch r.Method {
+	case http.MethodPut:
+		if err := f.objects.put(key, r); err == errFakeExists {
+			http.Error(w, err.Error(), http.StatusPreconditionFailed)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet:
+		data, ok := f.objects.get(key)
+		if !ok {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case http.MethodDelete:
+		if !f.objects.delete(key) {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestSwiftStore(t *testing.T) {
+	swift := &fakeSwift{container: "backups", objects: newFakeObjects(), expireAfter: 3}
+	server := httptest.NewServer(swift)
+	defer server.Close()
+	defer setenv(map[string]string{
+		"OS_AUTH_URL": server.URL + "/auth/v1.0",
+		"OS_USERNAME": "test:tester",
+		"OS_PASSWORD": "testing",
+	})()
+
+	store, err := NewStore("swift://backups/etcd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBackupStore(t, store)
+	if swift.tokens < 2 {
+		t.Errorf("expected the client to authenticate again after the token expired")
+	}
+	if _, ok := swift.objects.get("etcd/" + NewName(time.Date(2018, 3, 4, 5, 6, 7, 0, time.UTC)) + "/" + manifestFileName); !ok {
+		t.Errorf("backup not found in container")
+	}
+}
+
+func TestSwiftStoreOptions(t *testing.T) {
+	defer setenv(map[string]string{"OS_AUTH_URL": "", "OS_STORAGE_URL": "", "OS_AUTH_TOKEN": ""})()
+
+	if _, err := NewStore("swift://backups"); err == nil {
+		t.Errorf("expected error without credentials")
+	}
+
+	defer setenv(map[string]string{"OS_STORAGE_URL": "https://swift.example.com/v1/AUTH_test/", "OS_AUTH_TOKEN": "token"})()
+	store, err := NewStore("swift://backups")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := store.(*objectStore).client.(*swiftClient)
+	if storageURL, token, err := c.credentials(false); err != nil || storageURL != "https://swift.example.com/v1/AUTH_test" || token != "token" {
+		t.Errorf("unexpected credentials %s, %s, %v", storageURL, token, err)
+	}
+	if escaped := escapeKey("etcd/a b/c"); escaped != "etcd/a%20b/c" {
+		t.Errorf("unexpected escaped key %s", escaped)
+	}
+}
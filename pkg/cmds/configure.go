@@ -1,90 +1,88 @@
 package cmds
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"os"
+	"time"
 
 	"github.com/appscode/go/log"
-	"github.com/appscode/go/net"
-	"github.com/appscode/kutil/tools/certstore"
-	"github.com/pkg/errors"
-	"github.com/spf13/afero"
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/backup"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"github.com/etcd-manager/etcd-discovery/pkg/etcd"
+	"github.com/etcd-manager/etcd-discovery/pkg/pki"
 	"github.com/spf13/cobra"
-	"k8s.io/client-go/util/cert"
 )
 
-const organization = "system:etcd"
-
 func NewCmdConfigure() *cobra.Command {
 	var (
-		certDir = "etcd.local.config/certificates"
-		addr    = "127.0.0.1"
+		certDir      = "etcd.local.config/certificates"
+		dataDir      = "etcd.local.config/data"
+		addr         = "127.0.0.1"
+		clusterName  string
+		backupStore  string
+		seeds        []string
+		token        string
+		caCertHashes []string
+		timeout      = 5 * time.Minute
 	)
 	cmd := &cobra.Command{
-		Use:               "configure",
-		Short:             "Configure certs for etcd-discovery",
+		Use:   "configure",
+		Short: "Configure certs for etcd-discovery",
+		Long: `Issue the certificates of this peer from the cluster CA before the discovery server starts.
+With the bootstrap token the leader signs them, the discovery servers are trusted by the hash of
+their CA. Until the cluster elects its first leader, and without the token, they are signed with
+the CA of the backup store, which is created if the store has none.`,
 		DisableAutoGenTag: true,
 		Run: func(cmd *cobra.Command, args []string) {
-			externalIPs, internalIPs, err := net.HostIPs()
+			if err := os.MkdirAll(dataDir, 0755); err != nil {
+				log.Fatal(err)
+			}
+			id, err := etcd.PersistentPeerID(dataDir)
 			if err != nil {
 				log.Fatal(err)
 			}
-			fmt.Println("extenal-ips:", externalIPs)
-			fmt.Println("internal-ips:", internalIPs)
-
-			err = preparePeerCerts(certDir, "peer", addr)
+			for _, hash := range caCertHashes {
+				if err := pki.ValidateCACertHash(hash); err != nil {
+					log.Fatal(err)
+				}
+			}
+			store, err := backup.NewStore(backupStore)
 			if err != nil {
 				log.Fatal(err)
 			}
-			err = prepareServerCerts(certDir, "db", addr)
+			sans, err := pki.HostAltNames(net.ParseIP(addr))
 			if err != nil {
 				log.Fatal(err)
 			}
+			fmt.Println("dns-names:", sans.DNSNames)
+			fmt.Println("ips:", sans.IPs)
+
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			files := pki.Files{Dir: certDir}
+			b := pki.NewBootstrapper(files, string(id), clusterName, sans, token, caCertHashes, discovery.NewStaticSeedProvider(seeds), store)
+			if err := b.Run(ctx); err != nil {
+				log.Fatal(err)
+			}
+			if b.ID != string(id) {
+				if err := etcd.SavePeerID(dataDir, api.PeerID(b.ID)); err != nil {
+					log.Fatal(err)
+				}
+			}
 		},
 	}
 
 	cmd.Flags().StringVar(&certDir, "cert-dir", certDir, "Path to directory where pki files are stored.")
+	cmd.Flags().StringVar(&dataDir, "etcd-data-dir", dataDir, "Directory for storing etcd data, it keeps the id of the peer")
 	cmd.Flags().StringVar(&addr, "addr", addr, "Address of server ip")
+	cmd.Flags().StringVar(&clusterName, "etcd-cluster-name", clusterName, "Name of cluster")
+	cmd.Flags().StringVar(&backupStore, "etcd-backup-store", backupStore, "Backup store location, it keeps the cluster CA")
+	cmd.Flags().StringSliceVar(&seeds, "seeds", seeds, "Addresses (host[:port]) of discovery servers of the cluster")
+	cmd.Flags().StringVar(&token, "bootstrap-token", token, "Shared secret of the cluster with which the leader is asked for the certificates")
+	cmd.Flags().StringSliceVar(&caCertHashes, "discovery-ca-cert-hash", caCertHashes, "Hashes of the public key of the cluster CA (sha256:<hex>) the discovery servers are trusted with, defaults to the CA of the backup store")
+	cmd.Flags().DurationVar(&timeout, "bootstrap-timeout", timeout, "Time to wait for the certificates to be issued")
 	return cmd
 }
-
-func prepareServerCerts(certDir, ca, addr string) error {
-	store, err := certstore.NewCertStore(afero.NewOsFs(), certDir, organization)
-	if err != nil {
-		return errors.Wrap(err, "failed to create certificate store.")
-	}
-	err = store.InitCA(ca)
-	if err != nil {
-		return errors.Wrap(err, "failed to init ca.")
-	}
-
-	crt, key, err := store.NewServerCertPair("server", cert.AltNames{})
-	if err != nil {
-		return err
-	}
-	err = store.WriteBytes("server", crt, key)
-	if err != nil {
-		return err
-	}
-
-	crt, key, err = store.NewClientCertPair("discovery-client")
-	if err != nil {
-		return err
-	}
-	return store.WriteBytes("discovery-client", crt, key)
-}
-
-func preparePeerCerts(certDir, ca, addr string) error {
-	store, err := certstore.NewCertStore(afero.NewOsFs(), certDir, organization)
-	if err != nil {
-		return errors.Wrap(err, "failed to create certificate store.")
-	}
-	err = store.InitCA(ca)
-	if err != nil {
-		return errors.Wrap(err, "failed to init ca.")
-	}
-	crt, key, err := store.NewPeerCertPair(addr, cert.AltNames{})
-	if err != nil {
-		return err
-	}
-	return store.WriteBytes(addr, crt, key)
-}
@@ -0,0 +1,372 @@
+package ctl
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	cs "github.com/etcd-manager/etcd-discovery/client/clientset/versioned"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const none = "<none>"
+
+func newCmdStatus(o *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:               "status",
+		Short:             "Show the status of the etcd cluster",
+		Args:              cobra.NoArgs,
+		DisableAutoGenTag: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			return o.run(c, nil, func(client cs.Interface) error {
+				clusters, err := client.DiscoveryV1alpha1().EtcdClusters().List(metav1.ListOptions{})
+				if err != nil {
+					return err
+				}
+				return o.print(clusters, func() *table {
+					t := &table{header: []string{"NAME", "SIZE", "VERSION", "LEADER", "TERM", "MEMBERS", "QUARANTINE", "AVAILABLE", "HEALTHY", "LAST BACKUP"}}
+					for _, cluster := range clusters.Items {
+						lastBackup := none
+						if cluster.Status.LastBackupTime != nil {
+							lastBackup = formatTime(*cluster.Status.LastBackupTime)
+						}
+						t.add(cluster.Name,
+							strconv.Itoa(int(cluster.Spec.ClusterSize)),
+							orNone(cluster.Spec.EtcdVersion),
+							orNone(cluster.Status.Leader),
+							strconv.FormatInt(cluster.Status.Term, 10),
+							strconv.Itoa(len(cluster.Status.Members)),
+							orNone(string(cluster.Spec.Quarantine)),
+							conditionStatus(cluster.Status.Conditions, api.EtcdClusterAvailable),
+							conditionStatus(cluster.Status.Conditions, api.EtcdClusterHealthy),
+							lastBackup)
+					}
+					return t
+				})
+			})
+		},
+	}
+}
+
+func newCmdMembers(o *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:               "members",
+		Short:             "List the members of the etcd cluster",
+		Args:              cobra.NoArgs,
+		DisableAutoGenTag: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			return o.run(c, nil, func(client cs.Interface) error {
+				clusters, err := client.DiscoveryV1alpha1().EtcdClusters().List(metav1.ListOptions{})
+				if err != nil {
+					return err
+				}
+				members := []api.EtcdClusterMember{}
+				for _, cluster := range clusters.Items {
+					members = append(members, cluster.Status.Members...)
+				}
+				return o.print(members, func() *table {
+					t := &table{header: []string{"NAME", "ID", "VERSION", "LEADER", "DB SIZE", "RAFT INDEX", "HEALTHY", "PEER URLS"}}
+					for _, member := range members {
+						t.add(orNone(member.Name),
+							member.ID,
+							orNone(member.Version),
+							strconv.FormatBool(member.IsLeader),
+							strconv.FormatInt(member.DBSize, 10),
+							strconv.FormatInt(member.RaftIndex, 10),
+							conditionStatus(member.Conditions, api.EtcdMemberHealthy),
+							strings.Join(member.PeerURLs, ","))
+					}
+					return t
+				})
+			})
+		},
+	}
+}
+
+func newCmdPing(o *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "ping <peer>",
+		Short: "Ping the discovery server of a peer",
+		Long: `Ping the discovery server of a peer, given as host[:port], to learn its id and the leader it follows.
+The default port is the discovery port.`,
+		Args:              cobra.ExactArgs(1),
+		DisableAutoGenTag: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			cfg, err := o.PeerConfig(args[0])
+			if err != nil {
+				return err
+			}
+			return o.run(c, cfg, func(client cs.Interface) error {
+				resp, err := client.DiscoveryV1alpha1().Pings().Create(&api.Ping{Request: &api.PingRequest{}})
+				if err != nil {
+					return err
+				}
+				if resp.Response == nil {
+					return fmt.Errorf("peer %s did not answer the ping", args[0])
+				}
+				return o.print(resp.Response, func() *table {
+					t := &table{header: []string{"PEER", "HOSTS", "LEADER", "TERM"}}
+					var id string
+					var hosts []string
+					if info := resp.Response.Info; info != nil {
+						id, hosts = info.ID, info.Hosts
+					}
+					t.add(orNone(id), strings.Join(hosts, ","), orNone(resp.Response.Leader), strconv.FormatInt(resp.Response.Term, 10))
+					return t
+				})
+			})
+		},
+	}
+}
+
+func newCmdBackup(o *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "backup",
+		Short:             "Back up the etcd cluster",
+		DisableAutoGenTag: true,
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "now",
+		Short: "Ask the leader to back up the cluster now",
+		Long: `Ask the leader to back up the cluster into the backup store now, the schedule of the backups
+is not changed. Old backups are removed by the retention policy of the leader.`,
+		Args:              cobra.NoArgs,
+		DisableAutoGenTag: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			return o.run(c, nil, func(client cs.Interface) error {
+				resp, err := backups(client, true)
+				if err != nil {
+					return err
+				}
+				var taken []api.BackupInfo
+				for _, b := range resp.Backups {
+					if b.Name == resp.Name {
+						taken = append(taken, b)
+					}
+				}
+				return o.print(resp, func() *table { return backupTable(taken) })
+			})
+		},
+	})
+	return cmd
+}
+
+func newCmdBackups(o *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "backups",
+		Short:             "Inspect the backups of the etcd cluster",
+		DisableAutoGenTag: true,
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:               "list",
+		Short:             "List the backups in the backup store, oldest first",
+		Args:              cobra.NoArgs,
+		DisableAutoGenTag: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			return o.run(c, nil, func(client cs.Interface) error {
+				resp, err := backups(client, false)
+				if err != nil {
+					return err
+				}
+				return o.print(resp, func() *table { return backupTable(resp.Backups) })
+			})
+		},
+	})
+	return cmd
+}
+
+func backups(client cs.Interface, take bool) (*api.BackupResponse, error) {
+	resp, err := client.DiscoveryV1alpha1().Backups().Create(&api.Backup{Request: &api.BackupRequest{Take: take}})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Response == nil {
+		return nil, errors.New("the discovery server did not list the backups")
+	}
+	if resp.Response.Reason != "" {
+		return nil, errors.New(resp.Response.Reason)
+	}
+	return resp.Response, nil
+}
+
+func backupTable(backups []api.BackupInfo) *table {
+	t := &table{header: []string{"NAME", "REVISION", "VERSION", "TIMESTAMP"}}
+	for _, b := range backups {
+		t.add(b.Name, strconv.FormatInt(b.Revision, 10), orNone(b.EtcdVersion), formatTime(b.Timestamp))
+	}
+	return t
+}
+
+func newCmdRestore(o *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore [backup]",
+		Short: "Restore the running etcd cluster from a backup",
+		Long: `Restore the running etcd cluster from a backup, the latest one by default.
+The leader pushes the backup to every peer, which stops etcd, and then restores the same revision
+on every member into a fresh data dir. Old data is kept next to it. Clients of the cluster lose
+every write made after the backup was taken.`,
+		Args:              cobra.MaximumNArgs(1),
+		DisableAutoGenTag: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			req := &api.RestoreRequest{}
+			if len(args) > 0 {
+				req.Backup = args[0]
+			}
+			return o.run(c, nil, func(client cs.Interface) error {
+				resp, err := client.DiscoveryV1alpha1().Restores().Create(&api.Restore{Request: req})
+				if err != nil {
+					return err
+				}
+				if resp.Response == nil {
+					return errors.New("the discovery server did not restore the cluster")
+				}
+				if resp.Response.Reason != "" {
+					return errors.New(resp.Response.Reason)
+				}
+				return o.print(resp.Response, func() *table {
+					t := &table{header: []string{"BACKUP", "MEMBERS"}}
+					t.add(resp.Response.Backup, strings.Join(resp.Response.Members, ","))
+					return t
+				})
+			})
+		},
+	}
+}
+
+func newCmdQuarantine(o *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "quarantine on|off",
+		Short: "Move the clients of etcd to the quarantined port or back",
+		Long: `Move the clients of etcd to the quarantined port, out of reach of normal clients, or back to
+the client port. The leader restarts every member in the new mode.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgs:         []string{"on", "off"},
+		DisableAutoGenTag: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			var mode api.QuarantineMode
+			switch args[0] {
+			case "on":
+				mode = api.QuarantineModeEnabled
+			case "off":
+				mode = api.QuarantineModeDisabled
+			default:
+				return fmt.Errorf("unknown quarantine mode %q, use on or off", args[0])
+			}
+			return o.run(c, nil, func(client cs.Interface) error {
+				resp, err := client.DiscoveryV1alpha1().Quarantines().Create(&api.Quarantine{Request: &api.QuarantineRequest{Mode: mode}})
+				if err != nil {
+					return err
+				}
+				if resp.Response == nil {
+					return errors.New("the discovery server did not change the quarantine mode")
+				}
+				if resp.Response.Reason != "" {
+					return errors.New(resp.Response.Reason)
+				}
+				return o.print(resp.Response, func() *table {
+					t := &table{header: []string{"MEMBER", "MODE", "RUNNING", "QUARANTINED"}}
+					for _, member := range resp.Response.Members {
+						t.add(member.ID, orNone(string(member.Mode)), strconv.FormatBool(member.Running), strconv.FormatBool(member.Quarantined))
+					}
+					return t
+				})
+			})
+		},
+	}
+}
+
+func newCmdUpgrade(o *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "upgrade [version]",
+		Short: "Set the etcd version of the cluster",
+		Long: `Set the etcd version of the cluster, or show it without a version. The version is kept by every
+peer, the leader restarts one member at a time with it while all members are healthy. etcd upgrades
+one minor version at a time, a member that does not come up with the new version is rolled back.`,
+		Args:              cobra.MaximumNArgs(1),
+		DisableAutoGenTag: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			req := &api.UpgradeRequest{}
+			if len(args) > 0 {
+				req.ClusterVersion = args[0]
+			}
+			return o.run(c, nil, func(client cs.Interface) error {
+				resp, err := client.DiscoveryV1alpha1().Upgrades().Create(&api.Upgrade{Request: req})
+				if err != nil {
+					return err
+				}
+				if resp.Response == nil {
+					return errors.New("the discovery server did not set the etcd version")
+				}
+				if resp.Response.Reason != "" {
+					return errors.New(resp.Response.Reason)
+				}
+				return o.print(resp.Response, func() *table {
+					t := &table{header: []string{"VERSION"}}
+					t.add(resp.Response.ClusterVersion)
+					return t
+				})
+			})
+		},
+	}
+}
+
+func newCmdRemoveMember(o *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove-member <name>",
+		Short: "Remove a member from the etcd cluster",
+		Long: `Remove a member from the etcd cluster. The leader refuses to remove itself or to lose the quorum
+of healthy members. The peer of the member moves its data aside and joins the cluster again as a
+new member, unless the cluster is scaled down.`,
+		Args:              cobra.ExactArgs(1),
+		DisableAutoGenTag: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			return o.run(c, nil, func(client cs.Interface) error {
+				resp, err := client.DiscoveryV1alpha1().Removals().Create(&api.Removal{Request: &api.RemovalRequest{Member: args[0]}})
+				if err != nil {
+					return err
+				}
+				if resp.Response == nil {
+					return fmt.Errorf("the discovery server did not remove member %s", args[0])
+				}
+				if resp.Response.Reason != "" {
+					return errors.New(resp.Response.Reason)
+				}
+				return o.print(resp.Response, func() *table {
+					t := &table{header: []string{"MEMBER"}}
+					for _, member := range resp.Response.Members {
+						t.add(member)
+					}
+					return t
+				})
+			})
+		},
+	}
+}
+
+// conditionStatus returns the status of the condition of type typ, Unknown if it is missing
+func conditionStatus(conditions []api.EtcdClusterCondition, typ api.EtcdClusterConditionType) string {
+	for _, condition := range conditions {
+		if condition.Type == typ {
+			return string(condition.Status)
+		}
+	}
+	return string(api.ConditionUnknown)
+}
+
+func formatTime(t metav1.Time) string {
+	if t.IsZero() {
+		return none
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return none
+	}
+	return s
+}
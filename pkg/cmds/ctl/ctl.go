@@ -0,0 +1,181 @@
+package ctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	cs "github.com/etcd-manager/etcd-discovery/client/clientset/versioned"
+	"github.com/etcd-manager/etcd-discovery/pkg/config"
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Output formats of the ctl commands
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+// Options are the options shared by the ctl commands: the discovery server to talk to and the
+// client certificate of the operator, given kubeconfig style, and the output format.
+type Options struct {
+	Kubeconfig string
+	Overrides  clientcmd.ConfigOverrides
+	Output     string
+
+	Out io.Writer
+	// NewClient returns the clientset for config, tests replace it with a fake clientset
+	NewClient func(config *rest.Config) (cs.Interface, error)
+}
+
+func NewOptions(out io.Writer) *Options {
+	return &Options{
+		Overrides: clientcmd.ConfigOverrides{
+			// the discovery server of the local peer
+			ClusterDefaults: clientcmdapi.Cluster{
+				Server: "https://" + net.JoinHostPort("127.0.0.1", strconv.Itoa(config.DiscoveryPort)),
+			},
+		},
+		Output: OutputTable,
+		Out:    out,
+		NewClient: func(cfg *rest.Config) (cs.Interface, error) {
+			return cs.NewForConfig(cfg)
+		},
+	}
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	flagNames := clientcmd.RecommendedConfigOverrideFlags("")
+	fs.StringVar(&o.Kubeconfig, "kubeconfig", o.Kubeconfig, "Path to a kubeconfig file with the discovery server and the client certificate of the operator")
+	flagNames.CurrentContext.BindStringFlag(fs, &o.Overrides.CurrentContext)
+	flagNames.Timeout.BindStringFlag(fs, &o.Overrides.Timeout)
+	fs.StringVarP(&o.Overrides.ClusterInfo.Server, clientcmd.FlagAPIServer, "s", "", "Address (https://host:port) of the discovery server, the local one by default")
+	flagNames.ClusterOverrideFlags.CertificateAuthority.BindStringFlag(fs, &o.Overrides.ClusterInfo.CertificateAuthority)
+	flagNames.AuthOverrideFlags.ClientCertificate.BindStringFlag(fs, &o.Overrides.AuthInfo.ClientCertificate)
+	flagNames.AuthOverrideFlags.ClientKey.BindStringFlag(fs, &o.Overrides.AuthInfo.ClientKey)
+	fs.StringVarP(&o.Output, "output", "o", o.Output, "Output format, one of table, json or yaml")
+}
+
+func (o *Options) Validate() error {
+	switch o.Output {
+	case OutputTable, OutputJSON, OutputYAML:
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q, use table, json or yaml", o.Output)
+}
+
+// ClientConfig returns the client config of the discovery server from the kubeconfig file and the flags
+func (o *Options) ClientConfig() (*rest.Config, error) {
+	loadingRules := &clientcmd.ClientConfigLoadingRules{ExplicitPath: o.Kubeconfig}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &o.Overrides).ClientConfig()
+}
+
+// PeerConfig returns the client config of the discovery server listening on address (host[:port]),
+// with the credentials of the operator
+func (o *Options) PeerConfig(address string) (*rest.Config, error) {
+	cfg, err := o.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, strconv.Itoa(config.DiscoveryPort))
+	}
+	cfg = rest.CopyConfig(cfg)
+	cfg.Host = "https://" + address
+	return cfg, nil
+}
+
+// run validates the options and runs f with a client of cfg, or of the discovery server if cfg is nil.
+// Errors past this point are not usage errors, so the usage of c is not printed for them.
+func (o *Options) run(c *cobra.Command, cfg *rest.Config, f func(client cs.Interface) error) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	c.SilenceUsage = true
+	if cfg == nil {
+		var err error
+		if cfg, err = o.ClientConfig(); err != nil {
+			return err
+		}
+	}
+	client, err := o.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+	return f(client)
+}
+
+// table is the table output of a command, its rows are printed under header
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+func (t *table) add(columns ...string) {
+	t.rows = append(t.rows, columns)
+}
+
+// print prints obj in the json or yaml output format, or the table built by toTable
+func (o *Options) print(obj interface{}, toTable func() *table) error {
+	switch o.Output {
+	case OutputJSON:
+		data, err := json.MarshalIndent(obj, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(o.Out, string(data))
+		return err
+	case OutputYAML:
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		_, err = o.Out.Write(data)
+		return err
+	}
+
+	t := toTable()
+	w := tabwriter.NewWriter(o.Out, 10, 4, 3, ' ', 0)
+	fmt.Fprintln(w, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// NewCmdCtl returns the commands with which operators inspect and administer the cluster
+// through its discovery servers
+func NewCmdCtl(out io.Writer) *cobra.Command {
+	o := NewOptions(out)
+	cmd := &cobra.Command{
+		Use:   "ctl",
+		Short: "Inspect and administer the etcd cluster through its discovery servers",
+		Long: `Inspect and administer the etcd cluster through its discovery servers.
+The commands authenticate with a client certificate of the system:etcd:operators organization issued
+by the cluster CA. The server and the certificate are read from a kubeconfig file or given as flags.
+Requests that change the cluster are served by the leader, any other peer names it in its answer.`,
+		DisableAutoGenTag: true,
+	}
+	o.AddFlags(cmd.PersistentFlags())
+
+	cmd.AddCommand(newCmdStatus(o))
+	cmd.AddCommand(newCmdMembers(o))
+	cmd.AddCommand(newCmdPing(o))
+	cmd.AddCommand(newCmdBackup(o))
+	cmd.AddCommand(newCmdBackups(o))
+	cmd.AddCommand(newCmdRestore(o))
+	cmd.AddCommand(newCmdQuarantine(o))
+	cmd.AddCommand(newCmdUpgrade(o))
+	cmd.AddCommand(newCmdRemoveMember(o))
+	return cmd
+}
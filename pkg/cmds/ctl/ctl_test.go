@@ -0,0 +1,149 @@
+package ctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	cs "github.com/etcd-manager/etcd-discovery/client/clientset/versioned"
+	"github.com/etcd-manager/etcd-discovery/client/clientset/versioned/fake"
+	"github.com/ghodss/yaml"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func newTestOptions(client *fake.Clientset, output string) (*Options, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	o := NewOptions(out)
+	o.Output = output
+	o.NewClient = func(*rest.Config) (cs.Interface, error) {
+		return client, nil
+	}
+	return o, out
+}
+
+func TestStatus(t *testing.T) {
+	backupTime := metav1.NewTime(time.Date(2018, 3, 1, 12, 0, 0, 0, time.UTC))
+	client := fake.NewSimpleClientset(&api.EtcdCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec:       api.EtcdClusterSpec{ClusterSize: 3, EtcdVersion: "3.2.13"},
+		Status: api.EtcdClusterStatus{
+			Leader:         "a",
+			Term:           2,
+			Members:        []api.EtcdClusterMember{{ID: "1", Name: "a", IsLeader: true, PeerURLs: []string{"https://10.0.0.1:2380"}}, {ID: "2"}},
+			LastBackupTime: &backupTime,
+			Conditions:     []api.EtcdClusterCondition{{Type: api.EtcdClusterAvailable, Status: api.ConditionFalse}},
+		},
+	})
+
+	o, out := newTestOptions(client, OutputTable)
+	cmd := newCmdStatus(o)
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a header and a row, got %q", out.String())
+	}
+	if fields := strings.Fields(lines[0]); fields[0] != "NAME" || fields[len(fields)-1] != "BACKUP" {
+		t.Errorf("unexpected header %q", lines[0])
+	}
+	if fields, expected := strings.Fields(lines[1]), []string{"test", "3", "3.2.13", "a", "2", "2", "<none>", "False", "Unknown", "2018-03-01T12:00:00Z"}; strings.Join(fields, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected row %v, got %v", expected, fields)
+	}
+
+	o, out = newTestOptions(client, OutputJSON)
+	cmd = newCmdMembers(o)
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	var members []api.EtcdClusterMember
+	if err := json.Unmarshal(out.Bytes(), &members); err != nil {
+		t.Fatalf("invalid json %q: %v", out.String(), err)
+	}
+	if len(members) != 2 || members[0].Name != "a" || !members[0].IsLeader {
+		t.Errorf("unexpected members %+v", members)
+	}
+}
+
+func TestBackups(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	var requests []*api.BackupRequest
+	client.PrependReactor("create", api.ResourcePluralBackup, func(action clienttesting.Action) (bool, runtime.Object, error) {
+		req := action.(clienttesting.CreateAction).GetObject().(*api.Backup).Request
+		requests = append(requests, req)
+		resp := &api.BackupResponse{Backups: []api.BackupInfo{{Name: "old", Revision: 5}, {Name: "new", Revision: 9}}}
+		if req.Take {
+			resp.Name = "new"
+		}
+		return true, &api.Backup{Request: req, Response: resp}, nil
+	})
+
+	o, out := newTestOptions(client, OutputYAML)
+	cmd := newCmdBackups(o).Commands()[0]
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	var resp api.BackupResponse
+	if err := yaml.Unmarshal(out.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid yaml %q: %v", out.String(), err)
+	}
+	if len(resp.Backups) != 2 || resp.Backups[1].Revision != 9 {
+		t.Errorf("unexpected backups %+v", resp.Backups)
+	}
+
+	o, out = newTestOptions(client, OutputTable)
+	cmd = newCmdBackup(o).Commands()[0]
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 2 || requests[0].Take || !requests[1].Take {
+		t.Errorf("expected a list and a backup, got %+v", requests)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], "new ") {
+		t.Errorf("expected the new backup only, got %q", out.String())
+	}
+}
+
+func TestRefusals(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		switch obj := action.(clienttesting.CreateAction).GetObject().(type) {
+		case *api.Quarantine:
+			obj.Response = &api.QuarantineResponse{Reason: `peer b is not the leader, ask "a"`}
+		case *api.Upgrade:
+			obj.Response = &api.UpgradeResponse{Reason: "cannot upgrade etcd 3.1.12 to 3.3.1, etcd upgrades one minor version at a time"}
+		case *api.Removal:
+			obj.Response = &api.RemovalResponse{Reason: "member a is the leader"}
+		}
+		return true, action.(clienttesting.CreateAction).GetObject(), nil
+	})
+
+	o, _ := newTestOptions(client, OutputTable)
+	quarantine := newCmdQuarantine(o)
+	if err := quarantine.RunE(quarantine, []string{"on"}); err == nil || !strings.Contains(err.Error(), "not the leader") {
+		t.Errorf("expected the refusal of the peer, got %v", err)
+	}
+	if err := quarantine.RunE(quarantine, []string{"maybe"}); err == nil || !strings.Contains(err.Error(), "unknown quarantine mode") {
+		t.Errorf("expected an unknown mode, got %v", err)
+	}
+	upgrade := newCmdUpgrade(o)
+	if err := upgrade.RunE(upgrade, []string{"3.3.1"}); err == nil || !strings.Contains(err.Error(), "one minor version") {
+		t.Errorf("expected the refusal of the version, got %v", err)
+	}
+	removal := newCmdRemoveMember(o)
+	if err := removal.RunE(removal, []string{"a"}); err == nil || err.Error() != "member a is the leader" {
+		t.Errorf("expected the refusal of the leader, got %v", err)
+	}
+
+	o.Output = "xml"
+	if err := removal.RunE(removal, []string{"a"}); err == nil || !strings.Contains(err.Error(), "unknown output format") {
+		t.Errorf("expected an unknown output format, got %v", err)
+	}
+}
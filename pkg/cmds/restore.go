@@ -0,0 +1,43 @@
+package cmds
+
+import (
+	"io"
+
+	"github.com/etcd-manager/etcd-discovery/pkg/backup"
+	"github.com/etcd-manager/etcd-discovery/pkg/cmds/server"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdRestore(out, errOut io.Writer, stopCh <-chan struct{}) *cobra.Command {
+	o := server.NewDiscoveryServerOptions(out, errOut)
+
+	cmd := &cobra.Command{
+		Use:   "restore [backup]",
+		Short: "Launch a etcd discovery server that restores the cluster from a backup",
+		Long: `Launch a etcd discovery server that restores the cluster from a backup, the latest one by default.
+The leader picks the backup and every member restores the same revision into a fresh data dir,
+old data is kept next to it. Run it on every member of the cluster.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			etcd := o.RecommendedOptions.Etcd
+			if len(args) > 0 {
+				etcd.RestoreBackup = args[0]
+			}
+			if etcd.RestoreBackup == "" {
+				etcd.RestoreBackup = backup.Latest
+			}
+			if err := o.Complete(); err != nil {
+				return err
+			}
+			if err := o.Validate(args); err != nil {
+				return err
+			}
+			return o.Run(stopCh)
+		},
+	}
+
+	flags := cmd.Flags()
+	o.RecommendedOptions.AddFlags(flags)
+
+	return cmd
+}
@@ -8,6 +8,7 @@ import (
 
 	v "github.com/appscode/go/version"
 	"github.com/appscode/kutil/tools/analytics"
+	"github.com/etcd-manager/etcd-discovery/pkg/cmds/ctl"
 	"github.com/jpillora/go-ogle-analytics"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -46,8 +47,10 @@ func NewRootCmd() *cobra.Command {
 
 	stopCh := genericapiserver.SetupSignalHandler()
 	cmd.AddCommand(NewCmdRun(os.Stdout, os.Stderr, stopCh))
+	cmd.AddCommand(NewCmdRestore(os.Stdout, os.Stderr, stopCh))
 
 	cmd.AddCommand(NewCmdConfigure())
+	cmd.AddCommand(ctl.NewCmdCtl(os.Stdout))
 	cmd.AddCommand(v.NewCmdVersion())
 	return cmd
 }
@@ -1,12 +1,22 @@
 package cmds
 
 import (
+	"fmt"
 	"io"
+	"net"
+	"time"
 
 	"github.com/etcd-manager/etcd-discovery/pkg/cmds/server"
+	"github.com/etcd-manager/etcd-discovery/pkg/manager"
+	"github.com/golang/glog"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/wait"
 )
 
+// relistenTimeout is how long the run command waits for the address of the discovery server
+// to be released when it restarts
+const relistenTimeout = 30 * time.Second
+
 func NewCmdRun(out, errOut io.Writer, stopCh <-chan struct{}) *cobra.Command {
 	o := server.NewDiscoveryServerOptions(out, errOut)
 
@@ -21,10 +31,19 @@ func NewCmdRun(out, errOut io.Writer, stopCh <-chan struct{}) *cobra.Command {
 			if err := o.Validate(args); err != nil {
 				return err
 			}
-			if err := o.Run(stopCh); err != nil {
-				return err
+			// the server stops once the certificates of the peer were renewed, it is built
+			// again to load them
+			for {
+				err := o.Run(stopCh)
+				if err != manager.ErrRestart {
+					return err
+				}
+				glog.Infof("restarting the discovery server: %v", err)
+				serving := o.RecommendedOptions.SecureServing
+				if serving.Listener, err = relisten(serving.Listener.Addr()); err != nil {
+					return err
+				}
 			}
-			return nil
 		},
 	}
 
@@ -33,3 +52,18 @@ func NewCmdRun(out, errOut io.Writer, stopCh <-chan struct{}) *cobra.Command {
 
 	return cmd
 }
+
+// relisten listens on the address of a stopped server again. The server closes its listener
+// in the background once it stopped.
+func relisten(addr net.Addr) (net.Listener, error) {
+	var listener net.Listener
+	err := wait.PollImmediate(100*time.Millisecond, relistenTimeout, func() (bool, error) {
+		var err error
+		listener, err = net.Listen(addr.Network(), addr.String())
+		return err == nil, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listening on %s again: %v", addr, err)
+	}
+	return listener, nil
+}
@@ -0,0 +1,158 @@
+package cmds
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	cs "github.com/etcd-manager/etcd-discovery/client/clientset/versioned/typed/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/backup"
+	"github.com/etcd-manager/etcd-discovery/pkg/pki"
+	"k8s.io/client-go/rest"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+// ping asks the server at address until it answers, with the peer certificates of files
+func ping(t *testing.T, address string, files pki.Files) *api.PingResponse {
+	client, err := cs.NewForConfig(&rest.Config{
+		Host: "https://" + address,
+		TLSClientConfig: rest.TLSClientConfig{
+			CAFile:   files.CACert(),
+			CertFile: files.Cert(pki.ProfilePeer),
+			KeyFile:  files.Key(pki.ProfilePeer),
+		},
+		Timeout: time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		resp, err := client.Pings().Create(&api.Ping{Request: &api.PingRequest{}})
+		if err == nil && resp.Response != nil && resp.Response.Leader != "" {
+			return resp.Response
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("server at %s did not answer ping", address)
+	return nil
+}
+
+func TestRunRestartsAfterRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-discovery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+	_, port, _ := net.SplitHostPort(address)
+
+	ca, err := pki.NewCA("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := filepath.Join(dir, "backups")
+	if err := backup.NewFileStore(store).SaveCA(ca.CertPEM(), ca.KeyPEM()); err != nil {
+		t.Fatal(err)
+	}
+	files := pki.Files{Dir: filepath.Join(dir, "certificates")}
+
+	stopCh := make(chan struct{})
+	cmd := NewCmdRun(os.Stdout, os.Stderr, stopCh)
+	cmd.SetArgs([]string{
+		"--bind-address=127.0.0.1",
+		"--secure-port=" + port,
+		"--cert-dir=" + files.Dir,
+		"--etcd-cluster-name=test",
+		"--etcd-cluster-size=1",
+		"--etcd-backup-store=" + store,
+		"--etcd-data-dir=" + filepath.Join(dir, "data"),
+		// the certificates are renewed when the leader asks
+		"--cert-renew-before=" + strconv.Itoa(10*365*24) + "h",
+		"--ca-renew-before=0",
+	})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- cmd.Execute()
+	}()
+
+	for i := 0; i < 50 && !files.Issued(); i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	resp := ping(t, address, files)
+	issued, err := certutil.CertsFromFile(files.Cert(pki.ProfilePeer))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := cs.NewForConfig(&rest.Config{
+		Host: "https://" + address,
+		TLSClientConfig: rest.TLSClientConfig{
+			CAFile:   files.CACert(),
+			CertFile: files.Cert(pki.ProfilePeer),
+			KeyFile:  files.Key(pki.ProfilePeer),
+		},
+		Timeout: time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotation, err := client.Rotations().Create(&api.Rotation{Request: &api.RotationRequest{Leader: resp.Leader, Term: resp.Term}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotation.Response == nil || !rotation.Response.Rotated {
+		t.Fatalf("expected the certificates to be renewed, got %+v", rotation.Response)
+	}
+
+	// the command keeps serving with the renewed certificates
+	time.Sleep(time.Second)
+	select {
+	case err := <-errCh:
+		t.Fatalf("the command ended after the rotation: %v", err)
+	default:
+	}
+	ping(t, address, files)
+	renewed, err := certutil.CertsFromFile(files.Cert(pki.ProfilePeer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renewed[0].Equal(issued[0]) {
+		t.Error("expected renewed certificates")
+	}
+	if served := servedCertificate(t, address); !served.Equal(renewed[0]) {
+		t.Error("expected the server to serve its renewed certificate")
+	}
+
+	close(stopCh)
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("the command exited with error: %v", err)
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("the command did not end")
+	}
+}
+
+// servedCertificate returns the certificate the server at address serves
+func servedCertificate(t *testing.T, address string) *x509.Certificate {
+	conn, err := tls.Dial("tcp", address, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0]
+}
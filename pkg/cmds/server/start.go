@@ -1,9 +1,7 @@
 package server
 
 import (
-	"fmt"
 	"io"
-	"net"
 
 	"github.com/etcd-manager/etcd-discovery/pkg/manager"
 	"github.com/etcd-manager/etcd-discovery/pkg/server"
@@ -44,19 +42,6 @@ func (o *DiscoveryServerOptions) Complete() error {
 }
 
 func (o DiscoveryServerOptions) Config() (*server.Config, error) {
-	// TODO have a "real" external address
-	alternateIPs := []net.IP{
-		net.ParseIP("127.0.0.1"),
-		net.ParseIP("127.0.0.2"),
-		net.ParseIP("127.0.0.3"),
-	}
-	if ip, err := o.RecommendedOptions.SecureServing.DefaultExternalAddress(); err == nil {
-		alternateIPs = append(alternateIPs, ip)
-	}
-	if err := o.RecommendedOptions.SecureServing.MaybeDefaultWithSelfSignedCerts("localhost", nil, alternateIPs); err != nil {
-		return nil, fmt.Errorf("error creating self-signed certificates: %v", err)
-	}
-
 	config := &server.Config{
 		GenericConfig: genericapiserver.NewRecommendedConfig(server.Codecs),
 		EtcdConfig:    manager.NewEtcdConfig(),
@@ -78,5 +63,5 @@ func (o DiscoveryServerOptions) Run(stopCh <-chan struct{}) error {
 		return err
 	}
 
-	return srv.GenericAPIServer.PrepareRun().Run(stopCh)
+	return srv.Run(stopCh)
 }
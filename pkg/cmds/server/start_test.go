@@ -0,0 +1,98 @@
+package server
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	cs "github.com/etcd-manager/etcd-discovery/client/clientset/versioned/typed/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/backup"
+	"github.com/etcd-manager/etcd-discovery/pkg/pki"
+	"k8s.io/client-go/rest"
+)
+
+func TestRunServesUntilStopped(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-discovery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o := NewDiscoveryServerOptions(os.Stdout, os.Stderr)
+	o.RecommendedOptions.SecureServing.BindAddress = net.ParseIP("127.0.0.1")
+	o.RecommendedOptions.SecureServing.Listener = listener
+	o.RecommendedOptions.SecureServing.CertDirectory = filepath.Join(dir, "certificates")
+	o.RecommendedOptions.Etcd.ClusterName = "test"
+	o.RecommendedOptions.Etcd.ClusterSize = 1
+	o.RecommendedOptions.Etcd.BackupStorePath = filepath.Join(dir, "backups")
+	o.RecommendedOptions.Etcd.DataDir = filepath.Join(dir, "data")
+	if err := o.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// the CA of the backup store signs the certificates of the peer
+	ca, err := pki.NewCA("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := backup.NewFileStore(o.RecommendedOptions.Etcd.BackupStorePath).SaveCA(ca.CertPEM(), ca.KeyPEM()); err != nil {
+		t.Fatal(err)
+	}
+
+	stopCh := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- o.Run(stopCh)
+	}()
+
+	files := pki.Files{Dir: o.RecommendedOptions.SecureServing.CertDirectory}
+	for i := 0; i < 50 && !files.Issued(); i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	client, err := cs.NewForConfig(&rest.Config{
+		Host: "https://" + listener.Addr().String(),
+		TLSClientConfig: rest.TLSClientConfig{
+			CAFile:   files.CACert(),
+			CertFile: files.Cert(pki.ProfilePeer),
+			KeyFile:  files.Key(pki.ProfilePeer),
+		},
+		Timeout: time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var resp *api.Ping
+	for i := 0; i < 50; i++ {
+		resp, err = client.Pings().Create(&api.Ping{Request: &api.PingRequest{}})
+		if err == nil {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("server did not answer ping: %v", err)
+	}
+	if resp.Response == nil || resp.Response.Info == nil || resp.Response.Info.ID == "" {
+		t.Fatalf("unexpected ping response: %+v", resp)
+	}
+
+	close(stopCh)
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("server exited with error: %v", err)
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
@@ -14,6 +14,7 @@ type EtcdCluster struct {
 	ClusterName     string
 	ClusterToken    string
 	ClusterSize     int
+	EtcdVersion     EtcdVersion
 	BackupStorePath string
 	DataDir         string
 
@@ -43,6 +44,8 @@ type EtcdFlags struct {
 	Version         EtcdVersion `json:"-"`
 	Quarantined     bool        `json:"-"`
 	CertificatesDir string      `json:"-"`
+	// ClientTLS authenticates the clients of the manager to the local etcd member
+	ClientTLS etcdclient.TLSFiles `json:"-"`
 
 	Name                     string        `json:"name"`
 	InitialAdvertisePeerURLs *types.URLSet `json:"initial-advertise-peer-urls"`
@@ -105,10 +108,25 @@ func (f *EtcdFlags) ToArgs() ([]string, error) {
 	return meta.BuildArgumentListFromMap(m, nil), nil
 }
 
+// ClientURLs returns the urls etcd advertises to clients, on the quarantined port while quarantined
+func (f *EtcdFlags) ClientURLs() []string {
+	urls := *f.AdvertiseClientURLs
+	urls.Port = ClientPort
+	if f.Quarantined {
+		urls.Port = QuarantinedClientPort
+	}
+	if urls.Hosts.Len() == 0 {
+		return nil
+	}
+	return strings.Split(urls.String(), ",")
+}
+
+// NewClient returns a client of the local etcd member on its advertised client urls,
+// authenticated with ClientTLS
 func (p *EtcdFlags) NewClient() (etcdclient.EtcdClient, error) {
-	clientUrls := []string{""}
-	if p.Quarantined {
-		clientUrls = nil
+	tlsConfig, err := p.ClientTLS.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error loading etcd client certificates: %v", err)
 	}
-	return etcdclient.NewClient(string(p.Version), clientUrls)
+	return etcdclient.NewClient(string(p.Version), p.ClientURLs(), tlsConfig)
 }
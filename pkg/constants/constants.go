@@ -10,6 +10,14 @@ const (
 	// Etcd defines variable used internally when referring to etcd component
 	Etcd = "etcd"
 
+	// PeerOrganization defines the organization of certificates issued to cluster peers
+	PeerOrganization = "system:etcd"
+	// BootstrapUser and BootstrapGroup identify requests authenticated with the bootstrap token
+	BootstrapUser  = "system:etcd:bootstrap"
+	BootstrapGroup = "system:etcd:bootstrappers"
+	// OperatorGroup defines the organization of client certificates that may administer the cluster
+	OperatorGroup = "system:etcd:operators"
+
 	// EtcdCACertAndKeyBaseName defines etcd's CA certificate and key base name
 	EtcdCACertAndKeyBaseName = "etcd/ca"
 	// EtcdCACertName defines etcd's CA certificate name
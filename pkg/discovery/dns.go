@@ -0,0 +1,61 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+// SRVService is the service name looked up as _etcd-discovery._tcp.<name>
+const SRVService = "etcd-discovery"
+
+// Resolver is the subset of net.Resolver used to find seeds
+type Resolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+type dnsSeedProvider struct {
+	name     string
+	resolver Resolver
+}
+
+var _ SeedProvider = &dnsSeedProvider{}
+
+// NewDNSSeedProvider returns a SeedProvider that looks up the SRV records of name,
+// falling back to its A/AAAA records. A nil resolver uses net.DefaultResolver.
+func NewDNSSeedProvider(name string, resolver Resolver) SeedProvider {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &dnsSeedProvider{name: name, resolver: resolver}
+}
+
+func (p *dnsSeedProvider) GetSeeds(ctx context.Context) ([]string, error) {
+	_, records, err := p.resolver.LookupSRV(ctx, SRVService, "tcp", p.name)
+	if err == nil && len(records) > 0 {
+		var seeds []string
+		for _, srv := range records {
+			host := strings.TrimSuffix(srv.Target, ".")
+			seeds = append(seeds, net.JoinHostPort(host, strconv.Itoa(int(srv.Port))))
+		}
+		return sortedSeeds(seeds), nil
+	}
+	if err != nil {
+		glog.V(4).Infof("no SRV records for %s: %v", p.name, err)
+	}
+
+	addrs, err := p.resolver.LookupHost(ctx, p.name)
+	if err != nil {
+		return nil, fmt.Errorf("error looking up seeds for %s: %v", p.name, err)
+	}
+	var seeds []string
+	for _, addr := range addrs {
+		seeds = append(seeds, normalizeSeed(addr))
+	}
+	return sortedSeeds(seeds), nil
+}
@@ -0,0 +1,71 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+)
+
+type fakeResolver struct {
+	srv   map[string][]*net.SRV
+	hosts map[string][]string
+}
+
+func (r *fakeResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	cname := fmt.Sprintf("_%s._%s.%s", service, proto, name)
+	records, ok := r.srv[cname]
+	if !ok {
+		return "", nil, &net.DNSError{Err: "no such host", Name: cname}
+	}
+	return cname, records, nil
+}
+
+func (r *fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	addrs, ok := r.hosts[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host}
+	}
+	return addrs, nil
+}
+
+func TestDNSSeedProvider(t *testing.T) {
+	resolver := &fakeResolver{
+		srv: map[string][]*net.SRV{
+			"_etcd-discovery._tcp.srv.example.com": {
+				{Target: "b.example.com.", Port: 2381},
+				{Target: "a.example.com.", Port: 3000},
+			},
+		},
+		hosts: map[string][]string{
+			"a.example.com": {"10.0.0.2", "10.0.0.1"},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		expected []string
+		err      bool
+	}{
+		{name: "srv.example.com", expected: []string{"a.example.com:3000", "b.example.com:2381"}},
+		{name: "a.example.com", expected: []string{"10.0.0.1:2381", "10.0.0.2:2381"}},
+		{name: "missing.example.com", err: true},
+	}
+	for _, c := range cases {
+		seeds, err := NewDNSSeedProvider(c.name, resolver).GetSeeds(context.Background())
+		if c.err {
+			if err == nil {
+				t.Errorf("%s: expected error, got %v", c.name, seeds)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(seeds, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, seeds)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package discovery
+
+import (
+	"sync"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// Election is a lease based leader election among the discovery servers of a cluster.
+// It rides on the pings sent by Discoverer, so it does not need a working etcd.
+//
+// Every round the candidate, the reachable peer with the lowest id while no peer reports
+// a leader, asks the others to grant it a lease for a term. A peer grants one leader per
+// term, and rejects other claimants while the lease it granted has not expired. A candidate
+// granted a lease by a quorum is leader until its lease, counted from the start of the
+// round, expires. Leases granted by peers outlive the lease of the leader, so a new leader
+// is only elected once the old one has stepped down.
+type Election struct {
+	self          api.PeerID
+	leaseDuration time.Duration
+	clock         clock.Clock
+
+	mutex  sync.Mutex
+	quorum int
+	// term is the highest term seen
+	term int64
+	// leader holds the lease we granted, until leaseExpiry
+	leader      api.PeerID
+	leaseExpiry time.Time
+	// leaderTerm and leaderUntil are set while we are the leader
+	leaderTerm  int64
+	leaderUntil time.Time
+	// claim is set when we should ask for leadership in the next round
+	claim bool
+}
+
+func NewElection(self api.PeerID, clusterSize int, leaseDuration time.Duration, clock clock.Clock) *Election {
+	return &Election{
+		self:          self,
+		quorum:        clusterSize/2 + 1,
+		leaseDuration: leaseDuration,
+		clock:         clock,
+		// a single member cluster does not have to wait for a round of pings
+		claim: clusterSize <= 1,
+	}
+}
+
+// Leader returns the current leader and its term, or "" if there is none
+func (e *Election) Leader() (api.PeerID, int64) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.currentLeader(e.clock.Now())
+}
+
+// IsLeader returns true if we hold the leader lease
+func (e *Election) IsLeader() bool {
+	leader, _ := e.Leader()
+	return leader == e.self
+}
+
+func (e *Election) currentLeader(now time.Time) (api.PeerID, int64) {
+	if now.Before(e.leaderUntil) {
+		return e.self, e.leaderTerm
+	}
+	if e.leader != "" && e.leader != e.self && now.Before(e.leaseExpiry) {
+		return e.leader, e.term
+	}
+	return "", e.term
+}
+
+// Prepare fills in the election part of the ping request sent to every peer this round.
+// It returns the start of the round.
+func (e *Election) Prepare(req *api.PingRequest) time.Time {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	now := e.clock.Now()
+	if e.claim {
+		term := e.term + 1
+		if now.Before(e.leaderUntil) && e.leaderTerm == e.term {
+			term = e.term
+		}
+		// vote for ourselves, this fails while we honor the lease of another leader
+		if e.grant(e.self, term, now) {
+			req.Leader = string(e.self)
+			req.Term = term
+			return now
+		}
+	}
+	leader, _ := e.currentLeader(now)
+	req.Leader = string(leader)
+	req.Term = e.term
+	return now
+}
+
+// HandlePing answers the election part of a ping received from another peer
+func (e *Election) HandlePing(req *api.PingRequest, resp *api.PingResponse) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	now := e.clock.Now()
+	if req.Info != nil && req.Leader != "" && req.Leader == req.Info.ID {
+		resp.LeaseGranted = e.grant(api.PeerID(req.Leader), req.Term, now)
+	}
+	leader, term := e.currentLeader(now)
+	resp.Leader = string(leader)
+	resp.Term = term
+}
+
+// grant gives claimant the lease for term, if we have not promised it to somebody else
+func (e *Election) grant(claimant api.PeerID, term int64, now time.Time) bool {
+	switch {
+	case term < e.term:
+		return false
+	case term == e.term && e.leader != "" && claimant != e.leader:
+		// one leader per term
+		return false
+	case claimant != e.leader && e.leader != "" && now.Before(e.leaseExpiry):
+		return false
+	}
+	if claimant != e.self && now.Before(e.leaderUntil) {
+		glog.Infof("peer %s stepping down as leader, %s is leader for term %d", e.self, claimant, term)
+		e.leaderUntil = time.Time{}
+	}
+	e.term = term
+	e.leader = claimant
+	e.leaseExpiry = now.Add(e.leaseDuration)
+	return true
+}
+
+// Update counts the leases granted in response to req, and decides whether to claim
+// leadership in the next round. peers are the peers that answered, including ourselves.
+func (e *Election) Update(start time.Time, req *api.PingRequest, peers map[api.PeerID]*Peer) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	granted := 0
+	lowest := e.self
+	// another leader reported by a peer, we do not challenge it
+	var other api.PeerID
+	for id, p := range peers {
+		if id < lowest {
+			lowest = id
+		}
+		if id == e.self {
+			continue
+		}
+		if p.Term > e.term {
+			e.term = p.Term
+		}
+		if p.LeaseGranted {
+			granted++
+		}
+		if p.Leader != "" && p.Leader != e.self {
+			other = p.Leader
+		}
+	}
+
+	if req.Leader == string(e.self) {
+		// our own vote
+		granted++
+		if granted >= e.quorum {
+			if !start.Before(e.leaderUntil) || e.leaderTerm != req.Term {
+				glog.Infof("peer %s is leader for term %d", e.self, req.Term)
+			}
+			e.leaderTerm = req.Term
+			e.leaderUntil = start.Add(e.leaseDuration)
+		} else {
+			if start.Before(e.leaderUntil) {
+				glog.Infof("peer %s lost leadership of term %d, %d of %d peers granted the lease", e.self, e.leaderTerm, granted, e.quorum)
+			}
+			e.leaderUntil = time.Time{}
+			// release our vote, so we can follow another candidate
+			if e.leader == e.self {
+				e.leader = ""
+				e.leaseExpiry = time.Time{}
+			}
+		}
+	}
+
+	e.claim = lowest == e.self && other == "" && len(peers) >= e.quorum
+}
+
+// SetClusterSize changes the number of peers that form a quorum
+func (e *Election) SetClusterSize(clusterSize int) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.quorum = clusterSize/2 + 1
+}
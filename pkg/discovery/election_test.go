@@ -0,0 +1,203 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	cs "github.com/etcd-manager/etcd-discovery/client/clientset/versioned/typed/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/client/clientset/versioned/typed/discovery/v1alpha1/fake"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+const testLease = 30 * time.Second
+
+// testCluster runs the election of in-process discovery servers. Pings between
+// servers in different partitions fail.
+type testCluster struct {
+	clock      *clock.FakeClock
+	nodes      map[api.PeerID]*testNode
+	partitions map[api.PeerID]int
+}
+
+type testNode struct {
+	id         api.PeerID
+	address    string
+	election   *Election
+	discoverer *Discoverer
+}
+
+func newTestCluster(ids ...api.PeerID) *testCluster {
+	c := &testCluster{
+		clock:      clock.NewFakeClock(time.Now()),
+		nodes:      map[api.PeerID]*testNode{},
+		partitions: map[api.PeerID]int{},
+	}
+	var seeds []string
+	for i := range ids {
+		seeds = append(seeds, fmt.Sprintf("10.0.0.%d", i+1))
+	}
+	for i, id := range ids {
+		n := &testNode{
+			id:       id,
+			address:  fmt.Sprintf("10.0.0.%d:2381", i+1),
+			election: NewElection(id, len(ids), testLease, c.clock),
+		}
+		self := Peer{ID: id, Address: n.address}
+		n.discoverer = NewDiscoverer(self, NewStaticSeedProvider(seeds), c.clientsFor(id), n.election)
+		c.nodes[id] = n
+	}
+	return c
+}
+
+func (c *testCluster) clientsFor(from api.PeerID) ClientFactory {
+	return func(address string) (cs.PingsGetter, error) {
+		client := &fake.FakeDiscoveryV1alpha1{Fake: &clienttesting.Fake{}}
+		client.AddReactor("create", "pings", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			for _, n := range c.nodes {
+				if n.address != address {
+					continue
+				}
+				if c.partitions[from] != c.partitions[n.id] {
+					return true, nil, fmt.Errorf("%s is unreachable", address)
+				}
+				ping := action.(clienttesting.CreateAction).GetObject().(*api.Ping)
+				ping.Response = &api.PingResponse{Info: &api.PeerInfo{ID: string(n.id)}}
+				n.election.HandlePing(ping.Request, ping.Response)
+				return true, ping, nil
+			}
+			return true, nil, fmt.Errorf("connection refused")
+		})
+		return client, nil
+	}
+}
+
+// round lets every node refresh once, in id order, then advances the clock
+func (c *testCluster) round(ids ...api.PeerID) {
+	for _, id := range ids {
+		c.nodes[id].discoverer.Refresh(context.Background())
+	}
+	c.clock.Step(testLease / 3)
+}
+
+// leaders returns the nodes that believe they are leader
+func (c *testCluster) leaders() []api.PeerID {
+	var leaders []api.PeerID
+	for id, n := range c.nodes {
+		if n.election.IsLeader() {
+			leaders = append(leaders, id)
+		}
+	}
+	return leaders
+}
+
+func TestElectionSingleLeader(t *testing.T) {
+	c := newTestCluster("a", "b", "c")
+	for i := 0; i < 5; i++ {
+		c.round("a", "b", "c")
+		if leaders := c.leaders(); len(leaders) > 1 {
+			t.Fatalf("round %d: more than one leader: %v", i, leaders)
+		}
+	}
+	if leaders := c.leaders(); len(leaders) != 1 || leaders[0] != "a" {
+		t.Fatalf("expected a to be leader, got %v", leaders)
+	}
+	for _, id := range []api.PeerID{"b", "c"} {
+		if leader, _ := c.nodes[id].election.Leader(); leader != "a" {
+			t.Errorf("%s follows %q, expected a", id, leader)
+		}
+	}
+}
+
+func TestElectionMinorityPartition(t *testing.T) {
+	c := newTestCluster("a", "b", "c")
+	for i := 0; i < 3; i++ {
+		c.round("a", "b", "c")
+	}
+	if !c.nodes["a"].election.IsLeader() {
+		t.Fatalf("expected a to be leader")
+	}
+
+	// isolate the leader
+	c.partitions["a"] = 1
+	for i := 0; i < 6; i++ {
+		c.round("a", "b", "c")
+		if leaders := c.leaders(); len(leaders) > 1 {
+			t.Fatalf("round %d: more than one leader: %v", i, leaders)
+		}
+	}
+	if c.nodes["a"].election.IsLeader() {
+		t.Errorf("isolated peer a is still leader")
+	}
+	if !c.nodes["b"].election.IsLeader() {
+		t.Errorf("expected b to be elected by the majority, leaders are %v", c.leaders())
+	}
+	_, termB := c.nodes["b"].election.Leader()
+
+	// heal the partition, a must not take over while b holds its lease
+	c.partitions["a"] = 0
+	for i := 0; i < 3; i++ {
+		c.round("a", "b", "c")
+		if leaders := c.leaders(); len(leaders) > 1 {
+			t.Fatalf("round %d after heal: more than one leader: %v", i, leaders)
+		}
+	}
+	if leaders := c.leaders(); len(leaders) != 1 {
+		t.Fatalf("expected one leader after heal, got %v", leaders)
+	}
+	for _, n := range c.nodes {
+		if _, term := n.election.Leader(); term < termB {
+			t.Errorf("%s went back to term %d, b was elected for term %d", n.id, term, termB)
+		}
+	}
+}
+
+func TestElectionNoQuorum(t *testing.T) {
+	c := newTestCluster("a", "b", "c")
+	c.partitions["b"] = 1
+	c.partitions["c"] = 2
+	for i := 0; i < 5; i++ {
+		c.round("a", "b", "c")
+	}
+	if leaders := c.leaders(); len(leaders) != 0 {
+		t.Fatalf("expected no leader without quorum, got %v", leaders)
+	}
+}
+
+func TestElectionLeaseExpiry(t *testing.T) {
+	c := newTestCluster("a", "b", "c")
+	for i := 0; i < 3; i++ {
+		c.round("a", "b", "c")
+	}
+	if !c.nodes["a"].election.IsLeader() {
+		t.Fatalf("expected a to be leader")
+	}
+
+	// a stops renewing its lease
+	delete(c.nodes, "a")
+	c.round("b", "c")
+	if leader, _ := c.nodes["b"].election.Leader(); leader != "a" {
+		t.Fatalf("b must honor the lease of a, follows %q", leader)
+	}
+	for i := 0; i < 5; i++ {
+		c.round("b", "c")
+	}
+	if !c.nodes["b"].election.IsLeader() {
+		t.Fatalf("expected b to be leader after the lease of a expired, leaders are %v", c.leaders())
+	}
+	if leader, _ := c.nodes["c"].election.Leader(); leader != "b" {
+		t.Errorf("c follows %q, expected b", leader)
+	}
+}
+
+func TestElectionSingleMember(t *testing.T) {
+	c := newTestCluster("a")
+	c.round("a")
+	if !c.nodes["a"].election.IsLeader() {
+		t.Fatalf("single member must elect itself in the first round")
+	}
+}
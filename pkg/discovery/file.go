@@ -0,0 +1,75 @@
+package discovery
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+type fileSeedProvider struct {
+	path string
+
+	mutex   sync.Mutex
+	modTime time.Time
+	size    int64
+	seeds   []string
+}
+
+var _ SeedProvider = &fileSeedProvider{}
+
+// NewFileSeedProvider returns a SeedProvider that reads one seed per line from path.
+// Blank lines and lines starting with # are ignored. The file is read again whenever it changes.
+func NewFileSeedProvider(path string) SeedProvider {
+	return &fileSeedProvider{path: path}
+}
+
+func (p *fileSeedProvider) GetSeeds(ctx context.Context) ([]string, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	fi, err := os.Stat(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading seed file %s: %v", p.path, err)
+	}
+	if p.seeds != nil && fi.ModTime().Equal(p.modTime) && fi.Size() == p.size {
+		return p.seeds, nil
+	}
+
+	seeds, err := readSeedFile(p.path)
+	if err != nil {
+		return nil, err
+	}
+	glog.Infof("loaded %d seeds from %s", len(seeds), p.path)
+	p.seeds = seeds
+	p.modTime = fi.ModTime()
+	p.size = fi.Size()
+	return p.seeds, nil
+}
+
+func readSeedFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading seed file %s: %v", path, err)
+	}
+	defer f.Close()
+
+	seeds := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		seeds = append(seeds, normalizeSeed(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading seed file %s: %v", path, err)
+	}
+	return sortedSeeds(seeds), nil
+}
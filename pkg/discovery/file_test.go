@@ -0,0 +1,58 @@
+package discovery
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFileSeedProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "seeds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "seeds")
+	if err := ioutil.WriteFile(path, []byte("# peers\n10.0.0.2\n\n10.0.0.1:4000\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewFileSeedProvider(path)
+	seeds, err := p.GetSeeds(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"10.0.0.1:4000", "10.0.0.2:2381"}
+	if !reflect.DeepEqual(seeds, expected) {
+		t.Errorf("expected %v, got %v", expected, seeds)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("10.0.0.3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// make sure the change is visible even on filesystems with coarse timestamps
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	seeds, err = p.GetSeeds(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{"10.0.0.3:2381"}
+	if !reflect.DeepEqual(seeds, expected) {
+		t.Errorf("expected %v after reload, got %v", expected, seeds)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.GetSeeds(context.Background()); err == nil {
+		t.Error("expected error for missing seed file")
+	}
+}
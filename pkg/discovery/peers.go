@@ -0,0 +1,184 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	cs "github.com/etcd-manager/etcd-discovery/client/clientset/versioned/typed/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/metrics"
+	"github.com/golang/glog"
+)
+
+// Peer is a discovery server that answered our ping
+type Peer struct {
+	ID api.PeerID
+	// Address is the host:port of the discovery server
+	Address  string
+	Hosts    []string
+	LastSeen time.Time
+
+	// Term and Leader are the peer's view of the leader election
+	Term   int64
+	Leader api.PeerID
+	// LeaseGranted is true if the peer accepted us as leader in the last round
+	LeaseGranted bool
+}
+
+// PingTimeout bounds a round of pings, the seeds are pinged in parallel
+const PingTimeout = 5 * time.Second
+
+// ClientFactory returns a client for the discovery server at address
+type ClientFactory func(address string) (cs.PingsGetter, error)
+
+// Discoverer pings the seeds and keeps track of the discovery servers that answer.
+// If election is set, every round of pings is also a round of the leader election.
+type Discoverer struct {
+	self     Peer
+	seeds    SeedProvider
+	clients  ClientFactory
+	election *Election
+
+	mutex sync.Mutex
+	peers map[api.PeerID]*Peer
+}
+
+func NewDiscoverer(self Peer, seeds SeedProvider, clients ClientFactory, election *Election) *Discoverer {
+	return &Discoverer{
+		self:     self,
+		seeds:    seeds,
+		clients:  clients,
+		election: election,
+		peers:    map[api.PeerID]*Peer{},
+	}
+}
+
+// Refresh pings every seed and returns the peers that answered, including ourselves
+func (d *Discoverer) Refresh(ctx context.Context) map[api.PeerID]*Peer {
+	seeds, err := d.seeds.GetSeeds(ctx)
+	if err != nil {
+		glog.Warningf("error getting seeds: %v", err)
+	}
+
+	req := &api.PingRequest{
+		Info: &api.PeerInfo{
+			ID:    string(d.self.ID),
+			Hosts: d.self.Hosts,
+		},
+	}
+	var start time.Time
+	if d.election != nil {
+		start = d.election.Prepare(req)
+	}
+
+	now := time.Now()
+	peers := map[api.PeerID]*Peer{}
+	for _, r := range d.pingAll(ctx, seeds, req) {
+		if r.err != nil {
+			metrics.PingFailures.WithLabelValues(r.seed).Inc()
+			glog.V(2).Infof("unable to ping discovery server %s: %v", r.seed, r.err)
+			continue
+		}
+		metrics.PingDuration.WithLabelValues(r.seed).Observe(r.duration.Seconds())
+		if r.peer.ID == d.self.ID {
+			continue
+		}
+		r.peer.LastSeen = now
+		peers[r.peer.ID] = r.peer
+	}
+
+	self := d.self
+	self.LastSeen = now
+	peers[self.ID] = &self
+
+	if d.election != nil {
+		d.election.Update(start, req, peers)
+	}
+
+	d.mutex.Lock()
+	d.peers = peers
+	d.mutex.Unlock()
+	return d.Peers()
+}
+
+// Peers returns the peers that answered the last Refresh
+func (d *Discoverer) Peers() map[api.PeerID]*Peer {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	peers := make(map[api.PeerID]*Peer, len(d.peers))
+	for id, p := range d.peers {
+		c := *p
+		peers[id] = &c
+	}
+	return peers
+}
+
+// pingResult is the answer of the discovery server at seed
+type pingResult struct {
+	seed     string
+	peer     *Peer
+	err      error
+	duration time.Duration
+}
+
+// pingAll pings the seeds in parallel. Seeds that do not answer within PingTimeout, or
+// before ctx is done, fail, so dead seeds cannot hold up a round of the election.
+func (d *Discoverer) pingAll(ctx context.Context, seeds []string, req *api.PingRequest) []pingResult {
+	ctx, cancel := context.WithTimeout(ctx, PingTimeout)
+	defer cancel()
+
+	pending := map[string]bool{}
+	answers := make(chan pingResult, len(seeds))
+	for _, seed := range seeds {
+		if seed == d.self.Address || pending[seed] {
+			continue
+		}
+		pending[seed] = true
+		go func(seed string) {
+			start := time.Now()
+			p, err := d.ping(seed, req)
+			answers <- pingResult{seed: seed, peer: p, err: err, duration: time.Since(start)}
+		}(seed)
+	}
+
+	var results []pingResult
+	for len(pending) > 0 {
+		select {
+		case r := <-answers:
+			delete(pending, r.seed)
+			results = append(results, r)
+		case <-ctx.Done():
+			for seed := range pending {
+				results = append(results, pingResult{seed: seed, err: fmt.Errorf("no answer within the ping deadline")})
+			}
+			return results
+		}
+	}
+	return results
+}
+
+func (d *Discoverer) ping(address string, req *api.PingRequest) (*Peer, error) {
+	client, err := d.clients(address)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Pings().Create(&api.Ping{Request: req.DeepCopy()})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Response == nil || resp.Response.Info == nil || resp.Response.Info.ID == "" {
+		return nil, fmt.Errorf("discovery server %s returned an empty ping response", address)
+	}
+	return &Peer{
+		ID:      api.PeerID(resp.Response.Info.ID),
+		Address: address,
+		Hosts:   resp.Response.Info.Hosts,
+
+		Term:         resp.Response.Term,
+		Leader:       api.PeerID(resp.Response.Leader),
+		LeaseGranted: resp.Response.LeaseGranted,
+	}, nil
+}
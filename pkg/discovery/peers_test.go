@@ -0,0 +1,94 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	cs "github.com/etcd-manager/etcd-discovery/client/clientset/versioned/typed/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/client/clientset/versioned/typed/discovery/v1alpha1/fake"
+	"k8s.io/apimachinery/pkg/runtime"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// fakeServers answers pings for the discovery servers listed by address
+func fakeServers(ids map[string]api.PeerID) ClientFactory {
+	return func(address string) (cs.PingsGetter, error) {
+		client := &fake.FakeDiscoveryV1alpha1{Fake: &clienttesting.Fake{}}
+		client.AddReactor("create", "pings", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			id, ok := ids[address]
+			if !ok {
+				return true, nil, fmt.Errorf("connection refused")
+			}
+			ping := action.(clienttesting.CreateAction).GetObject().(*api.Ping)
+			ping.Response = &api.PingResponse{Info: &api.PeerInfo{ID: string(id)}}
+			return true, ping, nil
+		})
+		return client, nil
+	}
+}
+
+func TestDiscovererRefresh(t *testing.T) {
+	servers := map[string]api.PeerID{
+		"10.0.0.1:2381": "self",
+		"10.0.0.2:2381": "b",
+		"10.0.0.3:2381": "c",
+	}
+	seeds := NewStaticSeedProvider([]string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4"})
+	d := NewDiscoverer(Peer{ID: "self", Address: "10.0.0.1:2381"}, seeds, fakeServers(servers), nil)
+
+	peers := d.Refresh(context.Background())
+	if len(peers) != 3 {
+		t.Fatalf("expected 3 peers, got %v", peers)
+	}
+	for _, id := range []api.PeerID{"self", "b", "c"} {
+		if _, ok := peers[id]; !ok {
+			t.Errorf("peer %s not found", id)
+		}
+	}
+	if peers["b"].Address != "10.0.0.2:2381" {
+		t.Errorf("unexpected address for b: %s", peers["b"].Address)
+	}
+
+	delete(servers, "10.0.0.3:2381")
+	peers = d.Refresh(context.Background())
+	if _, ok := peers["c"]; ok {
+		t.Errorf("unreachable peer c is still live")
+	}
+	if len(d.Peers()) != 2 {
+		t.Errorf("expected 2 live peers, got %v", d.Peers())
+	}
+}
+
+func TestDiscovererRefreshDeadline(t *testing.T) {
+	blocked := make(chan struct{})
+	defer close(blocked)
+	answer := fakeServers(map[string]api.PeerID{"10.0.0.2:2381": "b"})
+	clients := func(address string) (cs.PingsGetter, error) {
+		if address == "10.0.0.2:2381" {
+			return answer(address)
+		}
+		// the other seeds are dead and never answer
+		client := &fake.FakeDiscoveryV1alpha1{Fake: &clienttesting.Fake{}}
+		client.AddReactor("create", "pings", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			<-blocked
+			return true, nil, fmt.Errorf("connection timed out")
+		})
+		return client, nil
+	}
+	seeds := NewStaticSeedProvider([]string{"10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5"})
+	d := NewDiscoverer(Peer{ID: "self", Address: "10.0.0.1:2381"}, seeds, clients, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	peers := d.Refresh(ctx)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the dead seeds to share one deadline, refresh took %v", elapsed)
+	}
+	if len(peers) != 2 || peers["b"] == nil {
+		t.Errorf("expected self and b, got %v", peers)
+	}
+}
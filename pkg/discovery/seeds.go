@@ -0,0 +1,80 @@
+package discovery
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/etcd-manager/etcd-discovery/pkg/config"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// SeedProvider returns the addresses (host:port) of discovery servers that may belong to the cluster
+type SeedProvider interface {
+	GetSeeds(ctx context.Context) ([]string, error)
+}
+
+type staticSeedProvider struct {
+	seeds []string
+}
+
+var _ SeedProvider = &staticSeedProvider{}
+
+// NewStaticSeedProvider returns a SeedProvider for a fixed list of seeds.
+// Seeds without a port use config.DiscoveryPort.
+func NewStaticSeedProvider(seeds []string) SeedProvider {
+	p := &staticSeedProvider{}
+	for _, seed := range seeds {
+		p.seeds = append(p.seeds, normalizeSeed(seed))
+	}
+	return p
+}
+
+func (p *staticSeedProvider) GetSeeds(ctx context.Context) ([]string, error) {
+	return p.seeds, nil
+}
+
+type multiSeedProvider []SeedProvider
+
+var _ SeedProvider = multiSeedProvider{}
+
+// NewMultiSeedProvider returns a SeedProvider that merges the seeds of all the given providers.
+// GetSeeds returns the seeds of the providers that succeeded along with the errors of the others.
+func NewMultiSeedProvider(providers ...SeedProvider) SeedProvider {
+	var p multiSeedProvider
+	for _, provider := range providers {
+		if provider != nil {
+			p = append(p, provider)
+		}
+	}
+	return p
+}
+
+func (p multiSeedProvider) GetSeeds(ctx context.Context) ([]string, error) {
+	seeds := sets.NewString()
+	var errs []error
+	for _, provider := range p {
+		s, err := provider.GetSeeds(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		seeds.Insert(s...)
+	}
+	return seeds.List(), utilerrors.NewAggregate(errs)
+}
+
+// normalizeSeed adds the default discovery port to seeds that do not specify one
+func normalizeSeed(seed string) string {
+	if _, _, err := net.SplitHostPort(seed); err == nil {
+		return seed
+	}
+	return net.JoinHostPort(seed, strconv.Itoa(config.DiscoveryPort))
+}
+
+func sortedSeeds(seeds []string) []string {
+	sort.Strings(seeds)
+	return seeds
+}
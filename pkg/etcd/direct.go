@@ -2,6 +2,7 @@ package etcd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path"
@@ -9,26 +10,67 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/etcd-manager/etcd-discovery/pkg/config"
 	"github.com/golang/glog"
 )
 
-// etcdDirect wraps a running etcd process
+const (
+	// restartBackoff is the delay before restarting a process that exited unexpectedly,
+	// doubled for every other exit within crashLoopWindow up to maxRestartBackoff
+	restartBackoff    = time.Second
+	maxRestartBackoff = 2 * time.Minute
+	// a process that exits more than crashLoopRestarts times within crashLoopWindow is
+	// not restarted again
+	crashLoopWindow   = 10 * time.Minute
+	crashLoopRestarts = 5
+	// exitHistoryLength is how many exits of a process are kept
+	exitHistoryLength  = 10
+	defaultStopTimeout = 30 * time.Second
+)
+
+// etcdDirect runs etcd as a child process, restarting it when it exits unexpectedly
 type etcdDirect struct {
 	BinDir string
 	cfg    *config.EtcdFlags
+	opts   ProcessOptions
 
-	cmd *exec.Cmd
+	restartBackoff    time.Duration
+	maxRestartBackoff time.Duration
+	crashLoopWindow   time.Duration
+	crashLoopRestarts int
 
-	mutex     sync.Mutex
+	mutex sync.Mutex
+	// cmd is the running etcd process, nil while it could not be restarted
+	cmd *exec.Cmd
+	// stopCh is closed by Stop, done once the process is no longer restarted
+	stopCh    chan struct{}
+	done      chan struct{}
+	stopping  bool
+	exits     []ProcessExit
 	exitError error
 	exitState *os.ProcessState
 }
 
 var _ Process = &etcdDirect{}
 
+func newEtcdDirect(binDir string, cfg *config.EtcdFlags, opts ProcessOptions) *etcdDirect {
+	if opts.StopTimeout <= 0 {
+		opts.StopTimeout = defaultStopTimeout
+	}
+	return &etcdDirect{
+		BinDir:            binDir,
+		cfg:               cfg,
+		opts:              opts,
+		restartBackoff:    restartBackoff,
+		maxRestartBackoff: maxRestartBackoff,
+		crashLoopWindow:   crashLoopWindow,
+		crashLoopRestarts: crashLoopRestarts,
+	}
+}
+
 func (p *etcdDirect) Type() ProcessType {
 	return ProcessTypeDirect
 }
@@ -53,64 +95,198 @@ func BindirForEtcdVersion(etcdVersion string, cmd string) (string, error) {
 }
 
 func (p *etcdDirect) Start() error {
+	stdout, stderr, logs, err := p.openLogs()
+	if err != nil {
+		return err
+	}
+	c, err := p.run(stdout, stderr)
+	if err != nil {
+		if logs != nil {
+			logs.Close()
+		}
+		return err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.cmd = c
+	p.stopCh = make(chan struct{})
+	p.done = make(chan struct{})
+	go p.supervise(c, stdout, stderr, logs)
+	return nil
+}
+
+// openLogs returns the writers of the output of etcd, and the log file to close once etcd
+// is no longer restarted
+func (p *etcdDirect) openLogs() (io.Writer, io.Writer, io.Closer, error) {
+	if p.opts.LogDir == "" {
+		return newPrefixWriter(os.Stdout, p.cfg.Name), newPrefixWriter(os.Stderr, p.cfg.Name), nil, nil
+	}
+	f, err := openRotatingFile(filepath.Join(p.opts.LogDir, "etcd-"+p.cfg.Name+".log"), p.opts.LogMaxSize, p.opts.LogMaxFiles)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// a single writer keeps the lines of stdout and stderr apart
+	w := newPrefixWriter(f, p.cfg.Name)
+	return w, w, f, nil
+}
+
+func (p *etcdDirect) run(stdout, stderr io.Writer) (*exec.Cmd, error) {
 	c := exec.Command(path.Join(p.BinDir, "etcd"))
 
 	args, err := p.cfg.ToArgs()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	c.Args = args
+	c.Args = append([]string{c.Path}, args...)
 	glog.Infof("executing command %s %s", c.Path, c.Args)
 
-	c.Stdout = os.Stdout
-	c.Stderr = os.Stderr
-	err = c.Start()
-	if err != nil {
-		return fmt.Errorf("error starting etcd: %v", err)
+	c.Stdout = stdout
+	c.Stderr = stderr
+	if err := c.Start(); err != nil {
+		return nil, fmt.Errorf("error starting etcd: %v", err)
 	}
-	p.cmd = c
+	return c, nil
+}
+
+// supervise waits for c to exit and restarts it with a backoff, until the process is
+// stopped or crash loops
+func (p *etcdDirect) supervise(c *exec.Cmd, stdout, stderr io.Writer, logs io.Closer) {
+	defer close(p.done)
+	if logs != nil {
+		defer logs.Close()
+	}
+
+	var startErr error
+	for {
+		var exit ProcessExit
+		var state *os.ProcessState
+		if c != nil {
+			err := c.Wait()
+			state = c.ProcessState
+			exit = newProcessExit(state, err)
+		} else {
+			exit = ProcessExit{ExitCode: -1, Message: startErr.Error()}
+		}
+		exit.Time = time.Now()
+
+		p.mutex.Lock()
+		exit.Expected = p.stopping
+		p.recordExit(exit)
+		if p.stopping {
+			p.exitState = state
+			p.mutex.Unlock()
+			glog.Infof("etcd exited: %s", exit.Message)
+			return
+		}
+		recent := p.recentExits(exit.Time)
+		if recent > p.crashLoopRestarts {
+			p.exitState = state
+			p.exitError = ErrCrashLoop
+			p.mutex.Unlock()
+			glog.Errorf("etcd exited %d times within %v, last with %s, not restarting it", recent, p.crashLoopWindow, exit.Message)
+			return
+		}
+		backoff := p.backoff(recent)
+		p.mutex.Unlock()
 
-	go func() {
-		processState, err := p.cmd.Process.Wait()
-		if err != nil {
-			glog.Warningf("etcd exited with error: %v", err)
+		glog.Warningf("etcd exited unexpectedly with %s, restarting it in %v", exit.Message, backoff)
+		select {
+		case <-time.After(backoff):
+		case <-p.stopCh:
 		}
+
 		p.mutex.Lock()
-		p.exitState = processState
-		p.exitError = err
+		if p.stopping {
+			p.exitState = state
+			p.mutex.Unlock()
+			return
+		}
+		c, startErr = p.run(stdout, stderr)
+		p.cmd = c
 		p.mutex.Unlock()
-	}()
+	}
+}
 
-	return nil
+func newProcessExit(state *os.ProcessState, err error) ProcessExit {
+	exit := ProcessExit{ExitCode: -1}
+	if state != nil {
+		exit.ExitCode = state.ExitCode()
+		exit.Message = state.String()
+	} else if err != nil {
+		exit.Message = err.Error()
+	}
+	return exit
+}
+
+// recordExit adds exit to the history, p.mutex must be held
+func (p *etcdDirect) recordExit(exit ProcessExit) {
+	p.exits = append(p.exits, exit)
+	if len(p.exits) > exitHistoryLength {
+		p.exits = p.exits[len(p.exits)-exitHistoryLength:]
+	}
+}
+
+// recentExits counts the unexpected exits within the crash loop window, p.mutex must be held
+func (p *etcdDirect) recentExits(now time.Time) int {
+	recent := 0
+	for _, exit := range p.exits {
+		if !exit.Expected && now.Sub(exit.Time) < p.crashLoopWindow {
+			recent++
+		}
+	}
+	return recent
+}
+
+// backoff returns the delay before restarting a process that exited recent times
+func (p *etcdDirect) backoff(recent int) time.Duration {
+	backoff := p.restartBackoff
+	for i := 1; i < recent && backoff < p.maxRestartBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.maxRestartBackoff {
+		backoff = p.maxRestartBackoff
+	}
+	return backoff
 }
 
+// Stop asks etcd to exit and kills it if it did not within the stop timeout
 func (p *etcdDirect) Stop() error {
-	if p.cmd == nil {
+	p.mutex.Lock()
+	if p.done == nil {
+		p.mutex.Unlock()
 		glog.Warningf("received Stop when process not running")
 		return nil
 	}
-	if err := p.cmd.Process.Kill(); err != nil {
-		p.mutex.Lock()
-		if p.exitState != nil {
-			glog.Infof("Exited etcd: %v", p.exitState)
-			return nil
-		}
-		p.mutex.Unlock()
-		return fmt.Errorf("failed to kill process: %v", err)
+	if !p.stopping {
+		p.stopping = true
+		close(p.stopCh)
 	}
+	c, done := p.cmd, p.done
+	p.mutex.Unlock()
 
-	for {
-		glog.Infof("Waiting for etcd to exit")
-		p.mutex.Lock()
-		if p.exitState != nil {
-			exitState := p.exitState
-			p.mutex.Unlock()
-			glog.Infof("Exited etcd: %v", exitState)
-			return nil
+	if c != nil {
+		// fails if etcd exited already, done is closed then
+		if err := c.Process.Signal(syscall.SIGTERM); err != nil {
+			glog.V(2).Infof("error signaling etcd: %v", err)
 		}
-		p.mutex.Unlock()
-		time.Sleep(100 * time.Millisecond)
 	}
+	glog.Infof("waiting for etcd to exit")
+	select {
+	case <-done:
+	case <-time.After(p.opts.StopTimeout):
+		glog.Warningf("etcd did not exit within %v, killing it", p.opts.StopTimeout)
+		if c != nil {
+			if err := c.Process.Kill(); err != nil {
+				glog.Warningf("failed to kill etcd: %v", err)
+			}
+		}
+		<-done
+	}
+
+	_, exitState := p.ExitState()
+	glog.Infof("Exited etcd: %v", exitState)
+	return nil
 }
 
 func (p *etcdDirect) ExitState() (error, *os.ProcessState) {
@@ -119,3 +295,10 @@ func (p *etcdDirect) ExitState() (error, *os.ProcessState) {
 
 	return p.exitError, p.exitState
 }
+
+func (p *etcdDirect) Exits() []ProcessExit {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return append([]ProcessExit(nil), p.exits...)
+}
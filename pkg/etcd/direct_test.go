@@ -0,0 +1,168 @@
+package etcd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/etcd-manager/etcd-discovery/pkg/config"
+)
+
+// newTestProcess returns a process that runs script in place of etcd
+func newTestProcess(t *testing.T, dir, script string) *etcdDirect {
+	if err := ioutil.WriteFile(filepath.Join(dir, "etcd"), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.NewEtcdFlags()
+	cfg.Version = "3.2.13"
+	cfg.Name = "a"
+	p := newEtcdDirect(dir, cfg, ProcessOptions{LogDir: filepath.Join(dir, "logs"), LogMaxFiles: 2, StopTimeout: time.Second})
+	p.restartBackoff = 10 * time.Millisecond
+	p.maxRestartBackoff = 40 * time.Millisecond
+	return p
+}
+
+func waitExited(t *testing.T, p *etcdDirect) (error, *os.ProcessState) {
+	select {
+	case <-p.done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("process was still supervised after 10s")
+	}
+	return p.ExitState()
+}
+
+// waitReady waits for the script of a test process to create dir/ready
+func waitReady(t *testing.T, dir string) {
+	ready := filepath.Join(dir, "ready")
+	for i := 0; i < 200; i++ {
+		if _, err := os.Stat(ready); err == nil {
+			os.Remove(ready)
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("process did not start after 2s")
+}
+
+func TestDirectCrashLoop(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-direct")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := newTestProcess(t, dir, "echo starting\nexit 3\n")
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	exitError, exitState := waitExited(t, p)
+	if exitError != ErrCrashLoop || exitState == nil || exitState.ExitCode() != 3 {
+		t.Errorf("expected a crash loop, got %v, %v", exitError, exitState)
+	}
+
+	exits := p.Exits()
+	if len(exits) != crashLoopRestarts+1 {
+		t.Fatalf("expected %d exits, got %d", crashLoopRestarts+1, len(exits))
+	}
+	for _, exit := range exits {
+		if exit.ExitCode != 3 || exit.Expected || exit.Message != "exit status 3" {
+			t.Errorf("unexpected exit %+v", exit)
+		}
+	}
+	if gap := exits[len(exits)-1].Time.Sub(exits[len(exits)-2].Time); gap < 40*time.Millisecond {
+		t.Errorf("expected restarts to back off, last one after %v", gap)
+	}
+
+	logs, err := ioutil.ReadFile(filepath.Join(dir, "logs", "etcd-a.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(logs), "[a] starting\n"); n != crashLoopRestarts+1 {
+		t.Errorf("expected %d prefixed lines in the log, got %q", crashLoopRestarts+1, logs)
+	}
+}
+
+func TestDirectStop(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-direct")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// exits on SIGTERM
+	p := newTestProcess(t, dir, "trap 'exit 0' TERM\ntouch "+dir+"/ready\nwhile true; do sleep 0.05; done\n")
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	waitReady(t, dir)
+	start := time.Now()
+	if err := p.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("expected etcd to exit on SIGTERM, took %v", elapsed)
+	}
+	exitError, exitState := p.ExitState()
+	if exitError != nil || exitState == nil || !exitState.Success() {
+		t.Errorf("expected a clean exit, got %v, %v", exitError, exitState)
+	}
+	if exits := p.Exits(); len(exits) != 1 || !exits[0].Expected {
+		t.Errorf("expected one expected exit, got %+v", exits)
+	}
+
+	// ignores SIGTERM
+	p = newTestProcess(t, dir, "trap '' TERM\ntouch "+dir+"/ready\nwhile true; do sleep 0.05; done\n")
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	waitReady(t, dir)
+	if err := p.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if _, exitState := p.ExitState(); exitState == nil || exitState.ExitCode() != -1 {
+		t.Errorf("expected etcd to be killed, got %v", exitState)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "etcd-a.log")
+	f, err := openRotatingFile(path, 10, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newPrefixWriter(f, "a")
+	for _, s := range []string{"one\n", "two\n", "thr", "ee\n", "four\n"} {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, expected := range map[string]string{
+		path:        "[a] four\n",
+		path + ".1": "[a] three\n",
+		path + ".2": "[a] two\n",
+	} {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected {
+			t.Errorf("expected %q in %s, got %q", expected, name, data)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected at most 3 log files, got %v", err)
+	}
+}
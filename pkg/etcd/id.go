@@ -12,6 +12,7 @@ import (
 
 	ioutils "github.com/appscode/go/ioutil"
 	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/fileutil"
 	"github.com/golang/glog"
 )
 
@@ -27,6 +28,7 @@ func PersistentPeerID(basedir string) (api.PeerID, error) {
 			if err := ioutils.WriteFile(idFile, bytes.NewBufferString(token), 0644); err != nil {
 				return "", fmt.Errorf("error creating id file %q: %v", idFile, err)
 			}
+			b = []byte(token)
 		} else {
 			return "", fmt.Errorf("error reading id file %q: %v", idFile, err)
 		}
@@ -36,6 +38,20 @@ func PersistentPeerID(basedir string) (api.PeerID, error) {
 	return uniqueID, nil
 }
 
+// SavePeerID replaces the id in the base directory with the one the leader assigned
+func SavePeerID(basedir string, id api.PeerID) error {
+	idFile := filepath.Join(basedir, "myid")
+	if err := fileutil.WriteFile(idFile, []byte(id), 0644); err != nil {
+		return fmt.Errorf("error writing id file %q: %v", idFile, err)
+	}
+	return nil
+}
+
+// NewPeerID returns a new random peer id
+func NewPeerID() api.PeerID {
+	return api.PeerID(randomToken())
+}
+
 func randomToken() string {
 	b := make([]byte, 16, 16)
 	_, err := io.ReadFull(crypto_rand.Reader, b)
@@ -1,8 +1,9 @@
-//go:generate go-enum -f=processtype.go --lower
+//go:generate go-enum -f=processtype.go --lower --flag
 package etcd
 
 // ProcessType x ENUM(
 // Direct,
-// StaticPod
+// StaticPod,
+// Systemd
 // )
 type ProcessType int32
@@ -13,13 +13,16 @@ const (
 	ProcessTypeDirect ProcessType = iota
 	// ProcessTypeStaticPod is a ProcessType of type StaticPod
 	ProcessTypeStaticPod
+	// ProcessTypeSystemd is a ProcessType of type Systemd
+	ProcessTypeSystemd
 )
 
-const _ProcessTypeName = "DirectStaticPod"
+const _ProcessTypeName = "DirectStaticPodSystemd"
 
 var _ProcessTypeMap = map[ProcessType]string{
 	0: _ProcessTypeName[0:6],
 	1: _ProcessTypeName[6:15],
+	2: _ProcessTypeName[15:22],
 }
 
 func (i ProcessType) String() string {
@@ -30,10 +33,12 @@ func (i ProcessType) String() string {
 }
 
 var _ProcessTypeValue = map[string]ProcessType{
-	_ProcessTypeName[0:6]:                   0,
-	strings.ToLower(_ProcessTypeName[0:6]):  0,
-	_ProcessTypeName[6:15]:                  1,
-	strings.ToLower(_ProcessTypeName[6:15]): 1,
+	_ProcessTypeName[0:6]:                    0,
+	strings.ToLower(_ProcessTypeName[0:6]):   0,
+	_ProcessTypeName[6:15]:                   1,
+	strings.ToLower(_ProcessTypeName[6:15]):  1,
+	_ProcessTypeName[15:22]:                  2,
+	strings.ToLower(_ProcessTypeName[15:22]): 2,
 }
 
 // ParseProcessType attempts to convert a string to a ProcessType
@@ -43,3 +48,20 @@ func ParseProcessType(name string) (ProcessType, error) {
 	}
 	return ProcessType(0), fmt.Errorf("%s is not a valid ProcessType", name)
 }
+
+// Set implements the Golang flag.Value interface func
+func (x *ProcessType) Set(val string) error {
+	v, err := ParseProcessType(val)
+	*x = v
+	return err
+}
+
+// Get implements the Golang flag.Getter interface func
+func (x *ProcessType) Get() interface{} {
+	return *x
+}
+
+// Type implements the github.com/spf13/pFlag Value interface
+func (x *ProcessType) Type() string {
+	return "ProcessType"
+}
@@ -0,0 +1,39 @@
+package etcd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+
+	"github.com/etcd-manager/etcd-discovery/pkg/config"
+	"github.com/golang/glog"
+)
+
+// RestoreSnapshot restores snapshotFile into the data dir of cfg with etcdctl of the same
+// version. The data dir must not exist. Every member of a restored cluster restores the
+// same snapshot with the same initial cluster and token, and then starts as a new cluster.
+func RestoreSnapshot(cfg *config.EtcdFlags, snapshotFile string) error {
+	if cfg.Version.IsV2() {
+		return fmt.Errorf("etcd %s does not support snapshot restore", cfg.Version)
+	}
+	binDir, err := BindirForEtcdVersion(string(cfg.Version), "etcdctl")
+	if err != nil {
+		return err
+	}
+
+	c := exec.Command(path.Join(binDir, "etcdctl"),
+		"snapshot", "restore", snapshotFile,
+		"--name", cfg.Name,
+		"--initial-cluster", cfg.InitialCluster.String(),
+		"--initial-cluster-token", cfg.InitialClusterToken,
+		"--initial-advertise-peer-urls", cfg.InitialAdvertisePeerURLs.String(),
+		"--data-dir", cfg.DataDir,
+	)
+	c.Env = append(os.Environ(), "ETCDCTL_API=3")
+	glog.Infof("executing command %s %s", c.Path, c.Args)
+	if output, err := c.CombinedOutput(); err != nil {
+		return fmt.Errorf("error restoring snapshot %s: %v: %s", snapshotFile, err, output)
+	}
+	return nil
+}
@@ -1,32 +1,75 @@
 package etcd
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
 
-	"github.com/appscode/kutil"
 	"github.com/appscode/kutil/meta"
 	"github.com/etcd-manager/etcd-discovery/pkg/config"
 	"github.com/etcd-manager/etcd-discovery/pkg/constants"
+	"github.com/etcd-manager/etcd-discovery/pkg/fileutil"
+	"github.com/golang/glog"
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 )
 
 const (
 	etcdVolumeName  = "etcd-data"
 	certsVolumeName = "etcd-certs"
+
+	defaultStartTimeout = 5 * time.Minute
+	// staticPodProbeInterval is how often the health of a running static pod is probed
+	staticPodProbeInterval = 5 * time.Second
+	staticPodProbeTimeout  = 2 * time.Second
+	// staticPodUnhealthyTimeout is how long a static pod may be unhealthy before it is
+	// reported as exited, longer than kubelet takes to restart it after its liveness probe failed
+	staticPodUnhealthyTimeout = 3 * time.Minute
 )
 
+// etcdStaticPod runs etcd as a static pod of kubelet and probes its health endpoint
 type etcdStaticPod struct {
 	manifestDir string
 	cfg         *config.EtcdFlags
+
+	startTimeout     time.Duration
+	unhealthyTimeout time.Duration
+	// probe returns an error if etcd is not healthy
+	probe func() error
+
+	mutex       sync.Mutex
+	lastProbe   time.Time
+	lastHealthy time.Time
+	probeError  error
+	exits       []ProcessExit
 }
 
 var _ Process = &etcdStaticPod{}
 
+func newEtcdStaticPod(cfg *config.EtcdFlags, opts ProcessOptions) *etcdStaticPod {
+	if opts.StartTimeout <= 0 {
+		opts.StartTimeout = defaultStartTimeout
+	}
+	p := &etcdStaticPod{
+		manifestDir:      opts.ManifestDir,
+		cfg:              cfg,
+		startTimeout:     opts.StartTimeout,
+		unhealthyTimeout: staticPodUnhealthyTimeout,
+	}
+	p.probe = p.probeHealth
+	return p
+}
+
 func (p *etcdStaticPod) Type() ProcessType {
 	return ProcessTypeStaticPod
 }
@@ -61,18 +104,121 @@ func (p *etcdStaticPod) Start() error {
 	if err := p.WriteStaticPodToDisk(constants.Etcd, spec); err != nil {
 		return err
 	}
+	glog.Infof("wrote static pod manifest for a local etcd instance to %q, waiting up to %v for etcd to be ready", p.GetStaticPodFilepath(), p.startTimeout)
+
+	var probeError error
+	err = wait.PollImmediate(time.Second, p.startTimeout, func() (bool, error) {
+		probeError = p.probe()
+		return probeError == nil, nil
+	})
+	if err != nil {
+		return fmt.Errorf("etcd static pod was not ready within %v: %v", p.startTimeout, probeError)
+	}
 
-	fmt.Printf("[etcd] Wrote Static Pod manifest for a local etcd instance to %q\n", p.GetStaticPodFilepath())
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.lastProbe = time.Now()
+	p.lastHealthy = p.lastProbe
+	p.probeError = nil
 	return nil
 }
 
 func (p *etcdStaticPod) Stop() error {
 	filename := p.GetStaticPodFilepath()
-	return os.Remove(filename)
+	if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
 }
 
+// ExitState probes etcd at most every staticPodProbeInterval and reports it as exited
+// once it was unhealthy for longer than the unhealthy timeout. Its manifest is removed
+// then, so that kubelet runs a new pod once the manifest is written again.
 func (p *etcdStaticPod) ExitState() (error, *os.ProcessState) {
-	return kutil.ErrUnknown, nil
+	p.mutex.Lock()
+	now := time.Now()
+	probe := now.Sub(p.lastProbe) >= staticPodProbeInterval
+	if probe {
+		p.lastProbe = now
+	}
+	p.mutex.Unlock()
+
+	// the probe takes up to staticPodProbeTimeout, Exits is not held up meanwhile
+	var probeError error
+	if probe {
+		probeError = p.probe()
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if probe {
+		p.probeError = probeError
+		if probeError == nil {
+			p.lastHealthy = now
+		}
+	}
+	if p.probeError == nil || now.Sub(p.lastHealthy) <= p.unhealthyTimeout {
+		return nil, nil
+	}
+
+	err := fmt.Errorf("etcd static pod is unhealthy since %v: %v", p.lastHealthy.Format(time.RFC3339), p.probeError)
+	if len(p.exits) == 0 || p.exits[len(p.exits)-1].Time.Before(p.lastHealthy) {
+		p.exits = append(p.exits, ProcessExit{Time: now, ExitCode: -1, Message: err.Error()})
+		if len(p.exits) > exitHistoryLength {
+			p.exits = p.exits[len(p.exits)-exitHistoryLength:]
+		}
+		if err := os.Remove(p.GetStaticPodFilepath()); err != nil && !os.IsNotExist(err) {
+			glog.Warningf("error removing static pod manifest of unhealthy etcd: %v", err)
+		}
+	}
+	return err, nil
+}
+
+// Exits returns when the static pod was found unhealthy
+func (p *etcdStaticPod) Exits() []ProcessExit {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]ProcessExit(nil), p.exits...)
+}
+
+// probeHealth reads the health endpoint of etcd with the certificates of its liveness probe
+func (p *etcdStaticPod) probeHealth() error {
+	tlsConfig := &tls.Config{}
+	if ca, err := ioutil.ReadFile(filepath.Join(p.cfg.CertificatesDir, constants.EtcdCACertName)); err == nil {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		tlsConfig.RootCAs.AppendCertsFromPEM(ca)
+	}
+	cert, err := tls.LoadX509KeyPair(
+		filepath.Join(p.cfg.CertificatesDir, constants.EtcdHealthcheckClientCertName),
+		filepath.Join(p.cfg.CertificatesDir, constants.EtcdHealthcheckClientKeyName),
+	)
+	if err == nil {
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	client := &http.Client{
+		Timeout:   staticPodProbeTimeout,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}
+	address := net.JoinHostPort(p.GetProbeAddress(), strconv.Itoa(p.cfg.ListenClientURLs.Port))
+	resp, err := client.Get("https://" + address + "/health")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("health check of %s returned %s", address, resp.Status)
+	}
+	var health struct {
+		Health string `json:"health"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		return fmt.Errorf("error reading health of %s: %v", address, err)
+	}
+	if health.Health != "true" {
+		return fmt.Errorf("etcd at %s is not healthy", address)
+	}
+	return nil
 }
 
 // getEtcdCommand builds the right etcd command from the given config object
@@ -165,7 +311,7 @@ func VolumeMapToSlice(volumes map[string]v1.Volume) []v1.Volume {
 	return v
 }
 
-// WriteStaticPodToDisk writes a static pod file to disk
+// WriteStaticPodToDisk writes a static pod file to disk, kubelet never reads it half written
 func (p *etcdStaticPod) WriteStaticPodToDisk(componentName string, pod v1.Pod) error {
 	// creates target folder if not already exists
 	if err := os.MkdirAll(p.manifestDir, 0700); err != nil {
@@ -180,7 +326,7 @@ func (p *etcdStaticPod) WriteStaticPodToDisk(componentName string, pod v1.Pod) e
 
 	filename := p.GetStaticPodFilepath()
 
-	if err := ioutil.WriteFile(filename, serialized, 0600); err != nil {
+	if err := fileutil.WriteFile(filename, serialized, 0600); err != nil {
 		return fmt.Errorf("failed to write static pod manifest file for %q (%q): %v", componentName, filename, err)
 	}
 
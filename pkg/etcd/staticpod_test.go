@@ -0,0 +1,107 @@
+package etcd
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/etcd-manager/etcd-discovery/pkg/config"
+)
+
+func newTestStaticPod(t *testing.T, dir string, probe func() error) *etcdStaticPod {
+	cfg := config.NewEtcdFlags()
+	cfg.Version = "3.2.13"
+	cfg.Name = "a"
+	cfg.DataDir = filepath.Join(dir, "data")
+	cfg.CertificatesDir = filepath.Join(dir, "pki")
+	p := newEtcdStaticPod(cfg, ProcessOptions{ManifestDir: filepath.Join(dir, "manifests"), StartTimeout: 3 * time.Second})
+	p.probe = probe
+	return p
+}
+
+func TestStaticPodStart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-staticpod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	probes := 0
+	p := newTestStaticPod(t, dir, func() error {
+		probes++
+		if probes < 2 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if probes != 2 {
+		t.Errorf("expected Start to wait for etcd to be ready, probed %d times", probes)
+	}
+	manifest, err := ioutil.ReadFile(p.GetStaticPodFilepath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(manifest), "kind: Pod") {
+		t.Errorf("unexpected manifest %s", manifest)
+	}
+	files, err := ioutil.ReadDir(filepath.Join(dir, "manifests"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("expected only the manifest in the manifest dir, got %d files", len(files))
+	}
+
+	p = newTestStaticPod(t, dir, func() error { return errors.New("connection refused") })
+	p.startTimeout = 100 * time.Millisecond
+	if err := p.Start(); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("expected Start to time out, got %v", err)
+	}
+}
+
+func TestStaticPodExitState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-staticpod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var probeError error
+	p := newTestStaticPod(t, dir, func() error { return probeError })
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if exitError, _ := p.ExitState(); exitError != nil {
+		t.Errorf("expected a running pod, got %v", exitError)
+	}
+
+	// a pod that turned unhealthy is running until the unhealthy timeout passed
+	probeError = errors.New("connection refused")
+	p.lastProbe = time.Time{}
+	if exitError, _ := p.ExitState(); exitError != nil {
+		t.Errorf("expected a recently healthy pod to be running, got %v", exitError)
+	}
+	p.lastHealthy = time.Now().Add(-p.unhealthyTimeout - time.Second)
+	if exitError, _ := p.ExitState(); exitError == nil || !strings.Contains(exitError.Error(), "unhealthy since") {
+		t.Errorf("expected an unhealthy pod to have exited, got %v", exitError)
+	}
+	if exits := p.Exits(); len(exits) != 1 || exits[0].Expected {
+		t.Errorf("expected one exit, got %+v", exits)
+	}
+	if _, err := os.Stat(p.GetStaticPodFilepath()); !os.IsNotExist(err) {
+		t.Errorf("expected the manifest of an unhealthy pod to be removed, got %v", err)
+	}
+	if exitError, _ := p.ExitState(); exitError == nil || len(p.Exits()) != 1 {
+		t.Errorf("expected the exit to be reported once, got %v, %+v", exitError, p.Exits())
+	}
+	if err := p.Stop(); err != nil {
+		t.Errorf("expected Stop without manifest to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,311 @@
+package etcd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-systemd/dbus"
+	"github.com/etcd-manager/etcd-discovery/pkg/config"
+	"github.com/etcd-manager/etcd-discovery/pkg/constants"
+	"github.com/etcd-manager/etcd-discovery/pkg/fileutil"
+	"github.com/golang/glog"
+)
+
+const (
+	defaultUnitDir = "/etc/systemd/system"
+	// systemdDropIn holds the command line of etcd, the unit file only changes with the etcd binary
+	systemdDropIn = "10-etcd-manager.conf"
+	// systemdStopTimeout is how long Stop waits for the stop job of the unit, longer than
+	// the default time systemd gives etcd to stop before it kills it
+	systemdStopTimeout = 2 * time.Minute
+
+	// ExecMainCode values, the si_code of the exit of the main process of a service
+	cldExited = 1
+	cldKilled = 2
+	cldDumped = 3
+)
+
+// systemdConnection is the part of the D-Bus API of the systemd manager used to run etcd as
+// a unit, the connection of go-systemd implements it
+type systemdConnection interface {
+	// Reload reloads the unit files
+	Reload() error
+	// StartUnit and StopUnit queue a job and send its result to ch once it completed
+	StartUnit(name string, mode string, ch chan<- string) (int, error)
+	StopUnit(name string, mode string, ch chan<- string) (int, error)
+	// GetUnitProperties returns the properties of a unit, GetUnitTypeProperties the ones
+	// of its type
+	GetUnitProperties(unit string) (map[string]interface{}, error)
+	GetUnitTypeProperties(unit string, unitType string) (map[string]interface{}, error)
+}
+
+var _ systemdConnection = &dbus.Conn{}
+
+var (
+	systemdMutex sync.Mutex
+	systemdConn  *dbus.Conn
+)
+
+// connectSystemd returns the connection to the systemd manager shared by the etcd units
+func connectSystemd() (systemdConnection, error) {
+	systemdMutex.Lock()
+	defer systemdMutex.Unlock()
+	if systemdConn == nil {
+		conn, err := dbus.NewSystemdConnection()
+		if err != nil {
+			return nil, fmt.Errorf("error connecting to systemd: %v", err)
+		}
+		systemdConn = conn
+	}
+	return systemdConn, nil
+}
+
+// unitStatus are the properties of a service unit that tell if its main process runs
+type unitStatus struct {
+	ActiveState string
+	SubState    string
+	// ExecMainCode tells how the main process exited, ExecMainStatus is its exit status or
+	// the signal that killed it
+	ExecMainCode   int
+	ExecMainStatus int
+}
+
+// readUnitStatus reads the status of the service unit name from systemd
+func readUnitStatus(conn systemdConnection, name string) (*unitStatus, error) {
+	unit, err := conn.GetUnitProperties(name)
+	if err != nil {
+		return nil, err
+	}
+	service, err := conn.GetUnitTypeProperties(name, "Service")
+	if err != nil {
+		return nil, err
+	}
+	status := &unitStatus{}
+	var ok bool
+	if status.ActiveState, ok = unit["ActiveState"].(string); !ok {
+		return nil, fmt.Errorf("unit %s has no ActiveState", name)
+	}
+	status.SubState, _ = unit["SubState"].(string)
+	code, ok := service["ExecMainCode"].(int32)
+	if !ok {
+		return nil, fmt.Errorf("unit %s has no ExecMainCode", name)
+	}
+	exitStatus, ok := service["ExecMainStatus"].(int32)
+	if !ok {
+		return nil, fmt.Errorf("unit %s has no ExecMainStatus", name)
+	}
+	status.ExecMainCode, status.ExecMainStatus = int(code), int(exitStatus)
+	return status, nil
+}
+
+// etcdSystemd runs etcd as a systemd service. systemd restarts it when it fails, as often
+// as etcd run directly is restarted.
+type etcdSystemd struct {
+	BinDir  string
+	cfg     *config.EtcdFlags
+	unitDir string
+	unit    string
+	conn    systemdConnection
+
+	mutex sync.Mutex
+	// startJob receives the result of the start job, it is nil once the job completed
+	startJob <-chan string
+	// exited is set once the exit of the unit was recorded
+	exited bool
+	exits  []ProcessExit
+}
+
+var _ Process = &etcdSystemd{}
+
+func newEtcdSystemd(binDir string, cfg *config.EtcdFlags, opts ProcessOptions, conn systemdConnection) *etcdSystemd {
+	unitDir := opts.UnitDir
+	if unitDir == "" {
+		unitDir = defaultUnitDir
+	}
+	return &etcdSystemd{
+		BinDir:  binDir,
+		cfg:     cfg,
+		unitDir: unitDir,
+		unit:    constants.Etcd + ".service",
+		conn:    conn,
+	}
+}
+
+func (p *etcdSystemd) Type() ProcessType {
+	return ProcessTypeSystemd
+}
+
+func (p *etcdSystemd) Start() error {
+	args, err := p.cfg.ToArgs()
+	if err != nil {
+		return err
+	}
+	if err := p.writeUnit(args); err != nil {
+		return err
+	}
+	if err := p.conn.Reload(); err != nil {
+		return fmt.Errorf("error reloading systemd units: %v", err)
+	}
+	// the start job completes once etcd notified systemd that it is ready, until then
+	// ExitState reports the unit as running
+	glog.Infof("starting unit %s", p.unit)
+	started := make(chan string, 1)
+	if _, err := p.conn.StartUnit(p.unit, "replace", started); err != nil {
+		return fmt.Errorf("error starting unit %s: %v", p.unit, err)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.startJob = started
+	p.exited = false
+	return nil
+}
+
+// writeUnit writes the unit file of etcd and the drop-in with its command line
+func (p *etcdSystemd) writeUnit(args []string) error {
+	binary := path.Join(p.BinDir, "etcd")
+	unit := fmt.Sprintf(`[Unit]
+Description=etcd member %s managed by etcd-discovery
+Documentation=https://github.com/coreos/etcd
+After=network-online.target
+Wants=network-online.target
+StartLimitIntervalSec=%d
+StartLimitBurst=%d
+
+[Service]
+Type=notify
+ExecStart=%s
+Restart=on-failure
+RestartSec=5
+LimitNOFILE=65536
+`, p.cfg.Name, int(crashLoopWindow.Seconds()), crashLoopRestarts, systemdQuote(binary))
+
+	var dropIn bytes.Buffer
+	dropIn.WriteString("[Service]\n")
+	// an empty ExecStart resets the command of the unit file
+	dropIn.WriteString("ExecStart=\n")
+	dropIn.WriteString("ExecStart=" + systemdQuote(binary))
+	for _, arg := range args {
+		dropIn.WriteString(" " + systemdQuote(arg))
+	}
+	dropIn.WriteString("\n")
+
+	dropInDir := filepath.Join(p.unitDir, p.unit+".d")
+	if err := os.MkdirAll(dropInDir, 0755); err != nil {
+		return fmt.Errorf("error creating drop-in directory of unit %s: %v", p.unit, err)
+	}
+	if err := fileutil.WriteFile(filepath.Join(p.unitDir, p.unit), []byte(unit), 0644); err != nil {
+		return fmt.Errorf("error writing unit %s: %v", p.unit, err)
+	}
+	// the flags may hold the locations of keys, but no secrets
+	if err := fileutil.WriteFile(filepath.Join(dropInDir, systemdDropIn), dropIn.Bytes(), 0644); err != nil {
+		return fmt.Errorf("error writing drop-in of unit %s: %v", p.unit, err)
+	}
+	return nil
+}
+
+// systemdQuote quotes an argument of a systemd command line
+func systemdQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$")
+	return `"` + r.Replace(s) + `"`
+}
+
+func (p *etcdSystemd) Stop() error {
+	glog.Infof("stopping unit %s", p.unit)
+	result := make(chan string, 1)
+	if _, err := p.conn.StopUnit(p.unit, "replace", result); err != nil {
+		return fmt.Errorf("error stopping unit %s: %v", p.unit, err)
+	}
+	select {
+	case r := <-result:
+		if r != "done" {
+			return fmt.Errorf("error stopping unit %s: job %s", p.unit, r)
+		}
+	case <-time.After(systemdStopTimeout):
+		return fmt.Errorf("unit %s did not stop within %v", p.unit, systemdStopTimeout)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if !p.exited {
+		p.exited = true
+		p.recordExit(ProcessExit{Time: time.Now(), ExitCode: -1, Message: "stopped", Expected: true})
+	}
+	return nil
+}
+
+// ExitState reports the unit as exited once systemd gave up restarting it, or its main
+// process exited successfully, from the ExecMainCode and ExecMainStatus of the unit.
+// Errors reading the state of the unit are logged and the unit is assumed to run.
+func (p *etcdSystemd) ExitState() (error, *os.ProcessState) {
+	p.mutex.Lock()
+	startJob := p.startJob
+	p.mutex.Unlock()
+	if startJob != nil {
+		select {
+		case result := <-startJob:
+			if result != "done" {
+				glog.Warningf("start job of unit %s is %s", p.unit, result)
+			}
+			p.mutex.Lock()
+			if p.startJob == startJob {
+				p.startJob = nil
+			}
+			p.mutex.Unlock()
+		default:
+			// the unit may still be inactive before the job runs
+			return nil, nil
+		}
+	}
+
+	status, err := readUnitStatus(p.conn, p.unit)
+	if err != nil {
+		glog.Warningf("error reading state of unit %s: %v", p.unit, err)
+		return nil, nil
+	}
+	if status.ActiveState != "failed" && status.ActiveState != "inactive" {
+		return nil, nil
+	}
+
+	code := status.ExecMainStatus
+	var message string
+	switch status.ExecMainCode {
+	case cldExited:
+		message = fmt.Sprintf("exit status %d", status.ExecMainStatus)
+	case cldKilled, cldDumped:
+		message = fmt.Sprintf("signal: %d", status.ExecMainStatus)
+		code = -1
+	default:
+		message = "did not run"
+		code = -1
+	}
+	exitError := fmt.Errorf("unit %s is %s (%s), etcd %s", p.unit, status.ActiveState, status.SubState, message)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if !p.exited {
+		p.exited = true
+		p.recordExit(ProcessExit{Time: time.Now(), ExitCode: code, Message: message})
+	}
+	return exitError, nil
+}
+
+func (p *etcdSystemd) Exits() []ProcessExit {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]ProcessExit(nil), p.exits...)
+}
+
+// recordExit adds exit to the history, p.mutex must be held
+func (p *etcdSystemd) recordExit(exit ProcessExit) {
+	p.exits = append(p.exits, exit)
+	if len(p.exits) > exitHistoryLength {
+		p.exits = p.exits[len(p.exits)-exitHistoryLength:]
+	}
+}
@@ -0,0 +1,148 @@
+package etcd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/etcd-manager/etcd-discovery/pkg/config"
+)
+
+// fakeSystemd is a fake D-Bus connection to systemd, it records the calls and returns the
+// properties of status for every unit. Jobs complete once complete is called.
+type fakeSystemd struct {
+	calls  []string
+	status unitStatus
+	jobs   []chan<- string
+}
+
+func (f *fakeSystemd) Reload() error {
+	f.calls = append(f.calls, "reload")
+	return nil
+}
+
+func (f *fakeSystemd) StartUnit(name string, mode string, ch chan<- string) (int, error) {
+	f.calls = append(f.calls, "start "+name)
+	f.status = unitStatus{ActiveState: "activating", SubState: "start"}
+	f.jobs = append(f.jobs, ch)
+	return len(f.jobs), nil
+}
+
+func (f *fakeSystemd) StopUnit(name string, mode string, ch chan<- string) (int, error) {
+	f.calls = append(f.calls, "stop "+name)
+	f.status = unitStatus{ActiveState: "inactive", SubState: "dead", ExecMainCode: cldExited}
+	// systemd sends the result once the process stopped
+	ch <- "done"
+	return len(f.jobs), nil
+}
+
+// complete completes the queued jobs with result
+func (f *fakeSystemd) complete(result string) {
+	for _, ch := range f.jobs {
+		if ch != nil {
+			ch <- result
+		}
+	}
+	f.jobs = nil
+}
+
+func (f *fakeSystemd) GetUnitProperties(unit string) (map[string]interface{}, error) {
+	return map[string]interface{}{"Id": unit, "ActiveState": f.status.ActiveState, "SubState": f.status.SubState}, nil
+}
+
+func (f *fakeSystemd) GetUnitTypeProperties(unit string, unitType string) (map[string]interface{}, error) {
+	if unitType != "Service" {
+		return nil, fmt.Errorf("unknown interface org.freedesktop.systemd1.%s", unitType)
+	}
+	// the exit of the main process has the D-Bus type int32
+	return map[string]interface{}{"ExecMainCode": int32(f.status.ExecMainCode), "ExecMainStatus": int32(f.status.ExecMainStatus)}, nil
+}
+
+func TestSystemd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-systemd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := config.NewEtcdFlags()
+	cfg.Version = "3.2.13"
+	cfg.Name = "a"
+	cfg.DataDir = "/var/lib/etcd data"
+	conn := &fakeSystemd{}
+	p := newEtcdSystemd("/opt/etcd-v3.2.13-linux-amd64", cfg, ProcessOptions{UnitDir: dir}, conn)
+
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"reload", "start etcd.service"}; !reflect.DeepEqual(conn.calls, expected) {
+		t.Errorf("expected calls %v, got %v", expected, conn.calls)
+	}
+	unit, err := ioutil.ReadFile(filepath.Join(dir, "etcd.service"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(unit), `ExecStart="/opt/etcd-v3.2.13-linux-amd64/etcd"`) || !strings.Contains(string(unit), "Type=notify") {
+		t.Errorf("unexpected unit:\n%s", unit)
+	}
+	dropIn, err := ioutil.ReadFile(filepath.Join(dir, "etcd.service.d", systemdDropIn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"ExecStart=\n", `"--name=a"`, `"--data-dir=/var/lib/etcd data"`} {
+		if !strings.Contains(string(dropIn), expected) {
+			t.Errorf("expected %q in drop-in:\n%s", expected, dropIn)
+		}
+	}
+
+	// the unit may be inactive until the start job runs
+	conn.status = unitStatus{ActiveState: "inactive", SubState: "dead"}
+	if exitError, _ := p.ExitState(); exitError != nil {
+		t.Errorf("expected a starting unit, got %v", exitError)
+	}
+	conn.status = unitStatus{ActiveState: "active", SubState: "running"}
+	conn.complete("done")
+	if exitError, _ := p.ExitState(); exitError != nil {
+		t.Errorf("expected a running unit, got %v", exitError)
+	}
+	// systemd restarting etcd is still running
+	conn.status = unitStatus{ActiveState: "activating", SubState: "auto-restart", ExecMainCode: cldExited, ExecMainStatus: 1}
+	if exitError, _ := p.ExitState(); exitError != nil {
+		t.Errorf("expected a restarting unit to run, got %v", exitError)
+	}
+
+	conn.status = unitStatus{ActiveState: "failed", SubState: "failed", ExecMainCode: cldExited, ExecMainStatus: 2}
+	exitError, _ := p.ExitState()
+	if exitError == nil || !strings.Contains(exitError.Error(), "exit status 2") {
+		t.Errorf("expected a failed unit, got %v", exitError)
+	}
+	p.ExitState()
+	if exits := p.Exits(); len(exits) != 1 || exits[0].ExitCode != 2 || exits[0].Expected {
+		t.Errorf("expected one exit with status 2, got %+v", exits)
+	}
+
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if exits := p.Exits(); len(exits) != 2 || !exits[1].Expected {
+		t.Errorf("expected an expected exit after Stop, got %+v", exits)
+	}
+}
+
+func TestReadUnitStatus(t *testing.T) {
+	conn := &fakeSystemd{status: unitStatus{ActiveState: "failed", SubState: "failed", ExecMainCode: cldKilled, ExecMainStatus: 9}}
+	status, err := readUnitStatus(conn, "etcd.service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *status != conn.status {
+		t.Errorf("expected %+v, got %+v", conn.status, *status)
+	}
+}
@@ -1,10 +1,77 @@
 package etcd
 
-import "os"
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/etcd-manager/etcd-discovery/pkg/config"
+)
+
+// ErrCrashLoop is the exit error of a process that exited too often to be restarted again
+var ErrCrashLoop = errors.New("etcd is crash looping")
 
 type Process interface {
 	Type() ProcessType
 	Start() error
 	Stop() error
+	// ExitState returns how the process exited once it is no longer restarted, nil while
+	// it runs or is about to be restarted
 	ExitState() (error, *os.ProcessState)
+	// Exits returns the last exits of the process, oldest first
+	Exits() []ProcessExit
+}
+
+// ProcessExit is an exit of the etcd process
+type ProcessExit struct {
+	Time time.Time
+	// ExitCode is -1 if the process was killed by a signal or could not be started
+	ExitCode int
+	Message  string
+	// Expected is true if the process was stopped on purpose
+	Expected bool
+}
+
+// ProcessOptions configures how etcd processes are run
+type ProcessOptions struct {
+	// ManifestDir is the directory watched by kubelet for static pod manifests
+	ManifestDir string
+	// UnitDir is the directory of the systemd unit of etcd
+	UnitDir string
+	// LogDir receives the output of etcd processes run directly, empty writes it to our own output
+	LogDir string
+	// LogMaxSize is the size in bytes at which log files are rotated, 0 disables rotation
+	LogMaxSize int64
+	// LogMaxFiles is how many log files are kept, including the current one
+	LogMaxFiles int
+	// StopTimeout is how long a stopped etcd process has to exit before it is killed
+	StopTimeout time.Duration
+	// StartTimeout is how long a static pod has to become ready after its manifest is written
+	StartTimeout time.Duration
+}
+
+// NewProcess returns a Process of the given type that runs etcd with the specified flags.
+func NewProcess(t ProcessType, cfg *config.EtcdFlags, opts ProcessOptions) (Process, error) {
+	switch t {
+	case ProcessTypeDirect:
+		binDir, err := BindirForEtcdVersion(string(cfg.Version), "etcd")
+		if err != nil {
+			return nil, err
+		}
+		return newEtcdDirect(binDir, cfg, opts), nil
+	case ProcessTypeStaticPod:
+		return newEtcdStaticPod(cfg, opts), nil
+	case ProcessTypeSystemd:
+		binDir, err := BindirForEtcdVersion(string(cfg.Version), "etcd")
+		if err != nil {
+			return nil, err
+		}
+		conn, err := connectSystemd()
+		if err != nil {
+			return nil, err
+		}
+		return newEtcdSystemd(binDir, cfg, opts, conn), nil
+	}
+	return nil, fmt.Errorf("unknown process type %v", t)
 }
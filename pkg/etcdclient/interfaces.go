@@ -2,6 +2,7 @@ package etcdclient
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"strings"
@@ -21,6 +22,8 @@ type EtcdClient interface {
 	Get(ctx context.Context, key string, quorum bool) ([]byte, error)
 
 	CopyTo(ctx context.Context, dest EtcdClient) (int, error)
+	// KeyCount returns the number of keys, directories of V2 are not counted
+	KeyCount(ctx context.Context) (int, error)
 	ListMembers(ctx context.Context) ([]*EtcdProcessMember, error)
 	AddMember(ctx context.Context, peerURLs []string) error
 	RemoveMember(ctx context.Context, member *EtcdProcessMember) error
@@ -41,14 +44,24 @@ type EtcdClient interface {
 // LocalNodeInfo has information about the etcd member node we are connected to
 type LocalNodeInfo struct {
 	IsLeader bool
+	// Revision is the revision of the key-value store, only set in V3
+	Revision int64
+	// DBSize, RaftIndex and RaftTerm describe the state of the member, only set in V3
+	DBSize    int64
+	RaftIndex uint64
+	RaftTerm  uint64
+	// Alarms are the alarms raised by the member, like NOSPACE, only set in V3
+	Alarms []string
 }
 
-func NewClient(etcdVersion string, clientURLs []string) (EtcdClient, error) {
+// NewClient returns a client of the etcd API of etcdVersion on clientURLs. tlsConfig secures
+// https urls, nil uses the default TLS config.
+func NewClient(etcdVersion string, clientURLs []string, tlsConfig *tls.Config) (EtcdClient, error) {
 	if IsV2(etcdVersion) {
-		return NewV2Client(clientURLs)
+		return NewV2Client(clientURLs, tlsConfig)
 	}
 	if IsV3(etcdVersion) {
-		return NewV3Client(clientURLs)
+		return NewV3Client(clientURLs, tlsConfig)
 	}
 	return nil, fmt.Errorf("unhandled etcd version %q", etcdVersion)
 }
@@ -65,13 +78,13 @@ func IsV3(etcdVersion string) bool {
 
 // ServerVersion attempts to find the version of etcd
 // If you already have a client, prefer calling ServerVersion on that
-func ServerVersion(ctx context.Context, endpoints []string) (string, error) {
+func ServerVersion(ctx context.Context, endpoints []string, tlsConfig *tls.Config) (string, error) {
 	if len(endpoints) == 0 {
 		return "", fmt.Errorf("no endpoints provided")
 	}
 	cfg := etcd_client_v2.Config{
 		Endpoints:               endpoints,
-		Transport:               etcd_client_v2.DefaultTransport,
+		Transport:               newTransport(tlsConfig),
 		HeaderTimeoutPerRequest: 10 * time.Second,
 	}
 	etcdClient, err := etcd_client_v2.New(cfg)
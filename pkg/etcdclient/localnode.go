@@ -63,10 +63,25 @@ func (c *V3Client) LocalNodeInfo(ctx context.Context) (*LocalNodeInfo, error) {
 		if err != nil {
 			glog.Warningf("unable to get status from %q: %v", endpoint, err)
 			lastErr = err
+			continue
 		}
-		return &LocalNodeInfo{
-			IsLeader: response.Header.MemberId == response.Leader,
-		}, nil
+		info := &LocalNodeInfo{
+			IsLeader:  response.Header.MemberId == response.Leader,
+			Revision:  response.Header.Revision,
+			DBSize:    response.DbSize,
+			RaftIndex: response.RaftIndex,
+			RaftTerm:  response.RaftTerm,
+		}
+		alarms, err := c.client.AlarmList(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, alarm := range alarms.Alarms {
+			if alarm.MemberID == response.Header.MemberId {
+				info.Alarms = append(info.Alarms, alarm.Alarm.String())
+			}
+		}
+		return info, nil
 	}
 	return nil, lastErr
 }
@@ -1,6 +1,8 @@
 package etcdclient
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 )
@@ -12,6 +14,8 @@ type EtcdProcessMember struct {
 	ClientURLs []string `json:"endpoints,omitempty"`
 
 	etcdVersion string
+	// tlsConfig is the one of the client that listed the member
+	tlsConfig *tls.Config
 
 	ID   string
 	idv2 string
@@ -19,7 +23,12 @@ type EtcdProcessMember struct {
 }
 
 func (m *EtcdProcessMember) NewClient() (EtcdClient, error) {
-	return NewClient(m.etcdVersion, m.ClientURLs)
+	return NewClient(m.etcdVersion, m.ClientURLs, m.tlsConfig)
+}
+
+// ServerVersion returns the version of etcd run by the member
+func (m *EtcdProcessMember) ServerVersion(ctx context.Context) (string, error) {
+	return ServerVersion(ctx, m.ClientURLs, m.tlsConfig)
 }
 
 func (m *EtcdProcessMember) String() string {
@@ -0,0 +1,49 @@
+package etcdclient
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+
+	etcd_client_v2 "github.com/coreos/etcd/client"
+	"github.com/coreos/etcd/pkg/transport"
+)
+
+// TLSFiles locates the pem encoded files with which a client verifies etcd and
+// authenticates to it, for etcd running with client-cert-auth
+type TLSFiles struct {
+	CACertFile string
+	CertFile   string
+	KeyFile    string
+}
+
+// ClientConfig returns the TLS config of a client, nil if no file is set. The certificate
+// is read again for every connection, renewed certificates are used once written.
+func (f TLSFiles) ClientConfig() (*tls.Config, error) {
+	if f == (TLSFiles{}) {
+		return nil, nil
+	}
+	info := transport.TLSInfo{
+		CertFile:      f.CertFile,
+		KeyFile:       f.KeyFile,
+		TrustedCAFile: f.CACertFile,
+	}
+	return info.ClientConfig()
+}
+
+// newTransport returns the transport of the v2 clients, the default one without TLS config
+func newTransport(tlsConfig *tls.Config) etcd_client_v2.CancelableTransport {
+	if tlsConfig == nil {
+		return etcd_client_v2.DefaultTransport
+	}
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		Dial: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).Dial,
+		TLSHandshakeTimeout: 10 * time.Second,
+		TLSClientConfig:     tlsConfig,
+	}
+}
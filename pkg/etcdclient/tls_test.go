@@ -0,0 +1,80 @@
+package etcdclient_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/etcd-manager/etcd-discovery/pkg/etcdclient"
+	"github.com/etcd-manager/etcd-discovery/pkg/pki"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+func TestClientCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcdclient")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca, err := pki.NewCA("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := pki.NewRequest("a", certutil.AltNames{IPs: []net.IP{net.ParseIP("127.0.0.1")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	certs, err := req.Sign(ca)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := pki.Files{Dir: dir}
+	if err := files.Write(req, ca.BundlePEM(), certs); err != nil {
+		t.Fatal(err)
+	}
+
+	// etcd running with client-cert-auth
+	serverCert, err := tls.LoadX509KeyPair(files.Cert(pki.ProfileServer), files.Key(pki.ProfileServer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"etcdserver":"3.2.18","etcdcluster":"3.2.0"}`))
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	tlsConfig, err := etcdclient.TLSFiles{
+		CACertFile: files.CACert(),
+		CertFile:   files.Cert(pki.ProfileHealthcheckClient),
+		KeyFile:    files.Key(pki.ProfileHealthcheckClient),
+	}.ClientConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	version, err := etcdclient.ServerVersion(context.Background(), []string{server.URL}, tlsConfig)
+	if err != nil || version != "3.2.18" {
+		t.Errorf("expected version 3.2.18 with the client certificate, got %q, %v", version, err)
+	}
+
+	if tlsConfig, err := (etcdclient.TLSFiles{}).ClientConfig(); err != nil || tlsConfig != nil {
+		t.Errorf("expected no TLS config without files, got %v, %v", tlsConfig, err)
+	}
+	if _, err := etcdclient.ServerVersion(context.Background(), []string{server.URL}, nil); err == nil {
+		t.Error("expected a client without the certificates of the cluster to fail")
+	}
+}
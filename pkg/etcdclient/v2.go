@@ -2,7 +2,9 @@ package etcdclient
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
@@ -13,6 +15,8 @@ import (
 // V2Client is a client for the etcd v2 API, implementing EtcdClient
 type V2Client struct {
 	clientUrls []string
+	tlsConfig  *tls.Config
+	transport  etcd_client_v2.CancelableTransport
 	client     etcd_client_v2.Client
 	keys       etcd_client_v2.KeysAPI
 	members    etcd_client_v2.MembersAPI
@@ -20,13 +24,14 @@ type V2Client struct {
 
 var _ EtcdClient = &V2Client{}
 
-func NewV2Client(clientUrls []string) (EtcdClient, error) {
+func NewV2Client(clientUrls []string, tlsConfig *tls.Config) (EtcdClient, error) {
 	if len(clientUrls) == 0 {
 		return nil, fmt.Errorf("no endpoints provided")
 	}
+	transport := newTransport(tlsConfig)
 	cfg := etcd_client_v2.Config{
 		Endpoints:               clientUrls,
-		Transport:               etcd_client_v2.DefaultTransport,
+		Transport:               transport,
 		HeaderTimeoutPerRequest: 10 * time.Second,
 	}
 	etcdClient, err := etcd_client_v2.New(cfg)
@@ -38,6 +43,8 @@ func NewV2Client(clientUrls []string) (EtcdClient, error) {
 
 	return &V2Client{
 		clientUrls: clientUrls,
+		tlsConfig:  tlsConfig,
+		transport:  transport,
 		client:     etcdClient,
 		keys:       keysAPI,
 		members:    etcd_client_v2.NewMembersAPI(etcdClient),
@@ -49,7 +56,10 @@ func (c *V2Client) String() string {
 }
 
 func (c *V2Client) Close() error {
-	// Nothing to close
+	// the default transport is shared, only the connections of our own are closed
+	if t, ok := c.transport.(*http.Transport); ok && c.transport != etcd_client_v2.DefaultTransport {
+		t.CloseIdleConnections()
+	}
 	return nil
 }
 
@@ -109,6 +119,7 @@ func (c *V2Client) ListMembers(ctx context.Context) ([]*EtcdProcessMember, error
 			idv2:        m.ID,
 			Name:        m.Name,
 			etcdVersion: "2.x",
+			tlsConfig:   c.tlsConfig,
 		})
 	}
 	return members, nil
@@ -172,6 +183,32 @@ func (c *V2Client) copySubtree(ctx context.Context, p string, dest EtcdClient) (
 	return count, nil
 }
 
+func (c *V2Client) KeyCount(ctx context.Context) (int, error) {
+	return c.countSubtree(ctx, "/")
+}
+
+func (c *V2Client) countSubtree(ctx context.Context, p string) (int, error) {
+	response, err := c.keys.Get(ctx, p, &etcd_client_v2.GetOptions{Quorum: true})
+	if err != nil {
+		return 0, fmt.Errorf("error reading %q: %v", p, err)
+	}
+	if response.Node == nil {
+		return 0, fmt.Errorf("node %q not found", p)
+	}
+	if !response.Node.Dir {
+		return 1, nil
+	}
+	count := 0
+	for _, n := range response.Node.Nodes {
+		subCount, err := c.countSubtree(ctx, n.Key)
+		if err != nil {
+			return count, err
+		}
+		count += subCount
+	}
+	return count, nil
+}
+
 func (c *V2Client) SnapshotSave(ctx context.Context, path string) error {
 	return fmt.Errorf("SnapshotSave is not supported in V2")
 }
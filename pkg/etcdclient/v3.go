@@ -3,6 +3,7 @@ package etcdclient
 import (
 	"compress/gzip"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -20,6 +21,7 @@ import (
 
 type V3Client struct {
 	endpoints []string
+	tlsConfig *tls.Config
 	client    *etcd_client_v3.Client
 	kv        etcd_client_v3.KV
 	cluster   etcd_client_v3.Cluster
@@ -27,13 +29,14 @@ type V3Client struct {
 
 var _ EtcdClient = &V3Client{}
 
-func NewV3Client(endpoints []string) (EtcdClient, error) {
+func NewV3Client(endpoints []string, tlsConfig *tls.Config) (EtcdClient, error) {
 	if len(endpoints) == 0 {
 		return nil, fmt.Errorf("no endpoints provided")
 	}
 	cfg := etcd_client_v3.Config{
 		Endpoints:   endpoints,
 		DialTimeout: 10 * time.Second,
+		TLS:         tlsConfig,
 	}
 	etcdClient, err := etcd_client_v3.New(cfg)
 	if err != nil {
@@ -43,6 +46,7 @@ func NewV3Client(endpoints []string) (EtcdClient, error) {
 	kv := etcd_client_v3.NewKV(etcdClient)
 	return &V3Client{
 		endpoints: endpoints,
+		tlsConfig: tlsConfig,
 		client:    etcdClient,
 		kv:        kv,
 		cluster:   etcd_client_v3.NewCluster(etcdClient),
@@ -166,6 +170,14 @@ func (c *V3Client) CopyTo(ctx context.Context, dest EtcdClient) (int, error) {
 	return count, nil
 }
 
+func (c *V3Client) KeyCount(ctx context.Context) (int, error) {
+	response, err := c.kv.Get(ctx, "\x00", etcd_client_v3.WithFromKey(), etcd_client_v3.WithCountOnly())
+	if err != nil {
+		return 0, err
+	}
+	return int(response.Count), nil
+}
+
 func (c *V3Client) ListMembers(ctx context.Context) ([]*EtcdProcessMember, error) {
 	response, err := c.cluster.MemberList(ctx)
 	if err != nil {
@@ -180,6 +192,7 @@ func (c *V3Client) ListMembers(ctx context.Context) ([]*EtcdProcessMember, error
 			idv3:        m.ID,
 			Name:        m.Name,
 			etcdVersion: "3.x",
+			tlsConfig:   c.tlsConfig,
 		})
 	}
 	return members, nil
@@ -0,0 +1,87 @@
+package fileutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// File is the content of a file written by WriteFiles
+type File struct {
+	Name string
+	Data []byte
+	Perm os.FileMode
+}
+
+// WriteFile writes data to a hidden temporary file next to filename and renames it into
+// place, so that readers of filename see either the old or the new content. Hidden files
+// are ignored by kubelet and systemd.
+func WriteFile(filename string, data []byte, perm os.FileMode) error {
+	return WriteFiles(File{Name: filename, Data: data, Perm: perm})
+}
+
+// WriteFiles writes every file to a temporary file first, then replaces the files in order.
+// Readers see either the old or the new version of each file. The files and their
+// directories are synced, so they survive a crash of the host once WriteFiles returns.
+func WriteFiles(files ...File) error {
+	var tmps []string
+	defer func() {
+		for _, tmp := range tmps {
+			os.Remove(tmp)
+		}
+	}()
+	for _, f := range files {
+		tmp, err := writeTemp(f)
+		if tmp != "" {
+			tmps = append(tmps, tmp)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	dirs := map[string]bool{}
+	for i, f := range files {
+		if err := os.Rename(tmps[i], f.Name); err != nil {
+			return err
+		}
+		dirs[filepath.Dir(f.Name)] = true
+	}
+	for dir := range dirs {
+		if err := syncDir(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTemp writes f to a hidden temporary file next to it and returns its name
+func writeTemp(f File) (string, error) {
+	tmp, err := ioutil.TempFile(filepath.Dir(f.Name), "."+filepath.Base(f.Name)+".")
+	if err != nil {
+		return "", err
+	}
+	_, err = tmp.Write(f.Data)
+	if err == nil {
+		err = tmp.Chmod(f.Perm)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	return tmp.Name(), err
+}
+
+// syncDir syncs the entries of dir, the renames into it
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if closeErr := d.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
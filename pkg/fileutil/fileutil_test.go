@@ -0,0 +1,43 @@
+package fileutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fileutil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	key, cert := filepath.Join(dir, "a.key"), filepath.Join(dir, "a.crt")
+	if err := WriteFile(cert, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFiles(File{Name: key, Data: []byte("key"), Perm: 0600}, File{Name: cert, Data: []byte("cert"), Perm: 0644}); err != nil {
+		t.Fatal(err)
+	}
+	for name, expected := range map[string]string{key: "key", cert: "cert"} {
+		if data, err := ioutil.ReadFile(name); err != nil || string(data) != expected {
+			t.Errorf("expected %q in %s, got %q, %v", expected, name, data, err)
+		}
+	}
+	if stat, err := os.Stat(key); err != nil || stat.Mode().Perm() != 0600 {
+		t.Errorf("expected the key to be private, got %v, %v", stat.Mode(), err)
+	}
+	if entries, err := ioutil.ReadDir(dir); err != nil || len(entries) != 2 {
+		t.Errorf("expected no temporary files to be left, got %d entries, %v", len(entries), err)
+	}
+
+	// a missing directory fails before any file is replaced
+	if err := WriteFiles(File{Name: cert, Data: []byte("new"), Perm: 0644}, File{Name: filepath.Join(dir, "missing", "b"), Perm: 0644}); err == nil {
+		t.Error("expected a file in a missing directory to fail")
+	}
+	if data, _ := ioutil.ReadFile(cert); string(data) != "cert" {
+		t.Errorf("expected the files to be kept, got %q", data)
+	}
+}
@@ -0,0 +1,58 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/golang/glog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HandleBackup lists the backups in the backup store. Backups requested by operators are
+// taken by the leader first, the schedule of the backups is not changed.
+func (m *EtcdManager) HandleBackup(ctx context.Context, req *api.BackupRequest) (*api.BackupResponse, error) {
+	resp := &api.BackupResponse{}
+	if req.Take {
+		if leader, _ := m.election.Leader(); leader != m.config.ID {
+			return &api.BackupResponse{Reason: fmt.Sprintf("peer %s is not the leader, ask %q", m.config.ID, leader)}, nil
+		}
+		m.mutex.Lock()
+		flags := m.flags
+		m.mutex.Unlock()
+		if flags == nil || !m.isRunning() {
+			return &api.BackupResponse{Reason: fmt.Sprintf("etcd is not running on leader %s", m.config.ID)}, nil
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, backupTimeout)
+		defer cancel()
+		client, err := flags.NewClient()
+		if err != nil {
+			return nil, err
+		}
+		defer client.Close()
+		glog.Infof("backing up cluster %s for an operator", m.config.ClusterName)
+		if resp.Name, err = m.backups.Backup(ctx, client, flags.InitialClusterToken); err != nil {
+			return nil, fmt.Errorf("error backing up cluster %s: %v", m.config.ClusterName, err)
+		}
+	}
+
+	store := m.backups.Store()
+	names, err := store.ListBackups()
+	if err != nil {
+		return nil, fmt.Errorf("error listing backups: %v", err)
+	}
+	for _, name := range names {
+		manifest, err := store.LoadManifest(name)
+		if err != nil {
+			return nil, fmt.Errorf("error loading manifest of backup %s: %v", name, err)
+		}
+		resp.Backups = append(resp.Backups, api.BackupInfo{
+			Name:        name,
+			Revision:    manifest.Revision,
+			EtcdVersion: manifest.EtcdVersion,
+			Timestamp:   metav1.NewTime(manifest.Timestamp),
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,199 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/config"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"github.com/golang/glog"
+)
+
+// newPlan picks the members of a new cluster of size peers: ourselves, followed by
+// the other peers with the lowest ids. Members are listed as name=url.
+func newPlan(self api.PeerID, peers map[api.PeerID]*discovery.Peer, size int) ([]string, error) {
+	if _, ok := peers[self]; !ok {
+		return nil, fmt.Errorf("peer %s is not in the list of peers", self)
+	}
+	if len(peers) < size {
+		return nil, fmt.Errorf("found %d of %d peers", len(peers), size)
+	}
+
+	ids := []string{string(self)}
+	var others []string
+	for id := range peers {
+		if id != self {
+			others = append(others, string(id))
+		}
+	}
+	sort.Strings(others)
+	ids = append(ids, others[:size-1]...)
+
+	var cluster []string
+	for _, id := range ids {
+		host := peerHost(peers[api.PeerID(id)])
+		if host == "" {
+			return nil, fmt.Errorf("peer %s has no address", id)
+		}
+		cluster = append(cluster, id+"=https://"+net.JoinHostPort(host, strconv.Itoa(config.PeerPort)))
+	}
+	return cluster, nil
+}
+
+// peerHost returns the address etcd of peer p advertises to its peers
+func peerHost(p *discovery.Peer) string {
+	if len(p.Hosts) > 0 {
+		return p.Hosts[0]
+	}
+	host, _, err := net.SplitHostPort(p.Address)
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
+// bootstrap forms a new cluster once ClusterSize peers are found. The leader pushes
+// the plan to every member and starts etcd when all of them accepted it.
+func (m *EtcdManager) bootstrap(peers map[api.PeerID]*discovery.Peer, term int64) error {
+	size := m.clusterSize()
+	if len(peers) < size {
+		glog.Infof("found %d of %d peers of cluster %s, waiting for the others", len(peers), size, m.config.ClusterName)
+		return nil
+	}
+	// retry the plan of this term, some members may have accepted it already
+	m.mutex.Lock()
+	plan := m.proposal
+	m.mutex.Unlock()
+	if plan == nil || plan.Term != term {
+		cluster, err := newPlan(m.config.ID, peers, size)
+		if err != nil {
+			return err
+		}
+		plan = &api.PlanRequest{
+			Leader:         string(m.config.ID),
+			Term:           term,
+			ClusterName:    m.config.ClusterName,
+			ClusterToken:   m.clusterToken(),
+			InitialCluster: cluster,
+			EtcdVersion:    string(m.clusterVersion()),
+		}
+		if m.restorePending() {
+			if err := m.planRestore(plan); err != nil {
+				return err
+			}
+		}
+		m.mutex.Lock()
+		m.proposal = plan
+		m.mutex.Unlock()
+	}
+
+	members, err := parseInitialCluster(plan.InitialCluster)
+	if err != nil {
+		return err
+	}
+	for name := range members {
+		if name == string(m.config.ID) {
+			continue
+		}
+		peer, ok := peers[api.PeerID(name)]
+		if !ok {
+			return fmt.Errorf("member %s of the plan of cluster %s is not reachable", name, m.config.ClusterName)
+		}
+		if err := m.pushPlan(peer, plan); err != nil {
+			return fmt.Errorf("error pushing plan of cluster %s to %s: %v", m.config.ClusterName, name, err)
+		}
+	}
+
+	m.mutex.Lock()
+	m.plan = plan
+	m.mutex.Unlock()
+
+	glog.Infof("forming new etcd cluster %s with %v", m.config.ClusterName, plan.InitialCluster)
+	return m.startFromPlan(plan)
+}
+
+func (m *EtcdManager) pushPlan(peer *discovery.Peer, plan *api.PlanRequest) error {
+	client, err := m.newPeerClient(peer.Address)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Plans().Create(&api.Plan{Request: plan})
+	if err != nil {
+		return err
+	}
+	if resp.Response == nil || !resp.Response.Accepted {
+		reason := "no response"
+		if resp.Response != nil {
+			reason = resp.Response.Reason
+		}
+		return fmt.Errorf("plan rejected: %s", reason)
+	}
+	return nil
+}
+
+// AcceptPlan stores the bootstrap plan pushed by the leader. The plan is used
+// by the next reconcile to start etcd with the same flags as the other members.
+func (m *EtcdManager) AcceptPlan(ctx context.Context, plan *api.PlanRequest) (*api.PlanResponse, error) {
+	reject := func(format string, args ...interface{}) (*api.PlanResponse, error) {
+		reason := fmt.Sprintf(format, args...)
+		glog.Warningf("rejecting plan from %s: %s", plan.Leader, reason)
+		return &api.PlanResponse{Reason: reason}, nil
+	}
+
+	if leader, term := m.election.Leader(); string(leader) != plan.Leader || term != plan.Term {
+		return reject("leader for term %d is %q", term, leader)
+	}
+	if plan.ClusterName != m.config.ClusterName {
+		return reject("peer %s is a member of cluster %s", m.config.ID, m.config.ClusterName)
+	}
+	restoring := m.restorePending()
+	if restoring && plan.Backup == "" {
+		return reject("peer %s waits for a restore of cluster %s", m.config.ID, m.config.ClusterName)
+	}
+	// the data of a member waiting for a restore is replaced by the backup
+	if m.isRunning() || (m.hasData() && !(restoring && plan.Backup != "")) {
+		return reject("peer %s already started etcd", m.config.ID)
+	}
+	members, err := parseInitialCluster(plan.InitialCluster)
+	if err != nil {
+		return reject("%v", err)
+	}
+	if _, ok := members[string(m.config.ID)]; !ok {
+		return reject("peer %s is not part of the plan", m.config.ID)
+	}
+	if plan.Backup != "" {
+		if err := m.checkRestorePlan(plan); err != nil {
+			return reject("%v", err)
+		}
+	}
+
+	m.mutex.Lock()
+	m.plan = plan.DeepCopy()
+	m.mutex.Unlock()
+	glog.Infof("accepted plan of cluster %s from leader %s for term %d", plan.ClusterName, plan.Leader, plan.Term)
+	return &api.PlanResponse{Accepted: true}, nil
+}
+
+// startFromPlan starts etcd with the plan made by the leader, restoring the backup of the plan first
+func (m *EtcdManager) startFromPlan(plan *api.PlanRequest) error {
+	members, err := parseInitialCluster(plan.InitialCluster)
+	if err != nil {
+		return err
+	}
+	if plan.EtcdVersion != "" {
+		if err := m.setClusterVersion(config.EtcdVersion(plan.EtcdVersion)); err != nil {
+			return err
+		}
+	}
+	if plan.Backup != "" {
+		if err := m.restoreData(plan, m.newEtcdFlags(config.ClusterStateNew, plan.ClusterToken, members)); err != nil {
+			return fmt.Errorf("error restoring backup %s: %v", plan.Backup, err)
+		}
+	}
+	glog.Infof("starting etcd member %s of new cluster %s planned by %s", m.config.ID, plan.ClusterName, plan.Leader)
+	return m.startEtcd(config.ClusterStateNew, plan.ClusterToken, members)
+}
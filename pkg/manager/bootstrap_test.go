@@ -0,0 +1,181 @@
+package manager
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/backup"
+	"github.com/etcd-manager/etcd-discovery/pkg/config"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func testPeers() map[api.PeerID]*discovery.Peer {
+	return map[api.PeerID]*discovery.Peer{
+		"a": {ID: "a", Address: "10.0.0.1:2381", Hosts: []string{"10.0.0.1"}},
+		"b": {ID: "b", Address: "10.0.0.2:2381"},
+		"c": {ID: "c", Address: "10.0.0.3:2381", Hosts: []string{"10.0.0.3"}},
+		"d": {ID: "d", Address: "10.0.0.4:2381", Hosts: []string{"10.0.0.4"}},
+	}
+}
+
+func TestNewPlan(t *testing.T) {
+	cluster, err := newPlan("c", testPeers(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"c=https://10.0.0.3:2380",
+		"a=https://10.0.0.1:2380",
+		"b=https://10.0.0.2:2380",
+	}
+	if !reflect.DeepEqual(cluster, expected) {
+		t.Errorf("expected %v, got %v", expected, cluster)
+	}
+
+	if _, err := newPlan("a", testPeers(), 5); err == nil {
+		t.Errorf("expected error planning 5 members with 4 peers")
+	}
+}
+
+func TestAcceptPlan(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// b follows a, which was elected leader for term 1
+	election := discovery.NewElection("b", 3, time.Minute, clock.NewFakeClock(time.Now()))
+	resp := &api.PingResponse{}
+	election.HandlePing(&api.PingRequest{Info: &api.PeerInfo{ID: "a"}, Leader: "a", Term: 1}, resp)
+	if !resp.LeaseGranted {
+		t.Fatalf("b did not grant the lease to a")
+	}
+
+	m := &EtcdManager{
+		config: &EtcdConfig{
+			EtcdCluster: config.EtcdCluster{ClusterName: "test", ClusterSize: 3, DataDir: dir},
+			ID:          "b",
+		},
+		election: election,
+	}
+	plan := &api.PlanRequest{
+		Leader:         "a",
+		Term:           1,
+		ClusterName:    "test",
+		ClusterToken:   "token",
+		InitialCluster: []string{"a=https://10.0.0.1:2380", "b=https://10.0.0.2:2380", "c=https://10.0.0.3:2380"},
+	}
+
+	cases := []struct {
+		name     string
+		modify   func(p *api.PlanRequest)
+		accepted bool
+	}{
+		{"not the leader", func(p *api.PlanRequest) { p.Leader = "c" }, false},
+		{"old term", func(p *api.PlanRequest) { p.Term = 0 }, false},
+		{"other cluster", func(p *api.PlanRequest) { p.ClusterName = "other" }, false},
+		{"not a member", func(p *api.PlanRequest) { p.InitialCluster = p.InitialCluster[:1] }, false},
+		{"valid", func(p *api.PlanRequest) {}, true},
+	}
+	for _, c := range cases {
+		p := plan.DeepCopy()
+		c.modify(p)
+		resp, err := m.AcceptPlan(context.Background(), p)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if resp.Accepted != c.accepted {
+			t.Errorf("%s: expected accepted=%v, got %+v", c.name, c.accepted, resp)
+		}
+	}
+	if !reflect.DeepEqual(m.plan, plan) {
+		t.Errorf("expected accepted plan %+v, got %+v", plan, m.plan)
+	}
+}
+
+func TestAcceptRestorePlan(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := backup.NewFileStore(filepath.Join(dir, "backups"))
+	snapshot := filepath.Join(dir, "snapshot")
+	if err := ioutil.WriteFile(snapshot, []byte("snapshot"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	name := backup.NewName(time.Now())
+	if err := store.AddBackup(name, snapshot, &backup.Manifest{Revision: 42}); err != nil {
+		t.Fatal(err)
+	}
+	// b has data from a lost cluster
+	dataDir := filepath.Join(dir, "data")
+	if err := os.MkdirAll(filepath.Join(dataDir, "etcd", "member"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	election := discovery.NewElection("b", 3, time.Minute, clock.NewFakeClock(time.Now()))
+	election.HandlePing(&api.PingRequest{Info: &api.PeerInfo{ID: "a"}, Leader: "a", Term: 1}, &api.PingResponse{})
+	m := &EtcdManager{
+		config: &EtcdConfig{
+			EtcdCluster: config.EtcdCluster{ClusterName: "test", ClusterSize: 3, DataDir: dataDir},
+			ID:          "b",
+		},
+		election: election,
+		backups:  backup.NewController(store, 0, backup.RetentionPolicy{}, clock.RealClock{}),
+	}
+	plan := &api.PlanRequest{
+		Leader:         "a",
+		Term:           1,
+		ClusterName:    "test",
+		ClusterToken:   "token-" + name,
+		InitialCluster: []string{"a=https://10.0.0.1:2380", "b=https://10.0.0.2:2380", "c=https://10.0.0.3:2380"},
+		Backup:         name,
+		Revision:       42,
+	}
+
+	cases := []struct {
+		name          string
+		restoreBackup string
+		modify        func(p *api.PlanRequest)
+		accepted      bool
+	}{
+		{"not restoring", "", func(p *api.PlanRequest) {}, false},
+		{"empty cluster", backup.Latest, func(p *api.PlanRequest) { p.Backup, p.Revision = "", 0 }, false},
+		{"other revision", backup.Latest, func(p *api.PlanRequest) { p.Revision = 41 }, false},
+		{"missing backup", backup.Latest, func(p *api.PlanRequest) { p.Backup = backup.NewName(time.Time{}) }, false},
+		{"valid", backup.Latest, func(p *api.PlanRequest) {}, true},
+	}
+	for _, c := range cases {
+		m.config.RestoreBackup = c.restoreBackup
+		p := plan.DeepCopy()
+		c.modify(p)
+		resp, err := m.AcceptPlan(context.Background(), p)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if resp.Accepted != c.accepted {
+			t.Errorf("%s: expected accepted=%v, got %+v", c.name, c.accepted, resp)
+		}
+	}
+
+	// once restored, the member keeps its data
+	if err := ioutil.WriteFile(filepath.Join(dataDir, restoreMarker), []byte(name), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if m.restorePending() {
+		t.Errorf("expected no pending restore after the member was restored")
+	}
+	if resp, err := m.AcceptPlan(context.Background(), plan.DeepCopy()); err != nil || resp.Accepted {
+		t.Errorf("expected a restored member to reject another restore, got %+v, %v", resp, err)
+	}
+}
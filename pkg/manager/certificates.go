@@ -0,0 +1,119 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/authz"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"github.com/etcd-manager/etcd-discovery/pkg/etcd"
+	"github.com/etcd-manager/etcd-discovery/pkg/pki"
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/sets"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+)
+
+// HandleCertificate signs the certificate requests of a peer with the cluster CA, which
+// the leader loads from the backup store. The peer trusts the CAs of the bundle, more
+// than one while the CA is rotated. Other peers name the leader in the reason, and the
+// term tells new peers whether the cluster has a leader yet.
+func (m *EtcdManager) HandleCertificate(ctx context.Context, req *api.CertificateRequest) (*api.CertificateResponse, error) {
+	leader, term := m.election.Leader()
+	if leader != m.config.ID {
+		return &api.CertificateResponse{Term: term, Reason: fmt.Sprintf("peer %s is not the leader, ask %q", m.config.ID, leader)}, nil
+	}
+	id, hosts, err := m.certificateSubject(ctx, req)
+	if err != nil {
+		return &api.CertificateResponse{Term: term, Reason: err.Error()}, nil
+	}
+
+	ca, err := pki.LoadCA(m.backups.Store())
+	if err != nil {
+		return nil, fmt.Errorf("error loading the cluster CA: %v", err)
+	}
+	csrs := map[pki.Profile]string{
+		pki.ProfilePeer:              req.PeerCSR,
+		pki.ProfileServer:            req.ServerCSR,
+		pki.ProfileHealthcheckClient: req.HealthcheckClientCSR,
+	}
+	certs := map[pki.Profile]string{}
+	for profile, csr := range csrs {
+		cert, err := ca.Sign([]byte(csr), profile, string(id), hosts)
+		if err != nil {
+			return &api.CertificateResponse{Term: term, Reason: err.Error()}, nil
+		}
+		certs[profile] = string(cert)
+	}
+	glog.Infof("issued certificates of peer %s for %v", id, hosts)
+
+	return &api.CertificateResponse{
+		ID:                    string(id),
+		CACert:                string(ca.BundlePEM()),
+		PeerCert:              certs[pki.ProfilePeer],
+		ServerCert:            certs[pki.ProfileServer],
+		HealthcheckClientCert: certs[pki.ProfileHealthcheckClient],
+		Term:                  term,
+	}, nil
+}
+
+// certificateSubject returns the id the certificates of a request are issued to and the
+// hosts they may name. Peers keep the id of their certificate, a new peer gets the id of
+// the peer discovered at its address. Other new peers keep the id they ask for unless it
+// is the id of another peer, or get a new one. The certificates may name the address the
+// request was sent from, the hosts of the discovered peer and the loopback addresses.
+func (m *EtcdManager) certificateSubject(ctx context.Context, req *api.CertificateRequest) (api.PeerID, []string, error) {
+	source, ok := authz.SourceFrom(ctx)
+	if !ok {
+		return "", nil, fmt.Errorf("the address of the request is not known")
+	}
+	u, ok := apirequest.UserFrom(ctx)
+	if !ok {
+		return "", nil, fmt.Errorf("the request is not authenticated")
+	}
+	m.mutex.Lock()
+	peers := m.peers
+	m.mutex.Unlock()
+
+	id := api.PeerID(req.ID)
+	if authz.IsPeer(u) {
+		id = api.PeerID(u.GetName())
+	} else if peer := peerAt(peers, source); peer != nil {
+		id = peer.ID
+	} else if _, ok := peers[id]; ok {
+		return "", nil, fmt.Errorf("peer %s is not at %s", id, source)
+	} else if id == "" {
+		id = etcd.NewPeerID()
+	}
+	if req.ID != "" && api.PeerID(req.ID) != id {
+		return "", nil, fmt.Errorf("the request from %s may not be issued for peer %s", source, req.ID)
+	}
+
+	hosts := sets.NewString(source.String(), "localhost", "127.0.0.1", "::1")
+	if peer, ok := peers[id]; ok {
+		hosts.Insert(peerHosts(peer)...)
+	}
+	return id, hosts.List(), nil
+}
+
+// peerAt returns the discovered peer at ip, nil if there is none
+func peerAt(peers map[api.PeerID]*discovery.Peer, ip net.IP) *discovery.Peer {
+	for _, peer := range peers {
+		for _, host := range peerHosts(peer) {
+			if h := net.ParseIP(host); h != nil && h.Equal(ip) {
+				return peer
+			}
+		}
+	}
+	return nil
+}
+
+// peerHosts returns the host of the address of peer and its other hosts
+func peerHosts(peer *discovery.Peer) []string {
+	hosts := append([]string(nil), peer.Hosts...)
+	if h, _, err := net.SplitHostPort(peer.Address); err == nil {
+		hosts = append(hosts, h)
+	}
+	return hosts
+}
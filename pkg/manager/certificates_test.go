@@ -0,0 +1,135 @@
+package manager
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/authz"
+	"github.com/etcd-manager/etcd-discovery/pkg/backup"
+	"github.com/etcd-manager/etcd-discovery/pkg/constants"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"github.com/etcd-manager/etcd-discovery/pkg/pki"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apiserver/pkg/authentication/user"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+func TestHandleCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := backup.NewFileStore(dir)
+	ca, err := pki.NewCA("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveCA(ca.CertPEM(), ca.KeyPEM()); err != nil {
+		t.Fatal(err)
+	}
+	csrs, err := pki.NewRequest("b", certutil.AltNames{DNSNames: []string{"node-b"}, IPs: []net.IP{net.ParseIP("10.0.0.2")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &api.CertificateRequest{
+		ID:                   "b",
+		PeerCSR:              string(csrs.CSRs[pki.ProfilePeer]),
+		ServerCSR:            string(csrs.CSRs[pki.ProfileServer]),
+		HealthcheckClientCSR: string(csrs.CSRs[pki.ProfileHealthcheckClient]),
+	}
+
+	// a follower names the leader
+	election := discovery.NewElection("c", 3, time.Minute, clock.NewFakeClock(time.Now()))
+	election.HandlePing(&api.PingRequest{Info: &api.PeerInfo{ID: "a"}, Leader: "a", Term: 1}, &api.PingResponse{})
+	m := &EtcdManager{
+		config:   &EtcdConfig{ID: "c"},
+		election: election,
+		backups:  backup.NewController(store, 0, backup.RetentionPolicy{}, clock.RealClock{}),
+	}
+	bootstrap := &user.DefaultInfo{Name: constants.BootstrapUser, Groups: []string{constants.BootstrapGroup}}
+	ctx := authz.WithSource(apirequest.WithUser(apirequest.NewContext(), bootstrap), net.ParseIP("10.0.0.2"))
+	resp, err := m.HandleCertificate(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Reason == "" || resp.PeerCert != "" || resp.Term != 1 {
+		t.Errorf("expected a follower to refuse with the term of the leader, got %+v", resp)
+	}
+
+	// the leader signs with the CA of the backup store
+	m.config.ID = "a"
+	m.election = soleLeader(t, "a")
+	m.peers = map[api.PeerID]*discovery.Peer{
+		"a": {ID: "a", Address: "10.0.0.1:2381"},
+		"b": {ID: "b", Address: "10.0.0.2:2381", Hosts: []string{"node-b"}},
+	}
+	resp, err = m.HandleCertificate(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Reason != "" {
+		t.Fatalf("expected the leader to sign, got %q", resp.Reason)
+	}
+	certs, err := certutil.ParseCertsPEM([]byte(resp.PeerCert))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := certs[0].CheckSignatureFrom(ca.Cert); err != nil {
+		t.Errorf("expected the peer certificate to be signed by the cluster CA: %v", err)
+	}
+	if resp.ID != "b" || certs[0].Subject.CommonName != "b" || len(certs[0].DNSNames) != 1 || certs[0].DNSNames[0] != "node-b" {
+		t.Errorf("unexpected peer certificate of %q: %+v, %v", resp.ID, certs[0].Subject, certs[0].DNSNames)
+	}
+
+	// a new peer without an id gets the id of the peer discovered at its address
+	req.ID = ""
+	if resp, err := m.HandleCertificate(ctx, req); err != nil || resp.Reason != "" || resp.ID != "b" {
+		t.Errorf("expected the id of the peer at the address, got %+v, %v", resp, err)
+	}
+
+	// the ids of other peers are refused
+	for _, c := range []struct {
+		name string
+		ctx  context.Context
+	}{
+		{"a new peer at the address of another", ctx},
+		{"a new peer elsewhere", authz.WithSource(apirequest.WithUser(apirequest.NewContext(), bootstrap), net.ParseIP("10.0.0.9"))},
+		{"a peer", authz.WithSource(apirequest.WithUser(apirequest.NewContext(), &user.DefaultInfo{Name: "b", Groups: []string{constants.PeerOrganization}}), net.ParseIP("10.0.0.2"))},
+	} {
+		req.ID = "a"
+		if resp, err := m.HandleCertificate(c.ctx, req); err != nil || resp.Reason == "" || resp.PeerCert != "" {
+			t.Errorf("%s: expected a forged id to be refused, got %+v, %v", c.name, resp, err)
+		}
+	}
+
+	// the hosts of other peers are refused
+	forged, err := pki.NewRequest("b", certutil.AltNames{DNSNames: []string{"node-b"}, IPs: []net.IP{net.ParseIP("10.0.0.1")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.ID = "b"
+	req.PeerCSR = string(forged.CSRs[pki.ProfilePeer])
+	if resp, err := m.HandleCertificate(ctx, req); err != nil || resp.Reason == "" || resp.PeerCert != "" {
+		t.Errorf("expected a forged SAN to be refused, got %+v, %v", resp, err)
+	}
+	// a new peer only gets the address it connects from
+	newPeer := authz.WithSource(apirequest.WithUser(apirequest.NewContext(), bootstrap), net.ParseIP("10.0.0.9"))
+	req.ID = "c"
+	req.PeerCSR = string(csrs.CSRs[pki.ProfilePeer])
+	if resp, err := m.HandleCertificate(newPeer, req); err != nil || resp.Reason == "" {
+		t.Errorf("expected the hosts of another peer to be refused to a new peer, got %+v, %v", resp, err)
+	}
+
+	req.PeerCSR = "invalid"
+	if resp, err := m.HandleCertificate(ctx, req); err != nil || resp.Reason == "" {
+		t.Errorf("expected an invalid request to be refused, got %+v, %v", resp, err)
+	}
+}
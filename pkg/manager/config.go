@@ -2,16 +2,65 @@ package manager
 
 import (
 	"net"
+	"strconv"
+	"time"
 
 	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/backup"
 	"github.com/etcd-manager/etcd-discovery/pkg/config"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"github.com/etcd-manager/etcd-discovery/pkg/etcd"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
+// TLSFiles locates the pem encoded files used to secure one of etcd's endpoints
+type TLSFiles struct {
+	CACertFile     string
+	CertFile       string
+	KeyFile        string
+	ClientCertAuth bool
+}
+
 type EtcdConfig struct {
 	config.EtcdCluster
 
 	ID               api.PeerID
 	AdvertiseAddress net.IP
+	// DiscoveryPort is the port the discovery server listens on, config.DiscoveryPort if zero
+	DiscoveryPort int
+
+	ProcessType etcd.ProcessType
+	Process     etcd.ProcessOptions
+
+	// BackupInterval is the time between backups taken by the leader
+	BackupInterval  time.Duration
+	BackupRetention backup.RetentionPolicy
+	// RestoreBackup restores the cluster from a backup, backup.Latest or the name of a
+	// backup, instead of starting etcd from the local data. Empty disables restores.
+	RestoreBackup string
+
+	// MemberGracePeriod is how long the peer of a member may be unreachable before the
+	// leader removes the member. Zero disables the removal of members.
+	MemberGracePeriod time.Duration
+
+	// Seeds finds the other discovery servers of the cluster
+	Seeds discovery.SeedProvider
+
+	CertificatesDir string
+	// PeerTLS is used for etcd peer traffic and to talk to other discovery servers
+	PeerTLS TLSFiles
+	// ServerTLS is used for etcd client traffic
+	ServerTLS TLSFiles
+	// ClientTLS authenticates the manager to the local etcd member, the CA of ServerTLS
+	// verifies etcd unless it has a CA of its own
+	ClientTLS TLSFiles
+	// CertRenewBefore is how long before their expiry the certificates issued to the peer by
+	// the cluster CA are renewed, they are kept in CertificatesDir. Zero disables renewals.
+	CertRenewBefore time.Duration
+	// CARenewBefore is how long before its expiry the leader rotates the cluster CA. Zero
+	// disables the rotation of the CA.
+	CARenewBefore time.Duration
 }
 
 func NewEtcdConfig() *EtcdConfig {
@@ -19,5 +68,61 @@ func NewEtcdConfig() *EtcdConfig {
 }
 
 func (c *EtcdConfig) New() (*EtcdManager, error) {
-	return &EtcdManager{}, nil
+	m := &EtcdManager{
+		config:         c,
+		statusWatchers: watch.NewBroadcaster(statusQueueLength, watch.DropIfChannelFull),
+		restarting:     make(chan struct{}),
+		newProcess:     etcd.NewProcess,
+	}
+	migration, err := m.loadMigration()
+	if err != nil {
+		return nil, err
+	}
+	m.migration = migration
+	// the size set through the scale resource overrides the configured one
+	size, err := m.loadClusterSize()
+	if err != nil {
+		return nil, err
+	}
+	if size != 0 {
+		c.ClusterSize = size
+	}
+	// and so does the etcd version set through the upgrade resource
+	version, err := m.loadClusterVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version != "" {
+		c.EtcdVersion = version
+	}
+
+	address := c.AdvertiseAddress.String()
+	port := c.DiscoveryPort
+	if port == 0 {
+		port = config.DiscoveryPort
+	}
+	self := discovery.Peer{
+		ID:      c.ID,
+		Address: net.JoinHostPort(address, strconv.Itoa(port)),
+		Hosts:   []string{address},
+	}
+	store, err := backup.NewStore(c.BackupStorePath)
+	if err != nil {
+		return nil, err
+	}
+	m.backups = backup.NewController(store, c.BackupInterval, c.BackupRetention, clock.RealClock{})
+
+	m.election = discovery.NewElection(c.ID, c.ClusterSize, leaseDuration, clock.RealClock{})
+	m.discoverer = discovery.NewDiscoverer(self, c.AllSeeds(), m.newPingClient, m.election)
+	return m, nil
+}
+
+// AllSeeds finds the discovery servers of the cluster with Seeds, the hosts of a manually
+// configured initial cluster are seeds as well
+func (c *EtcdConfig) AllSeeds() discovery.SeedProvider {
+	var hosts []string
+	for _, host := range c.InitialCluster {
+		hosts = append(hosts, host)
+	}
+	return discovery.NewMultiSeedProvider(c.Seeds, discovery.NewStaticSeedProvider(hosts))
 }
@@ -1,8 +1,477 @@
 package manager
 
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/appscode/go/encoding/json/types"
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/backup"
+	"github.com/etcd-manager/etcd-discovery/pkg/config"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"github.com/etcd-manager/etcd-discovery/pkg/etcd"
+	"github.com/etcd-manager/etcd-discovery/pkg/etcdclient"
+	"github.com/etcd-manager/etcd-discovery/pkg/metrics"
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const (
+	reconcileInterval = 10 * time.Second
+	// leaseDuration is how long an elected leader stays leader without renewing its lease
+	leaseDuration = 3 * reconcileInterval
+	backupTimeout = 10 * time.Minute
+	// crashLoopCooldown is how long a crash looping etcd process is not restarted from its data
+	crashLoopCooldown = 10 * time.Minute
+	// processExitHistory is how many exits of the local etcd process are reported
+	processExitHistory = 10
+)
+
 type EtcdManager struct {
+	config *EtcdConfig
+
+	mutex   sync.Mutex
+	process etcd.Process
+	flags   *config.EtcdFlags
+	// exits are the exits of the replaced etcd processes, crashLooped is when the last
+	// crash looping process was given up
+	exits       []etcd.ProcessExit
+	crashLooped time.Time
+	peers       map[api.PeerID]*discovery.Peer
+	leader      api.PeerID
+	// plan is the bootstrap plan of a new cluster, made or accepted by us
+	plan *api.PlanRequest
+	// proposal is the plan we push to the other members while leader
+	proposal *api.PlanRequest
+	// migration is the migration phase of the local member, persisted in the data dir
+	migration *api.MigrationRequest
+	// copying is true while the keys of a migrating cluster are copied
+	copying bool
+	// upgrading is the member the leader upgrades, upgradeFailed the last upgrade rolled back
+	upgrading     *upgradeState
+	upgradeFailed *upgradeState
+	// rotating is the member the leader renewed the certificates of, rotationChecked is when
+	// the leader last found every member with current certificates
+	rotating        *rotationState
+	rotationChecked time.Time
+	// restarting is closed once the certificates of the local peer were renewed
+	restarting chan struct{}
+	// quarantineAcked are the peers that took the quarantine mode of the leader in quarantineTerm
+	quarantineTerm  int64
+	quarantineAcked sets.String
+	// memberSeen is when the leader last found the peer of each member, by member id
+	memberSeenTerm int64
+	memberSeen     map[string]time.Time
+	// scaleAcked are the peers that took the cluster size and members of scaleKey
+	scaleKey   string
+	scaleAcked sets.String
+	// versionAcked are the peers that took the etcd version of the cluster of versionKey
+	versionKey   string
+	versionAcked sets.String
+	// scaleRemoved is set when the leader reports that the local member left the cluster
+	scaleRemoved bool
+	// status is the last reported status of the cluster, statusWatchers receive its changes
+	status         *api.EtcdCluster
+	statusVersion  int64
+	statusWatchers *watch.Broadcaster
+
+	discoverer peerDiscoverer
+	election   *discovery.Election
+	backups    *backup.Controller
+	// newProcess returns the etcd process to start, etcd.NewProcess
+	newProcess func(t etcd.ProcessType, cfg *config.EtcdFlags, opts etcd.ProcessOptions) (etcd.Process, error)
+}
+
+// peerDiscoverer finds the discovery servers of the cluster, every refresh is a round of
+// the leader election
+type peerDiscoverer interface {
+	Refresh(ctx context.Context) map[api.PeerID]*discovery.Peer
 }
 
+// Election returns the leader election this manager takes part in
+func (m *EtcdManager) Election() *discovery.Election {
+	return m.election
+}
+
+// Run reconciles the local etcd member with the cluster until stopCh is closed.
+// The local etcd process is stopped before returning.
 func (m *EtcdManager) Run(stopCh <-chan struct{}) error {
+	glog.Infof("starting etcd manager for peer %s", m.config.ID)
+	wait.Until(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), reconcileInterval)
+		defer cancel()
+		if err := m.reconcile(ctx); err != nil {
+			glog.Warningf("error reconciling etcd cluster %s: %v", m.config.ClusterName, err)
+		}
+		// the health checks get their own deadline, a slow reconcile must not fail them
+		statusCtx, cancel := context.WithTimeout(context.Background(), reconcileInterval)
+		defer cancel()
+		m.updateStatus(statusCtx)
+		m.observeCertificates()
+	}, reconcileInterval, stopCh)
+	m.statusWatchers.Shutdown()
+	return m.stopEtcd()
+}
+
+func (m *EtcdManager) reconcile(ctx context.Context) error {
+	peers := m.discoverer.Refresh(ctx)
+	leader, term := m.election.Leader()
+	isLeader := leader == m.config.ID
+	glog.V(4).Infof("found %d peers, leader is %q for term %d", len(peers), leader, term)
+
+	m.mutex.Lock()
+	if leader != m.leader {
+		metrics.ElectionLeaderChanges.Inc()
+	}
+	m.peers = peers
+	m.leader = leader
+	m.mutex.Unlock()
+	metrics.ElectionTerm.Set(float64(term))
+	if isLeader {
+		metrics.ElectionIsLeader.Set(1)
+	} else {
+		metrics.ElectionIsLeader.Set(0)
+	}
+
+	if err := m.reconcileMigration(); err != nil {
+		return err
+	}
+	if err := m.reconcileVersion(); err != nil {
+		return err
+	}
+	if err := m.reconcileQuarantine(); err != nil {
+		return err
+	}
+	if err := m.reconcileRestore(); err != nil {
+		return err
+	}
+	if m.isRunning() {
+		if isLeader {
+			if m.needsMigration() {
+				return m.migrate(ctx, peers, term)
+			}
+			m.pushQuarantine(ctx, peers, term, false)
+			if upgrading, err := m.upgrade(ctx, peers, term); upgrading || err != nil {
+				return err
+			}
+			if rotating, err := m.rotate(ctx, peers, term); rotating || err != nil {
+				return err
+			}
+			// backups may take longer than a reconcile, they must not hold up the election
+			go m.backup()
+			return m.checkMembership(ctx, peers, term)
+		}
+		return nil
+	}
+
+	// a member waiting for a restore does not restart from the data it is about to lose
+	restoring := m.restorePending()
+	if m.takeRemoved() {
+		// the data of a removed member cannot rejoin the cluster, it joins as a new member
+		if err := m.moveDataAside(); err != nil {
+			return err
+		}
+	}
+	if m.hasData() && !restoring {
+		if wait := m.crashLoopWait(); wait > 0 {
+			return fmt.Errorf("etcd member %s is crash looping, not restarting it for %v", m.config.ID, wait)
+		}
+		glog.Infof("restarting etcd member %s from existing data", m.config.ID)
+		return m.startEtcd(config.ClusterStateExisting, m.clusterToken(), map[string]string{
+			string(m.config.ID): m.config.AdvertiseAddress.String(),
+		})
+	}
+
+	m.mutex.Lock()
+	plan := m.plan
+	m.mutex.Unlock()
+	if plan != nil {
+		return m.startFromPlan(plan)
+	}
+
+	if leader == "" {
+		return fmt.Errorf("no leader elected among %d peers of cluster %s", len(peers), m.config.ClusterName)
+	}
+
+	if isLeader {
+		if m.config.InitialClusterState != config.ClusterStateNew && !restoring {
+			return fmt.Errorf("no peer to join, waiting for discovery servers of cluster %s", m.config.ClusterName)
+		}
+		return m.bootstrap(peers, term)
+	}
+	if restoring {
+		glog.Infof("waiting for leader %s to plan the restore of cluster %s", leader, m.config.ClusterName)
+		return nil
+	}
+
+	peer, ok := peers[leader]
+	if !ok {
+		return fmt.Errorf("leader %s of cluster %s is not reachable", leader, m.config.ClusterName)
+	}
+	glog.Infof("joining etcd cluster %s through leader %s", m.config.ClusterName, leader)
+	resp, err := m.join(peer)
+	if err != nil {
+		return fmt.Errorf("error joining cluster through leader %s: %v", leader, err)
+	}
+	cluster, err := parseInitialCluster(resp.PeerURLs)
+	if err != nil {
+		return err
+	}
+	cluster[string(m.config.ID)] = m.config.AdvertiseAddress.String()
+	if resp.EtcdVersion != "" {
+		if err := m.setClusterVersion(config.EtcdVersion(resp.EtcdVersion)); err != nil {
+			return err
+		}
+	}
+	return m.startEtcd(config.ClusterStateExisting, resp.ClusterToken, cluster)
+}
+
+// backup takes a backup of the cluster if one is due
+func (m *EtcdManager) backup() {
+	m.mutex.Lock()
+	flags := m.flags
+	m.mutex.Unlock()
+	if flags == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), backupTimeout)
+	defer cancel()
+
+	client, err := flags.NewClient()
+	if err != nil {
+		glog.Warningf("error creating etcd client for backup: %v", err)
+		return
+	}
+	defer client.Close()
+
+	if _, err := m.backups.MaybeBackup(ctx, client, flags.InitialClusterToken); err != nil {
+		glog.Warningf("error backing up cluster %s: %v", m.config.ClusterName, err)
+	}
+}
+
+// checkMembership compares the etcd member list with the desired cluster size. Members
+// whose peer is unreachable for longer than MemberGracePeriod are removed, so that another
+// peer can join in their place, and members beyond the cluster size are removed one at a
+// time. The peers learn the cluster size and its members from the leader.
+func (m *EtcdManager) checkMembership(ctx context.Context, peers map[api.PeerID]*discovery.Peer, term int64) error {
+	m.mutex.Lock()
+	flags := m.flags
+	m.mutex.Unlock()
+	client, err := flags.NewClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	members, err := client.ListMembers(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing members: %v", err)
+	}
+	if m.config.MemberGracePeriod > 0 && !m.migrating() {
+		m.mutex.Lock()
+		if m.memberSeen == nil || m.memberSeenTerm != term {
+			// a new leader gives every member a new grace period
+			m.memberSeen = map[string]time.Time{}
+			m.memberSeenTerm = term
+		}
+		dead := findDeadMember(members, peers, m.memberSeen, time.Now(), m.config.MemberGracePeriod)
+		m.mutex.Unlock()
+		if dead != nil {
+			glog.Warningf("removing member %s of cluster %s, its peer is unreachable for more than %v", dead, m.config.ClusterName, m.config.MemberGracePeriod)
+			err := client.RemoveMember(ctx, dead)
+			metrics.ObserveMemberOperation(metrics.OperationRemove, err)
+			if err != nil {
+				return fmt.Errorf("error removing member %s: %v", dead, err)
+			}
+			return nil
+		}
+	}
+	names := make([]string, 0, len(members))
+	for _, member := range members {
+		names = append(names, memberName(member, peers))
+	}
+	m.pushScale(ctx, peers, term, names)
+
+	size := m.clusterSize()
+	switch {
+	case len(members) < size:
+		glog.Infof("cluster %s has %d of %d members, waiting for peers to join", m.config.ClusterName, len(members), size)
+	case len(members) > size:
+		if m.migrating() {
+			glog.Warningf("cluster %s has %d members, expected %d", m.config.ClusterName, len(members), size)
+			return nil
+		}
+		return m.scaleDown(ctx, client, members, peers)
+	}
 	return nil
 }
+
+func (m *EtcdManager) isRunning() bool {
+	m.mutex.Lock()
+	process := m.process
+	m.mutex.Unlock()
+	if process == nil {
+		return false
+	}
+
+	// ExitState may probe etcd, the status and the api are not held up meanwhile
+	exitError, exitState := process.ExitState()
+	if exitError == nil && exitState == nil {
+		return true
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	glog.Warningf("etcd process exited (state=%v): %v", exitState, exitError)
+	if m.process != process {
+		// the process was stopped or replaced meanwhile
+		return m.process != nil
+	}
+	if exitError == etcd.ErrCrashLoop {
+		m.crashLooped = time.Now()
+	}
+	m.retireProcess()
+	return false
+}
+
+// retireProcess keeps the exits of the local etcd process before it is replaced, m.mutex
+// must be held
+func (m *EtcdManager) retireProcess() {
+	m.exits = lastExits(append(m.exits, m.process.Exits()...))
+	m.process = nil
+}
+
+// processExits returns the last exits of the local etcd processes, oldest first
+func (m *EtcdManager) processExits() []etcd.ProcessExit {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	exits := append([]etcd.ProcessExit(nil), m.exits...)
+	if m.process != nil {
+		exits = append(exits, m.process.Exits()...)
+	}
+	return lastExits(exits)
+}
+
+func lastExits(exits []etcd.ProcessExit) []etcd.ProcessExit {
+	if len(exits) > processExitHistory {
+		return exits[len(exits)-processExitHistory:]
+	}
+	return exits
+}
+
+// crashLoopWait returns how long the local etcd process is not restarted after it crash looped
+func (m *EtcdManager) crashLoopWait() time.Duration {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.crashLooped.IsZero() {
+		return 0
+	}
+	return crashLoopCooldown - time.Since(m.crashLooped)
+}
+
+func (m *EtcdManager) etcdDataDir() string {
+	return filepath.Join(m.config.DataDir, "etcd")
+}
+
+// hasData returns true if the local member was started before
+func (m *EtcdManager) hasData() bool {
+	_, err := os.Stat(filepath.Join(m.etcdDataDir(), "member"))
+	return err == nil
+}
+
+func (m *EtcdManager) clusterToken() string {
+	if m.config.ClusterToken != "" {
+		return m.config.ClusterToken
+	}
+	return m.config.ClusterName
+}
+
+func (m *EtcdManager) peerURL() string {
+	return "https://" + net.JoinHostPort(m.config.AdvertiseAddress.String(), strconv.Itoa(config.PeerPort))
+}
+
+func (m *EtcdManager) newEtcdFlags(state config.ClusterState, token string, cluster map[string]string) *config.EtcdFlags {
+	address := m.config.AdvertiseAddress.String()
+
+	f := config.NewEtcdFlags()
+	f.Version = m.etcdVersion()
+	f.CertificatesDir = m.config.CertificatesDir
+	f.Name = string(m.config.ID)
+	f.InitialAdvertisePeerURLs.Insert(address)
+	f.ListenPeerURLs.Insert(address)
+	f.ListenClientURLs.Insert(address)
+	f.AdvertiseClientURLs.Insert(address)
+	f.InitialClusterToken = token
+	for name, host := range cluster {
+		f.InitialCluster.Insert(name, host)
+	}
+	f.InitialClusterState = strings.ToLower(state.String())
+	f.DataDir = m.etcdDataDir()
+	f.CertFile = m.config.ServerTLS.CertFile
+	f.KeyFile = m.config.ServerTLS.KeyFile
+	f.TrustedCAFile = m.config.ServerTLS.CACertFile
+	f.ClientCertAuth = types.BoolYo(m.config.ServerTLS.ClientCertAuth)
+	f.PeerCertFile = m.config.PeerTLS.CertFile
+	f.PeerKeyFile = m.config.PeerTLS.KeyFile
+	f.PeerTrustedCAFile = m.config.PeerTLS.CACertFile
+	f.PeerClientCertAuth = types.BoolYo(m.config.PeerTLS.ClientCertAuth)
+	f.ClientTLS = etcdclient.TLSFiles{
+		CACertFile: m.config.ClientTLS.CACertFile,
+		CertFile:   m.config.ClientTLS.CertFile,
+		KeyFile:    m.config.ClientTLS.KeyFile,
+	}
+	if f.ClientTLS.CACertFile == "" {
+		f.ClientTLS.CACertFile = m.config.ServerTLS.CACertFile
+	}
+	f.Quarantined = m.quarantineMode() == api.QuarantineModeEnabled
+	m.migrationFlags(f)
+	return f
+}
+
+func (m *EtcdManager) startEtcd(state config.ClusterState, token string, cluster map[string]string) error {
+	flags := m.newEtcdFlags(state, token, cluster)
+	process, err := m.newProcess(m.config.ProcessType, flags, m.config.Process)
+	if err != nil {
+		return err
+	}
+	// Start blocks until etcd is ready, the status and the api are not held up meanwhile
+	if err := process.Start(); err != nil {
+		return err
+	}
+	if err := m.recordVersion(flags.Version); err != nil {
+		glog.Warning(err)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.flags != nil {
+		// etcd ran before, under this or an older version
+		metrics.EtcdRestarts.Inc()
+	}
+	m.process = process
+	m.flags = flags
+	return nil
+}
+
+func (m *EtcdManager) stopEtcd() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.process == nil {
+		return nil
+	}
+	glog.Infof("stopping etcd member %s", m.config.ID)
+	err := m.process.Stop()
+	m.retireProcess()
+	return err
+}
@@ -0,0 +1,223 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/config"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"github.com/etcd-manager/etcd-discovery/pkg/etcd"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// fakeDiscoverer finds a fixed set of peers
+type fakeDiscoverer map[api.PeerID]*discovery.Peer
+
+func (d fakeDiscoverer) Refresh(ctx context.Context) map[api.PeerID]*discovery.Peer {
+	return d
+}
+
+// fakeProcess records how etcd was started, it runs until exitError is set
+type fakeProcess struct {
+	flags     *config.EtcdFlags
+	started   bool
+	stopped   bool
+	exitError error
+	// probe is called by Start and ExitState, like the probes of a static pod
+	probe func()
+}
+
+func (p *fakeProcess) Type() etcd.ProcessType { return etcd.ProcessTypeDirect }
+func (p *fakeProcess) Stop() error            { p.stopped = true; return nil }
+func (p *fakeProcess) Start() error {
+	if p.probe != nil {
+		p.probe()
+	}
+	p.started = true
+	return nil
+}
+func (p *fakeProcess) Exits() []etcd.ProcessExit {
+	if p.exitError == nil {
+		return nil
+	}
+	return []etcd.ProcessExit{{ExitCode: 1, Message: p.exitError.Error()}}
+}
+func (p *fakeProcess) ExitState() (error, *os.ProcessState) {
+	if p.probe != nil {
+		p.probe()
+	}
+	return p.exitError, nil
+}
+
+// fakeProcesses returns the newProcess of a manager that records the processes it starts
+func fakeProcesses(processes *[]*fakeProcess) func(etcd.ProcessType, *config.EtcdFlags, etcd.ProcessOptions) (etcd.Process, error) {
+	return func(_ etcd.ProcessType, flags *config.EtcdFlags, _ etcd.ProcessOptions) (etcd.Process, error) {
+		p := &fakeProcess{flags: flags}
+		*processes = append(*processes, p)
+		return p, nil
+	}
+}
+
+func TestReconcileBootstrap(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var processes []*fakeProcess
+	m := &EtcdManager{
+		config: &EtcdConfig{
+			EtcdCluster: config.EtcdCluster{
+				ClusterName:         "test",
+				ClusterSize:         1,
+				DataDir:             dir,
+				EtcdVersion:         "3.2.13",
+				InitialClusterState: config.ClusterStateNew,
+			},
+			ID:               "a",
+			AdvertiseAddress: net.ParseIP("10.0.0.1"),
+		},
+		discoverer: fakeDiscoverer{"a": {ID: "a", Address: "10.0.0.1:2381", Hosts: []string{"10.0.0.1"}}},
+		election:   soleLeader(t, "a"),
+		newProcess: fakeProcesses(&processes),
+	}
+
+	if err := m.reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(processes) != 1 || !processes[0].started {
+		t.Fatalf("expected the leader to start etcd, got %v", processes)
+	}
+	flags := processes[0].flags
+	if flags.InitialClusterState != "new" || flags.Name != "a" || flags.Version != "3.2.13" {
+		t.Errorf("unexpected flags %+v", flags)
+	}
+	if m.plan == nil || m.plan.Leader != "a" || m.plan.Term != 1 {
+		t.Errorf("expected the plan of the leader for term 1, got %+v", m.plan)
+	}
+	if !m.isRunning() {
+		t.Errorf("expected etcd to run")
+	}
+}
+
+func TestReconcileRestartsExitedMember(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// b follows a, which was elected leader for term 1
+	election := discovery.NewElection("b", 3, time.Minute, clock.NewFakeClock(time.Now()))
+	resp := &api.PingResponse{}
+	election.HandlePing(&api.PingRequest{Info: &api.PeerInfo{ID: "a"}, Leader: "a", Term: 1}, resp)
+	if !resp.LeaseGranted {
+		t.Fatalf("b did not grant the lease to a")
+	}
+
+	var processes []*fakeProcess
+	exited := &fakeProcess{exitError: etcd.ErrCrashLoop}
+	m := &EtcdManager{
+		config: &EtcdConfig{
+			EtcdCluster:      config.EtcdCluster{ClusterName: "test", ClusterSize: 3, DataDir: dir, EtcdVersion: "3.2.13"},
+			ID:               "b",
+			AdvertiseAddress: net.ParseIP("10.0.0.2"),
+		},
+		discoverer: fakeDiscoverer(testPeers()),
+		election:   election,
+		newProcess: fakeProcesses(&processes),
+		process:    exited,
+	}
+	if err := os.MkdirAll(filepath.Join(m.etcdDataDir(), "member"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// a crash looping member is not restarted before its cooldown
+	if err := m.reconcile(context.Background()); err == nil || len(processes) != 0 {
+		t.Fatalf("expected the crash looping member to wait, got %v with %d processes", err, len(processes))
+	}
+	if exits := m.processExits(); len(exits) != 1 {
+		t.Errorf("expected the exit to be kept, got %v", exits)
+	}
+
+	m.crashLooped = time.Now().Add(-crashLoopCooldown)
+	if err := m.reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(processes) != 1 || !processes[0].started || processes[0].flags.InitialClusterState != "existing" {
+		t.Fatalf("expected etcd to restart from its data, got %v", processes)
+	}
+	if m.peers["a"] == nil || m.leader != "a" {
+		t.Errorf("expected the peers and the leader to be recorded, got %v, %q", m.peers, m.leader)
+	}
+
+	// a follower leaves the running member alone
+	if err := m.reconcile(context.Background()); err != nil || len(processes) != 1 {
+		t.Errorf("expected the running member to be kept, got %v with %d processes", err, len(processes))
+	}
+}
+
+func TestIsRunningProbesWithoutLock(t *testing.T) {
+	m := &EtcdManager{config: &EtcdConfig{EtcdCluster: config.EtcdCluster{EtcdVersion: "3.2.13"}}}
+	process := &fakeProcess{}
+	process.probe = func() {
+		// the status reads the manager while etcd is probed
+		m.clusterVersion()
+		process.exitError = errors.New("unhealthy")
+	}
+	m.process = process
+
+	done := make(chan bool)
+	go func() { done <- m.isRunning() }()
+	select {
+	case running := <-done:
+		if running || m.process != nil {
+			t.Errorf("expected the unhealthy process to be retired")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("the manager was locked while etcd was probed")
+	}
+}
+
+func TestStartEtcdWaitsWithoutLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := &EtcdManager{
+		config: &EtcdConfig{
+			EtcdCluster: config.EtcdCluster{ClusterName: "test", ClusterSize: 1, DataDir: dir, EtcdVersion: "3.2.13"},
+			ID:          "a",
+		},
+	}
+	process := &fakeProcess{}
+	process.probe = func() {
+		// the status reads the manager while Start waits for etcd to be ready
+		m.clusterVersion()
+	}
+	m.newProcess = func(_ etcd.ProcessType, flags *config.EtcdFlags, _ etcd.ProcessOptions) (etcd.Process, error) {
+		process.flags = flags
+		return process, nil
+	}
+
+	done := make(chan error)
+	go func() { done <- m.startEtcd(config.ClusterStateNew, "token", map[string]string{"a": m.peerURL()}) }()
+	select {
+	case err := <-done:
+		if err != nil || !process.started || m.process != process {
+			t.Errorf("expected etcd to be started, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("the manager was locked while etcd was started")
+	}
+}
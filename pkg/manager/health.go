@@ -0,0 +1,185 @@
+package manager
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"github.com/etcd-manager/etcd-discovery/pkg/etcdclient"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// healthKey is read linearizably to check that a member serves reads, it does not need to exist
+	healthKey = "/etcd-manager/health"
+	// healthCheckTimeout bounds the health checks of a single member
+	healthCheckTimeout = 5 * time.Second
+	// certificateExpiryWarning is how long before its expiry a certificate is reported as expiring
+	certificateExpiryWarning = 30 * 24 * time.Hour
+)
+
+func newCondition(t api.EtcdClusterConditionType, status api.ConditionStatus, reason, message string, now metav1.Time) api.EtcdClusterCondition {
+	return api.EtcdClusterCondition{Type: t, Status: status, Reason: reason, Message: message, LastTransitionTime: now}
+}
+
+// checkMemberHealth returns the status of member with the results of its health checks
+func checkMemberHealth(ctx context.Context, member *etcdclient.EtcdProcessMember, peers map[api.PeerID]*discovery.Peer, now metav1.Time) api.EtcdClusterMember {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	status := api.EtcdClusterMember{
+		ID:         member.ID,
+		Name:       member.Name,
+		PeerURLs:   member.PeerURLs,
+		ClientURLs: member.ClientURLs,
+	}
+
+	reachable := newCondition(api.EtcdMemberReachable, api.ConditionTrue, "PeerFound", "", now)
+	if name := memberName(member, peers); name == "" || peers[api.PeerID(name)] == nil {
+		reachable.Status = api.ConditionFalse
+		reachable.Reason = "PeerNotFound"
+		reachable.Message = "the discovery server of the member is not found"
+	}
+
+	if member.Name == "" {
+		status.Conditions = []api.EtcdClusterCondition{
+			newCondition(api.EtcdMemberHealthy, api.ConditionFalse, "NotStarted", "the member has not started yet", now),
+			newCondition(api.EtcdMemberReadable, api.ConditionUnknown, "NotStarted", "", now),
+			reachable,
+			newCondition(api.EtcdMemberCertificateValid, api.ConditionUnknown, "NotStarted", "", now),
+		}
+		return status
+	}
+
+	healthy := newCondition(api.EtcdMemberHealthy, api.ConditionTrue, "StatusOK", "", now)
+	readable := newCondition(api.EtcdMemberReadable, api.ConditionTrue, "ReadOK", "", now)
+	client, err := member.NewClient()
+	if err != nil {
+		healthy = newCondition(api.EtcdMemberHealthy, api.ConditionFalse, "ClientFailed", err.Error(), now)
+		readable = newCondition(api.EtcdMemberReadable, api.ConditionUnknown, "ClientFailed", err.Error(), now)
+	} else {
+		defer client.Close()
+		if version, err := client.ServerVersion(ctx); err == nil {
+			status.Version = version
+		}
+		if info, err := client.LocalNodeInfo(ctx); err != nil {
+			healthy = newCondition(api.EtcdMemberHealthy, api.ConditionFalse, "StatusFailed", err.Error(), now)
+		} else {
+			status.IsLeader = info.IsLeader
+			status.DBSize = info.DBSize
+			status.RaftIndex = int64(info.RaftIndex)
+			status.RaftTerm = int64(info.RaftTerm)
+			status.Alarms = info.Alarms
+			if len(info.Alarms) > 0 {
+				healthy = newCondition(api.EtcdMemberHealthy, api.ConditionFalse, "Alarm", "alarms raised: "+strings.Join(info.Alarms, ", "), now)
+			}
+		}
+		if _, err := client.Get(ctx, healthKey, true); err != nil {
+			readable = newCondition(api.EtcdMemberReadable, api.ConditionFalse, "ReadFailed", err.Error(), now)
+		}
+	}
+
+	certificate := newCondition(api.EtcdMemberCertificateValid, api.ConditionTrue, "CertificateValid", "", now)
+	if expiry, err := certificateExpiry(ctx, member.ClientURLs); err != nil {
+		certificate = newCondition(api.EtcdMemberCertificateValid, api.ConditionUnknown, "CertificateUnknown", err.Error(), now)
+	} else if expiry.IsZero() {
+		certificate = newCondition(api.EtcdMemberCertificateValid, api.ConditionUnknown, "NoTLS", "the member serves clients without TLS", now)
+	} else {
+		t := metav1.NewTime(expiry)
+		status.CertificateExpiry = &t
+		if left := expiry.Sub(now.Time); left <= 0 {
+			certificate = newCondition(api.EtcdMemberCertificateValid, api.ConditionFalse, "CertificateExpired", fmt.Sprintf("the certificate expired at %v", expiry), now)
+		} else if left < certificateExpiryWarning {
+			certificate = newCondition(api.EtcdMemberCertificateValid, api.ConditionFalse, "CertificateExpiring", fmt.Sprintf("the certificate expires at %v", expiry), now)
+		}
+	}
+
+	status.Conditions = []api.EtcdClusterCondition{healthy, readable, reachable, certificate}
+	return status
+}
+
+// certificateExpiry returns when the server certificate of the first https url of urls
+// expires, zero if none of them uses https
+func certificateExpiry(ctx context.Context, urls []string) (time.Time, error) {
+	for _, s := range urls {
+		u, err := url.Parse(s)
+		if err != nil || u.Scheme != "https" {
+			continue
+		}
+		dialer := &net.Dialer{}
+		if deadline, ok := ctx.Deadline(); ok {
+			dialer.Deadline = deadline
+		}
+		// only the certificate is read, verifying it is up to the clients of the member
+		conn, err := tls.DialWithDialer(dialer, "tcp", u.Host, &tls.Config{InsecureSkipVerify: true})
+		if err != nil {
+			return time.Time{}, err
+		}
+		defer conn.Close()
+		certs := conn.ConnectionState().PeerCertificates
+		if len(certs) == 0 {
+			return time.Time{}, fmt.Errorf("%s presented no certificate", u.Host)
+		}
+		return certs[0].NotAfter, nil
+	}
+	return time.Time{}, nil
+}
+
+// memberHealthy returns true if the Healthy and Readable conditions of member are true
+func memberHealthy(member api.EtcdClusterMember) bool {
+	healthy, readable := false, false
+	for _, c := range member.Conditions {
+		switch c.Type {
+		case api.EtcdMemberHealthy:
+			healthy = c.Status == api.ConditionTrue
+		case api.EtcdMemberReadable:
+			readable = c.Status == api.ConditionTrue
+		}
+	}
+	return healthy && readable
+}
+
+// healthyCondition returns the Healthy condition of a cluster with members
+func healthyCondition(members []api.EtcdClusterMember, now metav1.Time) api.EtcdClusterCondition {
+	if members == nil {
+		return newCondition(api.EtcdClusterHealthy, api.ConditionUnknown, "MembersUnknown", "", now)
+	}
+	var unhealthy []string
+	for _, member := range members {
+		if !memberHealthy(member) {
+			unhealthy = append(unhealthy, member.ID)
+		}
+	}
+	if len(unhealthy) > 0 {
+		return newCondition(api.EtcdClusterHealthy, api.ConditionFalse, "MembersUnhealthy", "unhealthy members: "+strings.Join(unhealthy, ", "), now)
+	}
+	return newCondition(api.EtcdClusterHealthy, api.ConditionTrue, "MembersHealthy", "", now)
+}
+
+// unhealthyMembers returns the names of the members that failed their last health check,
+// started members are named by their etcd name and the others by their member id
+func (m *EtcdManager) unhealthyMembers() sets.String {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	unhealthy := sets.NewString()
+	if m.status == nil {
+		return unhealthy
+	}
+	for _, member := range m.status.Status.Members {
+		if !memberHealthy(member) {
+			if member.Name != "" {
+				unhealthy.Insert(member.Name)
+			} else {
+				unhealthy.Insert(member.ID)
+			}
+		}
+	}
+	return unhealthy
+}
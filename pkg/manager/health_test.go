@@ -0,0 +1,74 @@
+package manager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/etcdclient"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCertificateExpiry(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	expiry, err := certificateExpiry(ctx, []string{"http://127.0.0.1:2379", server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := server.Certificate().NotAfter; !expiry.Equal(expected) {
+		t.Errorf("expected expiry %v, got %v", expected, expiry)
+	}
+
+	expiry, err = certificateExpiry(ctx, []string{"http://127.0.0.1:2379"})
+	if err != nil || !expiry.IsZero() {
+		t.Errorf("expected no expiry without https, got %v, %v", expiry, err)
+	}
+}
+
+func TestCheckMemberHealthNotStarted(t *testing.T) {
+	now := metav1.Now()
+	member := &etcdclient.EtcdProcessMember{ID: "3", PeerURLs: []string{"https://10.0.0.3:2380"}}
+	status := checkMemberHealth(context.Background(), member, testPeers(), now)
+	if memberHealthy(status) {
+		t.Errorf("expected a member that has not started to be unhealthy, got %+v", status)
+	}
+	for _, c := range status.Conditions {
+		if c.Type == api.EtcdMemberReachable && c.Status != api.ConditionTrue {
+			t.Errorf("expected peer c to be reachable, got %+v", c)
+		}
+	}
+}
+
+func TestHealthyCondition(t *testing.T) {
+	now := metav1.Now()
+	healthy := api.EtcdClusterMember{ID: "1", Name: "a", Conditions: []api.EtcdClusterCondition{
+		newCondition(api.EtcdMemberHealthy, api.ConditionTrue, "StatusOK", "", now),
+		newCondition(api.EtcdMemberReadable, api.ConditionTrue, "ReadOK", "", now),
+	}}
+	alarmed := api.EtcdClusterMember{ID: "2", Name: "b", Conditions: []api.EtcdClusterCondition{
+		newCondition(api.EtcdMemberHealthy, api.ConditionFalse, "Alarm", "alarms raised: NOSPACE", now),
+		newCondition(api.EtcdMemberReadable, api.ConditionTrue, "ReadOK", "", now),
+	}}
+
+	if c := healthyCondition(nil, now); c.Status != api.ConditionUnknown {
+		t.Errorf("expected unknown health without members, got %+v", c)
+	}
+	if c := healthyCondition([]api.EtcdClusterMember{healthy}, now); c.Status != api.ConditionTrue {
+		t.Errorf("expected a healthy cluster, got %+v", c)
+	}
+	if c := healthyCondition([]api.EtcdClusterMember{healthy, alarmed}, now); c.Status != api.ConditionFalse || c.Message != "unhealthy members: 2" {
+		t.Errorf("expected an unhealthy cluster, got %+v", c)
+	}
+
+	m := &EtcdManager{status: &api.EtcdCluster{Status: api.EtcdClusterStatus{Members: []api.EtcdClusterMember{healthy, alarmed}}}}
+	if unhealthy := m.unhealthyMembers(); unhealthy.Len() != 1 || !unhealthy.Has("b") {
+		t.Errorf("expected b to be unhealthy, got %v", unhealthy.List())
+	}
+}
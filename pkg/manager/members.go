@@ -0,0 +1,217 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"github.com/etcd-manager/etcd-discovery/pkg/etcdclient"
+	"github.com/etcd-manager/etcd-discovery/pkg/metrics"
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// AddMember adds peerURL to the etcd cluster and returns what the new member needs
+// to start with --initial-cluster-state=existing. Only the leader adds members.
+func (m *EtcdManager) AddMember(ctx context.Context, peerURL string) (*api.MemberResponse, error) {
+	m.mutex.Lock()
+	flags, peers := m.flags, m.peers
+	m.mutex.Unlock()
+
+	if leader, _ := m.election.Leader(); leader != m.config.ID {
+		return nil, fmt.Errorf("peer %s is not the leader, ask %q", m.config.ID, leader)
+	}
+	if flags == nil || !m.isRunning() {
+		return nil, fmt.Errorf("etcd is not running on leader %s", m.config.ID)
+	}
+	if m.migrating() {
+		return nil, fmt.Errorf("cluster %s is migrating, members cannot be added", m.config.ClusterName)
+	}
+
+	client, err := flags.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	members, err := client.ListMembers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing members: %v", err)
+	}
+	if findMember(members, peerURL) == nil {
+		if size := m.clusterSize(); len(members) >= size {
+			return nil, fmt.Errorf("cluster %s has %d of %d members", m.config.ClusterName, len(members), size)
+		}
+		for _, member := range members {
+			if member.Name == "" {
+				// members join one at a time, so that a failed join cannot cost the quorum
+				return nil, fmt.Errorf("member %v of cluster %s has not started yet", member.PeerURLs, m.config.ClusterName)
+			}
+		}
+		glog.Infof("adding member %s to cluster %s", peerURL, m.config.ClusterName)
+		err := client.AddMember(ctx, []string{peerURL})
+		metrics.ObserveMemberOperation(metrics.OperationAdd, err)
+		if err != nil {
+			return nil, fmt.Errorf("error adding member %s: %v", peerURL, err)
+		}
+		members, err = client.ListMembers(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing members: %v", err)
+		}
+	}
+
+	version, err := client.ServerVersion(ctx)
+	if err != nil {
+		glog.Warningf("unable to get etcd server version: %v", err)
+		version = string(m.clusterVersion())
+	}
+
+	resp := &api.MemberResponse{
+		ClusterName:  m.config.ClusterName,
+		ClusterToken: flags.InitialClusterToken,
+		EtcdVersion:  version,
+	}
+	for _, member := range members {
+		for _, u := range member.PeerURLs {
+			name := member.Name
+			if name == "" {
+				// members that have not started yet are only known by their peer url
+				name = peerNameForURL(peers, u)
+			}
+			resp.PeerURLs = append(resp.PeerURLs, name+"="+u)
+		}
+	}
+	return resp, nil
+}
+
+// HandleRemoval removes a member from the etcd cluster for an operator. Only the leader
+// removes members, never its own, and only if the healthy members keep a quorum without
+// it. The peer of the member learns from the leader that it was removed.
+func (m *EtcdManager) HandleRemoval(ctx context.Context, req *api.RemovalRequest) (*api.RemovalResponse, error) {
+	m.mutex.Lock()
+	flags, peers := m.flags, m.peers
+	m.mutex.Unlock()
+
+	if leader, _ := m.election.Leader(); leader != m.config.ID {
+		return &api.RemovalResponse{Reason: fmt.Sprintf("peer %s is not the leader, ask %q", m.config.ID, leader)}, nil
+	}
+	if req.Member == string(m.config.ID) {
+		return &api.RemovalResponse{Reason: fmt.Sprintf("leader %s does not remove its own member", m.config.ID)}, nil
+	}
+	if flags == nil || !m.isRunning() {
+		return &api.RemovalResponse{Reason: fmt.Sprintf("etcd is not running on leader %s", m.config.ID)}, nil
+	}
+	if m.migrating() {
+		return &api.RemovalResponse{Reason: fmt.Sprintf("cluster %s is migrating, members cannot be removed", m.config.ClusterName)}, nil
+	}
+
+	client, err := flags.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	members, err := client.ListMembers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing members: %v", err)
+	}
+	var member *etcdclient.EtcdProcessMember
+	for _, mem := range members {
+		if memberName(mem, peers) == req.Member {
+			member = mem
+		}
+	}
+	if member == nil {
+		return &api.RemovalResponse{Reason: fmt.Sprintf("%s is not a member of cluster %s", req.Member, m.config.ClusterName)}, nil
+	}
+	unhealthy := m.unhealthyMembers()
+	healthy := 0
+	for _, mem := range members {
+		if mem != member && peers[api.PeerID(mem.Name)] != nil && !unhealthy.Has(mem.Name) {
+			healthy++
+		}
+	}
+	if quorum := (len(members)-1)/2 + 1; healthy < quorum {
+		return &api.RemovalResponse{Reason: fmt.Sprintf("cluster %s keeps %d healthy members without %s, %d are needed", m.config.ClusterName, healthy, req.Member, quorum)}, nil
+	}
+
+	glog.Infof("removing member %s of cluster %s for an operator", req.Member, m.config.ClusterName)
+	err = client.RemoveMember(ctx, member)
+	metrics.ObserveMemberOperation(metrics.OperationRemove, err)
+	if err != nil {
+		return nil, fmt.Errorf("error removing member %s: %v", req.Member, err)
+	}
+	resp := &api.RemovalResponse{}
+	for _, mem := range members {
+		if mem != member {
+			resp.Members = append(resp.Members, memberName(mem, peers))
+		}
+	}
+	sort.Strings(resp.Members)
+	return resp, nil
+}
+
+// findMember returns the member advertising peerURL, or nil
+func findMember(members []*etcdclient.EtcdProcessMember, peerURL string) *etcdclient.EtcdProcessMember {
+	for _, member := range members {
+		for _, u := range member.PeerURLs {
+			if u == peerURL {
+				return member
+			}
+		}
+	}
+	return nil
+}
+
+// memberName returns the name of member, or the id of the peer it was added for if it has not started yet
+func memberName(member *etcdclient.EtcdProcessMember, peers map[api.PeerID]*discovery.Peer) string {
+	if member.Name != "" {
+		return member.Name
+	}
+	for _, u := range member.PeerURLs {
+		if name := peerNameForURL(peers, u); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// findDeadMember records in seen when the members were last found among peers, and
+// returns the member missing for the longest time if that is longer than grace. A
+// member is only returned if the members that are found keep a quorum without it.
+func findDeadMember(members []*etcdclient.EtcdProcessMember, peers map[api.PeerID]*discovery.Peer, seen map[string]time.Time, now time.Time, grace time.Duration) *etcdclient.EtcdProcessMember {
+	ids := sets.NewString()
+	live := 0
+	for _, member := range members {
+		ids.Insert(member.ID)
+		if name := memberName(member, peers); name != "" && peers[api.PeerID(name)] != nil {
+			seen[member.ID] = now
+			live++
+		} else if _, ok := seen[member.ID]; !ok {
+			// the grace period starts when the leader first misses the member
+			seen[member.ID] = now
+		}
+	}
+	for id := range seen {
+		if !ids.Has(id) {
+			delete(seen, id)
+		}
+	}
+	if live < (len(members)-1)/2+1 {
+		return nil
+	}
+
+	var dead *etcdclient.EtcdProcessMember
+	for _, member := range members {
+		if now.Sub(seen[member.ID]) <= grace {
+			continue
+		}
+		if dead == nil || seen[member.ID].Before(seen[dead.ID]) {
+			dead = member
+		}
+	}
+	return dead
+}
@@ -0,0 +1,43 @@
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/etcd-manager/etcd-discovery/pkg/etcdclient"
+)
+
+func TestFindDeadMember(t *testing.T) {
+	members := []*etcdclient.EtcdProcessMember{
+		{ID: "1", Name: "a", PeerURLs: []string{"https://10.0.0.1:2380"}},
+		{ID: "2", Name: "b", PeerURLs: []string{"https://10.0.0.2:2380"}},
+		// c was added but has not started
+		{ID: "3", PeerURLs: []string{"https://10.0.0.3:2380"}},
+	}
+	grace := 10 * time.Minute
+	start := time.Now()
+	seen := map[string]time.Time{"9": start}
+
+	peers := testPeers()
+	delete(peers, "b")
+	if dead := findDeadMember(members, peers, seen, start, grace); dead != nil {
+		t.Errorf("expected no dead member at first sight, got %v", dead)
+	}
+	if _, ok := seen["9"]; ok {
+		t.Errorf("expected removed member to be forgotten")
+	}
+
+	if dead := findDeadMember(members, peers, seen, start.Add(grace), grace); dead != nil {
+		t.Errorf("expected no dead member within the grace period, got %v", dead)
+	}
+	dead := findDeadMember(members, peers, seen, start.Add(grace+time.Second), grace)
+	if dead == nil || dead.ID != "2" {
+		t.Errorf("expected member b to be dead, got %v", dead)
+	}
+
+	// without c, removing b would leave a single member out of a quorum of two
+	delete(peers, "c")
+	if dead := findDeadMember(members, peers, seen, start.Add(2*grace), grace); dead != nil {
+		t.Errorf("expected no removal without quorum, got %v", dead)
+	}
+}
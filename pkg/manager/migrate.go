@@ -0,0 +1,446 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/config"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"github.com/etcd-manager/etcd-discovery/pkg/etcdclient"
+	"github.com/golang/glog"
+)
+
+const (
+	// migrationFile keeps the migration phase of the local member in the data dir
+	migrationFile = "migration.json"
+	// versionFile records the etcd version the local data was last run with
+	versionFile      = "etcd-version"
+	migrationTimeout = 30 * time.Minute
+	// every migrationSampleInterval'th copied key is read back from both APIs
+	migrationSampleInterval = 100
+)
+
+// migrationPhases lists the phases of a migration in order. Members never go back to an earlier phase.
+var migrationPhases = []api.MigrationPhase{
+	api.MigrationPhaseQuarantine,
+	api.MigrationPhaseUpgrade,
+	api.MigrationPhaseCopy,
+	api.MigrationPhaseComplete,
+}
+
+func phaseIndex(phase api.MigrationPhase) int {
+	for i, p := range migrationPhases {
+		if p == phase {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkMigrationVersions returns an error unless etcd can be upgraded from one version to the other.
+// etcd upgrades one minor version at a time, v2 clusters are upgraded from 2.3 to 3.0.
+func checkMigrationVersions(from, to config.EtcdVersion) error {
+	if !strings.HasPrefix(string(from), "2.3.") || !strings.HasPrefix(string(to), "3.0.") {
+		return fmt.Errorf("cannot migrate etcd %s to %s, migrate 2.3.x to 3.0.x first", from, to)
+	}
+	return nil
+}
+
+func (m *EtcdManager) loadMigration() (*api.MigrationRequest, error) {
+	data, err := ioutil.ReadFile(filepath.Join(m.config.DataDir, migrationFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	state := &api.MigrationRequest{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", migrationFile, err)
+	}
+	return state, nil
+}
+
+// saveMigration persists the migration phase of the local member, nil removes it
+func (m *EtcdManager) saveMigration(state *api.MigrationRequest) error {
+	path := filepath.Join(m.config.DataDir, migrationFile)
+	if state == nil {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	} else {
+		data, err := json.Marshal(state)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("error writing %s: %v", migrationFile, err)
+		}
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.migration = state
+	return nil
+}
+
+// migrating returns true while a migration has not reached its last phase
+func (m *EtcdManager) migrating() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.migration != nil && m.migration.Phase != api.MigrationPhaseComplete
+}
+
+// needsMigration returns true if the leader has a migration to start or to finish
+func (m *EtcdManager) needsMigration() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.migration != nil {
+		return true
+	}
+	return m.flags != nil && m.flags.Version.IsV2() && !m.config.EtcdVersion.IsV2()
+}
+
+// etcdVersion returns the version of etcd to run: the version the local data was last
+// run with, or the configured version for a new member. The leader upgrades members to
+// the configured version one at a time, and migrates the data of v2 members to v3.
+func (m *EtcdManager) etcdVersion() config.EtcdVersion {
+	if !m.hasData() {
+		return m.clusterVersion()
+	}
+	data, err := ioutil.ReadFile(filepath.Join(m.config.DataDir, versionFile))
+	if err != nil {
+		return m.clusterVersion()
+	}
+	if v := config.EtcdVersion(strings.TrimSpace(string(data))); v != "" {
+		return v
+	}
+	return m.clusterVersion()
+}
+
+// migrationFlags adjusts f to the migration phase of the local member
+func (m *EtcdManager) migrationFlags(f *config.EtcdFlags) {
+	m.mutex.Lock()
+	state := m.migration
+	m.mutex.Unlock()
+	if state == nil {
+		return
+	}
+	switch state.Phase {
+	case api.MigrationPhaseQuarantine:
+		f.Version = config.EtcdVersion(state.FromVersion)
+		f.Quarantined = true
+	case api.MigrationPhaseUpgrade, api.MigrationPhaseCopy:
+		f.Version = config.EtcdVersion(state.ToVersion)
+		f.Quarantined = true
+		f.EnableV2 = true
+	}
+}
+
+// migrationReady returns true if etcd runs as the migration phase of the local member requires
+func (m *EtcdManager) migrationReady() bool {
+	if !m.isRunning() {
+		return false
+	}
+	m.mutex.Lock()
+	flags := m.flags
+	m.mutex.Unlock()
+
+	desired := &config.EtcdFlags{Version: flags.Version}
+	m.migrationFlags(desired)
+	return flags.Version == desired.Version && flags.Quarantined == desired.Quarantined
+}
+
+// reconcileMigration stops etcd if it does not run as the migration phase requires,
+// reconcile then restarts it with the flags of the phase
+func (m *EtcdManager) reconcileMigration() error {
+	m.mutex.Lock()
+	state := m.migration
+	m.mutex.Unlock()
+	if state == nil || !m.isRunning() || m.migrationReady() {
+		return nil
+	}
+	glog.Infof("restarting etcd member %s for migration phase %s", m.config.ID, state.Phase)
+	return m.stopEtcd()
+}
+
+// HandleMigration moves the local member to the migration phase pushed by the leader.
+// The member is ready once etcd was restarted as the phase requires.
+func (m *EtcdManager) HandleMigration(ctx context.Context, req *api.MigrationRequest) (*api.MigrationResponse, error) {
+	if leader, term := m.election.Leader(); string(leader) != req.Leader || term != req.Term {
+		return &api.MigrationResponse{Reason: fmt.Sprintf("leader for term %d is %q", term, leader)}, nil
+	}
+	if phaseIndex(req.Phase) < 0 {
+		return &api.MigrationResponse{Reason: fmt.Sprintf("unknown migration phase %q", req.Phase)}, nil
+	}
+	if !m.isRunning() && !m.hasData() {
+		return &api.MigrationResponse{Reason: fmt.Sprintf("peer %s has not started etcd", m.config.ID)}, nil
+	}
+
+	m.mutex.Lock()
+	state := m.migration
+	m.mutex.Unlock()
+	if state == nil || phaseIndex(req.Phase) > phaseIndex(state.Phase) {
+		glog.Infof("moving etcd member %s to migration phase %s of leader %s", m.config.ID, req.Phase, req.Leader)
+		state = req.DeepCopy()
+		if err := m.saveMigration(state); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &api.MigrationResponse{Phase: state.Phase, Ready: m.migrationReady()}
+	if !resp.Ready {
+		resp.Reason = fmt.Sprintf("restarting etcd member %s", m.config.ID)
+	}
+	return resp, nil
+}
+
+// migrate runs the migration of a v2 cluster to the configured v3 version, one phase
+// per call. A phase is pushed to every member and the next phase starts once all of
+// them are ready. The phases are persisted by every member, so a new leader resumes
+// the migration where the last one stopped.
+func (m *EtcdManager) migrate(ctx context.Context, peers map[api.PeerID]*discovery.Peer, term int64) error {
+	m.mutex.Lock()
+	flags, state, version := m.flags, m.migration, m.config.EtcdVersion
+	m.mutex.Unlock()
+
+	if state == nil {
+		if !flags.Version.IsV2() || version.IsV2() {
+			return nil
+		}
+		if err := checkMigrationVersions(flags.Version, version); err != nil {
+			return err
+		}
+		glog.Infof("migrating cluster %s from etcd %s to %s", m.config.ClusterName, flags.Version, version)
+		state = &api.MigrationRequest{
+			Phase:       api.MigrationPhaseQuarantine,
+			FromVersion: string(flags.Version),
+			ToVersion:   string(version),
+		}
+	}
+	req := state.DeepCopy()
+	req.Leader = string(m.config.ID)
+	req.Term = term
+
+	ready, err := m.pushMigration(ctx, peers, req)
+	if err != nil || !ready {
+		return err
+	}
+
+	switch req.Phase {
+	case api.MigrationPhaseQuarantine:
+		req.Phase = api.MigrationPhaseUpgrade
+	case api.MigrationPhaseUpgrade:
+		req.Phase = api.MigrationPhaseCopy
+	case api.MigrationPhaseCopy:
+		// copying may take longer than a reconcile, it must not hold up the election
+		m.mutex.Lock()
+		copying := m.copying
+		m.copying = true
+		m.mutex.Unlock()
+		if !copying {
+			go m.copyKeys(req)
+		}
+		return nil
+	case api.MigrationPhaseComplete:
+		glog.Infof("migrated cluster %s to etcd %s", m.config.ClusterName, req.ToVersion)
+		return m.saveMigration(nil)
+	}
+	// the next reconcile pushes the next phase, after restarting the local member if needed
+	return m.saveMigration(req)
+}
+
+// pushMigration moves every member of the cluster to the phase of req. It returns true
+// if all of them are ready. A member that is ahead moves the local member to its phase.
+func (m *EtcdManager) pushMigration(ctx context.Context, peers map[api.PeerID]*discovery.Peer, req *api.MigrationRequest) (bool, error) {
+	resp, err := m.HandleMigration(ctx, req)
+	if err != nil {
+		return false, err
+	}
+	if !resp.Ready {
+		glog.Infof("waiting for migration phase %s of the local member: %s", req.Phase, resp.Reason)
+		return false, nil
+	}
+
+	members, err := m.migrationMembers(ctx)
+	if err != nil {
+		return false, err
+	}
+	ready := true
+	for _, name := range members {
+		if name == string(m.config.ID) {
+			continue
+		}
+		peer, ok := peers[api.PeerID(name)]
+		if !ok {
+			glog.Infof("waiting for member %s of cluster %s to be reachable", name, m.config.ClusterName)
+			ready = false
+			continue
+		}
+		client, err := m.newPeerClient(peer.Address)
+		if err != nil {
+			return false, err
+		}
+		result, err := client.Migrations().Create(&api.Migration{Request: req})
+		if err != nil {
+			glog.Warningf("error pushing migration phase %s to %s: %v", req.Phase, name, err)
+			ready = false
+			continue
+		}
+		resp := result.Response
+		if resp == nil {
+			ready = false
+			continue
+		}
+		if phaseIndex(resp.Phase) > phaseIndex(req.Phase) {
+			glog.Infof("member %s is in the later migration phase %s", name, resp.Phase)
+			next := req.DeepCopy()
+			next.Phase = resp.Phase
+			return false, m.saveMigration(next)
+		}
+		if !resp.Ready {
+			glog.Infof("waiting for migration phase %s of member %s: %s", req.Phase, name, resp.Reason)
+			ready = false
+		}
+	}
+	return ready, nil
+}
+
+// migrationMembers returns the names of the etcd members, read from the local member
+func (m *EtcdManager) migrationMembers(ctx context.Context) ([]string, error) {
+	m.mutex.Lock()
+	flags := m.flags
+	m.mutex.Unlock()
+
+	client, err := flags.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	members, err := client.ListMembers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing members: %v", err)
+	}
+	var names []string
+	for _, member := range members {
+		if member.Name == "" {
+			return nil, fmt.Errorf("member %v of cluster %s has not started", member.PeerURLs, m.config.ClusterName)
+		}
+		names = append(names, member.Name)
+	}
+	return names, nil
+}
+
+// copyKeys copies the keys of the v2 API of the quarantined cluster into its v3 keyspace,
+// then moves the local member to the last phase of the migration of req
+func (m *EtcdManager) copyKeys(req *api.MigrationRequest) {
+	m.mutex.Lock()
+	flags := m.flags
+	m.mutex.Unlock()
+	defer func() {
+		m.mutex.Lock()
+		m.copying = false
+		m.mutex.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), migrationTimeout)
+	defer cancel()
+
+	tlsConfig, err := flags.ClientTLS.ClientConfig()
+	if err != nil {
+		glog.Warningf("error loading etcd client certificates for migration: %v", err)
+		return
+	}
+	from, err := etcdclient.NewV2Client(flags.ClientURLs(), tlsConfig)
+	if err != nil {
+		glog.Warningf("error creating etcd v2 client for migration: %v", err)
+		return
+	}
+	defer from.Close()
+	to, err := etcdclient.NewV3Client(flags.ClientURLs(), tlsConfig)
+	if err != nil {
+		glog.Warningf("error creating etcd v3 client for migration: %v", err)
+		return
+	}
+	defer to.Close()
+
+	count, err := copyAndVerify(ctx, from, to)
+	if err != nil {
+		glog.Warningf("error migrating keys of cluster %s: %v", m.config.ClusterName, err)
+		return
+	}
+	glog.Infof("copied %d keys of cluster %s to the v3 keyspace", count, m.config.ClusterName)
+
+	m.mutex.Lock()
+	state := m.migration
+	m.mutex.Unlock()
+	if state == nil || state.Phase != api.MigrationPhaseCopy {
+		return
+	}
+	next := req.DeepCopy()
+	next.Phase = api.MigrationPhaseComplete
+	if err := m.saveMigration(next); err != nil {
+		glog.Warningf("error saving migration phase %s: %v", next.Phase, err)
+	}
+}
+
+// sampler records a sample of the keys written to the wrapped client
+type sampler struct {
+	etcdclient.EtcdClient
+
+	count   int
+	samples map[string][]byte
+}
+
+func (s *sampler) Put(ctx context.Context, key string, value []byte) error {
+	if err := s.EtcdClient.Put(ctx, key, value); err != nil {
+		return err
+	}
+	if s.count%migrationSampleInterval == 0 {
+		s.samples[key] = value
+	}
+	s.count++
+	return nil
+}
+
+// copyAndVerify copies every key of from to to, then compares the number of keys and
+// a sample of the values. Copying again overwrites the keys, so an interrupted copy is
+// simply repeated. It returns the number of copied keys.
+func copyAndVerify(ctx context.Context, from, to etcdclient.EtcdClient) (int, error) {
+	dest := &sampler{EtcdClient: to, samples: map[string][]byte{}}
+	count, err := from.CopyTo(ctx, dest)
+	if err != nil {
+		return count, err
+	}
+
+	for _, c := range []etcdclient.EtcdClient{from, to} {
+		n, err := c.KeyCount(ctx)
+		if err != nil {
+			return count, fmt.Errorf("error counting keys: %v", err)
+		}
+		if n != count {
+			return count, fmt.Errorf("copied %d keys, found %d keys in %v", count, n, c)
+		}
+	}
+	for key, value := range dest.samples {
+		for _, c := range []etcdclient.EtcdClient{from, to} {
+			actual, err := c.Get(ctx, key, true)
+			if err != nil {
+				return count, fmt.Errorf("error reading key %q: %v", key, err)
+			}
+			if !bytes.Equal(actual, value) {
+				return count, fmt.Errorf("value of key %q in %v does not match the copied value", key, c)
+			}
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,179 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/config"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"github.com/etcd-manager/etcd-discovery/pkg/etcdclient"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// fakeKeys is an etcd client keeping its keys in memory
+type fakeKeys struct {
+	etcdclient.EtcdClient
+
+	keys map[string][]byte
+}
+
+func (f *fakeKeys) Put(ctx context.Context, key string, value []byte) error {
+	f.keys[key] = value
+	return nil
+}
+
+func (f *fakeKeys) Get(ctx context.Context, key string, quorum bool) ([]byte, error) {
+	return f.keys[key], nil
+}
+
+func (f *fakeKeys) KeyCount(ctx context.Context) (int, error) {
+	return len(f.keys), nil
+}
+
+func (f *fakeKeys) CopyTo(ctx context.Context, dest etcdclient.EtcdClient) (int, error) {
+	var keys []string
+	for k := range f.keys {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := dest.Put(ctx, k, f.keys[k]); err != nil {
+			return 0, err
+		}
+	}
+	return len(keys), nil
+}
+
+func TestCopyAndVerify(t *testing.T) {
+	from := &fakeKeys{keys: map[string][]byte{}}
+	for i := 0; i < 250; i++ {
+		from.keys[fmt.Sprintf("/registry/pods/%03d", i)] = []byte(fmt.Sprintf("pod-%d", i))
+	}
+
+	to := &fakeKeys{keys: map[string][]byte{}}
+	count, err := copyAndVerify(context.Background(), from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 250 || len(to.keys) != 250 {
+		t.Errorf("expected 250 copied keys, got %d of %d", count, len(to.keys))
+	}
+
+	// a key left over in the destination does not belong to the copy
+	to.keys["/stale"] = []byte("stale")
+	if _, err := copyAndVerify(context.Background(), from, to); err == nil {
+		t.Errorf("expected error verifying a copy with extra keys")
+	}
+}
+
+func TestCheckMigrationVersions(t *testing.T) {
+	cases := []struct {
+		from, to config.EtcdVersion
+		valid    bool
+	}{
+		{"2.3.7", "3.0.17", true},
+		{"2.2.5", "3.0.17", false},
+		{"2.3.7", "3.1.11", false},
+		{"3.0.17", "3.1.11", false},
+	}
+	for _, c := range cases {
+		if err := checkMigrationVersions(c.from, c.to); (err == nil) != c.valid {
+			t.Errorf("migrating %s to %s: unexpected error %v", c.from, c.to, err)
+		}
+	}
+}
+
+func TestHandleMigration(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// b follows a, which was elected leader for term 1
+	election := discovery.NewElection("b", 3, time.Minute, clock.NewFakeClock(time.Now()))
+	resp := &api.PingResponse{}
+	election.HandlePing(&api.PingRequest{Info: &api.PeerInfo{ID: "a"}, Leader: "a", Term: 1}, resp)
+	if !resp.LeaseGranted {
+		t.Fatalf("b did not grant the lease to a")
+	}
+
+	m := &EtcdManager{
+		config: &EtcdConfig{
+			EtcdCluster: config.EtcdCluster{ClusterName: "test", ClusterSize: 3, DataDir: dir, EtcdVersion: "3.0.17"},
+			ID:          "b",
+		},
+		election: election,
+	}
+	req := &api.MigrationRequest{
+		Leader:      "a",
+		Term:        1,
+		Phase:       api.MigrationPhaseUpgrade,
+		FromVersion: "2.3.7",
+		ToVersion:   "3.0.17",
+	}
+
+	if resp, err := m.HandleMigration(context.Background(), req); err != nil || resp.Phase != "" {
+		t.Errorf("expected a member without data to refuse the migration, got %+v, %v", resp, err)
+	}
+	if err := os.MkdirAll(filepath.Join(m.etcdDataDir(), "member"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, versionFile), []byte("2.3.7"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if v := m.etcdVersion(); v != "2.3.7" {
+		t.Errorf("expected v2 data to run with etcd 2.3.7, got %s", v)
+	}
+
+	stale := req.DeepCopy()
+	stale.Term = 0
+	if resp, err := m.HandleMigration(context.Background(), stale); err != nil || resp.Phase != "" {
+		t.Errorf("expected a migration of an old term to be refused, got %+v, %v", resp, err)
+	}
+
+	resp2, err := m.HandleMigration(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp2.Phase != api.MigrationPhaseUpgrade || resp2.Ready {
+		t.Errorf("expected upgrade phase pending a restart, got %+v", resp2)
+	}
+	if !m.migrating() {
+		t.Errorf("expected member to be migrating")
+	}
+
+	// phases never go back
+	back := req.DeepCopy()
+	back.Phase = api.MigrationPhaseQuarantine
+	if resp, err := m.HandleMigration(context.Background(), back); err != nil || resp.Phase != api.MigrationPhaseUpgrade {
+		t.Errorf("expected member to stay in the upgrade phase, got %+v, %v", resp, err)
+	}
+
+	state, err := m.loadMigration()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state == nil || state.Phase != api.MigrationPhaseUpgrade {
+		t.Fatalf("expected persisted upgrade phase, got %+v", state)
+	}
+	f := &config.EtcdFlags{Version: m.etcdVersion()}
+	m.migrationFlags(f)
+	if f.Version != "3.0.17" || !f.Quarantined || !bool(f.EnableV2) {
+		t.Errorf("unexpected flags for the upgrade phase %+v", f)
+	}
+
+	if err := m.saveMigration(nil); err != nil {
+		t.Fatal(err)
+	}
+	if state, err := m.loadMigration(); err != nil || state != nil {
+		t.Errorf("expected migration to be removed, got %+v, %v", state, err)
+	}
+}
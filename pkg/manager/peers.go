@@ -0,0 +1,93 @@
+package manager
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	cs "github.com/etcd-manager/etcd-discovery/client/clientset/versioned/typed/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"k8s.io/client-go/rest"
+)
+
+const peerTimeout = 5 * time.Second
+
+// newPeerClient returns a client for the discovery server listening on address (host:port)
+func (m *EtcdManager) newPeerClient(address string) (cs.DiscoveryV1alpha1Interface, error) {
+	return cs.NewForConfig(&rest.Config{
+		Host: "https://" + address,
+		TLSClientConfig: rest.TLSClientConfig{
+			CAFile:   m.config.PeerTLS.CACertFile,
+			CertFile: m.config.PeerTLS.CertFile,
+			KeyFile:  m.config.PeerTLS.KeyFile,
+		},
+		Timeout: peerTimeout,
+	})
+}
+
+func (m *EtcdManager) newPingClient(address string) (cs.PingsGetter, error) {
+	return m.newPeerClient(address)
+}
+
+// join asks the leader to add us to its etcd cluster
+func (m *EtcdManager) join(leader *discovery.Peer) (*api.MemberResponse, error) {
+	client, err := m.newPeerClient(leader.Address)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Members().Create(&api.Member{
+		Request: &api.MemberRequest{
+			PeerURL: m.peerURL(),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Response == nil {
+		return nil, fmt.Errorf("leader %s did not return cluster membership", leader.ID)
+	}
+	return resp.Response, nil
+}
+
+// parseInitialCluster converts the name=url pairs returned by the Member endpoint
+// into the name to host mapping used by EtcdFlags.InitialCluster
+func parseInitialCluster(peerURLs []string) (map[string]string, error) {
+	cluster := map[string]string{}
+	for _, s := range peerURLs {
+		parts := strings.SplitN(s, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid peer url %q, expected name=url", s)
+		}
+		u, err := url.Parse(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid peer url %q: %v", s, err)
+		}
+		if parts[0] != "" {
+			cluster[parts[0]] = u.Hostname()
+		}
+	}
+	return cluster, nil
+}
+
+// peerNameForURL returns the id of the discovery peer running on the host of peerURL
+func peerNameForURL(peers map[api.PeerID]*discovery.Peer, peerURL string) string {
+	u, err := url.Parse(peerURL)
+	if err != nil {
+		return ""
+	}
+	host := u.Hostname()
+	for _, p := range peers {
+		if h, _, err := net.SplitHostPort(p.Address); err == nil && h == host {
+			return string(p.ID)
+		}
+		for _, h := range p.Hosts {
+			if h == host {
+				return string(p.ID)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,166 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// quarantineFile is written to the data dir while the local member is to run quarantined
+const quarantineFile = "quarantined"
+
+// quarantineMode returns the quarantine mode of the local member
+func (m *EtcdManager) quarantineMode() api.QuarantineMode {
+	if _, err := os.Stat(filepath.Join(m.config.DataDir, quarantineFile)); err == nil {
+		return api.QuarantineModeEnabled
+	}
+	return api.QuarantineModeDisabled
+}
+
+// setQuarantineMode persists the quarantine mode of the local member, reconcile then
+// restarts etcd with it
+func (m *EtcdManager) setQuarantineMode(mode api.QuarantineMode) error {
+	path := filepath.Join(m.config.DataDir, quarantineFile)
+	if mode == api.QuarantineModeEnabled {
+		if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+			return fmt.Errorf("error writing %s: %v", quarantineFile, err)
+		}
+	} else if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.quarantineAcked = nil
+	return nil
+}
+
+// quarantineStatus returns the quarantine mode of the local member and of its etcd process
+func (m *EtcdManager) quarantineStatus() api.QuarantineMember {
+	status := api.QuarantineMember{
+		ID:      string(m.config.ID),
+		Mode:    m.quarantineMode(),
+		Running: m.isRunning(),
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if status.Running && m.flags != nil {
+		status.Quarantined = m.flags.Quarantined
+	}
+	return status
+}
+
+// reconcileQuarantine stops etcd if it does not run in the quarantine mode of the local
+// member, reconcile then restarts it. A migration sets the quarantine of its phases.
+func (m *EtcdManager) reconcileQuarantine() error {
+	if m.migrating() || !m.isRunning() {
+		return nil
+	}
+	quarantined := m.quarantineMode() == api.QuarantineModeEnabled
+	m.mutex.Lock()
+	running := m.flags.Quarantined
+	m.mutex.Unlock()
+	if running != quarantined {
+		glog.Infof("restarting etcd member %s with quarantined=%v", m.config.ID, quarantined)
+		return m.stopEtcd()
+	}
+	return nil
+}
+
+// HandleQuarantine changes the quarantine mode. Requests of operators are served by the
+// leader, which persists the mode and pushes it to every peer. Requests of the leader
+// move the local member to its mode.
+func (m *EtcdManager) HandleQuarantine(ctx context.Context, req *api.QuarantineRequest) (*api.QuarantineResponse, error) {
+	leader, term := m.election.Leader()
+	if req.Leader == "" {
+		if leader != m.config.ID {
+			return &api.QuarantineResponse{Reason: fmt.Sprintf("peer %s is not the leader, ask %q", m.config.ID, leader)}, nil
+		}
+		if req.Mode != "" && req.Mode != m.quarantineMode() {
+			glog.Infof("moving cluster %s to quarantine mode %s", m.config.ClusterName, req.Mode)
+			if err := m.setQuarantineMode(req.Mode); err != nil {
+				return nil, err
+			}
+		}
+		m.mutex.Lock()
+		peers := m.peers
+		m.mutex.Unlock()
+		members := m.pushQuarantine(ctx, peers, term, true)
+		return &api.QuarantineResponse{Mode: m.quarantineMode(), Members: members}, nil
+	}
+
+	if string(leader) != req.Leader || term != req.Term {
+		return &api.QuarantineResponse{Reason: fmt.Sprintf("leader for term %d is %q", term, leader)}, nil
+	}
+	if req.Mode != "" && req.Mode != m.quarantineMode() {
+		glog.Infof("moving etcd member %s to quarantine mode %s of leader %s", m.config.ID, req.Mode, req.Leader)
+		if err := m.setQuarantineMode(req.Mode); err != nil {
+			return nil, err
+		}
+	}
+	return &api.QuarantineResponse{Mode: m.quarantineMode(), Members: []api.QuarantineMember{m.quarantineStatus()}}, nil
+}
+
+// pushQuarantine pushes the quarantine mode of the leader to the peers that did not
+// take it yet in term, or to every peer if all is true. It returns the status of the
+// peers it reached.
+func (m *EtcdManager) pushQuarantine(ctx context.Context, peers map[api.PeerID]*discovery.Peer, term int64, all bool) []api.QuarantineMember {
+	mode := m.quarantineMode()
+	m.mutex.Lock()
+	if m.quarantineAcked == nil || m.quarantineTerm != term {
+		m.quarantineAcked = sets.NewString()
+		m.quarantineTerm = term
+	}
+	acked := sets.NewString(m.quarantineAcked.List()...)
+	m.mutex.Unlock()
+
+	req := &api.QuarantineRequest{Leader: string(m.config.ID), Term: term, Mode: mode}
+	var members []api.QuarantineMember
+	for id, peer := range peers {
+		if !all && acked.Has(string(id)) {
+			continue
+		}
+		var status []api.QuarantineMember
+		if id == m.config.ID {
+			status = []api.QuarantineMember{m.quarantineStatus()}
+		} else {
+			client, err := m.newPeerClient(peer.Address)
+			if err != nil {
+				glog.Warningf("error creating client for peer %s: %v", id, err)
+				continue
+			}
+			result, err := client.Quarantines().Create(&api.Quarantine{Request: req})
+			if err != nil {
+				glog.Warningf("error pushing quarantine mode %s to %s: %v", mode, id, err)
+				continue
+			}
+			if result.Response == nil || result.Response.Reason != "" {
+				glog.Warningf("peer %s refused quarantine mode %s: %v", id, mode, result.Response)
+				continue
+			}
+			status = result.Response.Members
+		}
+		for _, s := range status {
+			if s.Mode == mode {
+				acked.Insert(s.ID)
+			}
+		}
+		members = append(members, status...)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].ID < members[j].ID })
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.quarantineAcked != nil && m.quarantineTerm == term {
+		m.quarantineAcked = acked
+	}
+	return members
+}
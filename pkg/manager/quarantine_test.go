@@ -0,0 +1,129 @@
+package manager
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/config"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// soleLeader returns the election of a single peer cluster, won by id for term 1
+func soleLeader(t *testing.T, id api.PeerID) *discovery.Election {
+	election := discovery.NewElection(id, 1, time.Minute, clock.NewFakeClock(time.Now()))
+	self := map[api.PeerID]*discovery.Peer{id: {ID: id}}
+	for i := 0; i < 2; i++ {
+		req := &api.PingRequest{}
+		start := election.Prepare(req)
+		election.Update(start, req, self)
+	}
+	if leader, term := election.Leader(); leader != id || term != 1 {
+		t.Fatalf("expected %s to lead term 1, got %q for term %d", id, leader, term)
+	}
+	return election
+}
+
+func TestHandleQuarantine(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// b follows a, which was elected leader for term 1
+	election := discovery.NewElection("b", 3, time.Minute, clock.NewFakeClock(time.Now()))
+	resp := &api.PingResponse{}
+	election.HandlePing(&api.PingRequest{Info: &api.PeerInfo{ID: "a"}, Leader: "a", Term: 1}, resp)
+	if !resp.LeaseGranted {
+		t.Fatalf("b did not grant the lease to a")
+	}
+
+	m := &EtcdManager{
+		config: &EtcdConfig{
+			EtcdCluster: config.EtcdCluster{ClusterName: "test", ClusterSize: 3, DataDir: dir},
+			ID:          "b",
+		},
+		election: election,
+	}
+	if mode := m.quarantineMode(); mode != api.QuarantineModeDisabled {
+		t.Errorf("expected quarantine to be disabled, got %s", mode)
+	}
+
+	// operators talk to the leader
+	resp2, err := m.HandleQuarantine(context.Background(), &api.QuarantineRequest{Mode: api.QuarantineModeEnabled})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp2.Reason == "" || m.quarantineMode() != api.QuarantineModeDisabled {
+		t.Errorf("expected follower to refuse the request of an operator, got %+v", resp2)
+	}
+
+	resp2, err = m.HandleQuarantine(context.Background(), &api.QuarantineRequest{Leader: "a", Term: 1, Mode: api.QuarantineModeEnabled})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := api.QuarantineMember{ID: "b", Mode: api.QuarantineModeEnabled}
+	if resp2.Mode != api.QuarantineModeEnabled || len(resp2.Members) != 1 || resp2.Members[0] != expected {
+		t.Errorf("unexpected response %+v", resp2)
+	}
+	if f := m.newEtcdFlags(config.ClusterStateExisting, "token", nil); !f.Quarantined {
+		t.Errorf("expected etcd to restart quarantined")
+	}
+
+	if resp, err := m.HandleQuarantine(context.Background(), &api.QuarantineRequest{Leader: "c", Term: 1, Mode: api.QuarantineModeDisabled}); err != nil || resp.Reason == "" {
+		t.Errorf("expected the mode of another leader to be refused, got %+v, %v", resp, err)
+	}
+	if mode := m.quarantineMode(); mode != api.QuarantineModeEnabled {
+		t.Errorf("expected quarantine to stay enabled, got %s", mode)
+	}
+}
+
+func TestQuarantineLeader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := &EtcdManager{
+		config: &EtcdConfig{
+			EtcdCluster: config.EtcdCluster{ClusterName: "test", ClusterSize: 1, DataDir: dir},
+			ID:          "a",
+		},
+		election: soleLeader(t, "a"),
+		peers:    map[api.PeerID]*discovery.Peer{"a": {ID: "a"}},
+	}
+
+	resp, err := m.HandleQuarantine(context.Background(), &api.QuarantineRequest{Mode: api.QuarantineModeEnabled})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := api.QuarantineMember{ID: "a", Mode: api.QuarantineModeEnabled}
+	if resp.Reason != "" || resp.Mode != api.QuarantineModeEnabled || len(resp.Members) != 1 || resp.Members[0] != expected {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if !m.quarantineAcked.Has("a") || m.quarantineTerm != 1 {
+		t.Errorf("expected leader to take its own mode in term 1, got %v for term %d", m.quarantineAcked, m.quarantineTerm)
+	}
+
+	// a status request does not change the mode
+	resp, err = m.HandleQuarantine(context.Background(), &api.QuarantineRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Mode != api.QuarantineModeEnabled {
+		t.Errorf("expected quarantine to stay enabled, got %+v", resp)
+	}
+
+	if _, err := m.HandleQuarantine(context.Background(), &api.QuarantineRequest{Mode: api.QuarantineModeDisabled}); err != nil {
+		t.Fatal(err)
+	}
+	if mode := m.quarantineMode(); mode != api.QuarantineModeDisabled {
+		t.Errorf("expected quarantine to be disabled, got %s", mode)
+	}
+}
@@ -0,0 +1,227 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/backup"
+	"github.com/etcd-manager/etcd-discovery/pkg/config"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"github.com/etcd-manager/etcd-discovery/pkg/etcd"
+	"github.com/golang/glog"
+)
+
+const (
+	// restoreMarker is written to the data dir once the local member is restored, so
+	// restarting with the same restore settings does not restore the cluster again
+	restoreMarker = "restored"
+	// restoreRequest keeps the backup pushed by the leader in the data dir until the local
+	// member is restored from it
+	restoreRequest = "restore-backup"
+)
+
+// restoreBackup returns the backup to restore the cluster from: the one pushed by the
+// leader, or RestoreBackup
+func (m *EtcdManager) restoreBackup() string {
+	if data, err := ioutil.ReadFile(filepath.Join(m.config.DataDir, restoreRequest)); err == nil {
+		return strings.TrimSpace(string(data))
+	}
+	return m.config.RestoreBackup
+}
+
+// restorePending returns true if the cluster is to be restored from a backup and
+// the local member was not restored yet
+func (m *EtcdManager) restorePending() bool {
+	if m.restoreBackup() == "" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(m.config.DataDir, restoreMarker))
+	return os.IsNotExist(err)
+}
+
+// reconcileRestore stops the local member once the leader pushed a restore, the member
+// then waits for the leader to plan the restore
+func (m *EtcdManager) reconcileRestore() error {
+	if !m.isRunning() || !m.restorePending() {
+		return nil
+	}
+	// the plan the member started with is replaced by the plan of the restore
+	m.mutex.Lock()
+	m.plan = nil
+	m.proposal = nil
+	m.mutex.Unlock()
+	glog.Infof("stopping etcd member %s to restore cluster %s from backup %s", m.config.ID, m.config.ClusterName, m.restoreBackup())
+	return m.stopEtcd()
+}
+
+// HandleRestore restores the running cluster from a backup. Requests of operators are
+// served by the leader, which pushes the backup to every peer, itself last. Requests of
+// the leader record the backup, the next reconcile stops the local member.
+func (m *EtcdManager) HandleRestore(ctx context.Context, req *api.RestoreRequest) (*api.RestoreResponse, error) {
+	leader, term := m.election.Leader()
+	if req.Leader == "" {
+		if leader != m.config.ID {
+			return &api.RestoreResponse{Reason: fmt.Sprintf("peer %s is not the leader, ask %q", m.config.ID, leader)}, nil
+		}
+		if m.migrating() {
+			return &api.RestoreResponse{Reason: fmt.Sprintf("cluster %s is migrating", m.config.ClusterName)}, nil
+		}
+		name := req.Backup
+		if name == "" {
+			name = backup.Latest
+		}
+		name, err := backup.ResolveName(m.backups.Store(), name)
+		if err != nil {
+			return &api.RestoreResponse{Reason: err.Error()}, nil
+		}
+		m.mutex.Lock()
+		peers := m.peers
+		m.mutex.Unlock()
+		return m.pushRestore(ctx, peers, term, name)
+	}
+
+	if string(leader) != req.Leader || term != req.Term {
+		return &api.RestoreResponse{Reason: fmt.Sprintf("leader for term %d is %q", term, leader)}, nil
+	}
+	if err := m.requestRestore(req.Backup); err != nil {
+		return nil, err
+	}
+	return &api.RestoreResponse{Backup: req.Backup, Members: []string{string(m.config.ID)}}, nil
+}
+
+// pushRestore pushes the restore of backup name to the peers, the local peer last. It
+// stops at the first peer that does not take it, the operator may ask again.
+func (m *EtcdManager) pushRestore(ctx context.Context, peers map[api.PeerID]*discovery.Peer, term int64, name string) (*api.RestoreResponse, error) {
+	glog.Infof("restoring cluster %s from backup %s", m.config.ClusterName, name)
+	req := &api.RestoreRequest{Leader: string(m.config.ID), Term: term, Backup: name}
+	resp := &api.RestoreResponse{Backup: name}
+
+	var ids []string
+	for id := range peers {
+		if id != m.config.ID {
+			ids = append(ids, string(id))
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		client, err := m.newPeerClient(peers[api.PeerID(id)].Address)
+		if err != nil {
+			return nil, err
+		}
+		result, err := client.Restores().Create(&api.Restore{Request: req})
+		if err != nil {
+			return nil, fmt.Errorf("error pushing the restore of backup %s to %s: %v", name, id, err)
+		}
+		if result.Response == nil || result.Response.Reason != "" {
+			resp.Reason = fmt.Sprintf("peer %s refused the restore: %v", id, result.Response)
+			return resp, nil
+		}
+		resp.Members = append(resp.Members, id)
+	}
+
+	if err := m.requestRestore(name); err != nil {
+		return nil, err
+	}
+	resp.Members = append(resp.Members, string(m.config.ID))
+	sort.Strings(resp.Members)
+	return resp, nil
+}
+
+// requestRestore records that the local member is to be restored from backup name
+func (m *EtcdManager) requestRestore(name string) error {
+	if err := ioutil.WriteFile(filepath.Join(m.config.DataDir, restoreRequest), []byte(name), 0644); err != nil {
+		return fmt.Errorf("error recording restore of backup %s: %v", name, err)
+	}
+	if err := os.Remove(filepath.Join(m.config.DataDir, restoreMarker)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	glog.Infof("etcd member %s is to be restored from backup %s", m.config.ID, name)
+	return nil
+}
+
+// planRestore makes plan restore the backup selected by restoreBackup
+func (m *EtcdManager) planRestore(plan *api.PlanRequest) error {
+	store := m.backups.Store()
+	name, err := backup.ResolveName(store, m.restoreBackup())
+	if err != nil {
+		return err
+	}
+	manifest, err := store.LoadManifest(name)
+	if err != nil {
+		return err
+	}
+	plan.Backup = name
+	plan.Revision = manifest.Revision
+	// a new token gives the restored cluster a new id, so members of the lost cluster cannot join it
+	plan.ClusterToken = plan.ClusterToken + "-" + name
+	glog.Infof("restoring cluster %s from backup %s at revision %d", m.config.ClusterName, name, manifest.Revision)
+	return nil
+}
+
+// checkRestorePlan returns an error if the backup of plan is not the one planned by the leader
+func (m *EtcdManager) checkRestorePlan(plan *api.PlanRequest) error {
+	manifest, err := m.backups.Store().LoadManifest(plan.Backup)
+	if err != nil {
+		return err
+	}
+	if manifest.Revision != plan.Revision {
+		return fmt.Errorf("backup %s is at revision %d, planned revision is %d", plan.Backup, manifest.Revision, plan.Revision)
+	}
+	return nil
+}
+
+// restoreData replaces the data of the local member with the backup of plan. flags are
+// the flags etcd starts with afterwards: the snapshot is restored with the same name,
+// initial cluster and token on every member, so the members start as a new cluster
+// and must not use --force-new-cluster, which would drop the other restored members.
+func (m *EtcdManager) restoreData(plan *api.PlanRequest, flags *config.EtcdFlags) error {
+	dir, err := ioutil.TempDir(m.config.DataDir, "restore")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	snapshot := filepath.Join(dir, "snapshot.db")
+	manifest, err := backup.FetchBackup(m.backups.Store(), plan.Backup, snapshot)
+	if err != nil {
+		return err
+	}
+	if manifest.Revision != plan.Revision {
+		return fmt.Errorf("backup %s is at revision %d, planned revision is %d", plan.Backup, manifest.Revision, plan.Revision)
+	}
+
+	if err := m.moveDataAside(); err != nil {
+		return err
+	}
+	flags.ForceNewCluster = false
+	if err := etcd.RestoreSnapshot(flags, snapshot); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(m.config.DataDir, restoreMarker), []byte(plan.Backup), 0644); err != nil {
+		return fmt.Errorf("error recording restore of backup %s: %v", plan.Backup, err)
+	}
+	if err := os.Remove(filepath.Join(m.config.DataDir, restoreRequest)); err != nil && !os.IsNotExist(err) {
+		glog.Warningf("error removing the restore request of backup %s: %v", plan.Backup, err)
+	}
+	glog.Infof("restored etcd member %s from backup %s at revision %d", m.config.ID, plan.Backup, manifest.Revision)
+	return nil
+}
+
+// moveDataAside makes room for a restore. The data of a member that ran before is kept
+// next to the new data dir, the leftovers of a failed restore are removed.
+func (m *EtcdManager) moveDataAside() error {
+	dataDir := m.etcdDataDir()
+	if m.hasData() {
+		old := dataDir + "-" + backup.NewName(time.Now())
+		glog.Infof("moving data of etcd member %s to %s", m.config.ID, old)
+		return os.Rename(dataDir, old)
+	}
+	return os.RemoveAll(dataDir)
+}
@@ -0,0 +1,119 @@
+package manager
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/backup"
+	"github.com/etcd-manager/etcd-discovery/pkg/config"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestHandleRestoreFollower(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// b follows a, which was elected leader for term 1
+	election := discovery.NewElection("b", 3, time.Minute, clock.NewFakeClock(time.Now()))
+	resp := &api.PingResponse{}
+	election.HandlePing(&api.PingRequest{Info: &api.PeerInfo{ID: "a"}, Leader: "a", Term: 1}, resp)
+	if !resp.LeaseGranted {
+		t.Fatalf("b did not grant the lease to a")
+	}
+
+	m := &EtcdManager{
+		config: &EtcdConfig{
+			EtcdCluster: config.EtcdCluster{ClusterName: "test", ClusterSize: 3, DataDir: dir},
+			ID:          "b",
+		},
+		election: election,
+	}
+	name := backup.NewName(time.Now())
+
+	// operators talk to the leader
+	if resp, err := m.HandleRestore(context.Background(), &api.RestoreRequest{Backup: name}); err != nil || resp.Reason == "" {
+		t.Errorf("expected follower to refuse the request of an operator, got %+v, %v", resp, err)
+	}
+	if resp, err := m.HandleRestore(context.Background(), &api.RestoreRequest{Leader: "c", Term: 1, Backup: name}); err != nil || resp.Reason == "" {
+		t.Errorf("expected the restore of another leader to be refused, got %+v, %v", resp, err)
+	}
+	if m.restorePending() {
+		t.Fatalf("expected no restore before the leader pushed one")
+	}
+
+	resp2, err := m.HandleRestore(context.Background(), &api.RestoreRequest{Leader: "a", Term: 1, Backup: name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp2.Reason != "" || resp2.Backup != name || len(resp2.Members) != 1 || resp2.Members[0] != "b" {
+		t.Errorf("unexpected response %+v", resp2)
+	}
+	if !m.restorePending() || m.restoreBackup() != name {
+		t.Errorf("expected member to be restored from %s, got %q", name, m.restoreBackup())
+	}
+}
+
+func TestHandleRestoreLeader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := backup.NewFileStore(filepath.Join(dir, "backups"))
+	m := &EtcdManager{
+		config: &EtcdConfig{
+			EtcdCluster: config.EtcdCluster{ClusterName: "test", ClusterSize: 1, DataDir: filepath.Join(dir, "data")},
+			ID:          "a",
+		},
+		election: soleLeader(t, "a"),
+		peers:    map[api.PeerID]*discovery.Peer{"a": {ID: "a"}},
+		backups:  backup.NewController(store, 0, backup.RetentionPolicy{}, clock.RealClock{}),
+	}
+	if err := os.MkdirAll(m.config.DataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp, err := m.HandleRestore(context.Background(), &api.RestoreRequest{}); err != nil || resp.Reason == "" {
+		t.Errorf("expected a restore without backups to be refused, got %+v, %v", resp, err)
+	}
+
+	snapshot := filepath.Join(dir, "snapshot")
+	if err := ioutil.WriteFile(snapshot, []byte("snapshot"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	taken := time.Now().Add(-time.Hour)
+	name := backup.NewName(taken)
+	if err := store.AddBackup(name, snapshot, &backup.Manifest{Revision: 42, EtcdVersion: "3.2.13", Timestamp: taken}); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := m.HandleBackup(context.Background(), &api.BackupRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backups.Name != "" || len(backups.Backups) != 1 || backups.Backups[0].Name != name || backups.Backups[0].Revision != 42 {
+		t.Errorf("unexpected backups %+v", backups)
+	}
+
+	// the latest backup by default
+	resp, err := m.HandleRestore(context.Background(), &api.RestoreRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Reason != "" || resp.Backup != name || len(resp.Members) != 1 || resp.Members[0] != "a" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if !m.restorePending() || m.restoreBackup() != name {
+		t.Errorf("expected leader to be restored from %s, got %q", name, m.restoreBackup())
+	}
+}
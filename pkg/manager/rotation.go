@@ -0,0 +1,249 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"github.com/etcd-manager/etcd-discovery/pkg/metrics"
+	"github.com/etcd-manager/etcd-discovery/pkg/pki"
+	"github.com/golang/glog"
+)
+
+const (
+	// rotationCheckInterval is how often the leader asks the members to renew their certificates
+	rotationCheckInterval = 10 * time.Minute
+	// rotationSettleTime is how long the leader waits for a member that renewed its
+	// certificates to restart, before it checks the health of the cluster again
+	rotationSettleTime = 3 * reconcileInterval
+)
+
+// ErrRestart is returned by the discovery server once the certificates of the local peer
+// were renewed. The server and etcd load certificates when they start, the run command
+// builds the discovery server again.
+var ErrRestart = errors.New("restarting to load the renewed certificates")
+
+// rotationState tracks the member whose certificates the leader renewed
+type rotationState struct {
+	member  string
+	started time.Time
+}
+
+// Restarting is closed once the certificates of the local peer were renewed
+func (m *EtcdManager) Restarting() <-chan struct{} {
+	return m.restarting
+}
+
+// restart stops the discovery server and etcd, so they load the renewed certificates
+func (m *EtcdManager) restart() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	select {
+	case <-m.restarting:
+	default:
+		close(m.restarting)
+	}
+}
+
+func (m *EtcdManager) certificateFiles() pki.Files {
+	return pki.Files{Dir: m.config.CertificatesDir}
+}
+
+// observeCertificates exports when the certificates of the local peer expire
+func (m *EtcdManager) observeCertificates() {
+	if m.config.CertRenewBefore == 0 {
+		return
+	}
+	expiry, err := m.certificateFiles().Expiry()
+	if err != nil {
+		glog.Warningf("error reading the certificates of peer %s: %v", m.config.ID, err)
+		return
+	}
+	for name, t := range expiry {
+		metrics.CertificateExpiry.WithLabelValues(name).Set(float64(t.Unix()))
+	}
+}
+
+// HandleRotation renews the certificates of the local peer from the cluster CA of the
+// backup store if they expire soon or the CA is rotated. The discovery server and etcd
+// then restart to load them.
+func (m *EtcdManager) HandleRotation(ctx context.Context, req *api.RotationRequest) (*api.RotationResponse, error) {
+	if leader, term := m.election.Leader(); string(leader) != req.Leader || term != req.Term {
+		return &api.RotationResponse{Reason: fmt.Sprintf("leader for term %d is %q", term, leader)}, nil
+	}
+	if m.config.CertRenewBefore == 0 {
+		return &api.RotationResponse{Reason: fmt.Sprintf("peer %s does not renew its certificates", m.config.ID)}, nil
+	}
+	select {
+	case <-m.restarting:
+		// renewed before, not restarted yet
+		return &api.RotationResponse{Rotated: true}, nil
+	default:
+	}
+
+	ca, err := pki.LoadCA(m.backups.Store())
+	if err != nil {
+		return nil, fmt.Errorf("error loading the cluster CA: %v", err)
+	}
+	files := m.certificateFiles()
+	reason, reissue, err := files.Renewal(ca, m.config.CertRenewBefore, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if reason == "" {
+		return &api.RotationResponse{}, nil
+	}
+	glog.Infof("renewing the certificates of peer %s for leader %s: %s", m.config.ID, req.Leader, reason)
+	if err := files.Renew(ca, string(m.config.ID), reissue); err != nil {
+		return nil, fmt.Errorf("error renewing the certificates of peer %s: %v", m.config.ID, err)
+	}
+	metrics.CertificateRotations.Inc()
+	m.restart()
+	return &api.RotationResponse{Rotated: true}, nil
+}
+
+// rotate renews the certificates of the members, one member at a time and only while the
+// cluster is healthy, so it keeps its quorum while the members restart. Once every member
+// uses the certificates of the cluster CA, the rotation of the CA moves to its next phase.
+// It returns true while the members restart.
+func (m *EtcdManager) rotate(ctx context.Context, peers map[api.PeerID]*discovery.Peer, term int64) (bool, error) {
+	if m.config.CertRenewBefore == 0 || m.migrating() {
+		return false, nil
+	}
+	m.mutex.Lock()
+	state := m.rotating
+	checked := m.rotationChecked
+	m.mutex.Unlock()
+	if state != nil {
+		if time.Since(state.started) < rotationSettleTime {
+			return true, nil
+		}
+		if unhealthy := m.unhealthyMembers(); unhealthy.Len() > 0 {
+			glog.Infof("waiting for members %v to recover from the restart of member %s", unhealthy.List(), state.member)
+			return true, nil
+		}
+		glog.Infof("etcd member %s of cluster %s restarted with its renewed certificates", state.member, m.config.ClusterName)
+		m.mutex.Lock()
+		m.rotating = nil
+		m.mutex.Unlock()
+	} else if time.Since(checked) < rotationCheckInterval {
+		return false, nil
+	}
+
+	if unhealthy := m.unhealthyMembers(); unhealthy.Len() > 0 {
+		glog.Warningf("not renewing the certificates of cluster %s, members %v are not healthy", m.config.ClusterName, unhealthy.List())
+		return false, nil
+	}
+	m.mutex.Lock()
+	flags := m.flags
+	m.mutex.Unlock()
+	client, err := flags.NewClient()
+	if err != nil {
+		return false, err
+	}
+	defer client.Close()
+	members, err := client.ListMembers(ctx)
+	if err != nil {
+		return false, fmt.Errorf("error listing members: %v", err)
+	}
+	var names []string
+	for _, member := range members {
+		names = append(names, member.Name)
+	}
+	// the local member restarts last, its restart hands the leadership over
+	self := string(m.config.ID)
+	sort.Slice(names, func(i, j int) bool {
+		if (names[i] == self) != (names[j] == self) {
+			return names[j] == self
+		}
+		return names[i] < names[j]
+	})
+
+	for _, name := range names {
+		rotated, err := m.pushRotation(ctx, peers, term, name)
+		if err != nil {
+			return false, fmt.Errorf("error renewing the certificates of member %s: %v", name, err)
+		}
+		if rotated {
+			glog.Infof("etcd member %s of cluster %s renewed its certificates, restarting it", name, m.config.ClusterName)
+			m.mutex.Lock()
+			m.rotating = &rotationState{member: name, started: time.Now()}
+			m.mutex.Unlock()
+			return true, nil
+		}
+	}
+
+	advanced, err := m.rotateCA()
+	if err != nil {
+		return false, err
+	}
+	if !advanced {
+		m.mutex.Lock()
+		m.rotationChecked = time.Now()
+		m.mutex.Unlock()
+	}
+	return false, nil
+}
+
+// rotateCA moves the rotation of the cluster CA to its next phase, once every member uses
+// the certificates of the current one. A stable CA is rotated when it expires within
+// CARenewBefore. It returns true if the CA changed.
+func (m *EtcdManager) rotateCA() (bool, error) {
+	store := m.backups.Store()
+	ca, err := pki.LoadCA(store)
+	if err != nil {
+		return false, fmt.Errorf("error loading the cluster CA: %v", err)
+	}
+	phase := ca.Phase()
+	if phase == pki.CAPhaseStable && (m.config.CARenewBefore == 0 || time.Until(ca.Cert.NotAfter) > m.config.CARenewBefore) {
+		return false, nil
+	}
+	next, err := ca.Rotate(m.config.ClusterName)
+	if err != nil {
+		return false, err
+	}
+	if err := pki.SaveCA(store, next); err != nil {
+		return false, fmt.Errorf("error saving the cluster CA: %v", err)
+	}
+	glog.Infof("rotated the CA of cluster %s from phase %s to %s", m.config.ClusterName, phase, next.Phase())
+	return true, nil
+}
+
+// pushRotation asks the member name to renew its certificates, it returns true if the
+// member renewed them and restarts
+func (m *EtcdManager) pushRotation(ctx context.Context, peers map[api.PeerID]*discovery.Peer, term int64, name string) (bool, error) {
+	req := &api.RotationRequest{Leader: string(m.config.ID), Term: term}
+
+	var resp *api.RotationResponse
+	if name == string(m.config.ID) {
+		var err error
+		if resp, err = m.HandleRotation(ctx, req); err != nil {
+			return false, err
+		}
+	} else {
+		peer, ok := peers[api.PeerID(name)]
+		if !ok {
+			return false, fmt.Errorf("member %s is not reachable", name)
+		}
+		client, err := m.newPeerClient(peer.Address)
+		if err != nil {
+			return false, err
+		}
+		result, err := client.Rotations().Create(&api.Rotation{Request: req})
+		if err != nil {
+			return false, err
+		}
+		resp = result.Response
+	}
+	if resp == nil {
+		return false, fmt.Errorf("no response")
+	}
+	if resp.Reason != "" {
+		return false, fmt.Errorf("rotation refused: %s", resp.Reason)
+	}
+	return resp.Rotated, nil
+}
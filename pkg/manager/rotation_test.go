@@ -0,0 +1,108 @@
+package manager
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/backup"
+	"github.com/etcd-manager/etcd-discovery/pkg/pki"
+	"k8s.io/apimachinery/pkg/util/clock"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+func TestHandleRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := backup.NewFileStore(filepath.Join(dir, "backups"))
+	ca, err := pki.NewCA("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pki.SaveCA(store, ca); err != nil {
+		t.Fatal(err)
+	}
+	files := pki.Files{Dir: filepath.Join(dir, "certificates")}
+	req, err := pki.NewRequest("a", certutil.AltNames{DNSNames: []string{"node-a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	certs, err := req.Sign(ca)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := files.Write(req, ca.BundlePEM(), certs); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &EtcdManager{
+		config:     &EtcdConfig{ID: "a", CertificatesDir: files.Dir, CertRenewBefore: 30 * 24 * time.Hour},
+		election:   soleLeader(t, "a"),
+		backups:    backup.NewController(store, 0, backup.RetentionPolicy{}, clock.RealClock{}),
+		restarting: make(chan struct{}),
+	}
+	rotation := &api.RotationRequest{Leader: "a", Term: 1}
+
+	// a request of another leader is refused
+	if resp, err := m.HandleRotation(context.Background(), &api.RotationRequest{Leader: "b", Term: 1}); err != nil || resp.Reason == "" {
+		t.Errorf("expected a request of another leader to be refused, got %+v, %v", resp, err)
+	}
+	// current certificates are kept
+	resp, err := m.HandleRotation(context.Background(), rotation)
+	if err != nil || resp.Reason != "" || resp.Rotated {
+		t.Fatalf("expected current certificates to be kept, got %+v, %v", resp, err)
+	}
+
+	// the leader starts the rotation of an expiring CA once the members are current
+	m.config.CARenewBefore = 20 * 365 * 24 * time.Hour
+	if rotated, err := m.rotateCA(); err != nil || !rotated {
+		t.Fatalf("expected the CA to be rotated, got %v, %v", rotated, err)
+	}
+	rotating, err := pki.LoadCA(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotating.Phase() != pki.CAPhaseTrust || !rotating.Cert.Equal(ca.Cert) {
+		t.Fatalf("expected the new CA to be trusted first, got phase %s", rotating.Phase())
+	}
+
+	resp, err = m.HandleRotation(context.Background(), rotation)
+	if err != nil || !resp.Rotated {
+		t.Fatalf("expected the peer to trust the new CA, got %+v, %v", resp, err)
+	}
+	select {
+	case <-m.Restarting():
+	default:
+		t.Error("expected the peer to restart with the renewed certificates")
+	}
+	if trusted, err := certutil.CertsFromFile(files.CACert()); err != nil || len(trusted) != 2 {
+		t.Errorf("expected the peer to trust both CAs, got %d, %v", len(trusted), err)
+	}
+	// the request is repeated until the peer restarted
+	if resp, err := m.HandleRotation(context.Background(), rotation); err != nil || !resp.Rotated {
+		t.Errorf("expected a restarting peer to report its rotation, got %+v, %v", resp, err)
+	}
+
+	m.restarting = make(chan struct{})
+	if rotated, err := m.rotateCA(); err != nil || !rotated {
+		t.Fatalf("expected the new CA to sign, got %v, %v", rotated, err)
+	}
+	if resp, err := m.HandleRotation(context.Background(), rotation); err != nil || !resp.Rotated {
+		t.Fatalf("expected the certificates to be issued by the new CA, got %+v, %v", resp, err)
+	}
+	peer, err := certutil.CertsFromFile(files.Cert(pki.ProfilePeer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := peer[0].CheckSignatureFrom(rotating.Next.Cert); err != nil {
+		t.Errorf("expected the peer certificate of the new CA: %v", err)
+	}
+}
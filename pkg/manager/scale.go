@@ -0,0 +1,267 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"github.com/etcd-manager/etcd-discovery/pkg/etcdclient"
+	"github.com/etcd-manager/etcd-discovery/pkg/metrics"
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// clusterSizeFile keeps the cluster size set through the scale resource in the data dir,
+// it overrides the configured size
+const clusterSizeFile = "cluster-size"
+
+// clusterSize returns the desired size of the cluster
+func (m *EtcdManager) clusterSize() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.config.ClusterSize
+}
+
+// loadClusterSize returns the cluster size set through the scale resource, 0 if none was set
+func (m *EtcdManager) loadClusterSize() (int, error) {
+	data, err := ioutil.ReadFile(filepath.Join(m.config.DataDir, clusterSizeFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	size, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("error parsing %s: %v", clusterSizeFile, err)
+	}
+	return size, nil
+}
+
+// setClusterSize persists the desired size of the cluster and applies it to the election
+func (m *EtcdManager) setClusterSize(size int) error {
+	if err := ioutil.WriteFile(filepath.Join(m.config.DataDir, clusterSizeFile), []byte(strconv.Itoa(size)), 0644); err != nil {
+		return fmt.Errorf("error writing %s: %v", clusterSizeFile, err)
+	}
+	m.mutex.Lock()
+	m.config.ClusterSize = size
+	m.scaleAcked = nil
+	m.mutex.Unlock()
+	m.election.SetClusterSize(size)
+	return nil
+}
+
+// HandleScale changes the desired size of the cluster. Requests of operators are served
+// by the leader, which persists the size and pushes it to every peer with the names of
+// the members. Requests of the leader move the local member to its size, a member that
+// was removed from the cluster moves its data aside before joining again.
+func (m *EtcdManager) HandleScale(ctx context.Context, req *api.ScaleRequest) (*api.ScaleResponse, error) {
+	leader, term := m.election.Leader()
+	if req.Leader == "" {
+		if leader != m.config.ID {
+			return &api.ScaleResponse{Reason: fmt.Sprintf("peer %s is not the leader, ask %q", m.config.ID, leader)}, nil
+		}
+		if size := int(req.ClusterSize); size != 0 && size != m.clusterSize() {
+			glog.Infof("scaling cluster %s from %d to %d members", m.config.ClusterName, m.clusterSize(), size)
+			if err := m.setClusterSize(size); err != nil {
+				return nil, err
+			}
+		}
+		resp := &api.ScaleResponse{ClusterSize: int32(m.clusterSize())}
+		if m.isRunning() {
+			members, err := m.memberNames(ctx)
+			if err != nil {
+				return nil, err
+			}
+			resp.Members = members
+		}
+		return resp, nil
+	}
+
+	if string(leader) != req.Leader || term != req.Term {
+		return &api.ScaleResponse{Reason: fmt.Sprintf("leader for term %d is %q", term, leader)}, nil
+	}
+	if size := int(req.ClusterSize); size != 0 && size != m.clusterSize() {
+		glog.Infof("changing size of cluster %s to %d members for leader %s", m.config.ClusterName, size, req.Leader)
+		if err := m.setClusterSize(size); err != nil {
+			return nil, err
+		}
+	}
+	if len(req.Members) > 0 && !sets.NewString(req.Members...).Has(string(m.config.ID)) && m.hasData() {
+		glog.Infof("etcd member %s was removed from cluster %s", m.config.ID, m.config.ClusterName)
+		m.mutex.Lock()
+		m.scaleRemoved = true
+		m.mutex.Unlock()
+	}
+	return &api.ScaleResponse{ClusterSize: int32(m.clusterSize())}, nil
+}
+
+// takeRemoved returns true once after the leader reported that the local member is no
+// longer a member of the cluster
+func (m *EtcdManager) takeRemoved() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	removed := m.scaleRemoved
+	m.scaleRemoved = false
+	return removed
+}
+
+// memberNames returns the names of the etcd members, members that have not started yet
+// are named after their peer
+func (m *EtcdManager) memberNames(ctx context.Context) ([]string, error) {
+	m.mutex.Lock()
+	flags, peers := m.flags, m.peers
+	m.mutex.Unlock()
+
+	client, err := flags.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	members, err := client.ListMembers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing members: %v", err)
+	}
+	var names []string
+	for _, member := range members {
+		names = append(names, memberName(member, peers))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// pushScale pushes the size of the cluster and the names of its members to the peers that
+// did not take them yet in term
+func (m *EtcdManager) pushScale(ctx context.Context, peers map[api.PeerID]*discovery.Peer, term int64, members []string) {
+	size := m.clusterSize()
+	sort.Strings(members)
+	key := fmt.Sprintf("%d/%d/%s", term, size, strings.Join(members, ","))
+
+	m.mutex.Lock()
+	if m.scaleAcked == nil || m.scaleKey != key {
+		m.scaleAcked = sets.NewString(string(m.config.ID))
+		m.scaleKey = key
+	}
+	acked := sets.NewString(m.scaleAcked.List()...)
+	m.mutex.Unlock()
+
+	req := &api.ScaleRequest{Leader: string(m.config.ID), Term: term, ClusterSize: int32(size), Members: members}
+	for id, peer := range peers {
+		if acked.Has(string(id)) {
+			continue
+		}
+		client, err := m.newPeerClient(peer.Address)
+		if err != nil {
+			glog.Warningf("error creating client for peer %s: %v", id, err)
+			continue
+		}
+		result, err := client.Scales().Create(&api.Scale{Request: req})
+		if err != nil {
+			glog.Warningf("error pushing cluster size %d to %s: %v", size, id, err)
+			continue
+		}
+		if result.Response == nil || result.Response.Reason != "" {
+			glog.Warningf("peer %s refused cluster size %d: %v", id, size, result.Response)
+			continue
+		}
+		acked.Insert(string(id))
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.scaleKey == key && m.scaleAcked != nil {
+		m.scaleAcked = acked
+	}
+}
+
+// scaleDownCandidate returns the member to remove from a cluster that is too large, or
+// nil if none can be removed. Members whose peer is not live go first, then unhealthy
+// members, then members that are not the etcd leader. The local member self is never
+// removed, and a member is only removed if the live members keep a quorum without it.
+func scaleDownCandidate(members []*etcdclient.EtcdProcessMember, live, unhealthy, leaders sets.String, self string) *etcdclient.EtcdProcessMember {
+	quorum := (len(members)-1)/2 + 1
+	var candidates []*etcdclient.EtcdProcessMember
+	for _, member := range members {
+		if member.Name == "" {
+			// a member is joining, members change one at a time
+			return nil
+		}
+		if member.Name != self {
+			candidates = append(candidates, member)
+		}
+	}
+	rank := func(member *etcdclient.EtcdProcessMember) int {
+		switch {
+		case !live.Has(member.Name):
+			return 0
+		case unhealthy.Has(member.Name):
+			return 1
+		case !leaders.Has(member.Name):
+			return 2
+		}
+		return 3
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if ri, rj := rank(candidates[i]), rank(candidates[j]); ri != rj {
+			return ri < rj
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+
+	for _, member := range candidates {
+		remaining := live.Len()
+		if live.Has(member.Name) {
+			remaining--
+		}
+		if remaining >= quorum {
+			return member
+		}
+	}
+	return nil
+}
+
+// scaleDown removes one member from a cluster that is larger than its desired size
+func (m *EtcdManager) scaleDown(ctx context.Context, client etcdclient.EtcdClient, members []*etcdclient.EtcdProcessMember, peers map[api.PeerID]*discovery.Peer) error {
+	live := sets.NewString()
+	leaders := sets.NewString()
+	for _, member := range members {
+		if peers[api.PeerID(member.Name)] == nil {
+			continue
+		}
+		live.Insert(member.Name)
+		c, err := member.NewClient()
+		if err != nil {
+			continue
+		}
+		info, err := c.LocalNodeInfo(ctx)
+		c.Close()
+		if err != nil {
+			glog.V(2).Infof("error getting state of member %s: %v", member.Name, err)
+			continue
+		}
+		if info.IsLeader {
+			leaders.Insert(member.Name)
+		}
+	}
+
+	member := scaleDownCandidate(members, live, m.unhealthyMembers(), leaders, string(m.config.ID))
+	if member == nil {
+		glog.Infof("cluster %s has %d members, waiting to scale down to %d", m.config.ClusterName, len(members), m.clusterSize())
+		return nil
+	}
+	glog.Infof("removing member %s to scale cluster %s down to %d members", member.Name, m.config.ClusterName, m.clusterSize())
+	err := client.RemoveMember(ctx, member)
+	metrics.ObserveMemberOperation(metrics.OperationRemove, err)
+	if err != nil {
+		return fmt.Errorf("error removing member %s: %v", member.Name, err)
+	}
+	return nil
+}
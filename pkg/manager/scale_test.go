@@ -0,0 +1,115 @@
+package manager
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/config"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"github.com/etcd-manager/etcd-discovery/pkg/etcdclient"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestScaleDownCandidate(t *testing.T) {
+	members := []*etcdclient.EtcdProcessMember{
+		{ID: "1", Name: "a"},
+		{ID: "2", Name: "b"},
+		{ID: "3", Name: "c"},
+		{ID: "4", Name: "d"},
+		{ID: "5", Name: "e"},
+	}
+	all := sets.NewString("a", "b", "c", "d", "e")
+
+	grid := []struct {
+		name      string
+		members   []*etcdclient.EtcdProcessMember
+		live      sets.String
+		unhealthy sets.String
+		leaders   sets.String
+		expected  string
+	}{
+		{name: "not the etcd leader", members: members, live: all, leaders: sets.NewString("b"), expected: "c"},
+		{name: "unhealthy first", members: members, live: all, unhealthy: sets.NewString("e"), leaders: sets.NewString("b"), expected: "e"},
+		{name: "unreachable first", members: members, live: sets.NewString("a", "b", "c", "e"), leaders: sets.NewString("b"), expected: "d"},
+		{name: "never self", members: members[:2], live: sets.NewString("a", "b"), leaders: sets.NewString("b"), expected: "b"},
+		{name: "keeps quorum", members: members, live: sets.NewString("a", "b", "c"), leaders: sets.NewString("a"), expected: "d"},
+		{name: "no quorum", members: members[:3], live: sets.NewString("a"), leaders: sets.NewString("a"), expected: ""},
+		{name: "joining member", members: append(members[:3:3], &etcdclient.EtcdProcessMember{ID: "6"}), live: all, leaders: sets.NewString("a"), expected: ""},
+	}
+	for _, g := range grid {
+		candidate := scaleDownCandidate(g.members, g.live, g.unhealthy, g.leaders, "a")
+		name := ""
+		if candidate != nil {
+			name = candidate.Name
+		}
+		if name != g.expected {
+			t.Errorf("%s: expected %q, got %q", g.name, g.expected, name)
+		}
+	}
+}
+
+func TestHandleScale(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.MkdirAll(filepath.Join(dir, "etcd", "member"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// b follows a, which was elected leader for term 1
+	election := discovery.NewElection("b", 3, time.Minute, clock.NewFakeClock(time.Now()))
+	resp := &api.PingResponse{}
+	election.HandlePing(&api.PingRequest{Info: &api.PeerInfo{ID: "a"}, Leader: "a", Term: 1}, resp)
+	if !resp.LeaseGranted {
+		t.Fatalf("b did not grant the lease to a")
+	}
+
+	m := &EtcdManager{
+		config: &EtcdConfig{
+			EtcdCluster: config.EtcdCluster{ClusterName: "test", ClusterSize: 3, DataDir: dir},
+			ID:          "b",
+		},
+		election: election,
+	}
+
+	resp2, err := m.HandleScale(context.Background(), &api.ScaleRequest{ClusterSize: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp2.Reason == "" || m.clusterSize() != 3 {
+		t.Errorf("expected follower to refuse the request of an operator, got %+v", resp2)
+	}
+
+	resp2, err = m.HandleScale(context.Background(), &api.ScaleRequest{Leader: "a", Term: 1, ClusterSize: 5, Members: []string{"a", "b", "c"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp2.Reason != "" || resp2.ClusterSize != 5 || m.clusterSize() != 5 {
+		t.Errorf("expected cluster size 5, got %+v", resp2)
+	}
+	if size, err := m.loadClusterSize(); err != nil || size != 5 {
+		t.Errorf("expected cluster size 5 to be persisted, got %d, %v", size, err)
+	}
+	if m.takeRemoved() {
+		t.Errorf("expected b to stay a member")
+	}
+
+	if resp, err := m.HandleScale(context.Background(), &api.ScaleRequest{Leader: "c", Term: 1, ClusterSize: 1}); err != nil || resp.Reason == "" {
+		t.Errorf("expected the size of another leader to be refused, got %+v, %v", resp, err)
+	}
+
+	if _, err := m.HandleScale(context.Background(), &api.ScaleRequest{Leader: "a", Term: 1, ClusterSize: 3, Members: []string{"a", "c", "d"}}); err != nil {
+		t.Fatal(err)
+	}
+	if !m.takeRemoved() || m.takeRemoved() {
+		t.Errorf("expected b to be removed once")
+	}
+}
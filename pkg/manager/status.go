@@ -0,0 +1,222 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/etcdclient"
+	"github.com/golang/glog"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// statusQueueLength is how many status changes are queued for watchers
+const statusQueueLength = 25
+
+// ClusterStatus returns the status of the cluster as seen by the local member, nil
+// before it was first reported
+func (m *EtcdManager) ClusterStatus() *api.EtcdCluster {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.status.DeepCopy()
+}
+
+// WatchClusterStatus returns a watch of the status of the cluster, starting with its
+// current status
+func (m *EtcdManager) WatchClusterStatus() watch.Interface {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	var events []watch.Event
+	if m.status != nil {
+		events = append(events, watch.Event{Type: watch.Added, Object: m.status.DeepCopy()})
+	}
+	return m.statusWatchers.WatchWithPrefix(events)
+}
+
+// updateStatus refreshes the status of the cluster and notifies watchers if it changed
+func (m *EtcdManager) updateStatus(ctx context.Context) {
+	status := m.newStatus(ctx)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	old := m.status
+	if old != nil {
+		keepTransitionTimes(status.Status.Conditions, old.Status.Conditions)
+		for i := range status.Status.Members {
+			for _, o := range old.Status.Members {
+				if o.ID == status.Status.Members[i].ID {
+					keepTransitionTimes(status.Status.Members[i].Conditions, o.Conditions)
+				}
+			}
+		}
+		if apiequality.Semantic.DeepEqual(status.Spec, old.Spec) && apiequality.Semantic.DeepEqual(status.Status, old.Status) {
+			return
+		}
+	}
+	m.statusVersion++
+	status.ResourceVersion = strconv.FormatInt(m.statusVersion, 10)
+	m.status = status
+	if old == nil {
+		m.statusWatchers.Action(watch.Added, status.DeepCopy())
+	} else {
+		m.statusWatchers.Action(watch.Modified, status.DeepCopy())
+	}
+}
+
+// newStatus returns the current status of the cluster
+func (m *EtcdManager) newStatus(ctx context.Context) *api.EtcdCluster {
+	leader, term := m.election.Leader()
+	status := &api.EtcdCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: m.config.ClusterName},
+		Spec: api.EtcdClusterSpec{
+			ClusterSize: int32(m.clusterSize()),
+			EtcdVersion: string(m.clusterVersion()),
+			Quarantine:  m.quarantineMode(),
+		},
+		Status: api.EtcdClusterStatus{
+			Leader: string(leader),
+			Term:   term,
+		},
+	}
+
+	now := metav1.Now()
+	members, err := m.statusMembers(ctx, now)
+	if err != nil {
+		glog.V(2).Infof("error listing members of cluster %s: %v", m.config.ClusterName, err)
+	}
+	status.Status.Members = members
+
+	if last, err := m.backups.LastBackup(); err != nil {
+		glog.V(2).Infof("error listing backups of cluster %s: %v", m.config.ClusterName, err)
+	} else if !last.IsZero() {
+		t := metav1.NewTime(last)
+		status.Status.LastBackupTime = &t
+	}
+
+	for _, exit := range m.processExits() {
+		status.Status.ProcessExits = append(status.Status.ProcessExits, api.EtcdProcessExit{
+			Time:     metav1.NewTime(exit.Time),
+			ExitCode: int32(exit.ExitCode),
+			Message:  exit.Message,
+			Expected: exit.Expected,
+		})
+	}
+
+	status.Status.Conditions = []api.EtcdClusterCondition{
+		m.availableCondition(members, err, now),
+		m.progressingCondition(len(members), now),
+		quarantinedCondition(status.Spec.Quarantine, now),
+		healthyCondition(members, now),
+	}
+	return status
+}
+
+// statusMembers returns the etcd members with the results of their health checks, nil
+// if etcd is not running
+func (m *EtcdManager) statusMembers(ctx context.Context, now metav1.Time) ([]api.EtcdClusterMember, error) {
+	m.mutex.Lock()
+	flags, peers := m.flags, m.peers
+	m.mutex.Unlock()
+	if flags == nil || !m.isRunning() {
+		return nil, nil
+	}
+
+	client, err := flags.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	members, err := client.ListMembers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// members are checked in parallel, an unreachable member must not delay the others
+	result := make([]api.EtcdClusterMember, len(members))
+	var wg sync.WaitGroup
+	for i, member := range members {
+		wg.Add(1)
+		go func(i int, member *etcdclient.EtcdProcessMember) {
+			defer wg.Done()
+			result[i] = checkMemberHealth(ctx, member, peers, now)
+		}(i, member)
+	}
+	wg.Wait()
+	return result, nil
+}
+
+func (m *EtcdManager) availableCondition(members []api.EtcdClusterMember, listErr error, now metav1.Time) api.EtcdClusterCondition {
+	condition := api.EtcdClusterCondition{Type: api.EtcdClusterAvailable, LastTransitionTime: now}
+	switch {
+	case listErr != nil:
+		condition.Status = api.ConditionUnknown
+		condition.Reason = "MembersUnknown"
+		condition.Message = listErr.Error()
+	case members == nil:
+		condition.Status = api.ConditionUnknown
+		condition.Reason = "EtcdNotRunning"
+		condition.Message = fmt.Sprintf("etcd is not running on peer %s", m.config.ID)
+	default:
+		healthy := 0
+		for _, member := range members {
+			if memberHealthy(member) {
+				healthy++
+			}
+		}
+		if size := m.clusterSize(); healthy == size && len(members) == size {
+			condition.Status = api.ConditionTrue
+			condition.Reason = "MembersReady"
+		} else {
+			condition.Status = api.ConditionFalse
+			condition.Reason = "MembersNotReady"
+			condition.Message = fmt.Sprintf("%d of %d members are healthy", healthy, size)
+		}
+	}
+	return condition
+}
+
+func (m *EtcdManager) progressingCondition(members int, now metav1.Time) api.EtcdClusterCondition {
+	condition := api.EtcdClusterCondition{Type: api.EtcdClusterProgressing, Status: api.ConditionFalse, LastTransitionTime: now}
+	m.mutex.Lock()
+	migration, upgrading := m.migration, m.upgrading
+	m.mutex.Unlock()
+	switch {
+	case migration != nil && migration.Phase != api.MigrationPhaseComplete:
+		condition.Status = api.ConditionTrue
+		condition.Reason = "Migrating"
+		condition.Message = fmt.Sprintf("migration is in phase %s", migration.Phase)
+	case upgrading != nil:
+		condition.Status = api.ConditionTrue
+		condition.Reason = "Upgrading"
+		condition.Message = fmt.Sprintf("upgrading member %s from %s to %s", upgrading.member, upgrading.from, upgrading.to)
+	case members != 0 && members != m.clusterSize():
+		condition.Status = api.ConditionTrue
+		condition.Reason = "Scaling"
+		condition.Message = fmt.Sprintf("scaling from %d to %d members", members, m.clusterSize())
+	}
+	return condition
+}
+
+func quarantinedCondition(mode api.QuarantineMode, now metav1.Time) api.EtcdClusterCondition {
+	condition := api.EtcdClusterCondition{Type: api.EtcdClusterQuarantined, Status: api.ConditionFalse, LastTransitionTime: now}
+	if mode == api.QuarantineModeEnabled {
+		condition.Status = api.ConditionTrue
+		condition.Reason = "QuarantineEnabled"
+	}
+	return condition
+}
+
+// keepTransitionTimes copies the transition times of the old conditions whose status did not change
+func keepTransitionTimes(conditions, old []api.EtcdClusterCondition) {
+	for i := range conditions {
+		for _, o := range old {
+			if o.Type == conditions[i].Type && o.Status == conditions[i].Status {
+				conditions[i].LastTransitionTime = o.LastTransitionTime
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package manager
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/backup"
+	"github.com/etcd-manager/etcd-discovery/pkg/config"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func condition(status *api.EtcdCluster, t api.EtcdClusterConditionType) api.EtcdClusterCondition {
+	for _, c := range status.Status.Conditions {
+		if c.Type == t {
+			return c
+		}
+	}
+	return api.EtcdClusterCondition{}
+}
+
+func TestUpdateStatus(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := backup.NewStore(filepath.Join(dir, "backups"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &EtcdManager{
+		config: &EtcdConfig{
+			EtcdCluster: config.EtcdCluster{ClusterName: "test", ClusterSize: 1, DataDir: dir, EtcdVersion: "3.2.13"},
+			ID:          "a",
+		},
+		election:       soleLeader(t, "a"),
+		backups:        backup.NewController(store, 0, backup.RetentionPolicy{}, clock.RealClock{}),
+		statusWatchers: watch.NewBroadcaster(statusQueueLength, watch.DropIfChannelFull),
+	}
+	defer m.statusWatchers.Shutdown()
+
+	if status := m.ClusterStatus(); status != nil {
+		t.Fatalf("expected no status before the first update, got %v", status)
+	}
+	w := m.WatchClusterStatus()
+	defer w.Stop()
+
+	m.updateStatus(context.Background())
+	status := m.ClusterStatus()
+	if status == nil || status.Name != "test" || status.Spec.ClusterSize != 1 || status.Spec.EtcdVersion != "3.2.13" {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if status.Status.Leader != "a" || status.Status.Term != 1 || status.Status.LastBackupTime != nil {
+		t.Errorf("unexpected status %+v", status.Status)
+	}
+	if c := condition(status, api.EtcdClusterAvailable); c.Status != api.ConditionUnknown || c.Reason != "EtcdNotRunning" {
+		t.Errorf("unexpected available condition %+v", c)
+	}
+	if c := condition(status, api.EtcdClusterQuarantined); c.Status != api.ConditionFalse {
+		t.Errorf("unexpected quarantined condition %+v", c)
+	}
+	event := <-w.ResultChan()
+	if event.Type != watch.Added || event.Object.(*api.EtcdCluster).ResourceVersion != status.ResourceVersion {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	// an unchanged status keeps its version
+	m.updateStatus(context.Background())
+	if version := m.ClusterStatus().ResourceVersion; version != status.ResourceVersion {
+		t.Errorf("expected version %s, got %s", status.ResourceVersion, version)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if err := m.setQuarantineMode(api.QuarantineModeEnabled); err != nil {
+		t.Fatal(err)
+	}
+	m.updateStatus(context.Background())
+	updated := m.ClusterStatus()
+	if updated.ResourceVersion == status.ResourceVersion || updated.Spec.Quarantine != api.QuarantineModeEnabled {
+		t.Errorf("expected a new status, got %+v", updated)
+	}
+	if c := condition(updated, api.EtcdClusterQuarantined); c.Status != api.ConditionTrue || !c.LastTransitionTime.After(condition(status, api.EtcdClusterQuarantined).LastTransitionTime.Time) {
+		t.Errorf("unexpected quarantined condition %+v", c)
+	}
+	if c := condition(updated, api.EtcdClusterAvailable); !c.LastTransitionTime.Time.Equal(condition(status, api.EtcdClusterAvailable).LastTransitionTime.Time) {
+		t.Errorf("expected available condition to keep its transition time, got %+v", c)
+	}
+	event = <-w.ResultChan()
+	if event.Type != watch.Modified || event.Object.(*api.EtcdCluster).Spec.Quarantine != api.QuarantineModeEnabled {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
@@ -0,0 +1,463 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/config"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"github.com/etcd-manager/etcd-discovery/pkg/etcd"
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// clusterVersionFile keeps the etcd version set through the upgrade resource in the data dir,
+// it overrides the configured version
+const clusterVersionFile = "cluster-version"
+
+const (
+	// upgradeTimeout is how long a member may take to report the new version before it is rolled back
+	upgradeTimeout = 5 * time.Minute
+	// upgradeRetryInterval is how long the leader waits before retrying a version that was rolled back
+	upgradeRetryInterval = time.Hour
+)
+
+// upgradeState tracks the upgrade of one member by the leader
+type upgradeState struct {
+	member  string
+	from    config.EtcdVersion
+	to      config.EtcdVersion
+	started time.Time
+	// rollback is true once the member is restarted with the version it ran before
+	rollback bool
+}
+
+// target returns the version the member is expected to report
+func (s *upgradeState) target() config.EtcdVersion {
+	if s.rollback {
+		return s.from
+	}
+	return s.to
+}
+
+// memberVersion is the etcd version reported by a member, empty if it is unreachable
+type memberVersion struct {
+	name    string
+	version config.EtcdVersion
+}
+
+// parseMinorVersion returns the major and minor version of v
+func parseMinorVersion(v config.EtcdVersion) (int, int, error) {
+	parts := strings.SplitN(strings.TrimPrefix(string(v), "v"), ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, fmt.Errorf("invalid etcd version %q", v)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid etcd version %q", v)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid etcd version %q", v)
+	}
+	return major, minor, nil
+}
+
+// checkUpgradeVersions returns an error unless a member can be upgraded from one version
+// to the other. etcd supports upgrades to the next minor version, not downgrades.
+func checkUpgradeVersions(from, to config.EtcdVersion) error {
+	fromMajor, fromMinor, err := parseMinorVersion(from)
+	if err != nil {
+		return err
+	}
+	toMajor, toMinor, err := parseMinorVersion(to)
+	if err != nil {
+		return err
+	}
+	if fromMajor != toMajor || toMinor < fromMinor || toMinor > fromMinor+1 {
+		return fmt.Errorf("cannot upgrade etcd %s to %s, etcd upgrades one minor version at a time", from, to)
+	}
+	return nil
+}
+
+// nextUpgrade returns the member to upgrade to version next, or "" if every member runs
+// it. Members are upgraded only while all of them are healthy and a quorum survives the
+// restart of one of them. The local member self is upgraded last.
+func nextUpgrade(members []memberVersion, self string, version config.EtcdVersion) (string, error) {
+	sort.Slice(members, func(i, j int) bool {
+		if (members[i].name == self) != (members[j].name == self) {
+			return members[j].name == self
+		}
+		return members[i].name < members[j].name
+	})
+
+	var outdated bool
+	for _, member := range members {
+		outdated = outdated || (member.version != "" && member.version != version)
+	}
+	if !outdated {
+		return "", nil
+	}
+	for _, member := range members {
+		if member.version == "" {
+			return "", fmt.Errorf("member %s is not healthy", member.name)
+		}
+	}
+	for _, member := range members {
+		if member.version == version {
+			continue
+		}
+		if err := checkUpgradeVersions(member.version, version); err != nil {
+			return "", err
+		}
+		// a single member cluster is unavailable while it restarts, but it has no quorum to lose
+		if len(members) > 1 && len(members)-1 < len(members)/2+1 {
+			return "", fmt.Errorf("restarting member %s would lose the quorum of %d members", member.name, len(members))
+		}
+		return member.name, nil
+	}
+	return "", nil
+}
+
+// clusterVersion returns the etcd version the cluster is to run
+func (m *EtcdManager) clusterVersion() config.EtcdVersion {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.config.EtcdVersion
+}
+
+// loadClusterVersion returns the etcd version set through the upgrade resource, "" if none was set
+func (m *EtcdManager) loadClusterVersion() (config.EtcdVersion, error) {
+	data, err := ioutil.ReadFile(filepath.Join(m.config.DataDir, clusterVersionFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return config.EtcdVersion(strings.TrimSpace(string(data))), nil
+}
+
+// setClusterVersion persists the etcd version the cluster is to run
+func (m *EtcdManager) setClusterVersion(version config.EtcdVersion) error {
+	if err := ioutil.WriteFile(filepath.Join(m.config.DataDir, clusterVersionFile), []byte(version), 0644); err != nil {
+		return fmt.Errorf("error writing %s: %v", clusterVersionFile, err)
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.config.EtcdVersion = version
+	return nil
+}
+
+// recordVersion records the etcd version the local data is run with
+func (m *EtcdManager) recordVersion(version config.EtcdVersion) error {
+	if err := ioutil.WriteFile(filepath.Join(m.config.DataDir, versionFile), []byte(version), 0644); err != nil {
+		return fmt.Errorf("error recording etcd version %s: %v", version, err)
+	}
+	return nil
+}
+
+// checkVersionAvailable returns an error if the local member cannot run version
+func (m *EtcdManager) checkVersionAvailable(version config.EtcdVersion) error {
+	if m.config.ProcessType != etcd.ProcessTypeDirect {
+		// the image of a static pod is pulled by the kubelet
+		return nil
+	}
+	_, err := etcd.BindirForEtcdVersion(string(version), "etcd")
+	return err
+}
+
+// HandleUpgrade sets the etcd version of the cluster for an operator. Peers keep the
+// cluster version pushed by the leader and record the version the leader restarts the
+// local member with, reconcile then restarts it.
+func (m *EtcdManager) HandleUpgrade(ctx context.Context, req *api.UpgradeRequest) (*api.UpgradeResponse, error) {
+	if req.Leader == "" {
+		return m.upgradeCluster(req)
+	}
+	if leader, term := m.election.Leader(); string(leader) != req.Leader || term != req.Term {
+		return &api.UpgradeResponse{Reason: fmt.Sprintf("leader for term %d is %q", term, leader)}, nil
+	}
+	if req.ClusterVersion != "" && config.EtcdVersion(req.ClusterVersion) != m.clusterVersion() {
+		glog.Infof("etcd version of cluster %s set to %s by leader %s", m.config.ClusterName, req.ClusterVersion, req.Leader)
+		if err := m.setClusterVersion(config.EtcdVersion(req.ClusterVersion)); err != nil {
+			return nil, err
+		}
+	}
+	if req.Version == "" {
+		return &api.UpgradeResponse{ClusterVersion: string(m.clusterVersion())}, nil
+	}
+	if m.migrating() {
+		return &api.UpgradeResponse{Reason: fmt.Sprintf("peer %s is migrating", m.config.ID)}, nil
+	}
+	if !m.hasData() {
+		return &api.UpgradeResponse{Reason: fmt.Sprintf("peer %s has not started etcd", m.config.ID)}, nil
+	}
+	version := config.EtcdVersion(req.Version)
+	if err := m.checkVersionAvailable(version); err != nil {
+		return &api.UpgradeResponse{Reason: err.Error()}, nil
+	}
+
+	if m.etcdVersion() != version {
+		glog.Infof("restarting etcd member %s with etcd %s for leader %s", m.config.ID, version, req.Leader)
+		if err := m.recordVersion(version); err != nil {
+			return nil, err
+		}
+	}
+	return &api.UpgradeResponse{Version: req.Version, ClusterVersion: string(m.clusterVersion())}, nil
+}
+
+// upgradeCluster sets the etcd version the leader upgrades the cluster to, one minor version
+// at a time
+func (m *EtcdManager) upgradeCluster(req *api.UpgradeRequest) (*api.UpgradeResponse, error) {
+	if leader, _ := m.election.Leader(); leader != m.config.ID {
+		return &api.UpgradeResponse{Reason: fmt.Sprintf("peer %s is not the leader, ask %q", m.config.ID, leader)}, nil
+	}
+	current := m.clusterVersion()
+	version := config.EtcdVersion(req.ClusterVersion)
+	if version == "" || version == current {
+		return &api.UpgradeResponse{ClusterVersion: string(current)}, nil
+	}
+	if m.migrating() {
+		return &api.UpgradeResponse{Reason: fmt.Sprintf("cluster %s is migrating to etcd v3", m.config.ClusterName)}, nil
+	}
+	if err := checkUpgradeVersions(current, version); err != nil {
+		return &api.UpgradeResponse{Reason: err.Error()}, nil
+	}
+	if err := m.checkVersionAvailable(version); err != nil {
+		return &api.UpgradeResponse{Reason: err.Error()}, nil
+	}
+
+	glog.Infof("etcd version of cluster %s set to %s, it was %s", m.config.ClusterName, version, current)
+	if err := m.setClusterVersion(version); err != nil {
+		return nil, err
+	}
+	return &api.UpgradeResponse{ClusterVersion: string(version)}, nil
+}
+
+// reconcileVersion stops etcd if it runs another version than the recorded one, reconcile
+// then restarts it from its data with the recorded version
+func (m *EtcdManager) reconcileVersion() error {
+	if m.migrating() || !m.isRunning() {
+		return nil
+	}
+	m.mutex.Lock()
+	running := m.flags.Version
+	m.mutex.Unlock()
+	if version := m.etcdVersion(); version != running {
+		glog.Infof("restarting etcd member %s to change etcd %s to %s", m.config.ID, running, version)
+		return m.stopEtcd()
+	}
+	return nil
+}
+
+// upgrade upgrades the members to the configured etcd version, one member at a time.
+// A member that does not report the new version in time is rolled back to the version
+// it ran before. It returns true while an upgrade is in progress.
+func (m *EtcdManager) upgrade(ctx context.Context, peers map[api.PeerID]*discovery.Peer, term int64) (bool, error) {
+	members, err := m.memberVersions(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	m.mutex.Lock()
+	state := m.upgrading
+	m.mutex.Unlock()
+	if state != nil {
+		return true, m.checkUpgrade(ctx, peers, term, state, members)
+	}
+
+	version := m.clusterVersion()
+	// every peer keeps the version, so that the next leader upgrades to it as well
+	if !m.pushClusterVersion(ctx, peers, term, version) {
+		return false, nil
+	}
+	m.mutex.Lock()
+	failed := m.upgradeFailed
+	m.mutex.Unlock()
+	if failed != nil && failed.to == version && time.Since(failed.started) < upgradeRetryInterval {
+		return false, nil
+	}
+
+	if unhealthy := m.unhealthyMembers(); unhealthy.Len() > 0 {
+		glog.Warningf("not upgrading cluster %s to etcd %s, members %v are not healthy", m.config.ClusterName, version, unhealthy.List())
+		return false, nil
+	}
+	name, err := nextUpgrade(members, string(m.config.ID), version)
+	if err != nil {
+		glog.Warningf("not upgrading cluster %s to etcd %s: %v", m.config.ClusterName, version, err)
+		return false, nil
+	}
+	if name == "" {
+		return false, nil
+	}
+	state = &upgradeState{member: name, to: version, started: time.Now()}
+	for _, member := range members {
+		if member.name == name {
+			state.from = member.version
+		}
+	}
+
+	glog.Infof("upgrading etcd member %s of cluster %s from %s to %s", name, m.config.ClusterName, state.from, state.to)
+	if err := m.pushUpgrade(ctx, peers, term, name, state.to); err != nil {
+		// the member refused the version, it is retried later like a rolled back one
+		m.mutex.Lock()
+		m.upgradeFailed = state
+		m.mutex.Unlock()
+		return false, fmt.Errorf("error upgrading etcd member %s: %v", name, err)
+	}
+	m.mutex.Lock()
+	m.upgrading = state
+	m.mutex.Unlock()
+	return true, nil
+}
+
+// checkUpgrade waits for the member of state to report its target version, and rolls
+// it back once upgradeTimeout passed
+func (m *EtcdManager) checkUpgrade(ctx context.Context, peers map[api.PeerID]*discovery.Peer, term int64, state *upgradeState, members []memberVersion) error {
+	for _, member := range members {
+		if member.name != state.member || member.version != state.target() {
+			continue
+		}
+		m.mutex.Lock()
+		m.upgrading = nil
+		if state.rollback {
+			m.upgradeFailed = state
+		}
+		m.mutex.Unlock()
+		if state.rollback {
+			return fmt.Errorf("rolled back etcd member %s to %s, upgrading to %s failed", state.member, state.from, state.to)
+		}
+		glog.Infof("upgraded etcd member %s of cluster %s to %s", state.member, m.config.ClusterName, state.to)
+		return nil
+	}
+
+	if !state.rollback && time.Since(state.started) > upgradeTimeout {
+		glog.Warningf("etcd member %s did not report version %s within %v, rolling back to %s", state.member, state.to, upgradeTimeout, state.from)
+		state.rollback = true
+		state.started = time.Now()
+	}
+	// the request is repeated until the member reports the version, in case it was lost
+	return m.pushUpgrade(ctx, peers, term, state.member, state.target())
+}
+
+// pushUpgrade makes the member name restart with version
+func (m *EtcdManager) pushUpgrade(ctx context.Context, peers map[api.PeerID]*discovery.Peer, term int64, name string, version config.EtcdVersion) error {
+	req := &api.UpgradeRequest{Leader: string(m.config.ID), Term: term, Version: string(version)}
+
+	var resp *api.UpgradeResponse
+	if name == string(m.config.ID) {
+		var err error
+		if resp, err = m.HandleUpgrade(ctx, req); err != nil {
+			return err
+		}
+	} else {
+		peer, ok := peers[api.PeerID(name)]
+		if !ok {
+			return fmt.Errorf("member %s is not reachable", name)
+		}
+		client, err := m.newPeerClient(peer.Address)
+		if err != nil {
+			return err
+		}
+		result, err := client.Upgrades().Create(&api.Upgrade{Request: req})
+		if err != nil {
+			return err
+		}
+		resp = result.Response
+	}
+	if resp == nil || resp.Version != string(version) {
+		reason := "no response"
+		if resp != nil {
+			reason = resp.Reason
+		}
+		return fmt.Errorf("upgrade to %s refused: %s", version, reason)
+	}
+	return nil
+}
+
+// pushClusterVersion pushes the etcd version of the cluster to the peers that did not take it
+// yet in term. It returns true once every peer took it.
+func (m *EtcdManager) pushClusterVersion(ctx context.Context, peers map[api.PeerID]*discovery.Peer, term int64, version config.EtcdVersion) bool {
+	key := fmt.Sprintf("%d/%s", term, version)
+
+	m.mutex.Lock()
+	if m.versionAcked == nil || m.versionKey != key {
+		m.versionAcked = sets.NewString(string(m.config.ID))
+		m.versionKey = key
+	}
+	acked := sets.NewString(m.versionAcked.List()...)
+	m.mutex.Unlock()
+
+	req := &api.UpgradeRequest{Leader: string(m.config.ID), Term: term, ClusterVersion: string(version)}
+	for id, peer := range peers {
+		if acked.Has(string(id)) {
+			continue
+		}
+		client, err := m.newPeerClient(peer.Address)
+		if err != nil {
+			glog.Warningf("error creating client for peer %s: %v", id, err)
+			continue
+		}
+		result, err := client.Upgrades().Create(&api.Upgrade{Request: req})
+		if err != nil {
+			glog.Warningf("error pushing etcd version %s to %s: %v", version, id, err)
+			continue
+		}
+		if result.Response == nil || result.Response.ClusterVersion != string(version) {
+			glog.Warningf("peer %s refused etcd version %s: %v", id, version, result.Response)
+			continue
+		}
+		acked.Insert(string(id))
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.versionKey == key && m.versionAcked != nil {
+		m.versionAcked = acked
+	}
+	for id := range peers {
+		if !acked.Has(string(id)) {
+			return false
+		}
+	}
+	return true
+}
+
+// memberVersions returns the etcd version reported by every member of the cluster
+func (m *EtcdManager) memberVersions(ctx context.Context) ([]memberVersion, error) {
+	m.mutex.Lock()
+	flags := m.flags
+	m.mutex.Unlock()
+
+	client, err := flags.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	members, err := client.ListMembers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing members: %v", err)
+	}
+	var versions []memberVersion
+	for _, member := range members {
+		mv := memberVersion{name: member.Name}
+		if member.Name == "" {
+			mv.name = strings.Join(member.PeerURLs, ",")
+		} else if v, err := member.ServerVersion(ctx); err != nil {
+			glog.V(2).Infof("error getting etcd version of member %s: %v", member.Name, err)
+		} else {
+			mv.version = config.EtcdVersion(v)
+		}
+		versions = append(versions, mv)
+	}
+	return versions, nil
+}
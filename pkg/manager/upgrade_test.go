@@ -0,0 +1,198 @@
+package manager
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/config"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"github.com/etcd-manager/etcd-discovery/pkg/etcd"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestCheckUpgradeVersions(t *testing.T) {
+	cases := []struct {
+		from, to config.EtcdVersion
+		valid    bool
+	}{
+		{"3.1.12", "3.2.18", true},
+		{"3.2.13", "3.2.18", true},
+		{"3.1.12", "3.3.3", false},
+		{"3.2.18", "3.1.12", false},
+		{"2.3.7", "3.0.17", false},
+		{"latest", "3.2.18", false},
+	}
+	for _, c := range cases {
+		if err := checkUpgradeVersions(c.from, c.to); (err == nil) != c.valid {
+			t.Errorf("upgrading %s to %s: unexpected error %v", c.from, c.to, err)
+		}
+	}
+}
+
+func TestNextUpgrade(t *testing.T) {
+	cases := []struct {
+		name     string
+		members  []memberVersion
+		expected string
+		err      bool
+	}{
+		{
+			name:    "up to date",
+			members: []memberVersion{{"a", "3.2.18"}, {"b", "3.2.18"}, {"c", ""}},
+		},
+		{
+			name:     "leader last",
+			members:  []memberVersion{{"a", "3.1.12"}, {"b", "3.2.18"}, {"c", "3.1.12"}},
+			expected: "c",
+		},
+		{
+			name:     "leader only",
+			members:  []memberVersion{{"a", "3.1.12"}, {"b", "3.2.18"}, {"c", "3.2.18"}},
+			expected: "a",
+		},
+		{
+			name:    "unhealthy member",
+			members: []memberVersion{{"a", "3.1.12"}, {"b", "3.1.12"}, {"c", ""}},
+			err:     true,
+		},
+		{
+			name:    "no quorum",
+			members: []memberVersion{{"a", "3.1.12"}, {"b", "3.1.12"}},
+			err:     true,
+		},
+		{
+			name:     "single member",
+			members:  []memberVersion{{"a", "3.1.12"}},
+			expected: "a",
+		},
+		{
+			name:    "two minor versions",
+			members: []memberVersion{{"a", "3.1.12"}, {"b", "3.0.17"}, {"c", "3.1.12"}},
+			err:     true,
+		},
+	}
+	for _, c := range cases {
+		name, err := nextUpgrade(c.members, "a", "3.2.18")
+		if (err != nil) != c.err || name != c.expected {
+			t.Errorf("%s: expected %q, got %q, %v", c.name, c.expected, name, err)
+		}
+	}
+}
+
+func TestHandleUpgrade(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// b follows a, which was elected leader for term 1
+	election := discovery.NewElection("b", 3, time.Minute, clock.NewFakeClock(time.Now()))
+	resp := &api.PingResponse{}
+	election.HandlePing(&api.PingRequest{Info: &api.PeerInfo{ID: "a"}, Leader: "a", Term: 1}, resp)
+	if !resp.LeaseGranted {
+		t.Fatalf("b did not grant the lease to a")
+	}
+
+	m := &EtcdManager{
+		config: &EtcdConfig{
+			EtcdCluster: config.EtcdCluster{ClusterName: "test", ClusterSize: 3, DataDir: dir, EtcdVersion: "3.2.18"},
+			ID:          "b",
+			ProcessType: etcd.ProcessTypeStaticPod,
+		},
+		election: election,
+	}
+	if err := os.MkdirAll(filepath.Join(m.etcdDataDir(), "member"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.recordVersion("3.1.12"); err != nil {
+		t.Fatal(err)
+	}
+	if v := m.etcdVersion(); v != "3.1.12" {
+		t.Errorf("expected existing data to run with etcd 3.1.12, got %s", v)
+	}
+
+	req := &api.UpgradeRequest{Leader: "a", Term: 2, Version: "3.2.18"}
+	if resp, err := m.HandleUpgrade(context.Background(), req); err != nil || resp.Version != "" {
+		t.Errorf("expected an upgrade of another term to be refused, got %+v, %v", resp, err)
+	}
+
+	req.Term = 1
+	resp2, err := m.HandleUpgrade(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp2.Version != "3.2.18" {
+		t.Errorf("expected upgrade to be accepted, got %+v", resp2)
+	}
+	if v := m.etcdVersion(); v != "3.2.18" {
+		t.Errorf("expected member to restart with etcd 3.2.18, got %s", v)
+	}
+
+	// the version of the cluster is kept by every peer
+	if resp, err := m.HandleUpgrade(context.Background(), &api.UpgradeRequest{Leader: "a", Term: 1, ClusterVersion: "3.3.1"}); err != nil || resp.ClusterVersion != "3.3.1" {
+		t.Errorf("expected the version of the cluster to be taken, got %+v, %v", resp, err)
+	}
+	if v, err := m.loadClusterVersion(); err != nil || v != "3.3.1" {
+		t.Errorf("expected etcd 3.3.1 to be kept, got %s, %v", v, err)
+	}
+	if resp, err := m.HandleUpgrade(context.Background(), &api.UpgradeRequest{ClusterVersion: "3.3.1"}); err != nil || resp.Reason == "" {
+		t.Errorf("expected follower to refuse the request of an operator, got %+v, %v", resp, err)
+	}
+
+	// direct processes need the binaries of the version
+	m.config.ProcessType = etcd.ProcessTypeDirect
+	req.Version = "3.3.99"
+	if resp, err := m.HandleUpgrade(context.Background(), req); err != nil || resp.Version != "" {
+		t.Errorf("expected an upgrade to a missing version to be refused, got %+v, %v", resp, err)
+	}
+	if v := m.etcdVersion(); v != "3.2.18" {
+		t.Errorf("expected member to keep etcd 3.2.18, got %s", v)
+	}
+}
+
+func TestUpgradeCluster(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := &EtcdManager{
+		config: &EtcdConfig{
+			EtcdCluster: config.EtcdCluster{ClusterName: "test", ClusterSize: 1, DataDir: dir, EtcdVersion: "3.1.12"},
+			ID:          "a",
+			ProcessType: etcd.ProcessTypeStaticPod,
+		},
+		election: soleLeader(t, "a"),
+	}
+
+	for _, version := range []string{"3.3.1", "3.0.17", "latest"} {
+		if resp, err := m.HandleUpgrade(context.Background(), &api.UpgradeRequest{ClusterVersion: version}); err != nil || resp.Reason == "" {
+			t.Errorf("expected etcd %s to be refused, got %+v, %v", version, resp, err)
+		}
+	}
+	resp, err := m.HandleUpgrade(context.Background(), &api.UpgradeRequest{ClusterVersion: "3.2.18"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Reason != "" || resp.ClusterVersion != "3.2.18" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if v, err := m.loadClusterVersion(); err != nil || v != "3.2.18" || m.clusterVersion() != "3.2.18" {
+		t.Errorf("expected etcd 3.2.18 to be kept for the next leader, got %s, %v", v, err)
+	}
+
+	// a status request does not change the version
+	if resp, err := m.HandleUpgrade(context.Background(), &api.UpgradeRequest{}); err != nil || resp.ClusterVersion != "3.2.18" {
+		t.Errorf("expected the version of the cluster, got %+v, %v", resp, err)
+	}
+	if !m.pushClusterVersion(context.Background(), map[api.PeerID]*discovery.Peer{"a": {ID: "a"}}, 1, "3.2.18") {
+		t.Errorf("expected the leader to take its own version")
+	}
+}
@@ -0,0 +1,207 @@
+// Package metrics defines the prometheus metrics of the discovery server and the etcd
+// manager. They are served with the metrics of the apiserver on /metrics.
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "etcd_manager"
+
+var (
+	// PingDuration is the latency of successful pings, by address of the discovery server
+	PingDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "discovery",
+			Name:      "ping_duration_seconds",
+			Help:      "Latency of successful pings of discovery servers.",
+		},
+		[]string{"address"},
+	)
+	// PingFailures counts the failed pings, by address of the discovery server
+	PingFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "discovery",
+			Name:      "ping_failures_total",
+			Help:      "Number of failed pings of discovery servers.",
+		},
+		[]string{"address"},
+	)
+
+	// ElectionTerm is the highest leader election term seen
+	ElectionTerm = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "election",
+			Name:      "term",
+			Help:      "Term of the current leader.",
+		},
+	)
+	// ElectionIsLeader is 1 while the local peer is the leader
+	ElectionIsLeader = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "election",
+			Name:      "is_leader",
+			Help:      "1 while the local peer is the leader, 0 otherwise.",
+		},
+	)
+	// ElectionLeaderChanges counts the changes of the leader seen by the local peer
+	ElectionLeaderChanges = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "election",
+			Name:      "leader_changes_total",
+			Help:      "Number of leader changes seen by the local peer.",
+		},
+	)
+
+	// MemberOperations counts the etcd member changes made by the leader, by operation
+	// (add or remove) and result (success or error)
+	MemberOperations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "manager",
+			Name:      "member_operations_total",
+			Help:      "Number of etcd members added and removed.",
+		},
+		[]string{"operation", "result"},
+	)
+	// EtcdRestarts counts the restarts of the local etcd process
+	EtcdRestarts = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "manager",
+			Name:      "etcd_restarts_total",
+			Help:      "Number of restarts of the local etcd process.",
+		},
+	)
+
+	// BackupDuration is the duration of successful backups
+	BackupDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "backup",
+			Name:      "duration_seconds",
+			Help:      "Duration of successful backups.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+		},
+	)
+	// BackupSize is the size of the snapshot of the last successful backup
+	BackupSize = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "backup",
+			Name:      "size_bytes",
+			Help:      "Size of the snapshot of the last successful backup.",
+		},
+	)
+	// BackupFailures counts the failed backups
+	BackupFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "backup",
+			Name:      "failures_total",
+			Help:      "Number of failed backups.",
+		},
+	)
+	// CertificateExpiry is when the certificates of the local peer expire, by certificate
+	// (ca, peer, server or healthcheck-client)
+	CertificateExpiry = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "pki",
+			Name:      "certificate_expiry_timestamp_seconds",
+			Help:      "Unix time at which the certificates of the local peer expire.",
+		},
+		[]string{"certificate"},
+	)
+	// CertificateRotations counts the renewals of the certificates of the local peer
+	CertificateRotations = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "pki",
+			Name:      "certificate_rotations_total",
+			Help:      "Number of renewals of the certificates of the local peer.",
+		},
+	)
+
+	backupAge = &backupAgeCollector{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "backup", "last_success_age_seconds"),
+			"Time since the last successful backup, not reported before one is known.",
+			nil, nil,
+		),
+	}
+)
+
+const (
+	OperationAdd    = "add"
+	OperationRemove = "remove"
+)
+
+var registerMetrics sync.Once
+
+// Register registers the metrics with the default prometheus registry
+func Register() {
+	registerMetrics.Do(func() {
+		prometheus.MustRegister(PingDuration)
+		prometheus.MustRegister(PingFailures)
+		prometheus.MustRegister(ElectionTerm)
+		prometheus.MustRegister(ElectionIsLeader)
+		prometheus.MustRegister(ElectionLeaderChanges)
+		prometheus.MustRegister(MemberOperations)
+		prometheus.MustRegister(EtcdRestarts)
+		prometheus.MustRegister(BackupDuration)
+		prometheus.MustRegister(BackupSize)
+		prometheus.MustRegister(BackupFailures)
+		prometheus.MustRegister(CertificateExpiry)
+		prometheus.MustRegister(CertificateRotations)
+		prometheus.MustRegister(backupAge)
+	})
+}
+
+// ObserveMemberOperation counts an etcd member change that failed with err, or succeeded
+func ObserveMemberOperation(operation string, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	MemberOperations.WithLabelValues(operation, result).Inc()
+}
+
+// SetLastBackup records when the last successful backup was taken
+func SetLastBackup(t time.Time) {
+	backupAge.mutex.Lock()
+	defer backupAge.mutex.Unlock()
+	if t.After(backupAge.last) {
+		backupAge.last = t
+	}
+}
+
+// backupAgeCollector reports the time since the last successful backup when it is collected
+type backupAgeCollector struct {
+	desc *prometheus.Desc
+
+	mutex sync.Mutex
+	last  time.Time
+}
+
+func (c *backupAgeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *backupAgeCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mutex.Lock()
+	last := c.last
+	c.mutex.Unlock()
+	if last.IsZero() {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, time.Since(last).Seconds())
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func scrape(t *testing.T) string {
+	server := httptest.NewServer(prometheus.Handler())
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	Register()
+	// registering twice must not panic
+	Register()
+
+	if body := scrape(t); strings.Contains(body, "etcd_manager_backup_last_success_age_seconds") {
+		t.Errorf("expected no backup age before a backup is known")
+	}
+
+	PingDuration.WithLabelValues("10.0.0.1:2381").Observe(0.01)
+	PingFailures.WithLabelValues("10.0.0.2:2381").Inc()
+	ElectionTerm.Set(3)
+	ElectionIsLeader.Set(1)
+	ElectionLeaderChanges.Inc()
+	ObserveMemberOperation(OperationAdd, nil)
+	ObserveMemberOperation(OperationRemove, errors.New("etcdserver: unhealthy cluster"))
+	EtcdRestarts.Inc()
+	BackupDuration.Observe(2)
+	BackupSize.Set(1024)
+	BackupFailures.Inc()
+	CertificateExpiry.WithLabelValues("peer").Set(1.5e9)
+	CertificateRotations.Inc()
+	SetLastBackup(time.Now().Add(-time.Minute))
+	SetLastBackup(time.Now().Add(-time.Hour))
+
+	body := scrape(t)
+	for _, expected := range []string{
+		`etcd_manager_discovery_ping_duration_seconds_count{address="10.0.0.1:2381"} 1`,
+		`etcd_manager_discovery_ping_failures_total{address="10.0.0.2:2381"} 1`,
+		`etcd_manager_election_term 3`,
+		`etcd_manager_election_is_leader 1`,
+		`etcd_manager_election_leader_changes_total 1`,
+		`etcd_manager_manager_member_operations_total{operation="add",result="success"} 1`,
+		`etcd_manager_manager_member_operations_total{operation="remove",result="error"} 1`,
+		`etcd_manager_manager_etcd_restarts_total 1`,
+		`etcd_manager_backup_duration_seconds_count 1`,
+		`etcd_manager_backup_size_bytes 1024`,
+		`etcd_manager_backup_failures_total 1`,
+		`etcd_manager_pki_certificate_expiry_timestamp_seconds{certificate="peer"} 1.5e+09`,
+		`etcd_manager_pki_certificate_rotations_total 1`,
+		`etcd_manager_backup_last_success_age_seconds 6`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected %q in metrics:\n%s", expected, body)
+		}
+	}
+}
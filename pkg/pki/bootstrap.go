@@ -0,0 +1,366 @@
+package pki
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	netutil "github.com/appscode/go/net"
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	cs "github.com/etcd-manager/etcd-discovery/client/clientset/versioned/typed/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/backup"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"github.com/golang/glog"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+const (
+	requestTimeout = 10 * time.Second
+	retryInterval  = 10 * time.Second
+)
+
+var (
+	// errNoAnswer is returned when no discovery server answered the certificate request
+	errNoAnswer = errors.New("no discovery server answered")
+	// errNoLeader is returned when the discovery servers that answered have not elected a
+	// leader since they started
+	errNoLeader = errors.New("no leader was elected")
+)
+
+// CertificatesGetterFunc returns a client for the discovery server at address that
+// authenticates with the bootstrap token, and trusts the server if it is issued by a CA
+// with one of caCertHashes
+type CertificatesGetterFunc func(address, token string, caCertHashes []string) (cs.CertificatesGetter, error)
+
+// Bootstrapper obtains the certificates of a peer from the cluster CA. With the bootstrap
+// token the leader signs them, the peer only trusts discovery servers issued by a pinned CA.
+// The first peers of a cluster sign their certificates with the CA of the backup store,
+// which one of them creates, as there is no leader before they have their certificates.
+// Without the token every peer signs its certificates with the CA of the backup store.
+type Bootstrapper struct {
+	Files Files
+	// ID is the id of the peer, it is replaced by the id the leader issues the certificates to
+	ID      string
+	Cluster string
+	SANs    certutil.AltNames
+	// Token is the bootstrap token of the cluster, empty skips asking the leader
+	Token string
+	// CACertHashes pin the CAs the discovery servers are trusted with, see CACertHash.
+	// Without them the CAs of the backup store are pinned.
+	CACertHashes []string
+	Seeds        discovery.SeedProvider
+	Store        backup.BackupStore
+
+	clients CertificatesGetterFunc
+}
+
+func NewBootstrapper(files Files, id, cluster string, sans certutil.AltNames, token string, caCertHashes []string, seeds discovery.SeedProvider, store backup.BackupStore) *Bootstrapper {
+	return &Bootstrapper{
+		Files:        files,
+		ID:           id,
+		Cluster:      cluster,
+		SANs:         sans,
+		Token:        token,
+		CACertHashes: caCertHashes,
+		Seeds:        seeds,
+		Store:        store,
+		clients:      newBootstrapClient,
+	}
+}
+
+// newBootstrapClient returns a client that trusts the discovery server if the CA that
+// issued its certificate is pinned. The server sends the CA along with its certificate.
+func newBootstrapClient(address, token string, caCertHashes []string) (cs.CertificatesGetter, error) {
+	return cs.NewForConfig(&rest.Config{
+		Host:        "https://" + address,
+		BearerToken: token,
+		Transport: utilnet.SetTransportDefaults(&http.Transport{
+			TLSClientConfig: &tls.Config{
+				MinVersion: tls.VersionTLS12,
+				// the chain is verified against the pinned CAs instead of the system roots
+				InsecureSkipVerify:    true,
+				VerifyPeerCertificate: verifyPinnedChain(caCertHashes),
+			},
+		}),
+		Timeout: requestTimeout,
+	})
+}
+
+// verifyPinnedChain accepts the certificate chains whose first certificate is issued by
+// one of the other certificates of the chain, which is a CA with one of caCertHashes
+func verifyPinnedChain(caCertHashes []string) func([][]byte, [][]*x509.Certificate) error {
+	pins := sets.NewString()
+	for _, hash := range caCertHashes {
+		pins.Insert(strings.ToLower(hash))
+	}
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("the discovery server sent no certificate")
+		}
+		certs := make([]*x509.Certificate, len(rawCerts))
+		for i, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("error parsing the certificate of the discovery server: %v", err)
+			}
+			certs[i] = cert
+		}
+		for _, ca := range certs[1:] {
+			if !ca.IsCA || !pins.Has(CACertHash(ca)) {
+				continue
+			}
+			roots := x509.NewCertPool()
+			roots.AddCert(ca)
+			if _, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}}); err == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("the certificate of the discovery server %s is not issued by a pinned CA", certs[0].Subject.CommonName)
+	}
+}
+
+// Run writes the certificates of the peer unless they were issued before. It retries
+// until they are issued or ctx is done.
+func (b *Bootstrapper) Run(ctx context.Context) error {
+	if b.Files.Issued() {
+		return nil
+	}
+	req, err := NewRequest(b.ID, b.SANs)
+	if err != nil {
+		return err
+	}
+	for {
+		err := b.issue(ctx, req)
+		if err == nil {
+			glog.Infof("issued certificates of peer %s in %s", b.ID, b.Files.Dir)
+			return nil
+		}
+		glog.Warningf("unable to issue certificates of peer %s: %v", b.ID, err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("certificates of peer %s were not issued: %v", b.ID, err)
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+// issue asks the leader for the certificates when there is a bootstrap token. They are
+// only signed locally while the cluster is formed: no discovery server answers and the
+// backup store has no CA, or the servers that answer have not elected a leader yet.
+func (b *Bootstrapper) issue(ctx context.Context, req *Request) error {
+	if b.Token != "" {
+		hashes, hasCA, err := b.caCertHashes()
+		if err != nil {
+			return err
+		}
+		err = errNoAnswer
+		if len(hashes) > 0 {
+			var caCert []byte
+			var certs map[Profile][]byte
+			caCert, certs, err = b.requestFromLeader(ctx, req, hashes)
+			if err == nil {
+				b.ID = req.ID
+				return b.Files.Write(req, caCert, certs)
+			}
+		}
+		if err == errNoAnswer && hasCA {
+			return fmt.Errorf("no discovery server of cluster %s answered", b.Cluster)
+		}
+		if err != errNoAnswer && err != errNoLeader {
+			return err
+		}
+		glog.Infof("cluster %s has no leader yet, signing the certificates of peer %s with the CA of the backup store", b.Cluster, b.ID)
+	}
+
+	ca, err := b.loadCA()
+	if err != nil {
+		return err
+	}
+	certs, err := req.Sign(ca)
+	if err != nil {
+		return err
+	}
+	return b.Files.Write(req, ca.BundlePEM(), certs)
+}
+
+// caCertHashes returns the pins of the CAs the discovery servers are trusted with, and
+// whether the backup store has a CA
+func (b *Bootstrapper) caCertHashes() ([]string, bool, error) {
+	certPEM, _, err := b.Store.LoadCA()
+	if err == backup.ErrNoCA {
+		return b.CACertHashes, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if len(b.CACertHashes) > 0 {
+		return b.CACertHashes, true, nil
+	}
+	cas, err := certutil.ParseCertsPEM(certPEM)
+	if err != nil {
+		return nil, true, fmt.Errorf("error parsing CA certificate: %v", err)
+	}
+	var hashes []string
+	for _, ca := range cas {
+		hashes = append(hashes, CACertHash(ca))
+	}
+	return hashes, true, nil
+}
+
+// requestFromLeader sends the certificate request to every seed, only the leader signs it.
+// It returns errNoAnswer if no seed answered, and errNoLeader if those that answered have
+// no leader.
+func (b *Bootstrapper) requestFromLeader(ctx context.Context, req *Request, caCertHashes []string) ([]byte, map[Profile][]byte, error) {
+	seeds, err := b.Seeds.GetSeeds(ctx)
+	if len(seeds) == 0 {
+		if err == nil {
+			err = errNoAnswer
+		}
+		return nil, nil, err
+	}
+	var refused error
+	noLeader := false
+	for _, seed := range seeds {
+		caCert, certs, err := b.request(seed, req, caCertHashes)
+		switch err {
+		case nil:
+			return caCert, certs, nil
+		case errNoLeader:
+			noLeader = true
+		case errNoAnswer:
+		default:
+			refused = err
+		}
+	}
+	switch {
+	case refused != nil:
+		return nil, nil, fmt.Errorf("none of the discovery servers %v issued certificates: %v", seeds, refused)
+	case noLeader:
+		return nil, nil, errNoLeader
+	}
+	return nil, nil, errNoAnswer
+}
+
+func (b *Bootstrapper) request(address string, req *Request, caCertHashes []string) ([]byte, map[Profile][]byte, error) {
+	client, err := b.clients(address, b.Token, caCertHashes)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := client.Certificates().Create(&api.Certificate{
+		Request: &api.CertificateRequest{
+			ID:                   req.ID,
+			PeerCSR:              string(req.CSRs[ProfilePeer]),
+			ServerCSR:            string(req.CSRs[ProfileServer]),
+			HealthcheckClientCSR: string(req.CSRs[ProfileHealthcheckClient]),
+		},
+	})
+	if _, ok := err.(apierrors.APIStatus); err != nil && !ok {
+		glog.V(2).Infof("discovery server %s did not answer: %v", address, err)
+		return nil, nil, errNoAnswer
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.Response == nil {
+		return nil, nil, fmt.Errorf("empty response")
+	}
+	if resp.Response.Reason != "" {
+		glog.V(2).Infof("discovery server %s did not issue certificates: %s", address, resp.Response.Reason)
+		if resp.Response.Term == 0 {
+			return nil, nil, errNoLeader
+		}
+		return nil, nil, fmt.Errorf("%s", resp.Response.Reason)
+	}
+	if resp.Response.ID == "" {
+		return nil, nil, fmt.Errorf("no peer id in the response")
+	}
+	caCert := []byte(resp.Response.CACert)
+	certs := map[Profile][]byte{
+		ProfilePeer:              []byte(resp.Response.PeerCert),
+		ProfileServer:            []byte(resp.Response.ServerCert),
+		ProfileHealthcheckClient: []byte(resp.Response.HealthcheckClientCert),
+	}
+	if err := verifyChain(caCert, certs); err != nil {
+		return nil, nil, err
+	}
+	req.ID = resp.Response.ID
+	return caCert, certs, nil
+}
+
+// loadCA returns the CA of the backup store, it creates one if the store has none. Peers of
+// a new cluster start together, only one of them creates the CA and the others adopt it.
+func (b *Bootstrapper) loadCA() (*CA, error) {
+	ca, err := LoadCA(b.Store)
+	if err != backup.ErrNoCA {
+		return ca, err
+	}
+
+	glog.Infof("creating the CA of cluster %s in %s", b.Cluster, b.Store.Spec())
+	ca, err = NewCA(b.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	err = b.Store.CreateCA(ca.BundlePEM(), ca.KeyPEM())
+	if err == nil {
+		return ca, nil
+	}
+	if err != backup.ErrCAExists {
+		return nil, err
+	}
+	ca, err = LoadCA(b.Store)
+	if err == backup.ErrNoCA {
+		return nil, fmt.Errorf("the CA of cluster %s is being created by another peer", b.Cluster)
+	}
+	return ca, err
+}
+
+// LoadCA returns the CA saved in store, backup.ErrNoCA if there is none
+func LoadCA(store backup.BackupStore) (*CA, error) {
+	cert, key, err := store.LoadCA()
+	if err != nil {
+		return nil, err
+	}
+	return ParseCA(cert, key)
+}
+
+// SaveCA saves ca in store along with the CAs trusted during its rotation
+func SaveCA(store backup.BackupStore, ca *CA) error {
+	return store.SaveCA(ca.BundlePEM(), ca.KeyPEM())
+}
+
+// HostAltNames returns the SANs of the certificates of this host: localhost, the loopback
+// address and ips. Without ips it takes the external IPs of the host. The leader only issues
+// certificates for the address a peer connects from and the hosts it knows for the peer.
+func HostAltNames(ips ...net.IP) (certutil.AltNames, error) {
+	sans := certutil.AltNames{DNSNames: []string{"localhost"}}
+	addresses := sets.NewString()
+	for _, ip := range ips {
+		if ip != nil && !ip.IsUnspecified() {
+			addresses.Insert(ip.String())
+		}
+	}
+	if addresses.Len() == 0 {
+		external, _, err := netutil.HostIPs()
+		if err != nil {
+			return sans, fmt.Errorf("error listing host IPs: %v", err)
+		}
+		addresses.Insert(external...)
+	}
+	addresses.Insert("127.0.0.1")
+	for _, address := range addresses.List() {
+		if ip := net.ParseIP(address); ip != nil {
+			sans.IPs = append(sans.IPs, ip)
+		}
+	}
+	return sans, nil
+}
@@ -0,0 +1,320 @@
+// Package pki issues the certificates of the peers from the cluster CA. The CA is kept in
+// the backup store, the leader signs the certificate requests of new peers with it.
+package pki
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/etcd-manager/etcd-discovery/pkg/constants"
+	"k8s.io/apimachinery/pkg/util/sets"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+const (
+	caValidity   = 10 * 365 * 24 * time.Hour
+	certValidity = 365 * 24 * time.Hour
+	// clockSkew backdates certificates, so peers with a late clock accept them
+	clockSkew = 5 * time.Minute
+)
+
+// Profile is the kind of certificate issued for a certificate request
+type Profile string
+
+const (
+	// ProfilePeer certificates secure etcd peer traffic and the discovery servers
+	ProfilePeer Profile = "peer"
+	// ProfileServer certificates secure etcd client traffic
+	ProfileServer Profile = "server"
+	// ProfileHealthcheckClient certificates authenticate the manager to the local etcd
+	ProfileHealthcheckClient Profile = "healthcheck-client"
+)
+
+// Profiles are the certificates issued to every peer
+var Profiles = []Profile{ProfilePeer, ProfileServer, ProfileHealthcheckClient}
+
+// CAPhase is the phase of the rotation of the cluster CA. A new CA is first trusted by every
+// peer, then it issues the certificates of every peer, then the old CA is no longer trusted.
+type CAPhase string
+
+const (
+	// CAPhaseStable trusts only the CA
+	CAPhaseStable CAPhase = "Stable"
+	// CAPhaseTrust trusts the next CA along with the CA, which still signs
+	CAPhaseTrust CAPhase = "Trust"
+	// CAPhaseRetire signs with the new CA, the old one is trusted until the certificates
+	// it issued are renewed
+	CAPhaseRetire CAPhase = "Retire"
+)
+
+// CA is the certificate authority of the cluster
+type CA struct {
+	Cert *x509.Certificate
+	Key  *rsa.PrivateKey
+	// Trusted are the other CAs trusted by the peers while the CA is rotated
+	Trusted []*x509.Certificate
+	// Next is the CA that replaces this one, set in CAPhaseTrust
+	Next *CA
+}
+
+// NewCA creates a self-signed CA for cluster
+func NewCA(cluster string) (*CA, error) {
+	key, err := certutil.NewPrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("error creating CA key: %v", err)
+	}
+	serial, err := newSerial()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:   fmt.Sprintf("etcd-ca-%s", cluster),
+			Organization: []string{constants.PeerOrganization},
+		},
+		NotBefore:             now.Add(-clockSkew).UTC(),
+		NotAfter:              now.Add(caValidity).UTC(),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating CA certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &CA{Cert: cert, Key: key}, nil
+}
+
+// ParseCA parses the pem encoded certificates and keys of a CA. The first certificate is
+// the one of the CA and the others are trusted along with it. A second key belongs to the
+// second certificate, the next CA.
+func ParseCA(certPEM, keyPEM []byte) (*CA, error) {
+	certs, err := certutil.ParseCertsPEM(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing CA certificate: %v", err)
+	}
+	keys, err := parseKeysPEM(keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing CA key: %v", err)
+	}
+	if len(keys) > len(certs) || len(keys) > 2 {
+		return nil, fmt.Errorf("found %d CA keys for %d CA certificates", len(keys), len(certs))
+	}
+	for i, key := range keys {
+		if !certs[i].IsCA {
+			return nil, fmt.Errorf("certificate %s is not a CA", certs[i].Subject.CommonName)
+		}
+		if pub, ok := certs[i].PublicKey.(*rsa.PublicKey); !ok || pub.N.Cmp(key.N) != 0 || pub.E != key.E {
+			return nil, fmt.Errorf("the key of CA %s does not match its certificate", certs[i].Subject.CommonName)
+		}
+	}
+	ca := &CA{Cert: certs[0], Key: keys[0], Trusted: certs[1:]}
+	if len(keys) == 2 {
+		ca.Next = &CA{Cert: certs[1], Key: keys[1]}
+	}
+	return ca, nil
+}
+
+// parseKeysPEM parses every RSA private key of data
+func parseKeysPEM(data []byte) ([]*rsa.PrivateKey, error) {
+	var keys []*rsa.PrivateKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != certutil.RSAPrivateKeyBlockType {
+			continue
+		}
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no RSA private key found")
+	}
+	return keys, nil
+}
+
+// CertPEM returns the pem encoded certificate of the CA
+func (ca *CA) CertPEM() []byte {
+	return certutil.EncodeCertPEM(ca.Cert)
+}
+
+// KeyPEM returns the pem encoded key of the CA, followed by the one of the next CA
+func (ca *CA) KeyPEM() []byte {
+	data := certutil.EncodePrivateKeyPEM(ca.Key)
+	if ca.Next != nil {
+		data = append(data, certutil.EncodePrivateKeyPEM(ca.Next.Key)...)
+	}
+	return data
+}
+
+// BundlePEM returns the pem encoded certificates trusted by the peers, the one of the CA first
+func (ca *CA) BundlePEM() []byte {
+	data := ca.CertPEM()
+	for _, cert := range ca.Trusted {
+		data = append(data, certutil.EncodeCertPEM(cert)...)
+	}
+	return data
+}
+
+// Phase returns the phase of the rotation of the CA
+func (ca *CA) Phase() CAPhase {
+	switch {
+	case ca.Next != nil:
+		return CAPhaseTrust
+	case len(ca.Trusted) > 0:
+		return CAPhaseRetire
+	}
+	return CAPhaseStable
+}
+
+// Rotate returns the CA of the next phase of its rotation. A stable CA starts the rotation
+// with a new CA of cluster, which is trusted first. The peers must use the certificates of
+// the current phase before the next one starts.
+func (ca *CA) Rotate(cluster string) (*CA, error) {
+	switch ca.Phase() {
+	case CAPhaseTrust:
+		return &CA{Cert: ca.Next.Cert, Key: ca.Next.Key, Trusted: []*x509.Certificate{ca.Cert}}, nil
+	case CAPhaseRetire:
+		return &CA{Cert: ca.Cert, Key: ca.Key}, nil
+	}
+	next, err := NewCA(cluster)
+	if err != nil {
+		return nil, err
+	}
+	return &CA{Cert: ca.Cert, Key: ca.Key, Trusted: []*x509.Certificate{next.Cert}, Next: next}, nil
+}
+
+// Sign issues a certificate of profile to peer id for the pem encoded certificate request.
+// The subject and usages are set by the profile, the SANs are the ones of the request. The
+// request is refused if it asks for SANs other than hosts, DNS names or IP addresses.
+func (ca *CA) Sign(csrPEM []byte, profile Profile, id string, hosts []string) ([]byte, error) {
+	block, _ := pem.Decode(csrPEM)
+	if block == nil || block.Type != certutil.CertificateRequestBlockType {
+		return nil, fmt.Errorf("no certificate request found for %s certificate", profile)
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s certificate request: %v", profile, err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("invalid signature of %s certificate request: %v", profile, err)
+	}
+	if denied := deniedAltNames(csr, hosts); len(denied) > 0 {
+		return nil, fmt.Errorf("the %s certificate of %s may not be issued for %s", profile, id, strings.Join(denied, ", "))
+	}
+	serial, err := newSerial()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: id},
+		DNSNames:     csr.DNSNames,
+		IPAddresses:  csr.IPAddresses,
+		NotBefore:    now.Add(-clockSkew).UTC(),
+		NotAfter:     now.Add(certValidity).UTC(),
+		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+	}
+	switch profile {
+	case ProfilePeer:
+		// the organization makes the peer a member of the cluster to the discovery servers
+		tmpl.Subject.Organization = []string{constants.PeerOrganization}
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	case ProfileServer:
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	case ProfileHealthcheckClient:
+		tmpl.Subject.CommonName = constants.EtcdHealthcheckClientCertCommonName
+		tmpl.DNSNames, tmpl.IPAddresses = nil, nil
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	default:
+		return nil, fmt.Errorf("unknown certificate profile %q", profile)
+	}
+	if tmpl.NotAfter.After(ca.Cert.NotAfter) {
+		tmpl.NotAfter = ca.Cert.NotAfter
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.Cert, csr.PublicKey, ca.Key)
+	if err != nil {
+		return nil, fmt.Errorf("error signing %s certificate: %v", profile, err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateBlockType, Bytes: der}), nil
+}
+
+// deniedAltNames returns the SANs of csr that are not one of hosts
+func deniedAltNames(csr *x509.CertificateRequest, hosts []string) []string {
+	allowed := sets.NewString()
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			allowed.Insert(ip.String())
+		} else {
+			allowed.Insert(strings.ToLower(host))
+		}
+	}
+	var denied []string
+	for _, name := range csr.DNSNames {
+		if !allowed.Has(strings.ToLower(name)) {
+			denied = append(denied, name)
+		}
+	}
+	for _, ip := range csr.IPAddresses {
+		if !allowed.Has(ip.String()) {
+			denied = append(denied, ip.String())
+		}
+	}
+	denied = append(denied, csr.EmailAddresses...)
+	for _, uri := range csr.URIs {
+		denied = append(denied, uri.String())
+	}
+	return denied
+}
+
+func newSerial() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).SetInt64(math.MaxInt64))
+	if err != nil {
+		return nil, fmt.Errorf("error creating serial number: %v", err)
+	}
+	return serial, nil
+}
+
+// CACertHash returns the pin of a CA certificate, the hex encoded SHA-256 of its public key
+// prefixed with "sha256:" like the CA certificate hashes of kubeadm
+func CACertHash(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// ValidateCACertHash checks that hash is formatted like the hashes of CACertHash
+func ValidateCACertHash(hash string) error {
+	if !strings.HasPrefix(hash, "sha256:") {
+		return fmt.Errorf("CA certificate hash %q does not start with sha256:", hash)
+	}
+	if sum, err := hex.DecodeString(strings.TrimPrefix(hash, "sha256:")); err != nil || len(sum) != sha256.Size {
+		return fmt.Errorf("CA certificate hash %q is not a hex encoded SHA-256", hash)
+	}
+	return nil
+}
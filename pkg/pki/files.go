@@ -0,0 +1,254 @@
+package pki
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/etcd-manager/etcd-discovery/pkg/constants"
+	"github.com/etcd-manager/etcd-discovery/pkg/fileutil"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+// baseNames locates the certificate and key of each profile in the certificates directory
+var baseNames = map[Profile]string{
+	ProfilePeer:              constants.EtcdPeerCertAndKeyBaseName,
+	ProfileServer:            constants.EtcdServerCertAndKeyBaseName,
+	ProfileHealthcheckClient: constants.EtcdHealthcheckClientCertAndKeyBaseName,
+}
+
+// Files are the certificates of a peer in the certificates directory Dir
+type Files struct {
+	Dir string
+}
+
+// CACert is the certificate of the cluster CA, trusted for peer and client traffic
+func (f Files) CACert() string {
+	return filepath.Join(f.Dir, constants.EtcdCACertName)
+}
+
+func (f Files) Cert(profile Profile) string {
+	return filepath.Join(f.Dir, baseNames[profile]+".crt")
+}
+
+func (f Files) Key(profile Profile) string {
+	return filepath.Join(f.Dir, baseNames[profile]+".key")
+}
+
+// Issued reports if the CA certificate and the certificates and keys of every profile exist
+func (f Files) Issued() bool {
+	if _, err := os.Stat(f.CACert()); err != nil {
+		return false
+	}
+	for _, profile := range Profiles {
+		if ok, _ := certutil.CanReadCertAndKey(f.Cert(profile), f.Key(profile)); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Write stores the certificates issued for req along with its keys. The files are replaced
+// one by one once all of them are written, the CA certificate last. Issued only reports
+// complete sets of certificates.
+func (f Files) Write(req *Request, caCert []byte, certs map[Profile][]byte) error {
+	var files []fileutil.File
+	for _, profile := range Profiles {
+		cert, ok := certs[profile]
+		if !ok {
+			return fmt.Errorf("no %s certificate was issued", profile)
+		}
+		if err := req.check(profile, cert); err != nil {
+			return err
+		}
+		files = append(files,
+			fileutil.File{Name: f.Key(profile), Data: certutil.EncodePrivateKeyPEM(req.keys[profile]), Perm: 0600},
+			fileutil.File{Name: f.Cert(profile), Data: cert, Perm: 0644},
+		)
+	}
+	return writeFiles(append(files, fileutil.File{Name: f.CACert(), Data: caCert, Perm: 0644})...)
+}
+
+// Expiry returns when the CA and the certificate of each profile expire, by profile. The
+// CA expires with the first CA of its bundle.
+func (f Files) Expiry() (map[string]time.Time, error) {
+	expiry := map[string]time.Time{}
+	cas, err := certutil.CertsFromFile(f.CACert())
+	if err != nil {
+		return nil, err
+	}
+	for _, ca := range cas {
+		if t, ok := expiry["ca"]; !ok || ca.NotAfter.Before(t) {
+			expiry["ca"] = ca.NotAfter
+		}
+	}
+	for _, profile := range Profiles {
+		certs, err := certutil.CertsFromFile(f.Cert(profile))
+		if err != nil {
+			return nil, err
+		}
+		expiry[string(profile)] = certs[0].NotAfter
+	}
+	return expiry, nil
+}
+
+// Renewal returns why the certificates are to be renewed to use those of ca, empty if they
+// are current. The certificates are reissued if they were not issued by ca or expire within
+// renewBefore, unless ca expires first. Otherwise only the CA bundle differs.
+func (f Files) Renewal(ca *CA, renewBefore time.Duration, now time.Time) (reason string, reissue bool, err error) {
+	for _, profile := range Profiles {
+		certs, err := certutil.CertsFromFile(f.Cert(profile))
+		if err != nil {
+			return "", false, err
+		}
+		cert := certs[0]
+		if err := cert.CheckSignatureFrom(ca.Cert); err != nil {
+			return fmt.Sprintf("the %s certificate was not issued by CA %s", profile, ca.Cert.Subject.CommonName), true, nil
+		}
+		if cert.NotAfter.Before(now.Add(renewBefore)) && cert.NotAfter.Before(ca.Cert.NotAfter) {
+			return fmt.Sprintf("the %s certificate expires at %v", profile, cert.NotAfter), true, nil
+		}
+	}
+
+	trusted, err := certutil.CertsFromFile(f.CACert())
+	if err != nil {
+		return "", false, err
+	}
+	bundle := append([]*x509.Certificate{ca.Cert}, ca.Trusted...)
+	if len(trusted) != len(bundle) {
+		return "the trusted CAs changed", false, nil
+	}
+	for i := range bundle {
+		if !trusted[i].Equal(bundle[i]) {
+			return "the trusted CAs changed", false, nil
+		}
+	}
+	return "", false, nil
+}
+
+// Renew replaces the CA bundle with the one of ca. If reissue is set, the certificates of
+// peer id are issued again by ca, for the SANs of the current peer certificate.
+func (f Files) Renew(ca *CA, id string, reissue bool) error {
+	if !reissue {
+		return writeFiles(fileutil.File{Name: f.CACert(), Data: ca.BundlePEM(), Perm: 0644})
+	}
+	certs, err := certutil.CertsFromFile(f.Cert(ProfilePeer))
+	if err != nil {
+		return err
+	}
+	req, err := NewRequest(id, certutil.AltNames{DNSNames: certs[0].DNSNames, IPs: certs[0].IPAddresses})
+	if err != nil {
+		return err
+	}
+	issued, err := req.Sign(ca)
+	if err != nil {
+		return err
+	}
+	return f.Write(req, ca.BundlePEM(), issued)
+}
+
+// writeFiles writes the certificate files, creating their directory first
+func writeFiles(files ...fileutil.File) error {
+	if len(files) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(files[0].Name), 0755); err != nil {
+		return fmt.Errorf("error creating certificates directory: %v", err)
+	}
+	return fileutil.WriteFiles(files...)
+}
+
+// Request holds new keys of a peer and the certificate requests for them
+type Request struct {
+	ID   string
+	sans certutil.AltNames
+	keys map[Profile]*rsa.PrivateKey
+	// CSRs are the pem encoded certificate requests, by profile
+	CSRs map[Profile][]byte
+}
+
+// NewRequest creates the keys of peer id and requests certificates for its IPs and host names
+func NewRequest(id string, sans certutil.AltNames) (*Request, error) {
+	req := &Request{
+		ID:   id,
+		sans: sans,
+		keys: map[Profile]*rsa.PrivateKey{},
+		CSRs: map[Profile][]byte{},
+	}
+	for _, profile := range Profiles {
+		key, err := certutil.NewPrivateKey()
+		if err != nil {
+			return nil, fmt.Errorf("error creating %s key: %v", profile, err)
+		}
+		csr, err := certutil.MakeCSR(key, &pkix.Name{CommonName: id}, sans.DNSNames, sans.IPs)
+		if err != nil {
+			return nil, fmt.Errorf("error creating %s certificate request: %v", profile, err)
+		}
+		req.keys[profile] = key
+		req.CSRs[profile] = csr
+	}
+	return req, nil
+}
+
+// Hosts returns the DNS names and IP addresses the certificates are requested for
+func (r *Request) Hosts() []string {
+	hosts := append([]string(nil), r.sans.DNSNames...)
+	for _, ip := range r.sans.IPs {
+		hosts = append(hosts, ip.String())
+	}
+	return hosts
+}
+
+// Sign issues the requested certificates with ca, the peer signs its own requests with the CA
+// of the backup store
+func (r *Request) Sign(ca *CA) (map[Profile][]byte, error) {
+	certs := map[Profile][]byte{}
+	for _, profile := range Profiles {
+		cert, err := ca.Sign(r.CSRs[profile], profile, r.ID, r.Hosts())
+		if err != nil {
+			return nil, err
+		}
+		certs[profile] = cert
+	}
+	return certs, nil
+}
+
+// check verifies that cert was issued for the key of profile
+func (r *Request) check(profile Profile, cert []byte) error {
+	certs, err := certutil.ParseCertsPEM(cert)
+	if err != nil {
+		return fmt.Errorf("error parsing %s certificate: %v", profile, err)
+	}
+	pub, ok := certs[0].PublicKey.(*rsa.PublicKey)
+	if !ok || pub.N.Cmp(r.keys[profile].N) != 0 || pub.E != r.keys[profile].E {
+		return fmt.Errorf("the %s certificate does not match its key", profile)
+	}
+	return nil
+}
+
+// verifyChain checks that the certificates were issued by the CA certificate caCert
+func verifyChain(caCert []byte, certs map[Profile][]byte) error {
+	roots, err := certutil.ParseCertsPEM(caCert)
+	if err != nil {
+		return fmt.Errorf("error parsing CA certificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	for _, root := range roots {
+		pool.AddCert(root)
+	}
+	for profile, cert := range certs {
+		parsed, err := certutil.ParseCertsPEM(cert)
+		if err != nil {
+			return fmt.Errorf("error parsing %s certificate: %v", profile, err)
+		}
+		opts := x509.VerifyOptions{Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}
+		if _, err := parsed[0].Verify(opts); err != nil {
+			return fmt.Errorf("the %s certificate was not issued by the CA: %v", profile, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,458 @@
+package pki
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	cs "github.com/etcd-manager/etcd-discovery/client/clientset/versioned/typed/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/backup"
+	"github.com/etcd-manager/etcd-discovery/pkg/constants"
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+var testSANs = certutil.AltNames{
+	DNSNames: []string{"localhost", "node-a"},
+	IPs:      []net.IP{net.ParseIP("127.0.0.1").To4(), net.ParseIP("10.0.0.1").To4()},
+}
+
+func readCert(t *testing.T, path string) *x509.Certificate {
+	certs, err := certutil.CertsFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certs[0]
+}
+
+func TestSign(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pki")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca, err := NewCA("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := NewRequest("a", testSANs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certs, err := req.Sign(ca)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := Files{Dir: dir}
+	if files.Issued() {
+		t.Fatal("expected no certificates before they are written")
+	}
+	if err := files.Write(req, ca.CertPEM(), certs); err != nil {
+		t.Fatal(err)
+	}
+	if !files.Issued() {
+		t.Fatal("expected the written certificates to be issued")
+	}
+	if _, err := os.Stat(filepath.Join(dir, constants.EtcdPeerKeyName)); err != nil {
+		t.Errorf("expected the peer key under its kubeadm name: %v", err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+	for profile, usage := range map[Profile]x509.ExtKeyUsage{
+		ProfilePeer:              x509.ExtKeyUsageClientAuth,
+		ProfileServer:            x509.ExtKeyUsageServerAuth,
+		ProfileHealthcheckClient: x509.ExtKeyUsageClientAuth,
+	} {
+		cert := readCert(t, files.Cert(profile))
+		if _, err := cert.Verify(x509.VerifyOptions{Roots: pool, KeyUsages: []x509.ExtKeyUsage{usage}}); err != nil {
+			t.Errorf("%s certificate: %v", profile, err)
+		}
+	}
+
+	peer := readCert(t, files.Cert(ProfilePeer))
+	if peer.Subject.CommonName != "a" || !reflect.DeepEqual(peer.Subject.Organization, []string{constants.PeerOrganization}) {
+		t.Errorf("unexpected subject of the peer certificate: %+v", peer.Subject)
+	}
+	if !reflect.DeepEqual(peer.DNSNames, testSANs.DNSNames) || len(peer.IPAddresses) != 2 || !peer.IPAddresses[1].Equal(testSANs.IPs[1]) {
+		t.Errorf("expected the SANs of the request, got %v, %v", peer.DNSNames, peer.IPAddresses)
+	}
+	client := readCert(t, files.Cert(ProfileHealthcheckClient))
+	if client.Subject.CommonName != constants.EtcdHealthcheckClientCertCommonName || len(client.DNSNames)+len(client.IPAddresses) != 0 {
+		t.Errorf("unexpected healthcheck client certificate: %+v, %v, %v", client.Subject, client.DNSNames, client.IPAddresses)
+	}
+
+	// certificates of other keys are refused
+	other, err := NewRequest("a", testSANs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := files.Write(other, ca.CertPEM(), certs); err == nil {
+		t.Error("expected certificates of other keys to be refused")
+	}
+}
+
+func TestSignRefusesForgedSANs(t *testing.T) {
+	ca, err := NewCA("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := NewRequest("a", testSANs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, hosts := range [][]string{
+		{"localhost", "node-a", "127.0.0.1"},
+		{"localhost", "127.0.0.1", "10.0.0.1"},
+		nil,
+	} {
+		if _, err := ca.Sign(req.CSRs[ProfilePeer], ProfilePeer, "a", hosts); err == nil {
+			t.Errorf("expected a request for %v to be refused for hosts %v", req.Hosts(), hosts)
+		}
+	}
+	if _, err := ca.Sign(req.CSRs[ProfilePeer], ProfilePeer, "a", []string{"NODE-A", "localhost", "::ffff:10.0.0.1", "127.0.0.1"}); err != nil {
+		t.Errorf("expected a request for the allowed hosts to be signed: %v", err)
+	}
+}
+
+func TestBootstrapCreatesCA(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pki")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := backup.NewFileStore(filepath.Join(dir, "backups"))
+	var cas []*x509.Certificate
+	for _, id := range []string{"a", "b"} {
+		b := NewBootstrapper(Files{Dir: filepath.Join(dir, id)}, id, "test", testSANs, "", nil, discovery.NewStaticSeedProvider(nil), store)
+		if err := b.Run(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		cas = append(cas, readCert(t, b.Files.CACert()))
+	}
+	if !cas[0].Equal(cas[1]) {
+		t.Error("expected both peers to trust the CA of the backup store")
+	}
+	if ca, err := LoadCA(store); err != nil || !ca.Cert.Equal(cas[0]) {
+		t.Errorf("expected the CA in the backup store, got %v", err)
+	}
+}
+
+// racingStore creates the CA of another peer once a peer found none in the store
+type racingStore struct {
+	backup.BackupStore
+	other *CA
+}
+
+func (s *racingStore) LoadCA() ([]byte, []byte, error) {
+	cert, key, err := s.BackupStore.LoadCA()
+	if err == backup.ErrNoCA && s.other != nil {
+		if err := s.BackupStore.CreateCA(s.other.BundlePEM(), s.other.KeyPEM()); err != nil {
+			return nil, nil, err
+		}
+		s.other = nil
+	}
+	return cert, key, err
+}
+
+func TestBootstrapAdoptsCreatedCA(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pki")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	other, err := NewCA("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &racingStore{BackupStore: backup.NewFileStore(filepath.Join(dir, "backups")), other: other}
+	b := NewBootstrapper(Files{Dir: filepath.Join(dir, "a")}, "a", "test", testSANs, "", nil, discovery.NewStaticSeedProvider(nil), store)
+	if err := b.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !readCert(t, b.Files.CACert()).Equal(other.Cert) {
+		t.Error("expected the peer to trust the CA created by the other peer")
+	}
+	if ca, err := LoadCA(store); err != nil || !ca.Cert.Equal(other.Cert) {
+		t.Errorf("expected the CA of the other peer in the backup store, got %v", err)
+	}
+}
+
+// fakeLeader signs certificate requests like the leader, for the hosts of testSANs
+type fakeLeader struct {
+	ca *CA
+	// id is the id the leader assigns
+	id string
+	// term is the term of the leader, the requests are refused with reason if set
+	term   int64
+	reason string
+}
+
+func (f *fakeLeader) Certificates() cs.CertificateInterface {
+	return f
+}
+
+func (f *fakeLeader) Create(c *api.Certificate) (*api.Certificate, error) {
+	if f.reason != "" {
+		c.Response = &api.CertificateResponse{Term: f.term, Reason: f.reason}
+		return c, nil
+	}
+	resp := &api.CertificateResponse{ID: f.id, CACert: string(f.ca.CertPEM()), Term: f.term}
+	hosts := (&Request{sans: testSANs}).Hosts()
+	for profile, csr := range map[Profile]*string{
+		ProfilePeer:              &c.Request.PeerCSR,
+		ProfileServer:            &c.Request.ServerCSR,
+		ProfileHealthcheckClient: &c.Request.HealthcheckClientCSR,
+	} {
+		cert, err := f.ca.Sign([]byte(*csr), profile, f.id, hosts)
+		if err != nil {
+			return nil, err
+		}
+		switch profile {
+		case ProfilePeer:
+			resp.PeerCert = string(cert)
+		case ProfileServer:
+			resp.ServerCert = string(cert)
+		case ProfileHealthcheckClient:
+			resp.HealthcheckClientCert = string(cert)
+		}
+	}
+	c.Response = resp
+	return c, nil
+}
+
+// unreachable fails like a discovery server that does not answer
+type unreachable struct{}
+
+func (u unreachable) Certificates() cs.CertificateInterface {
+	return u
+}
+
+func (unreachable) Create(*api.Certificate) (*api.Certificate, error) {
+	return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+}
+
+func TestBootstrapFromLeader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pki")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca, err := NewCA("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	leader := &fakeLeader{ca: ca, id: "c", term: 1}
+	var asked []string
+	b := NewBootstrapper(Files{Dir: dir}, "b", "test", testSANs, "secret", []string{CACertHash(ca.Cert)}, discovery.NewStaticSeedProvider([]string{"10.0.0.1:2381"}), backup.NewFileStore(filepath.Join(dir, "backups")))
+	b.clients = func(address, token string, caCertHashes []string) (cs.CertificatesGetter, error) {
+		asked = append(asked, fmt.Sprintf("%s %s %v", address, token, caCertHashes))
+		return leader, nil
+	}
+	if err := b.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{fmt.Sprintf("10.0.0.1:2381 secret [%s]", CACertHash(ca.Cert))}; !reflect.DeepEqual(asked, expected) {
+		t.Errorf("expected the seed to be asked with the token and the pinned CA, got %v", asked)
+	}
+	if !readCert(t, b.Files.CACert()).Equal(ca.Cert) {
+		t.Error("expected the CA of the leader")
+	}
+	if b.ID != "c" || readCert(t, b.Files.Cert(ProfilePeer)).Subject.CommonName != "c" {
+		t.Errorf("expected the id assigned by the leader, got %q", b.ID)
+	}
+	if _, err := LoadCA(b.Store); err != backup.ErrNoCA {
+		t.Errorf("expected no CA to be created in the backup store, got %v", err)
+	}
+}
+
+func TestBootstrapSignsOnlyWithoutLeader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pki")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca, err := NewCA("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refusing := &fakeLeader{term: 2, reason: "peer a is not the leader, ask \"b\""}
+	forming := &fakeLeader{reason: "peer a is not the leader, ask \"\""}
+	for i, test := range []struct {
+		name   string
+		server cs.CertificatesGetter
+		hasCA  bool
+		signed bool
+	}{
+		{"a refusing leader", refusing, true, false},
+		{"a refusing leader of a new cluster", refusing, false, false},
+		{"no answer", unreachable{}, true, false},
+		{"no answer to the first peer", unreachable{}, false, true},
+		{"no leader elected yet", forming, true, true},
+	} {
+		store := backup.NewFileStore(filepath.Join(dir, strconv.Itoa(i), "backups"))
+		if test.hasCA {
+			if err := store.CreateCA(ca.BundlePEM(), ca.KeyPEM()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		b := NewBootstrapper(Files{Dir: filepath.Join(dir, strconv.Itoa(i))}, "a", "test", testSANs, "secret", []string{CACertHash(ca.Cert)}, discovery.NewStaticSeedProvider([]string{"10.0.0.2:2381"}), store)
+		b.clients = func(address, token string, caCertHashes []string) (cs.CertificatesGetter, error) {
+			return test.server, nil
+		}
+		req, err := NewRequest("a", testSANs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = b.issue(context.Background(), req)
+		if signed := err == nil && b.Files.Issued(); signed != test.signed {
+			t.Errorf("%s: expected signed %v, got %v", test.name, test.signed, err)
+		}
+		if test.signed && test.hasCA && !readCert(t, b.Files.CACert()).Equal(ca.Cert) {
+			t.Errorf("%s: expected the CA of the backup store", test.name)
+		}
+	}
+}
+
+func TestVerifyPinnedChain(t *testing.T) {
+	ca, err := NewCA("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewCA("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := NewRequest("a", testSANs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der := func(ca *CA) []byte {
+		certPEM, err := ca.Sign(req.CSRs[ProfilePeer], ProfilePeer, "a", req.Hosts())
+		if err != nil {
+			t.Fatal(err)
+		}
+		certs, err := certutil.ParseCertsPEM(certPEM)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return certs[0].Raw
+	}
+	verify := verifyPinnedChain([]string{CACertHash(ca.Cert)})
+	for _, test := range []struct {
+		name  string
+		chain [][]byte
+		valid bool
+	}{
+		{"issued by the pinned CA", [][]byte{der(ca), ca.Cert.Raw}, true},
+		{"issued by the pinned CA during a rotation", [][]byte{der(ca), other.Cert.Raw, ca.Cert.Raw}, true},
+		{"without the CA", [][]byte{der(ca)}, false},
+		{"issued by another CA", [][]byte{der(other), other.Cert.Raw}, false},
+		{"issued by another CA sent with the pinned one", [][]byte{der(other), ca.Cert.Raw}, false},
+	} {
+		if err := verify(test.chain, nil); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %v, got %v", test.name, test.valid, err)
+		}
+	}
+}
+
+func TestRotateCA(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pki")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := backup.NewFileStore(filepath.Join(dir, "backups"))
+	old, err := NewCA("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := NewRequest("a", testSANs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certs, err := req.Sign(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := Files{Dir: filepath.Join(dir, "a")}
+	if err := files.Write(req, old.BundlePEM(), certs); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if reason, _, err := files.Renewal(old, 30*24*time.Hour, now); err != nil || reason != "" {
+		t.Fatalf("expected current certificates, got %q, %v", reason, err)
+	}
+	if reason, reissue, err := files.Renewal(old, 2*certValidity, now); err != nil || reason == "" || !reissue {
+		t.Errorf("expected certificates expiring within the renewal period to be reissued, got %q, %v", reason, err)
+	}
+
+	// every phase is saved in the store and renews the certificates of the peer
+	ca := old
+	for _, expected := range []struct {
+		phase   CAPhase
+		reissue bool
+	}{
+		{CAPhaseTrust, false},
+		{CAPhaseRetire, true},
+		{CAPhaseStable, false},
+	} {
+		next, err := ca.Rotate("test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := SaveCA(store, next); err != nil {
+			t.Fatal(err)
+		}
+		if ca, err = LoadCA(store); err != nil {
+			t.Fatal(err)
+		}
+		if ca.Phase() != expected.phase {
+			t.Fatalf("expected phase %s, got %s", expected.phase, ca.Phase())
+		}
+		reason, reissue, err := files.Renewal(ca, 30*24*time.Hour, now)
+		if err != nil || reason == "" || reissue != expected.reissue {
+			t.Fatalf("phase %s: expected a renewal with reissue=%v, got %q, %v, %v", ca.Phase(), expected.reissue, reason, reissue, err)
+		}
+		if err := files.Renew(ca, "a", reissue); err != nil {
+			t.Fatal(err)
+		}
+		if reason, _, err := files.Renewal(ca, 30*24*time.Hour, now); err != nil || reason != "" {
+			t.Fatalf("phase %s: expected current certificates after the renewal, got %q, %v", ca.Phase(), reason, err)
+		}
+		trusted, err := certutil.CertsFromFile(files.CACert())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(trusted) != 1+len(ca.Trusted) {
+			t.Errorf("phase %s: expected %d trusted CAs, got %d", ca.Phase(), 1+len(ca.Trusted), len(trusted))
+		}
+	}
+
+	if ca.Cert.Equal(old.Cert) {
+		t.Fatal("expected a new CA")
+	}
+	peer := readCert(t, files.Cert(ProfilePeer))
+	if err := peer.CheckSignatureFrom(ca.Cert); err != nil {
+		t.Errorf("expected the peer certificate to be issued by the new CA: %v", err)
+	}
+	if !reflect.DeepEqual(peer.DNSNames, testSANs.DNSNames) || len(peer.IPAddresses) != len(testSANs.IPs) {
+		t.Errorf("expected the SANs of the previous certificate, got %v, %v", peer.DNSNames, peer.IPAddresses)
+	}
+}
@@ -0,0 +1,51 @@
+package backup
+
+import (
+	"context"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// BackupTaker takes backups of the cluster and lists the backups in the backup store
+type BackupTaker interface {
+	HandleBackup(ctx context.Context, req *api.BackupRequest) (*api.BackupResponse, error)
+}
+
+type REST struct {
+	taker BackupTaker
+}
+
+var _ rest.Creater = &REST{}
+var _ rest.GroupVersionKindProvider = &REST{}
+
+func NewREST(taker BackupTaker) *REST {
+	return &REST{taker}
+}
+
+func (r *REST) New() runtime.Object {
+	return &api.Backup{}
+}
+
+func (r *REST) GroupVersionKind(containingGV schema.GroupVersion) schema.GroupVersionKind {
+	return api.SchemeGroupVersion.WithKind(api.ResourceKindBackup)
+}
+
+func (r *REST) Create(ctx apirequest.Context, obj runtime.Object, _ rest.ValidateObjectFunc, _ bool) (runtime.Object, error) {
+	req := obj.(*api.Backup)
+
+	if req.Request == nil {
+		return nil, apierrors.NewBadRequest("request is required")
+	}
+
+	resp, err := r.taker.HandleBackup(ctx, req.Request)
+	if err != nil {
+		return nil, apierrors.NewServiceUnavailable(err.Error())
+	}
+	req.Response = resp
+	return req, nil
+}
@@ -0,0 +1,51 @@
+package certificate
+
+import (
+	"context"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// Issuer signs the certificate requests of peers with the cluster CA
+type Issuer interface {
+	HandleCertificate(ctx context.Context, req *api.CertificateRequest) (*api.CertificateResponse, error)
+}
+
+type REST struct {
+	issuer Issuer
+}
+
+var _ rest.Creater = &REST{}
+var _ rest.GroupVersionKindProvider = &REST{}
+
+func NewREST(issuer Issuer) *REST {
+	return &REST{issuer}
+}
+
+func (r *REST) New() runtime.Object {
+	return &api.Certificate{}
+}
+
+func (r *REST) GroupVersionKind(containingGV schema.GroupVersion) schema.GroupVersionKind {
+	return api.SchemeGroupVersion.WithKind(api.ResourceKindCertificate)
+}
+
+func (r *REST) Create(ctx apirequest.Context, obj runtime.Object, _ rest.ValidateObjectFunc, _ bool) (runtime.Object, error) {
+	req := obj.(*api.Certificate)
+
+	if req.Request == nil || req.Request.PeerCSR == "" || req.Request.ServerCSR == "" || req.Request.HealthcheckClientCSR == "" {
+		return nil, apierrors.NewBadRequest("request.peerCSR, request.serverCSR and request.healthcheckClientCSR are required")
+	}
+
+	resp, err := r.issuer.HandleCertificate(ctx, req.Request)
+	if err != nil {
+		return nil, apierrors.NewServiceUnavailable(err.Error())
+	}
+	req.Response = resp
+	return req, nil
+}
@@ -0,0 +1,89 @@
+package etcdcluster
+
+import (
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// StatusReporter reports the status of the cluster
+type StatusReporter interface {
+	// ClusterStatus returns the status of the cluster, nil if it is not known yet
+	ClusterStatus() *api.EtcdCluster
+	// WatchClusterStatus returns a watch of the status, starting with the current one
+	WatchClusterStatus() watch.Interface
+}
+
+type REST struct {
+	reporter StatusReporter
+}
+
+var _ rest.Getter = &REST{}
+var _ rest.Lister = &REST{}
+var _ rest.Watcher = &REST{}
+var _ rest.GroupVersionKindProvider = &REST{}
+
+func NewREST(reporter StatusReporter) *REST {
+	return &REST{reporter}
+}
+
+func (r *REST) New() runtime.Object {
+	return &api.EtcdCluster{}
+}
+
+func (r *REST) NewList() runtime.Object {
+	return &api.EtcdClusterList{}
+}
+
+func (r *REST) GroupVersionKind(containingGV schema.GroupVersion) schema.GroupVersionKind {
+	return api.SchemeGroupVersion.WithKind(api.ResourceKindEtcdCluster)
+}
+
+func (r *REST) Get(ctx apirequest.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	status := r.reporter.ClusterStatus()
+	if status == nil || status.Name != name {
+		return nil, apierrors.NewNotFound(api.Resource(api.ResourcePluralEtcdCluster), name)
+	}
+	return status, nil
+}
+
+func (r *REST) List(ctx apirequest.Context, options *metainternalversion.ListOptions) (runtime.Object, error) {
+	list := &api.EtcdClusterList{}
+	status := r.reporter.ClusterStatus()
+	if status != nil {
+		list.ResourceVersion = status.ResourceVersion
+		if matches(status, options) {
+			list.Items = append(list.Items, *status)
+		}
+	}
+	return list, nil
+}
+
+func (r *REST) Watch(ctx apirequest.Context, options *metainternalversion.ListOptions) (watch.Interface, error) {
+	return watch.Filter(r.reporter.WatchClusterStatus(), func(in watch.Event) (watch.Event, bool) {
+		status, ok := in.Object.(*api.EtcdCluster)
+		return in, !ok || matches(status, options)
+	}), nil
+}
+
+// matches returns true if status is selected by the label and field selectors of options
+func matches(status *api.EtcdCluster, options *metainternalversion.ListOptions) bool {
+	if options == nil {
+		return true
+	}
+	if options.LabelSelector != nil && !options.LabelSelector.Matches(labels.Set(status.Labels)) {
+		return false
+	}
+	if options.FieldSelector != nil && !options.FieldSelector.Matches(fields.Set{"metadata.name": status.Name}) {
+		return false
+	}
+	return true
+}
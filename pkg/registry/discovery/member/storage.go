@@ -1,21 +1,32 @@
 package member
 
 import (
+	"context"
+	"fmt"
+	"net/url"
+
 	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
 )
 
+// Joiner adds new members to the etcd cluster
+type Joiner interface {
+	AddMember(ctx context.Context, peerURL string) (*api.MemberResponse, error)
+}
+
 type REST struct {
+	joiner Joiner
 }
 
 var _ rest.Creater = &REST{}
 var _ rest.GroupVersionKindProvider = &REST{}
 
-func NewREST() *REST {
-	return &REST{}
+func NewREST(joiner Joiner) *REST {
+	return &REST{joiner}
 }
 
 func (r *REST) New() runtime.Object {
@@ -28,5 +39,18 @@ func (r *REST) GroupVersionKind(containingGV schema.GroupVersion) schema.GroupVe
 
 func (r *REST) Create(ctx apirequest.Context, obj runtime.Object, _ rest.ValidateObjectFunc, _ bool) (runtime.Object, error) {
 	req := obj.(*api.Member)
+
+	if req.Request == nil || req.Request.PeerURL == "" {
+		return nil, apierrors.NewBadRequest("request.peerURL is required")
+	}
+	if pu, err := url.Parse(req.Request.PeerURL); err != nil || pu.Host == "" {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid peer url %q", req.Request.PeerURL))
+	}
+
+	resp, err := r.joiner.AddMember(ctx, req.Request.PeerURL)
+	if err != nil {
+		return nil, apierrors.NewServiceUnavailable(err.Error())
+	}
+	req.Response = resp
 	return req, nil
 }
@@ -0,0 +1,51 @@
+package migration
+
+import (
+	"context"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// Migrator moves the local member to the phase of a v2 to v3 migration run by the leader
+type Migrator interface {
+	HandleMigration(ctx context.Context, req *api.MigrationRequest) (*api.MigrationResponse, error)
+}
+
+type REST struct {
+	migrator Migrator
+}
+
+var _ rest.Creater = &REST{}
+var _ rest.GroupVersionKindProvider = &REST{}
+
+func NewREST(migrator Migrator) *REST {
+	return &REST{migrator}
+}
+
+func (r *REST) New() runtime.Object {
+	return &api.Migration{}
+}
+
+func (r *REST) GroupVersionKind(containingGV schema.GroupVersion) schema.GroupVersionKind {
+	return api.SchemeGroupVersion.WithKind(api.ResourceKindMigration)
+}
+
+func (r *REST) Create(ctx apirequest.Context, obj runtime.Object, _ rest.ValidateObjectFunc, _ bool) (runtime.Object, error) {
+	req := obj.(*api.Migration)
+
+	if req.Request == nil || req.Request.Leader == "" || req.Request.Phase == "" {
+		return nil, apierrors.NewBadRequest("request.leader and request.phase are required")
+	}
+
+	resp, err := r.migrator.HandleMigration(ctx, req.Request)
+	if err != nil {
+		return nil, apierrors.NewServiceUnavailable(err.Error())
+	}
+	req.Response = resp
+	return req, nil
+}
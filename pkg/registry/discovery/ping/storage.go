@@ -4,22 +4,29 @@ import (
 	"net"
 
 	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/authz"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
 )
 
+// Elector answers the leader election part of a ping
+type Elector interface {
+	HandlePing(req *api.PingRequest, resp *api.PingResponse)
+}
+
 type REST struct {
-	id   api.PeerID
-	host net.IP
+	id      api.PeerID
+	host    net.IP
+	elector Elector
 }
 
 var _ rest.Creater = &REST{}
 var _ rest.GroupVersionKindProvider = &REST{}
 
-func NewREST(id api.PeerID, host net.IP) *REST {
-	return &REST{id, host}
+func NewREST(id api.PeerID, host net.IP, elector Elector) *REST {
+	return &REST{id, host, elector}
 }
 
 func (r *REST) New() runtime.Object {
@@ -39,5 +46,14 @@ func (r *REST) Create(ctx apirequest.Context, obj runtime.Object, _ rest.Validat
 			Hosts: []string{r.host.String()},
 		},
 	}
+	if r.elector != nil && req.Request != nil {
+		election := req.Request
+		if u, _ := apirequest.UserFrom(ctx); !authz.IsPeer(u) {
+			// operators learn the leader, only peers take part in the election, the
+			// authorizer lets them ping as the peer of their certificate only
+			election = &api.PingRequest{}
+		}
+		r.elector.HandlePing(election, req.Response)
+	}
 	return req, nil
 }
@@ -0,0 +1,51 @@
+package plan
+
+import (
+	"context"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// Planner accepts the bootstrap plan of a new cluster from the leader
+type Planner interface {
+	AcceptPlan(ctx context.Context, plan *api.PlanRequest) (*api.PlanResponse, error)
+}
+
+type REST struct {
+	planner Planner
+}
+
+var _ rest.Creater = &REST{}
+var _ rest.GroupVersionKindProvider = &REST{}
+
+func NewREST(planner Planner) *REST {
+	return &REST{planner}
+}
+
+func (r *REST) New() runtime.Object {
+	return &api.Plan{}
+}
+
+func (r *REST) GroupVersionKind(containingGV schema.GroupVersion) schema.GroupVersionKind {
+	return api.SchemeGroupVersion.WithKind(api.ResourceKindPlan)
+}
+
+func (r *REST) Create(ctx apirequest.Context, obj runtime.Object, _ rest.ValidateObjectFunc, _ bool) (runtime.Object, error) {
+	req := obj.(*api.Plan)
+
+	if req.Request == nil || req.Request.Leader == "" || len(req.Request.InitialCluster) == 0 {
+		return nil, apierrors.NewBadRequest("request.leader and request.initialCluster are required")
+	}
+
+	resp, err := r.planner.AcceptPlan(ctx, req.Request)
+	if err != nil {
+		return nil, apierrors.NewServiceUnavailable(err.Error())
+	}
+	req.Response = resp
+	return req, nil
+}
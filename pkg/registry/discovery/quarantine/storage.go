@@ -0,0 +1,57 @@
+package quarantine
+
+import (
+	"context"
+	"fmt"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// Quarantiner changes and reports the quarantine mode of the cluster
+type Quarantiner interface {
+	HandleQuarantine(ctx context.Context, req *api.QuarantineRequest) (*api.QuarantineResponse, error)
+}
+
+type REST struct {
+	quarantiner Quarantiner
+}
+
+var _ rest.Creater = &REST{}
+var _ rest.GroupVersionKindProvider = &REST{}
+
+func NewREST(quarantiner Quarantiner) *REST {
+	return &REST{quarantiner}
+}
+
+func (r *REST) New() runtime.Object {
+	return &api.Quarantine{}
+}
+
+func (r *REST) GroupVersionKind(containingGV schema.GroupVersion) schema.GroupVersionKind {
+	return api.SchemeGroupVersion.WithKind(api.ResourceKindQuarantine)
+}
+
+func (r *REST) Create(ctx apirequest.Context, obj runtime.Object, _ rest.ValidateObjectFunc, _ bool) (runtime.Object, error) {
+	req := obj.(*api.Quarantine)
+
+	if req.Request == nil {
+		return nil, apierrors.NewBadRequest("request is required")
+	}
+	switch req.Request.Mode {
+	case "", api.QuarantineModeEnabled, api.QuarantineModeDisabled:
+	default:
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("request.mode must be %s or %s", api.QuarantineModeEnabled, api.QuarantineModeDisabled))
+	}
+
+	resp, err := r.quarantiner.HandleQuarantine(ctx, req.Request)
+	if err != nil {
+		return nil, apierrors.NewServiceUnavailable(err.Error())
+	}
+	req.Response = resp
+	return req, nil
+}
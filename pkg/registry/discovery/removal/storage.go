@@ -0,0 +1,51 @@
+package removal
+
+import (
+	"context"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// Remover removes members from the etcd cluster
+type Remover interface {
+	HandleRemoval(ctx context.Context, req *api.RemovalRequest) (*api.RemovalResponse, error)
+}
+
+type REST struct {
+	remover Remover
+}
+
+var _ rest.Creater = &REST{}
+var _ rest.GroupVersionKindProvider = &REST{}
+
+func NewREST(remover Remover) *REST {
+	return &REST{remover}
+}
+
+func (r *REST) New() runtime.Object {
+	return &api.Removal{}
+}
+
+func (r *REST) GroupVersionKind(containingGV schema.GroupVersion) schema.GroupVersionKind {
+	return api.SchemeGroupVersion.WithKind(api.ResourceKindRemoval)
+}
+
+func (r *REST) Create(ctx apirequest.Context, obj runtime.Object, _ rest.ValidateObjectFunc, _ bool) (runtime.Object, error) {
+	req := obj.(*api.Removal)
+
+	if req.Request == nil || req.Request.Member == "" {
+		return nil, apierrors.NewBadRequest("request.member is required")
+	}
+
+	resp, err := r.remover.HandleRemoval(ctx, req.Request)
+	if err != nil {
+		return nil, apierrors.NewServiceUnavailable(err.Error())
+	}
+	req.Response = resp
+	return req, nil
+}
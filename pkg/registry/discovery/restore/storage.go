@@ -0,0 +1,54 @@
+package restore
+
+import (
+	"context"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// Restorer restores the running cluster from a backup
+type Restorer interface {
+	HandleRestore(ctx context.Context, req *api.RestoreRequest) (*api.RestoreResponse, error)
+}
+
+type REST struct {
+	restorer Restorer
+}
+
+var _ rest.Creater = &REST{}
+var _ rest.GroupVersionKindProvider = &REST{}
+
+func NewREST(restorer Restorer) *REST {
+	return &REST{restorer}
+}
+
+func (r *REST) New() runtime.Object {
+	return &api.Restore{}
+}
+
+func (r *REST) GroupVersionKind(containingGV schema.GroupVersion) schema.GroupVersionKind {
+	return api.SchemeGroupVersion.WithKind(api.ResourceKindRestore)
+}
+
+func (r *REST) Create(ctx apirequest.Context, obj runtime.Object, _ rest.ValidateObjectFunc, _ bool) (runtime.Object, error) {
+	req := obj.(*api.Restore)
+
+	if req.Request == nil {
+		return nil, apierrors.NewBadRequest("request is required")
+	}
+	if req.Request.Leader != "" && req.Request.Backup == "" {
+		return nil, apierrors.NewBadRequest("request.backup is required")
+	}
+
+	resp, err := r.restorer.HandleRestore(ctx, req.Request)
+	if err != nil {
+		return nil, apierrors.NewServiceUnavailable(err.Error())
+	}
+	req.Response = resp
+	return req, nil
+}
@@ -0,0 +1,51 @@
+package rotation
+
+import (
+	"context"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// Rotator renews the certificates of the local peer for the leader
+type Rotator interface {
+	HandleRotation(ctx context.Context, req *api.RotationRequest) (*api.RotationResponse, error)
+}
+
+type REST struct {
+	rotator Rotator
+}
+
+var _ rest.Creater = &REST{}
+var _ rest.GroupVersionKindProvider = &REST{}
+
+func NewREST(rotator Rotator) *REST {
+	return &REST{rotator}
+}
+
+func (r *REST) New() runtime.Object {
+	return &api.Rotation{}
+}
+
+func (r *REST) GroupVersionKind(containingGV schema.GroupVersion) schema.GroupVersionKind {
+	return api.SchemeGroupVersion.WithKind(api.ResourceKindRotation)
+}
+
+func (r *REST) Create(ctx apirequest.Context, obj runtime.Object, _ rest.ValidateObjectFunc, _ bool) (runtime.Object, error) {
+	req := obj.(*api.Rotation)
+
+	if req.Request == nil || req.Request.Leader == "" {
+		return nil, apierrors.NewBadRequest("request.leader is required")
+	}
+
+	resp, err := r.rotator.HandleRotation(ctx, req.Request)
+	if err != nil {
+		return nil, apierrors.NewServiceUnavailable(err.Error())
+	}
+	req.Response = resp
+	return req, nil
+}
@@ -0,0 +1,54 @@
+package scale
+
+import (
+	"context"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// Scaler changes and reports the desired size of the cluster
+type Scaler interface {
+	HandleScale(ctx context.Context, req *api.ScaleRequest) (*api.ScaleResponse, error)
+}
+
+type REST struct {
+	scaler Scaler
+}
+
+var _ rest.Creater = &REST{}
+var _ rest.GroupVersionKindProvider = &REST{}
+
+func NewREST(scaler Scaler) *REST {
+	return &REST{scaler}
+}
+
+func (r *REST) New() runtime.Object {
+	return &api.Scale{}
+}
+
+func (r *REST) GroupVersionKind(containingGV schema.GroupVersion) schema.GroupVersionKind {
+	return api.SchemeGroupVersion.WithKind(api.ResourceKindScale)
+}
+
+func (r *REST) Create(ctx apirequest.Context, obj runtime.Object, _ rest.ValidateObjectFunc, _ bool) (runtime.Object, error) {
+	req := obj.(*api.Scale)
+
+	if req.Request == nil {
+		return nil, apierrors.NewBadRequest("request is required")
+	}
+	if size := req.Request.ClusterSize; size < 0 || (size > 0 && size%2 == 0) {
+		return nil, apierrors.NewBadRequest("request.clusterSize must be an odd number")
+	}
+
+	resp, err := r.scaler.HandleScale(ctx, req.Request)
+	if err != nil {
+		return nil, apierrors.NewServiceUnavailable(err.Error())
+	}
+	req.Response = resp
+	return req, nil
+}
@@ -0,0 +1,55 @@
+package upgrade
+
+import (
+	"context"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// Upgrader sets the etcd version of the cluster, and restarts the local member with the etcd
+// version of an upgrade run by the leader
+type Upgrader interface {
+	HandleUpgrade(ctx context.Context, req *api.UpgradeRequest) (*api.UpgradeResponse, error)
+}
+
+type REST struct {
+	upgrader Upgrader
+}
+
+var _ rest.Creater = &REST{}
+var _ rest.GroupVersionKindProvider = &REST{}
+
+func NewREST(upgrader Upgrader) *REST {
+	return &REST{upgrader}
+}
+
+func (r *REST) New() runtime.Object {
+	return &api.Upgrade{}
+}
+
+func (r *REST) GroupVersionKind(containingGV schema.GroupVersion) schema.GroupVersionKind {
+	return api.SchemeGroupVersion.WithKind(api.ResourceKindUpgrade)
+}
+
+func (r *REST) Create(ctx apirequest.Context, obj runtime.Object, _ rest.ValidateObjectFunc, _ bool) (runtime.Object, error) {
+	req := obj.(*api.Upgrade)
+
+	if req.Request == nil {
+		return nil, apierrors.NewBadRequest("request is required")
+	}
+	if req.Request.Leader == "" && req.Request.Version != "" {
+		return nil, apierrors.NewBadRequest("request.version is set by the leader, operators set request.clusterVersion")
+	}
+
+	resp, err := r.upgrader.HandleUpgrade(ctx, req.Request)
+	if err != nil {
+		return nil, apierrors.NewServiceUnavailable(err.Error())
+	}
+	req.Response = resp
+	return req, nil
+}
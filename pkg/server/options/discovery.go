@@ -0,0 +1,43 @@
+package options
+
+import (
+	"github.com/etcd-manager/etcd-discovery/pkg/discovery"
+	"github.com/etcd-manager/etcd-discovery/pkg/manager"
+	"github.com/spf13/pflag"
+)
+
+// DiscoveryOptions configures how the other discovery servers of the cluster are found
+type DiscoveryOptions struct {
+	Seeds       []string
+	SeedDNSName string
+	SeedFile    string
+}
+
+func NewDiscoveryOptions() *DiscoveryOptions {
+	return &DiscoveryOptions{}
+}
+
+func (s *DiscoveryOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringSliceVar(&s.Seeds, "seeds", s.Seeds, "Addresses (host[:port]) of discovery servers of the cluster")
+	fs.StringVar(&s.SeedDNSName, "seed-dns-name", s.SeedDNSName, "DNS name whose SRV (_etcd-discovery._tcp) or A records list the discovery servers of the cluster")
+	fs.StringVar(&s.SeedFile, "seed-file", s.SeedFile, "File listing one discovery server address per line, read again when it changes")
+}
+
+func (s *DiscoveryOptions) Validate() []error {
+	return nil
+}
+
+func (s *DiscoveryOptions) ApplyTo(cfg *manager.EtcdConfig) error {
+	var providers []discovery.SeedProvider
+	if len(s.Seeds) > 0 {
+		providers = append(providers, discovery.NewStaticSeedProvider(s.Seeds))
+	}
+	if s.SeedDNSName != "" {
+		providers = append(providers, discovery.NewDNSSeedProvider(s.SeedDNSName, nil))
+	}
+	if s.SeedFile != "" {
+		providers = append(providers, discovery.NewFileSeedProvider(s.SeedFile))
+	}
+	cfg.Seeds = discovery.NewMultiSeedProvider(providers...)
+	return nil
+}
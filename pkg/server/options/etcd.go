@@ -3,8 +3,11 @@ package options
 import (
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/etcd-manager/etcd-discovery/pkg/backup"
 	"github.com/etcd-manager/etcd-discovery/pkg/config"
+	"github.com/etcd-manager/etcd-discovery/pkg/constants"
 	"github.com/etcd-manager/etcd-discovery/pkg/etcd"
 	"github.com/etcd-manager/etcd-discovery/pkg/manager"
 	"github.com/pkg/errors"
@@ -14,16 +17,47 @@ import (
 type EtcdOptions struct {
 	ClusterName     string
 	ClusterSize     int
+	EtcdVersion     string
 	BackupStorePath string
 	DataDir         string
 
+	BackupInterval   time.Duration
+	BackupKeepLast   int
+	BackupKeepDaily  int
+	BackupKeepWeekly int
+	RestoreBackup    string
+
+	MemberGracePeriod time.Duration
+
+	ProcessType     etcd.ProcessType
+	ManifestDir     string
+	UnitDir         string
+	LogDir          string
+	LogMaxSizeMB    int
+	LogMaxFiles     int
+	EtcdStopTimeout time.Duration
+	StartTimeout    time.Duration
+
 	InitialClusterState config.ClusterState
 	InitialCluster      map[string]string
 }
 
 func NewEtcdOptions() *EtcdOptions {
 	opts := &EtcdOptions{
+		EtcdVersion:         constants.DefaultEtcdVersion,
 		DataDir:             "etcd.local.config/data",
+		BackupInterval:      30 * time.Minute,
+		BackupKeepLast:      48,
+		BackupKeepDaily:     7,
+		BackupKeepWeekly:    4,
+		MemberGracePeriod:   15 * time.Minute,
+		ProcessType:         etcd.ProcessTypeDirect,
+		ManifestDir:         "/etc/kubernetes/manifests",
+		UnitDir:             "/etc/systemd/system",
+		LogMaxSizeMB:        100,
+		LogMaxFiles:         5,
+		EtcdStopTimeout:     30 * time.Second,
+		StartTimeout:        5 * time.Minute,
 		InitialClusterState: config.ClusterStateNew,
 	}
 	return opts
@@ -32,9 +66,24 @@ func NewEtcdOptions() *EtcdOptions {
 func (s *EtcdOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&s.ClusterName, "etcd-cluster-name", s.ClusterName, "Name of cluster")
 	fs.IntVar(&s.ClusterSize, "etcd-cluster-size", s.ClusterSize, "Size of cluster size")
+	fs.StringVar(&s.EtcdVersion, "etcd-version", s.EtcdVersion, "Version of etcd to run until one is set with ctl upgrade, which every peer keeps. The leader upgrades running members one at a time, one minor version at a time, and migrates etcd 2.3 clusters to 3.0")
 
-	fs.StringVar(&s.BackupStorePath, "etcd-backup-store", s.BackupStorePath, "Backup store location")
+	fs.StringVar(&s.BackupStorePath, "etcd-backup-store", s.BackupStorePath, "Backup store location, a directory or file://, s3://, gs://, azure:// or swift:// url")
+	fs.DurationVar(&s.BackupInterval, "etcd-backup-interval", s.BackupInterval, "Interval between backups taken by the leader, 0 disables backups")
+	fs.IntVar(&s.BackupKeepLast, "etcd-backup-keep-last", s.BackupKeepLast, "Number of most recent backups to keep")
+	fs.IntVar(&s.BackupKeepDaily, "etcd-backup-keep-daily", s.BackupKeepDaily, "Number of days to keep a daily backup for")
+	fs.IntVar(&s.BackupKeepWeekly, "etcd-backup-keep-weekly", s.BackupKeepWeekly, "Number of weeks to keep a weekly backup for")
+	fs.StringVar(&s.RestoreBackup, "etcd-restore-backup", s.RestoreBackup, "Restore the cluster from a backup, latest or the name of a backup. A member is restored once, until the restored file is removed from the data dir")
+	fs.DurationVar(&s.MemberGracePeriod, "etcd-member-grace-period", s.MemberGracePeriod, "Time the discovery server of a member may be unreachable before the leader replaces the member, 0 disables replacement")
 	fs.StringVar(&s.DataDir, "etcd-data-dir", s.DataDir, "Directory for storing etcd data")
+	fs.Var(&s.ProcessType, "etcd-process-type", "How etcd is run, one of direct, staticpod or systemd")
+	fs.StringVar(&s.ManifestDir, "static-pod-manifest-dir", s.ManifestDir, "Directory watched by kubelet for static pod manifests")
+	fs.StringVar(&s.UnitDir, "systemd-unit-dir", s.UnitDir, "Directory of the systemd unit of etcd, when run by systemd")
+	fs.StringVar(&s.LogDir, "etcd-log-dir", s.LogDir, "Directory of the log files of etcd run directly, empty writes its output to ours with the member name as prefix")
+	fs.IntVar(&s.LogMaxSizeMB, "etcd-log-max-size", s.LogMaxSizeMB, "Size in megabytes at which etcd log files are rotated, 0 disables rotation")
+	fs.IntVar(&s.LogMaxFiles, "etcd-log-max-files", s.LogMaxFiles, "Number of etcd log files to keep, including the current one")
+	fs.DurationVar(&s.EtcdStopTimeout, "etcd-stop-timeout", s.EtcdStopTimeout, "Time etcd run directly has to exit after SIGTERM before it is killed")
+	fs.DurationVar(&s.StartTimeout, "etcd-start-timeout", s.StartTimeout, "Time etcd run as a static pod has to become ready after its manifest is written")
 
 	fs.StringToStringVar(&s.InitialCluster, "initial-cluster", s.InitialCluster, "Initial cluster configuration")
 	fs.Var(&s.InitialClusterState, "initial-cluster-state", "Initial cluster state")
@@ -50,8 +99,39 @@ func (s *EtcdOptions) Validate() []error {
 	} else if s.ClusterSize%2 == 0 {
 		errors = append(errors, fmt.Errorf("cluster-size must be an odd number"))
 	}
+	if s.EtcdVersion == "" {
+		errors = append(errors, fmt.Errorf("etcd-version is required"))
+	}
 	if s.BackupStorePath == "" {
 		errors = append(errors, fmt.Errorf("backup-store is required"))
+	} else if _, err := backup.NewStore(s.BackupStorePath); err != nil {
+		errors = append(errors, err)
+	}
+	if s.BackupInterval < 0 {
+		errors = append(errors, fmt.Errorf("backup-interval must not be negative"))
+	}
+	if s.RestoreBackup != "" && s.RestoreBackup != backup.Latest {
+		if _, err := backup.ParseName(s.RestoreBackup); err != nil {
+			errors = append(errors, fmt.Errorf("restore-backup must be latest or the name of a backup"))
+		}
+	}
+	if s.MemberGracePeriod < 0 {
+		errors = append(errors, fmt.Errorf("member-grace-period must not be negative"))
+	}
+	if s.BackupKeepLast < 0 || s.BackupKeepDaily < 0 || s.BackupKeepWeekly < 0 {
+		errors = append(errors, fmt.Errorf("backup retention must not be negative"))
+	}
+	if s.LogMaxSizeMB < 0 {
+		errors = append(errors, fmt.Errorf("etcd-log-max-size must not be negative"))
+	}
+	if s.LogMaxFiles < 1 {
+		errors = append(errors, fmt.Errorf("etcd-log-max-files must be at least 1"))
+	}
+	if s.EtcdStopTimeout <= 0 {
+		errors = append(errors, fmt.Errorf("etcd-stop-timeout must be positive"))
+	}
+	if s.StartTimeout <= 0 {
+		errors = append(errors, fmt.Errorf("etcd-start-timeout must be positive"))
 	}
 	return errors
 }
@@ -70,8 +150,27 @@ func (s *EtcdOptions) ApplyTo(cfg *manager.EtcdConfig) error {
 	cfg.ID = peerID
 	cfg.ClusterName = s.ClusterName
 	cfg.ClusterSize = s.ClusterSize
+	cfg.EtcdVersion = config.EtcdVersion(s.EtcdVersion)
 	cfg.BackupStorePath = s.BackupStorePath
+	cfg.BackupInterval = s.BackupInterval
+	cfg.BackupRetention = backup.RetentionPolicy{
+		KeepLast:   s.BackupKeepLast,
+		KeepDaily:  s.BackupKeepDaily,
+		KeepWeekly: s.BackupKeepWeekly,
+	}
+	cfg.RestoreBackup = s.RestoreBackup
+	cfg.MemberGracePeriod = s.MemberGracePeriod
 	cfg.DataDir = s.DataDir
+	cfg.ProcessType = s.ProcessType
+	cfg.Process = etcd.ProcessOptions{
+		ManifestDir:  s.ManifestDir,
+		UnitDir:      s.UnitDir,
+		LogDir:       s.LogDir,
+		LogMaxSize:   int64(s.LogMaxSizeMB) * 1024 * 1024,
+		LogMaxFiles:  s.LogMaxFiles,
+		StopTimeout:  s.EtcdStopTimeout,
+		StartTimeout: s.StartTimeout,
+	}
 	cfg.InitialClusterState = s.InitialClusterState
 	cfg.InitialCluster = map[string]string{}
 	for k, v := range s.InitialCluster {
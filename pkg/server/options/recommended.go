@@ -1,6 +1,8 @@
 package options
 
 import (
+	"fmt"
+
 	"github.com/etcd-manager/etcd-discovery/pkg/server"
 	"github.com/spf13/pflag"
 	genericoptions "k8s.io/apiserver/pkg/server/options"
@@ -11,6 +13,7 @@ import (
 // Each of them can be nil to leave the feature unconfigured on ApplyTo.
 type RecommendedOptions struct {
 	Etcd          *EtcdOptions
+	Discovery     *DiscoveryOptions
 	SecureServing *SecureServingOptions
 	Audit         *genericoptions.AuditOptions
 	Features      *genericoptions.FeatureOptions
@@ -19,6 +22,7 @@ type RecommendedOptions struct {
 func NewRecommendedOptions() *RecommendedOptions {
 	return &RecommendedOptions{
 		Etcd:          NewEtcdOptions(),
+		Discovery:     NewDiscoveryOptions(),
 		SecureServing: NewSecureServingOptions(),
 		Audit:         genericoptions.NewAuditOptions(),
 		Features:      genericoptions.NewFeatureOptions(),
@@ -27,6 +31,7 @@ func NewRecommendedOptions() *RecommendedOptions {
 
 func (o *RecommendedOptions) AddFlags(fs *pflag.FlagSet) {
 	o.Etcd.AddFlags(fs)
+	o.Discovery.AddFlags(fs)
 	o.SecureServing.AddFlags(fs)
 	o.Audit.AddFlags(fs)
 	o.Features.AddFlags(fs)
@@ -36,11 +41,18 @@ func (o *RecommendedOptions) ApplyTo(config *server.Config) error {
 	if err := o.Etcd.ApplyTo(config.EtcdConfig); err != nil {
 		return err
 	}
+	if err := o.Discovery.ApplyTo(config.EtcdConfig); err != nil {
+		return err
+	}
 	var err error
 	config.EtcdConfig.AdvertiseAddress, err = o.SecureServing.DefaultExternalAddress()
 	if err != nil {
 		return err
 	}
+	if err := o.SecureServing.MaybeDefaultWithClusterCerts(config.EtcdConfig); err != nil {
+		return fmt.Errorf("error issuing certificates: %v", err)
+	}
+	o.SecureServing.ApplyToEtcdConfig(config.EtcdConfig)
 	if err := o.SecureServing.ApplyTo(&config.GenericConfig.Config); err != nil {
 		return err
 	}
@@ -55,6 +67,8 @@ func (o *RecommendedOptions) ApplyTo(config *server.Config) error {
 
 func (o *RecommendedOptions) Validate() []error {
 	var errors []error
+	errors = append(errors, o.Etcd.Validate()...)
+	errors = append(errors, o.Discovery.Validate()...)
 	errors = append(errors, o.SecureServing.Validate()...)
 	errors = append(errors, o.Audit.Validate()...)
 	errors = append(errors, o.Features.Validate()...)
@@ -1,29 +1,39 @@
 package options
 
 import (
+	"context"
+	"crypto/subtle"
 	"crypto/tls"
 	"encoding/pem"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"strconv"
-
-	"github.com/appscode/kutil/tools/certstore"
+	"time"
+
+	api "github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/authz"
+	"github.com/etcd-manager/etcd-discovery/pkg/backup"
+	"github.com/etcd-manager/etcd-discovery/pkg/constants"
+	"github.com/etcd-manager/etcd-discovery/pkg/etcd"
+	"github.com/etcd-manager/etcd-discovery/pkg/manager"
+	"github.com/etcd-manager/etcd-discovery/pkg/pki"
 	"github.com/golang/glog"
 	"github.com/pborman/uuid"
-	"github.com/pkg/errors"
-	"github.com/spf13/afero"
 	"github.com/spf13/pflag"
 	utilnet "k8s.io/apimachinery/pkg/util/net"
-	"k8s.io/apiserver/pkg/authentication/authenticatorfactory"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/group"
+	"k8s.io/apiserver/pkg/authentication/request/anonymous"
+	"k8s.io/apiserver/pkg/authentication/request/bearertoken"
+	"k8s.io/apiserver/pkg/authentication/request/union"
+	x509request "k8s.io/apiserver/pkg/authentication/request/x509"
+	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/apiserver/pkg/server"
 	genericoptions "k8s.io/apiserver/pkg/server/options"
-	"k8s.io/client-go/util/cert"
 	certutil "k8s.io/client-go/util/cert"
 )
 
-const organization = "system:etcd"
-
 type SecureServingOptions struct {
 	BindAddress net.IP
 	BindPort    int
@@ -48,6 +58,21 @@ type SecureServingOptions struct {
 
 	// ClientCert is the TLS client cert info for connecting to local etcd server
 	DiscoveryClientCert GeneratableKeyCert
+
+	// BootstrapToken authenticates peers that request their certificates from the leader
+	BootstrapToken string
+	// DiscoveryCACertHashes pin the CAs that issue the certificates of the discovery servers
+	// new peers ask for their certificates
+	DiscoveryCACertHashes []string
+	// BootstrapTimeout is how long a peer waits for its certificates to be issued
+	BootstrapTimeout time.Duration
+	// CertRenewBefore is how long before their expiry the certificates issued by the cluster
+	// CA are renewed, CARenewBefore how long before its expiry the cluster CA is rotated
+	CertRenewBefore time.Duration
+	CARenewBefore   time.Duration
+
+	// clusterCerts is true if the certificates were issued by the cluster CA
+	clusterCerts bool
 }
 
 type CertKey struct {
@@ -85,6 +110,9 @@ func NewSecureServingOptions() *SecureServingOptions {
 		DiscoveryClientCert: GeneratableKeyCert{
 			PairName: "discovery-client",
 		},
+		BootstrapTimeout: 5 * time.Minute,
+		CertRenewBefore:  30 * 24 * time.Hour,
+		CARenewBefore:    2 * 365 * 24 * time.Hour,
 	}
 }
 
@@ -102,6 +130,20 @@ func (s *SecureServingOptions) Validate() []error {
 	if s.BindPort < 0 || s.BindPort > 65535 {
 		errors = append(errors, fmt.Errorf("--secure-port %v must be between 0 and 65535, inclusive. 0 for turning off secure port", s.BindPort))
 	}
+	for _, hash := range s.DiscoveryCACertHashes {
+		if err := pki.ValidateCACertHash(hash); err != nil {
+			errors = append(errors, fmt.Errorf("--discovery-ca-cert-hash: %v", err))
+		}
+	}
+	if s.BootstrapTimeout <= 0 {
+		errors = append(errors, fmt.Errorf("--bootstrap-timeout must be positive"))
+	}
+	if s.CertRenewBefore < 0 || s.CARenewBefore < 0 {
+		errors = append(errors, fmt.Errorf("--cert-renew-before and --ca-renew-before must not be negative"))
+	}
+	if s.CARenewBefore > 0 && s.CARenewBefore <= s.CertRenewBefore {
+		errors = append(errors, fmt.Errorf("--ca-renew-before must be longer than --cert-renew-before"))
+	}
 
 	return errors
 }
@@ -128,8 +170,8 @@ func (s *SecureServingOptions) AddFlags(fs *pflag.FlagSet) {
 		"File containing the default x509 Certificate used for SSL/TLS connections between peers. "+
 		"This will be used both for listening on the peer address as well as sending requests to "+
 		"other peers. If HTTPS serving is enabled, and --peer-cert-file and --peer-private-key-file "+
-		"are not provided, a self-signed certificate and key are generated for the public address and "+
-		"saved to the directory specified by --cert-dir.")
+		"are not provided, the certificates of the peer are issued by the cluster CA and saved to the "+
+		"directory specified by --cert-dir.")
 
 	fs.StringVar(&s.PeerCert.CertKey.KeyFile, "peer-private-key-file", s.PeerCert.CertKey.KeyFile,
 		"File containing the default x509 private key matching --peer-cert-file.")
@@ -145,8 +187,8 @@ func (s *SecureServingOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&s.ServerCert.CertKey.CertFile, "cert-file", s.ServerCert.CertKey.CertFile, ""+
 		"File containing the default x509 Certificate used for SSL/TLS connections to etcd. When this "+
 		"option is set, advertise-client-urls can use the HTTPS schema. If HTTPS serving is enabled, "+
-		"and --cert-file and --private-key-file are not provided, a self-signed certificate and key are "+
-		"generated for the public address and saved to the directory specified by --cert-dir.")
+		"and --peer-cert-file and --peer-private-key-file are not provided, the certificate is issued by "+
+		"the cluster CA and saved to the directory specified by --cert-dir.")
 
 	fs.StringVar(&s.ServerCert.CertKey.KeyFile, "private-key-file", s.ServerCert.CertKey.KeyFile,
 		"File containing the default x509 private key matching --cert-file.")
@@ -160,6 +202,37 @@ func (s *SecureServingOptions) AddFlags(fs *pflag.FlagSet) {
 		"by the --trusted-ca-file, requests that don't supply a valid client certificate will fail. "+
 		"If authentication is enabled, the certificate provides credentials for the user name given by "+
 		"the Common Name field.")
+
+	fs.StringVar(&s.DiscoveryClientCert.CertKey.CertFile, "discovery-client-cert-file", s.DiscoveryClientCert.CertKey.CertFile, ""+
+		"File containing the x509 client certificate with which the manager connects to the local etcd "+
+		"member, required if it runs with --client-cert-auth. If HTTPS serving is enabled, and "+
+		"--discovery-client-cert-file and --discovery-client-key-file are not provided, the healthcheck "+
+		"client certificate issued by the cluster CA is used.")
+
+	fs.StringVar(&s.DiscoveryClientCert.CertKey.KeyFile, "discovery-client-key-file", s.DiscoveryClientCert.CertKey.KeyFile,
+		"File containing the x509 private key matching --discovery-client-cert-file.")
+
+	fs.StringVar(&s.BootstrapToken, "bootstrap-token", s.BootstrapToken, ""+
+		"Shared secret of the cluster with which new peers ask the leader for their certificates. "+
+		"Only the first peers of a cluster, which start before a leader is elected, sign their "+
+		"certificates with the cluster CA kept in the backup store. Without it, every peer does.")
+
+	fs.StringSliceVar(&s.DiscoveryCACertHashes, "discovery-ca-cert-hash", s.DiscoveryCACertHashes, ""+
+		"Hash of the public key of the cluster CA (sha256:<hex>), new peers only send the bootstrap "+
+		"token to discovery servers whose certificate is issued by a CA with one of the hashes. "+
+		"Defaults to the hashes of the cluster CA kept in the backup store.")
+
+	fs.DurationVar(&s.BootstrapTimeout, "bootstrap-timeout", s.BootstrapTimeout, ""+
+		"Time to wait for the certificates of the peer to be issued by the cluster CA.")
+
+	fs.DurationVar(&s.CertRenewBefore, "cert-renew-before", s.CertRenewBefore, ""+
+		"How long before their expiry the certificates issued by the cluster CA are renewed. The "+
+		"leader renews the certificates of one member at a time, which then restarts with them. "+
+		"0 disables renewals.")
+
+	fs.DurationVar(&s.CARenewBefore, "ca-renew-before", s.CARenewBefore, ""+
+		"How long before its expiry the cluster CA is rotated. Every peer trusts the new CA before "+
+		"its certificates are issued by it. 0 disables the rotation of the CA.")
 }
 
 // ApplyTo fills up serving information in the server configuration.
@@ -215,6 +288,41 @@ func (s *SecureServingOptions) ApplyTo(c *server.Config) error {
 	return nil
 }
 
+// ApplyToEtcdConfig passes the peer and server certificates on to the etcd manager.
+func (s *SecureServingOptions) ApplyToEtcdConfig(cfg *manager.EtcdConfig) {
+	if s == nil {
+		return
+	}
+	cfg.CertificatesDir = s.CertDirectory
+	cfg.DiscoveryPort = s.BindPort
+	if s.Listener != nil {
+		if addr, ok := s.Listener.Addr().(*net.TCPAddr); ok {
+			cfg.DiscoveryPort = addr.Port
+		}
+	}
+	cfg.PeerTLS = manager.TLSFiles{
+		CACertFile:     s.PeerCert.CACertFile,
+		CertFile:       s.PeerCert.CertKey.CertFile,
+		KeyFile:        s.PeerCert.CertKey.KeyFile,
+		ClientCertAuth: s.PeerCert.ClientCertAuth,
+	}
+	cfg.ServerTLS = manager.TLSFiles{
+		CACertFile:     s.ServerCert.CACertFile,
+		CertFile:       s.ServerCert.CertKey.CertFile,
+		KeyFile:        s.ServerCert.CertKey.KeyFile,
+		ClientCertAuth: s.ServerCert.ClientCertAuth,
+	}
+	cfg.ClientTLS = manager.TLSFiles{
+		CACertFile: s.DiscoveryClientCert.CACertFile,
+		CertFile:   s.DiscoveryClientCert.CertKey.CertFile,
+		KeyFile:    s.DiscoveryClientCert.CertKey.KeyFile,
+	}
+	if s.clusterCerts {
+		cfg.CertRenewBefore = s.CertRenewBefore
+		cfg.CARenewBefore = s.CARenewBefore
+	}
+}
+
 func (s *SecureServingOptions) applyServingInfoTo(c *server.Config) error {
 	if len(s.PeerCert.CACertFile) == 0 {
 		return fmt.Errorf("cluster doesn't provide --peer-ca-file")
@@ -247,6 +355,14 @@ func (s *SecureServingOptions) applyServingInfoTo(c *server.Config) error {
 	secureServingInfo.CACert = &tls.Certificate{
 		Certificate: [][]byte{block.Bytes},
 	}
+	if secureServingInfo.Cert != nil {
+		// new peers trust the server by the hash of the CA sent along with its certificate
+		for ; block != nil; block, pemData = pem.Decode(pemData) {
+			if block.Type == "CERTIFICATE" {
+				secureServingInfo.Cert.Certificate = append(secureServingInfo.Cert.Certificate, block.Bytes)
+			}
+		}
+	}
 	secureServingInfo.SNICerts = map[string]*tls.Certificate{}
 
 	c.SecureServingInfo = secureServingInfo
@@ -258,25 +374,25 @@ func (s *SecureServingOptions) applyServingInfoTo(c *server.Config) error {
 		return fmt.Errorf("unable to load client CA file: %v", err)
 	}
 
-	cfg, err := s.ToAuthenticationConfig()
+	auth, err := s.newAuthenticator()
 	if err != nil {
 		return err
 	}
-	authenticator, securityDefinitions, err := cfg.New()
-	if err != nil {
-		return err
+	if s.BootstrapToken != "" {
+		// new peers have no certificate yet, they request one with the token
+		auth = union.New(auth, bearertoken.New(bootstrapTokenAuthenticator(s.BootstrapToken)))
 	}
 
-	c.Authenticator = authenticator
-	if c.OpenAPIConfig != nil {
-		c.OpenAPIConfig.SecurityDefinitions = securityDefinitions
-	}
+	c.Authenticator = auth
 	c.SupportsBasicAuth = false
 
 	return nil
 }
 
-func (s *SecureServingOptions) MaybeDefaultWithSelfSignedCerts(publicAddress string, alternateDNS []string, alternateIPs []net.IP) error {
+// MaybeDefaultWithClusterCerts issues the peer, server and healthcheck client certificates
+// of the peer from the cluster CA, unless --peer-cert-file and --peer-private-key-file are
+// provided. It blocks until they are issued, cfg must hold the configuration of the cluster.
+func (s *SecureServingOptions) MaybeDefaultWithClusterCerts(cfg *manager.EtcdConfig) error {
 	if s == nil {
 		return nil
 	}
@@ -285,102 +401,78 @@ func (s *SecureServingOptions) MaybeDefaultWithSelfSignedCerts(publicAddress str
 		return nil
 	}
 
-	// add either the bind address or localhost to the valid alternates
-	bindIP := s.BindAddress.String()
-	if bindIP == "0.0.0.0" {
-		alternateDNS = append(alternateDNS, "localhost")
-	} else {
-		alternateIPs = append(alternateIPs, s.BindAddress)
-	}
-	sans := cert.AltNames{
-		IPs:      alternateIPs,
-		DNSNames: alternateDNS,
-	}
-
-	// peer certs
-	err := s.generatePeerCerts(publicAddress, sans)
+	sans, err := pki.HostAltNames(cfg.AdvertiseAddress, s.BindAddress)
 	if err != nil {
-		return fmt.Errorf("unable to generate self signed peer cert: %v", err)
-	} else {
-		glog.Infof("Generated self-signed peer cert (%s, %s)", keyCert.CertFile, keyCert.KeyFile)
-	}
-
-	// server certs
-	err = s.generateDatabaseCerts(publicAddress, sans)
-	if err != nil {
-		return fmt.Errorf("unable to generate self signed server cert: %v", err)
-	} else {
-		glog.Infof("Generated self-signed server cert (%s, %s)", keyCert.CertFile, keyCert.KeyFile)
-	}
-
-	return nil
-}
-
-func (s *SecureServingOptions) generatePeerCerts(host string, sans cert.AltNames) error {
-	store, err := certstore.NewCertStore(afero.NewOsFs(), s.CertDirectory, organization)
-	if err != nil {
-		return errors.Wrap(err, "failed to create certificate store.")
-	}
-	err = store.InitCA(s.PeerCert.PairName)
-	if err != nil {
-		return errors.Wrap(err, "failed to init ca.")
+		return err
 	}
-	crt, key, err := store.NewPeerCertPair(host, sans)
+	store, err := backup.NewStore(cfg.BackupStorePath)
 	if err != nil {
 		return err
 	}
-	err = store.WriteBytes(host, crt, key)
-	if err != nil {
+	files := pki.Files{Dir: s.CertDirectory}
+	ctx, cancel := context.WithTimeout(context.Background(), s.BootstrapTimeout)
+	defer cancel()
+	b := pki.NewBootstrapper(files, string(cfg.ID), cfg.ClusterName, sans, s.BootstrapToken, s.DiscoveryCACertHashes, cfg.AllSeeds(), store)
+	if err := b.Run(ctx); err != nil {
 		return err
 	}
-
-	s.PeerCert.CACertFile = store.CertFile(store.CAName())
-	s.PeerCert.CertKey.CertFile = store.CertFile(host)
-	s.PeerCert.CertKey.KeyFile = store.KeyFile(host)
-	return nil
-}
-
-func (s *SecureServingOptions) generateDatabaseCerts(host string, sans cert.AltNames) error {
-	store, err := certstore.NewCertStore(afero.NewOsFs(), s.CertDirectory, organization)
-	if err != nil {
-		return errors.Wrap(err, "failed to create certificate store.")
+	if id := api.PeerID(b.ID); id != cfg.ID {
+		if err := etcd.SavePeerID(cfg.DataDir, id); err != nil {
+			return err
+		}
+		cfg.ID = id
 	}
-	err = store.InitCA(s.ServerCert.PairName)
-	if err != nil {
-		return errors.Wrap(err, "failed to init ca.")
+
+	s.clusterCerts = true
+	s.PeerCert.CACertFile = files.CACert()
+	keyCert.CertFile = files.Cert(pki.ProfilePeer)
+	keyCert.KeyFile = files.Key(pki.ProfilePeer)
+	glog.Infof("Using peer cert issued by the cluster CA (%s, %s)", keyCert.CertFile, keyCert.KeyFile)
+	if cas, err := certutil.CertsFromFile(files.CACert()); err == nil {
+		glog.Infof("New peers trust the cluster CA with --discovery-ca-cert-hash=%s", pki.CACertHash(cas[0]))
 	}
 
-	crt, key, err := store.NewServerCertPair(host, sans)
-	if err != nil {
-		return err
+	if len(s.ServerCert.CertKey.CertFile) == 0 && len(s.ServerCert.CertKey.KeyFile) == 0 {
+		s.ServerCert.CACertFile = files.CACert()
+		s.ServerCert.CertKey.CertFile = files.Cert(pki.ProfileServer)
+		s.ServerCert.CertKey.KeyFile = files.Key(pki.ProfileServer)
 	}
-	err = store.WriteBytes("server", crt, key)
-	if err != nil {
-		return err
+	if len(s.DiscoveryClientCert.CertKey.CertFile) == 0 && len(s.DiscoveryClientCert.CertKey.KeyFile) == 0 {
+		s.DiscoveryClientCert.CACertFile = files.CACert()
+		s.DiscoveryClientCert.CertKey.CertFile = files.Cert(pki.ProfileHealthcheckClient)
+		s.DiscoveryClientCert.CertKey.KeyFile = files.Key(pki.ProfileHealthcheckClient)
 	}
-	s.ServerCert.CACertFile = store.CertFile(store.CAName())
-	s.ServerCert.CertKey.CertFile = store.CertFile("server")
-	s.ServerCert.CertKey.KeyFile = store.KeyFile("server")
+	return nil
+}
 
-	crt, key, err = store.NewClientCertPair(s.DiscoveryClientCert.PairName)
+// newAuthenticator authenticates peers and operators by their client certificates issued by
+// the CA of --peer-trusted-ca-file. The users keep the alternative names of the certificates
+// to authorize the joins of peers.
+func (s *SecureServingOptions) newAuthenticator() (authenticator.Request, error) {
+	clientCAs, err := certutil.NewPool(s.PeerCert.CACertFile)
 	if err != nil {
-		return err
-	}
-	err = store.WriteBytes(s.DiscoveryClientCert.PairName, crt, key)
-	if err != nil {
-		return err
+		return nil, fmt.Errorf("unable to load client CA file %s: %v", s.PeerCert.CACertFile, err)
 	}
-	s.DiscoveryClientCert.CertKey.CertFile = store.CertFile(s.DiscoveryClientCert.PairName)
-	s.DiscoveryClientCert.CertKey.KeyFile = store.KeyFile(s.DiscoveryClientCert.PairName)
+	verifyOpts := x509request.DefaultVerifyOptions()
+	verifyOpts.Roots = clientCAs
 
-	return nil
+	var auth authenticator.Request = group.NewAuthenticatedGroupAdder(x509request.New(verifyOpts, authz.UserConversion))
+	if !s.PeerCert.ClientCertAuth {
+		auth = union.NewFailOnError(auth, anonymous.NewAuthenticator())
+	}
+	return auth, nil
 }
 
-func (s *SecureServingOptions) ToAuthenticationConfig() (authenticatorfactory.DelegatingAuthenticatorConfig, error) {
-	ret := authenticatorfactory.DelegatingAuthenticatorConfig{
-		Anonymous:    !s.PeerCert.ClientCertAuth,
-		CacheTTL:     0,
-		ClientCAFile: s.PeerCert.CACertFile,
-	}
-	return ret, nil
+// bootstrapTokenAuthenticator authenticates peers that present the bootstrap token as
+// constants.BootstrapUser
+func bootstrapTokenAuthenticator(token string) authenticator.Token {
+	return authenticator.TokenFunc(func(t string) (user.Info, bool, error) {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) != 1 {
+			return nil, false, nil
+		}
+		return &user.DefaultInfo{
+			Name:   constants.BootstrapUser,
+			Groups: []string{constants.BootstrapGroup},
+		}, true, nil
+	})
 }
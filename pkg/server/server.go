@@ -1,12 +1,27 @@
 package server
 
 import (
+	"net/http"
+
 	"github.com/etcd-manager/etcd-discovery/apis/discovery"
 	"github.com/etcd-manager/etcd-discovery/apis/discovery/install"
 	"github.com/etcd-manager/etcd-discovery/apis/discovery/v1alpha1"
+	"github.com/etcd-manager/etcd-discovery/pkg/authz"
 	"github.com/etcd-manager/etcd-discovery/pkg/manager"
+	"github.com/etcd-manager/etcd-discovery/pkg/metrics"
+	backupstorage "github.com/etcd-manager/etcd-discovery/pkg/registry/discovery/backup"
+	certstorage "github.com/etcd-manager/etcd-discovery/pkg/registry/discovery/certificate"
+	clusterstorage "github.com/etcd-manager/etcd-discovery/pkg/registry/discovery/etcdcluster"
 	memstorage "github.com/etcd-manager/etcd-discovery/pkg/registry/discovery/member"
+	migrationstorage "github.com/etcd-manager/etcd-discovery/pkg/registry/discovery/migration"
 	pingstorage "github.com/etcd-manager/etcd-discovery/pkg/registry/discovery/ping"
+	planstorage "github.com/etcd-manager/etcd-discovery/pkg/registry/discovery/plan"
+	quarantinestorage "github.com/etcd-manager/etcd-discovery/pkg/registry/discovery/quarantine"
+	removalstorage "github.com/etcd-manager/etcd-discovery/pkg/registry/discovery/removal"
+	restorestorage "github.com/etcd-manager/etcd-discovery/pkg/registry/discovery/restore"
+	rotationstorage "github.com/etcd-manager/etcd-discovery/pkg/registry/discovery/rotation"
+	scalestorage "github.com/etcd-manager/etcd-discovery/pkg/registry/discovery/scale"
+	upgradestorage "github.com/etcd-manager/etcd-discovery/pkg/registry/discovery/upgrade"
 	"k8s.io/apimachinery/pkg/apimachinery/announced"
 	"k8s.io/apimachinery/pkg/apimachinery/registered"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -54,9 +69,43 @@ type DiscoveryServer struct {
 	Controller       *manager.EtcdManager
 }
 
+// Run starts the etcd manager and serves the discovery api until stopCh is closed.
+// On shutdown the manager stops the local etcd process first, then in-flight api
+// requests are drained. The server also stops once the certificates of the peer were
+// renewed, it returns manager.ErrRestart to be started again with them.
 func (op *DiscoveryServer) Run(stopCh <-chan struct{}) error {
-	go op.Controller.Run(stopCh)
-	return op.GenericAPIServer.PrepareRun().Run(stopCh)
+	managerStopCh := make(chan struct{})
+	managerErrCh := make(chan error, 1)
+	go func() {
+		managerErrCh <- op.Controller.Run(managerStopCh)
+	}()
+
+	err := op.GenericAPIServer.AddPreShutdownHook("stop-etcd-manager", func() error {
+		close(managerStopCh)
+		return <-managerErrCh
+	})
+	if err != nil {
+		close(managerStopCh)
+		return err
+	}
+
+	serverStopCh := make(chan struct{})
+	go func() {
+		select {
+		case <-stopCh:
+		case <-op.Controller.Restarting():
+		}
+		close(serverStopCh)
+	}()
+	if err := op.GenericAPIServer.PrepareRun().Run(serverStopCh); err != nil {
+		return err
+	}
+	select {
+	case <-op.Controller.Restarting():
+		return manager.ErrRestart
+	default:
+		return nil
+	}
 }
 
 type completedConfig struct {
@@ -71,6 +120,18 @@ type CompletedConfig struct {
 
 // Complete fills in any fields not set that are required to have valid data. It's mutating the receiver.
 func (cfg *Config) Complete() CompletedConfig {
+	// the metrics of the discovery server and the etcd manager are served with those of the apiserver
+	cfg.GenericConfig.EnableMetrics = true
+	// peers and operators are authorized by the groups of their certificates, denials are audited
+	authorizer := authz.New()
+	cfg.GenericConfig.Authorizer = authorizer
+	cfg.GenericConfig.AuditPolicyChecker = authz.NewPolicyChecker(authorizer, cfg.GenericConfig.AuditPolicyChecker)
+	// the leader issues the certificates of new peers for the address they connect from, the
+	// requests pushed by the leader and joins are authorized by their body
+	cfg.GenericConfig.BuildHandlerChainFunc = func(apiHandler http.Handler, c *genericapiserver.Config) http.Handler {
+		handler := authorizer.WithRequestBody(apiHandler, c.RequestContextMapper, c.Serializer, c.AuditBackend)
+		return genericapiserver.DefaultBuildHandlerChain(authz.WithSourceAddress(handler, c.RequestContextMapper), c)
+	}
 	c := completedConfig{
 		cfg.GenericConfig.Complete(),
 		cfg.EtcdConfig,
@@ -91,15 +152,33 @@ func (c completedConfig) New() (*DiscoveryServer, error) {
 		return nil, err
 	}
 
+	ctrl, err := c.EtcdConfig.New()
+	if err != nil {
+		return nil, err
+	}
+	metrics.Register()
+
 	s := &DiscoveryServer{
 		GenericAPIServer: genericServer,
+		Controller:       ctrl,
 	}
 
 	apiGroupInfo := genericapiserver.NewDefaultAPIGroupInfo(discovery.GroupName, registry, Scheme, metav1.ParameterCodec, Codecs)
 	apiGroupInfo.GroupMeta.GroupVersion = v1alpha1.SchemeGroupVersion
 	v1alpha1storage := map[string]rest.Storage{}
-	v1alpha1storage[v1alpha1.ResourcePluralPing] = pingstorage.NewREST(c.EtcdConfig.ID, c.EtcdConfig.AdvertiseAddress)
-	v1alpha1storage[v1alpha1.ResourcePluralMember] = memstorage.NewREST()
+	v1alpha1storage[v1alpha1.ResourcePluralPing] = pingstorage.NewREST(c.EtcdConfig.ID, c.EtcdConfig.AdvertiseAddress, ctrl.Election())
+	v1alpha1storage[v1alpha1.ResourcePluralMember] = memstorage.NewREST(ctrl)
+	v1alpha1storage[v1alpha1.ResourcePluralPlan] = planstorage.NewREST(ctrl)
+	v1alpha1storage[v1alpha1.ResourcePluralMigration] = migrationstorage.NewREST(ctrl)
+	v1alpha1storage[v1alpha1.ResourcePluralUpgrade] = upgradestorage.NewREST(ctrl)
+	v1alpha1storage[v1alpha1.ResourcePluralQuarantine] = quarantinestorage.NewREST(ctrl)
+	v1alpha1storage[v1alpha1.ResourcePluralScale] = scalestorage.NewREST(ctrl)
+	v1alpha1storage[v1alpha1.ResourcePluralCertificate] = certstorage.NewREST(ctrl)
+	v1alpha1storage[v1alpha1.ResourcePluralRotation] = rotationstorage.NewREST(ctrl)
+	v1alpha1storage[v1alpha1.ResourcePluralBackup] = backupstorage.NewREST(ctrl)
+	v1alpha1storage[v1alpha1.ResourcePluralRestore] = restorestorage.NewREST(ctrl)
+	v1alpha1storage[v1alpha1.ResourcePluralRemoval] = removalstorage.NewREST(ctrl)
+	v1alpha1storage[v1alpha1.ResourcePluralEtcdCluster] = clusterstorage.NewREST(ctrl)
 	apiGroupInfo.VersionedResourcesStorageMap[v1alpha1.SchemeGroupVersion.Version] = v1alpha1storage
 
 	if err := s.GenericAPIServer.InstallAPIGroup(&apiGroupInfo); err != nil {